@@ -2,12 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"os/signal"
+	"sletish/internal/config"
 	"sletish/internal/container"
 	"sletish/internal/handlers"
 	"sletish/internal/logger"
+	"sletish/internal/services"
+	"strings"
 	"syscall"
 	"time"
 
@@ -27,32 +31,56 @@ func main() {
 	if botToken == "" {
 		log.Fatal("BOT_TOKEN is required. Set it in .env file or as environment variable")
 	}
+	if err := services.ValidateBotToken(botToken); err != nil {
+		log.WithError(err).Fatal("BOT_TOKEN is invalid")
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	webhookPath := getWebhookPath()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// catch a revoked or mistyped token now rather than as opaque errors on the first update
+	meCtx, meCancel := context.WithTimeout(ctx, 10*time.Second)
+	me, err := services.GetMe(meCtx, botToken)
+	meCancel()
+	if errors.Is(err, services.ErrInvalidBotToken) {
+		log.WithError(err).Fatal("Telegram rejected BOT_TOKEN, check it with BotFather")
+	} else if err != nil {
+		log.WithError(err).Warn("Couldn't verify BOT_TOKEN with Telegram, continuing")
+	} else {
+		log.Infof("Authorized as @%s", me.Username)
+	}
+
 	container, err := container.New(ctx)
 	if err != nil {
 		log.WithError(err).Fatal("Failed to initialize container")
 	}
 	defer container.Close()
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/webhook", handlers.WebhookHandler(container, botToken))
+	container.StartWorkers(ctx)
 
 	server := &http.Server{
 		Addr:         ":" + port,
-		Handler:      mux,
+		Handler:      newRouter(container, botToken, webhookPath, os.Getenv("API_KEY")),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// WEBHOOK_URL is the bot's public base URL, e.g. https://bot.example.com
+	if webhookURL := os.Getenv("WEBHOOK_URL"); webhookURL != "" {
+		if err := services.SetWebhook(ctx, botToken, strings.TrimRight(webhookURL, "/")+webhookPath); err != nil {
+			log.WithError(err).Error("Failed to set webhook")
+		} else {
+			log.Info("Webhook registered")
+		}
+	}
+
 	go func() {
 		log.Infof("Bot starting on port %s", port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -71,5 +99,32 @@ func main() {
 		log.WithError(err).Error("Server forced to shutdown")
 	}
 
+	// the workers stop before the deferred Close shuts their connections
+	cancel()
+	if err := container.WaitForWorkers(sdCtx); err != nil {
+		log.WithError(err).Warn("Workers didn't stop in time")
+	}
+
 	log.Info("Server exited")
 }
+
+// getWebhookPath returns WEBHOOK_PATH, or /webhook when it's unset, always with a leading slash.
+// A hard to guess path keeps random POSTs away from the webhook.
+func getWebhookPath() string {
+	path := config.GetEnv("WEBHOOK_PATH", "/webhook")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// newRouter serves the webhook on webhookPath alongside the API, health check and landing page.
+func newRouter(container *container.Container, botToken, webhookPath, apiKey string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(webhookPath, handlers.WebhookHandler(container, botToken))
+	mux.Handle("/api/", handlers.APIHandler(container, apiKey))
+	mux.HandleFunc("/healthz", handlers.HealthHandler(container))
+	mux.HandleFunc("GET /{$}", handlers.RootHandler(container.BotName))
+	mux.HandleFunc("GET /favicon.ico", handlers.FaviconHandler())
+	return mux
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sletish/internal/container"
+	"sletish/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGetWebhookPath(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", "/webhook"},
+		{"/hook-a1b2c3", "/hook-a1b2c3"},
+		{"hook-a1b2c3", "/hook-a1b2c3"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("WEBHOOK_PATH", tt.env)
+		if got := getWebhookPath(); got != tt.want {
+			t.Errorf("WEBHOOK_PATH %q got %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+// newTestRouter returns the server's routes with the webhook on webhookPath, backed by
+// services without a database.
+func newTestRouter(webhookPath string) http.Handler {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	container := &container.Container{
+		Logger:          logger,
+		ReminderService: services.NewReminderService(nil, logger, nil, "", nil, time.Hour),
+		AiringService:   services.NewAiringService(nil, logger, "", nil, time.Hour),
+		AotdService:     services.NewAnimeOfTheDayService(nil, logger, "", nil),
+		BotName:         "Test Bot",
+	}
+	return newRouter(container, "token", webhookPath, "")
+}
+
+func TestRouterServesConfiguredWebhookPath(t *testing.T) {
+	router := newTestRouter("/hook-a1b2c3")
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		// the webhook handler rejects the body, so the path reached it
+		{"/hook-a1b2c3", http.StatusBadRequest},
+		{"/webhook", http.StatusNotFound},
+		{"/hook", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("not json")))
+		if rec.Code != tt.want {
+			t.Errorf("POST %s got %d, want %d", tt.path, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestRouterAnswersRootAndFavicon(t *testing.T) {
+	router := newTestRouter("/webhook")
+
+	tests := []struct {
+		method, path string
+		want         int
+		wantBody     string
+	}{
+		{http.MethodGet, "/", http.StatusOK, "Test Bot"},
+		{http.MethodHead, "/", http.StatusOK, ""},
+		{http.MethodGet, "/favicon.ico", http.StatusNoContent, ""},
+		{http.MethodPost, "/", http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "/wp-login.php", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s got %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+		if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+			t.Errorf("%s %s got body %q, want %q", tt.method, tt.path, rec.Body.String(), tt.wantBody)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Version is the bot's release, set at build time:
+//
+//	go build -ldflags "-X sletish/internal/bot.Version=v1.4.0" ./cmd/bot
+var Version = "dev"
+
+// startedAt is when the process started, for /about's uptime.
+var startedAt = time.Now()
+
+// handleAbout shows the bot's version, uptime and how much it tracks.
+func (h *Handler) handleAbout(ctx context.Context, cmd BotCommand) {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("<b>ℹ️ About %s</b>\n", esc(h.botName)))
+	message.WriteString(esc(h.botDescription) + "\n\n")
+	message.WriteString(fmt.Sprintf("🏷 Version: <code>%s</code>\n", esc(Version)))
+	message.WriteString(fmt.Sprintf("🐹 Go: <code>%s</code>\n", runtime.Version()))
+	message.WriteString(fmt.Sprintf("⏱ Uptime: %s\n", formatDuration(time.Since(startedAt))))
+
+	users, media, err := h.userService.TrackedCounts()
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to get tracked counts")
+	} else {
+		message.WriteString(fmt.Sprintf("\n👥 Users: %d\n", users))
+		message.WriteString(fmt.Sprintf("🎬 Anime tracked: %d\n", media))
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, message.String())
+}
+
+// formatDuration renders d as days, hours and minutes, e.g. "3d 4h 12m".
+func formatDuration(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"runtime"
+	"sletish/internal/testutil"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{59 * time.Second, "0m"},
+		{42 * time.Minute, "42m"},
+		{2*time.Hour + 5*time.Minute, "2h 5m"},
+		{3*24*time.Hour + 4*time.Hour + 12*time.Minute, "3d 4h 12m"},
+		{24 * time.Hour, "1d 0h 0m"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestAbout(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetBranding("Test <Bot>", "")
+
+	original := Version
+	Version = "v1.4.0"
+	t.Cleanup(func() { Version = original })
+
+	replies := env.run(env.handler.handleAbout, "1", "/about")
+	for _, want := range []string{
+		"<b>ℹ️ About Test &lt;Bot&gt;</b>",
+		"🏷 Version: <code>v1.4.0</code>",
+		"🐹 Go: <code>" + runtime.Version() + "</code>",
+		"⏱ Uptime: ",
+	} {
+		if !containsAny(replies, want) {
+			t.Errorf("got %q, want it to contain %q", replies, want)
+		}
+	}
+
+	// the counts are left out while the database is down
+	if containsAny(replies, "Users:") {
+		t.Errorf("got %q, want no counts without the database", replies)
+	}
+}
+
+func TestAboutCountsFromDatabase(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	env.command(t, 1, 1, "/add 5114 watching")
+	replies := env.command(t, 2, 2, "/about")
+
+	if !containsAny(replies, "👥 Users: 2\n") || !containsAny(replies, "🎬 Anime tracked: 1\n") {
+		t.Errorf("got %q, want 2 users and 1 anime", replies)
+	}
+}
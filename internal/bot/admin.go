@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// warmCacheTimeout bounds a /syncsearchcache run, Jikan allows about one request a second.
+	warmCacheTimeout = 10 * time.Minute
+	// defaultRefreshDays is how old media metadata must be for /refreshmedia to re-fetch it.
+	defaultRefreshDays = 30
+)
+
+// SetWarmQueries sets the popular searches /syncsearchcache warms when run without arguments.
+func (h *Handler) SetWarmQueries(queries []string) {
+	h.warmQueries = queries
+}
+
+// SetMediaService gives the handler the media service used by admin maintenance commands.
+func (h *Handler) SetMediaService(mediaService *services.MediaService) {
+	h.mediaService = mediaService
+}
+
+// handleMergeMedia folds a duplicate media row into another (admin only):
+// /mergemedia <keep_id> <drop_id>, both internal media IDs rather than MAL IDs.
+func (h *Handler) handleMergeMedia(ctx context.Context, cmd BotCommand) {
+	if !h.isAdmin(cmd.UserID) {
+		h.sendMessage(ctx, cmd.ChatID, "Unknown command. Use /help to see available commands")
+		return
+	}
+
+	if len(cmd.Args) != 2 {
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /mergemedia &lt;keep_id&gt; &lt;drop_id&gt;\n\nBoth are internal media IDs. Everything pointing at the second is moved to the first, then the second is deleted.")
+		return
+	}
+
+	keepID, keepErr := strconv.Atoi(cmd.Args[0])
+	dropID, dropErr := strconv.Atoi(cmd.Args[1])
+	if keepErr != nil || dropErr != nil || keepID <= 0 || dropID <= 0 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid media ID. Please use valid numeric IDs.")
+		return
+	}
+
+	if h.mediaService == nil {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Media maintenance isn't available.")
+		return
+	}
+
+	result, err := h.mediaService.MergeMedia(keepID, dropID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMergeSameMedia):
+			h.sendMessage(ctx, cmd.ChatID, "❌ The two media IDs must be different.")
+		case errors.Is(err, services.ErrMediaNotFound):
+			h.sendMessage(ctx, cmd.ChatID, "❌ One or both media IDs don't exist.")
+		default:
+			h.logger.WithError(err).Error("Failed to merge media")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Couldn't merge the media, nothing was changed.")
+		}
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf(
+		"✅ Merged media <code>%d</code> into <code>%d</code>.\n\n📋 List entries moved: %d\n🗑 Duplicate entries dropped: %d\n⏰ Reminders moved: %d\n🗑 Duplicate reminders dropped: %d\n🗂 Custom list items moved: %d\n📑 Queue items moved: %d",
+		dropID, keepID, result.UserMedia, result.Duplicates, result.Reminders, result.DuplicateReminders, result.CustomListItems, result.QueueItems,
+	))
+}
+
+// handleRefreshMedia re-fetches media metadata older than the given days (admin only):
+// /refreshmedia [days]. It runs in the background and reports back when done.
+func (h *Handler) handleRefreshMedia(ctx context.Context, cmd BotCommand) {
+	if !h.isAdmin(cmd.UserID) {
+		h.sendMessage(ctx, cmd.ChatID, "Unknown command. Use /help to see available commands")
+		return
+	}
+
+	days := defaultRefreshDays
+	if len(cmd.Args) > 0 {
+		d, err := strconv.Atoi(cmd.Args[0])
+		if err != nil || d < 0 {
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("<b>Usage:</b> /refreshmedia [days]\n\nRe-fetches media not updated in that many days (default %d).", defaultRefreshDays))
+			return
+		}
+		days = d
+	}
+
+	if h.mediaService == nil {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Media maintenance isn't available.")
+		return
+	}
+
+	statusMsgID := h.sendStatusMessage(ctx, cmd.ChatID, fmt.Sprintf("⏳ Refreshing media older than %d days...", days))
+
+	// this outlives the update's context, the rate limiter makes it slow
+	go func() {
+		result, err := h.mediaService.RefreshStale(time.Duration(days) * 24 * time.Hour)
+
+		reportCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err != nil && result.Updated == 0 {
+			h.logger.WithError(err).Error("Failed to refresh media")
+			h.updateStatusMessage(reportCtx, cmd.ChatID, statusMsgID, "❌ Couldn't refresh media: "+err.Error())
+			return
+		}
+
+		message := fmt.Sprintf("✅ Refreshed %d of %d stale media.", result.Updated, result.Stale)
+		if result.Failed > 0 {
+			message += fmt.Sprintf("\n⚠️ %d couldn't be updated.", result.Failed)
+		}
+		if err != nil {
+			message += "\n⚠️ Stopped early: " + err.Error()
+		}
+		h.updateStatusMessage(reportCtx, cmd.ChatID, statusMsgID, message)
+	}()
+}
+
+// handleSyncSearchCache pre-fetches popular searches into the cache (admin only).
+// Queries can be given comma-separated, otherwise the configured list is used.
+func (h *Handler) handleSyncSearchCache(ctx context.Context, cmd BotCommand) {
+	if !h.isAdmin(cmd.UserID) {
+		h.sendMessage(ctx, cmd.ChatID, "Unknown command. Use /help to see available commands")
+		return
+	}
+
+	queries := h.warmQueries
+	if len(cmd.Args) > 0 {
+		queries = nil
+		for _, query := range strings.Split(strings.Join(cmd.Args, " "), ",") {
+			if query = strings.TrimSpace(query); query != "" {
+				queries = append(queries, query)
+			}
+		}
+	}
+
+	if len(queries) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /syncsearchcache [query, query, ...]\n\nNo queries given and WARM_SEARCH_QUERIES isn't set.")
+		return
+	}
+
+	statusMsgID := h.sendStatusMessage(ctx, cmd.ChatID, fmt.Sprintf("⏳ Warming the search cache with %d queries...", len(queries)))
+
+	// this outlives the update's context, the rate limiter makes it slow
+	go func() {
+		warmCtx, cancelWarm := context.WithTimeout(context.Background(), warmCacheTimeout)
+		warmed, err := h.animeService.WarmCache(warmCtx, queries)
+		cancelWarm()
+
+		// the warm context may be spent by now, the report gets its own
+		reportCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err != nil && warmed == 0 {
+			h.logger.WithError(err).Error("Failed to warm search cache")
+			h.updateStatusMessage(reportCtx, cmd.ChatID, statusMsgID, "❌ Couldn't warm the search cache: "+err.Error())
+			return
+		}
+
+		message := fmt.Sprintf("✅ Warmed %d of %d search queries.", warmed, len(queries))
+		if err != nil {
+			message += "\n⚠️ Stopped early: " + err.Error()
+		}
+		h.updateStatusMessage(reportCtx, cmd.ChatID, statusMsgID, message)
+	}()
+}
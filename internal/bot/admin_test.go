@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitForMessage waits for a message containing substr, sent or edited in by a background job.
+func waitForMessage(t *testing.T, env *testEnv, substr string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !containsAny(env.telegram.messages(), substr) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %q, want a message containing %q", env.telegram.messages(), substr)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSyncSearchCacheIsAdminOnly(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetAdmins([]string{"99"}, "")
+	env.handler.SetWarmQueries([]string{"naruto"})
+
+	if replies := env.run(env.handler.handleSyncSearchCache, "1", "/syncsearchcache"); !containsAny(replies, "Unknown command") {
+		t.Errorf("got %q, want it hidden from non-admins", replies)
+	}
+}
+
+func TestSyncSearchCacheNeedsQueries(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetAdmins([]string{"99"}, "")
+
+	if replies := env.run(env.handler.handleSyncSearchCache, "99", "/syncsearchcache"); !containsAny(replies, "WARM_SEARCH_QUERIES isn't set") {
+		t.Errorf("got %q, want the usage", replies)
+	}
+}
+
+func TestSyncSearchCacheReportsWarmed(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetAdmins([]string{"99"}, "")
+	env.handler.SetWarmQueries([]string{"naruto", "bleach"})
+
+	// the configured queries by default
+	env.run(env.handler.handleSyncSearchCache, "99", "/syncsearchcache")
+	waitForMessage(t, env, "✅ Warmed 2 of 2 search queries.")
+
+	// or the ones given, comma separated
+	env.run(env.handler.handleSyncSearchCache, "99", "/syncsearchcache one piece, , frieren, spy x family")
+	waitForMessage(t, env, "✅ Warmed 3 of 3 search queries.")
+
+	// the fake counts every query as warmed, then reports the error as stopping early
+	env.anime.err = errors.New("jikan down")
+	env.run(env.handler.handleSyncSearchCache, "99", "/syncsearchcache naruto")
+	waitForMessage(t, env, "✅ Warmed 1 of 1 search queries.\n⚠️ Stopped early: jikan down")
+}
+
+func TestMergeMediaValidation(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetAdmins([]string{"99"}, "")
+
+	tests := []struct {
+		userID, text string
+		want         string
+	}{
+		{"1", "/mergemedia 1 2", "Unknown command"},
+		{"99", "/mergemedia 1", "Usage:</b> /mergemedia"},
+		{"99", "/mergemedia 1 two", "Invalid media ID"},
+		{"99", "/mergemedia 0 2", "Invalid media ID"},
+		{"99", "/mergemedia 1 2", "isn't available"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(env.handler.handleMergeMedia, tt.userID, tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%s %q got %q, want %q", tt.userID, tt.text, replies, tt.want)
+		}
+	}
+}
+
+func TestRefreshMediaValidation(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetAdmins([]string{"99"}, "")
+
+	tests := []struct {
+		userID, text string
+		want         string
+	}{
+		{"1", "/refreshmedia", "Unknown command"},
+		{"99", "/refreshmedia soon", "Usage:</b> /refreshmedia [days]"},
+		{"99", "/refreshmedia -1", "Usage:</b> /refreshmedia [days]"},
+		{"99", "/refreshmedia", "isn't available"},
+		{"99", "/refreshmedia 0", "isn't available"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(env.handler.handleRefreshMedia, tt.userID, tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%s %q got %q, want %q", tt.userID, tt.text, replies, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"testing"
+)
+
+// everyCallback has a payload for every action handleCallbackQuery dispatches, and
+// one it doesn't know.
+var everyCallback = []models.CallbackData{
+	{Action: "add_anime", AnimeID: "5114", Status: "watching"},
+	{Action: "update_status", AnimeID: "5114", Status: "completed"},
+	{Action: "remove_anime", AnimeID: "5114"},
+	{Action: "view_details", AnimeID: "5114"},
+	{Action: "list_page", Page: 2, Limit: 10, Total: 30, Status: "watching"},
+	{Action: "cancel_reminder", AnimeID: "1"},
+	{Action: "full_synopsis", AnimeID: "5114"},
+	{Action: "confirm_remove", AnimeID: "5114"},
+	{Action: "bulk_status", Status: "watchlist", Target: "watching", Page: 1, Limit: 10},
+	{Action: "confirm_bulk_status", Status: "watchlist", Target: "watching", Query: "1,2"},
+	{Action: "history_search", Query: "fullmetal"},
+	{Action: "cancel_pending"},
+	{Action: "wizard_pick", AnimeID: "5114"},
+	{Action: "char_page", AnimeID: "5114", Page: 1},
+	{Action: "ep_page", AnimeID: "5114", Page: 1},
+	{Action: "toggle_setting", Query: "nsfw"},
+	{Action: "no_such_action"},
+}
+
+// checkOneAnswerPerCallback presses a button for every callback action as userID in
+// chatID and checks each press got exactly one answer.
+func checkOneAnswerPerCallback(t *testing.T, env *testEnv, userID, chatID int) {
+	t.Helper()
+
+	for i, payload := range everyCallback {
+		t.Run(payload.Action, func(t *testing.T) {
+			id := fmt.Sprintf("cb-%d-%d", chatID, i)
+			env.press(userID, chatID, id, env.callbackData(t, payload))
+
+			if answers := env.telegram.answersFor(id); len(answers) != 1 {
+				t.Errorf("got %d answers %+v, want exactly 1", len(answers), answers)
+			}
+		})
+	}
+}
+
+func TestEveryCallbackIsAnsweredOnce(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.anime.results = []models.AnimeData{fmab}
+
+	// the database is down, so these mostly take the error paths
+	checkOneAnswerPerCallback(t, env, 42, 42)
+}
+
+func TestEveryCallbackIsAnsweredOnceInSharedGroup(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.mredis.Set("chat:mode:-1001", string(models.ChatModeShared))
+
+	checkOneAnswerPerCallback(t, env, 42, testGroupChat)
+}
+
+func TestEveryCallbackIsAnsweredOnceWithDatabase(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.anime.results = []models.AnimeData{fmab}
+	env.command(t, 42, 42, "/add 5114 watchlist")
+
+	checkOneAnswerPerCallback(t, env, 42, 42)
+}
+
+func TestCallbackRequestsAreAnsweredOnce(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"noop", "noop"},
+		{"expired", "t:doesnotexist"},
+		{"inline json", `{"a":"add_anime"}`},
+		{"empty", ""},
+	}
+
+	env := newTestEnv(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := "cb-" + tt.name
+			env.press(42, 42, id, tt.data)
+
+			if answers := env.telegram.answersFor(id); len(answers) != 1 {
+				t.Errorf("got %d answers %+v, want exactly 1", len(answers), answers)
+			}
+		})
+	}
+}
+
+func TestEnsureCallbackAnswered(t *testing.T) {
+	t.Run("unanswered gets an empty answer", func(t *testing.T) {
+		env := newTestEnv(t)
+		ctx, answer := withCallbackAnswer(context.Background(), "cb-1")
+
+		env.handler.ensureCallbackAnswered(ctx, answer)
+
+		answers := env.telegram.answersFor("cb-1")
+		if len(answers) != 1 || answers[0].Text != "" {
+			t.Errorf("got answers %+v, want a single empty one", answers)
+		}
+	})
+
+	t.Run("answered isn't answered again", func(t *testing.T) {
+		env := newTestEnv(t)
+		ctx, answer := withCallbackAnswer(context.Background(), "cb-1")
+
+		env.handler.answerCallback(ctx, "cb-1", "✅ Done", false)
+		env.handler.answerCallback(ctx, "cb-1", "❌ Failed", false)
+		env.handler.ensureCallbackAnswered(ctx, answer)
+
+		answers := env.telegram.answersFor("cb-1")
+		if len(answers) != 1 || answers[0].Text != "✅ Done" {
+			t.Errorf("got answers %+v, want only the first", answers)
+		}
+	})
+}
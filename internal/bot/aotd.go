@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/services"
+	"strings"
+)
+
+// handleAotd shows or changes the anime of the day subscription: /aotd [on|off] [timezone]
+func (h *Handler) handleAotd(ctx context.Context, cmd BotCommand) {
+	usage := "<b>Usage:</b> /aotd on|off [timezone]\n\n<b>Example:</b> /aotd on Europe/Berlin"
+
+	if len(cmd.Args) == 0 {
+		settings, err := h.userService.GetSettings(cmd.UserID)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to get settings for anime of the day check")
+			h.sendMessage(ctx, cmd.ChatID, usage)
+			return
+		}
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("🌅 Anime of the day is currently <b>%s</b> (time zone: <code>%s</code>).\n\n%s",
+			onOff(settings.AotdEnabled), esc(settings.Timezone), usage))
+		return
+	}
+
+	var enabled bool
+	switch strings.ToLower(cmd.Args[0]) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		h.sendMessage(ctx, cmd.ChatID, usage)
+		return
+	}
+
+	var timezone string
+	if len(cmd.Args) > 1 {
+		timezone = cmd.Args[1]
+	}
+
+	if err := h.userService.SetAnimeOfTheDay(cmd.UserID, enabled, timezone); err != nil {
+		if errors.Is(err, services.ErrInvalidTimezone) {
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ Unknown time zone <code>%s</code>. Use a name like <code>Europe/Berlin</code> or <code>America/New_York</code>.", esc(timezone)))
+			return
+		}
+		h.logger.WithError(err).Error("Failed to update anime of the day setting")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update your setting. Please try again later.")
+		return
+	}
+
+	if !enabled {
+		h.sendMessage(ctx, cmd.ChatID, "✅ Anime of the day turned off.")
+		return
+	}
+
+	message := "✅ Anime of the day turned on! You'll get a suggestion every morning."
+	if timezone == "" {
+		message += "\n\n<i>Times are in your saved time zone (UTC unless you set one). Add it to change it, e.g. /aotd on Asia/Tokyo</i>"
+	}
+	h.sendMessage(ctx, cmd.ChatID, message)
+}
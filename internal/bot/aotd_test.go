@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"sletish/internal/testutil"
+	"testing"
+)
+
+func TestAotdRejectsBadArgs(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleAotd, "1", "/aotd maybe"); !containsAny(replies, "Usage:</b> /aotd on|off") {
+		t.Errorf("got %q, want the usage", replies)
+	}
+}
+
+func TestAotdSubscribe(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+
+	if replies := env.command(t, 1, 1, "/aotd on Mars/Olympus"); !containsAny(replies, "Unknown time zone <code>Mars/Olympus</code>") {
+		t.Errorf("got %q, want the time zone rejected", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/aotd on Asia/Tokyo"); !containsAny(replies, "Anime of the day turned on") {
+		t.Errorf("got %q, want it turned on", replies)
+	}
+	if replies := env.command(t, 1, 1, "/aotd"); !containsAny(replies, "currently <b>on</b> (time zone: <code>Asia/Tokyo</code>)") {
+		t.Errorf("got %q, want it on in Tokyo time", replies)
+	}
+
+	env.command(t, 1, 1, "/aotd off")
+	if replies := env.command(t, 1, 1, "/aotd"); !containsAny(replies, "currently <b>off</b>") {
+		t.Errorf("got %q, want it off", replies)
+	}
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/services"
+	"strconv"
+)
+
+// listArchivedKeyword is accepted by /list to show entries archived by /remove.
+// It travels in the list filter's status slot, so paging works like any status.
+const listArchivedKeyword = "archived"
+
+// handleUndo restores the anime the user removed most recently.
+func (h *Handler) handleUndo(ctx context.Context, cmd BotCommand) {
+	if !h.userService.SoftDelete() {
+		h.sendMessage(ctx, cmd.ChatID, "ℹ️ Removed anime aren't kept, so there's nothing to undo.")
+		return
+	}
+
+	media, err := h.userService.UndoRemoval(cmd.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNothingToUndo):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("ℹ️ Nothing to undo. Removed anime are kept for %d days.", services.ArchiveRetentionDays))
+		case errors.Is(err, services.ErrListFull):
+			h.sendMessage(ctx, cmd.ChatID, "❌ "+h.listFullMessage())
+		default:
+			h.logger.WithError(err).Error("Failed to undo removal")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't restore the anime. Please try again later.")
+		}
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("↩️ Restored <b>%s</b> (ID: %s) to your list.", esc(media.Title), media.ExternalID))
+}
+
+// handleRestore brings back a specific archived anime: /restore <anime_id>
+func (h *Handler) handleRestore(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) != 1 {
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /restore &lt;anime_id&gt;
+
+See what can be restored with /trash`)
+		return
+	}
+
+	animeID, err := strconv.Atoi(cmd.Args[0])
+	if err != nil || animeID <= 0 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please provide a valid number.")
+		return
+	}
+
+	entry, err := h.userService.RestoreEntry(cmd.UserID, animeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotArchived):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ Anime not found in your archive. Removed anime are kept for %d days, see /trash.", services.ArchiveRetentionDays))
+		case errors.Is(err, services.ErrListFull):
+			h.sendMessage(ctx, cmd.ChatID, "❌ "+h.listFullMessage())
+		default:
+			h.logger.WithError(err).Error("Failed to restore entry")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't restore the anime. Please try again later.")
+		}
+		return
+	}
+
+	message := fmt.Sprintf("↩️ Restored <b>%s</b> to your list as %s %s.",
+		esc(entry.Media.Title), getStatusEmoji(entry.UserMedia.Status), entry.UserMedia.Status)
+	if entry.UserMedia.Rating > 0 {
+		message += fmt.Sprintf("\n⭐ Your rating: %.1f", entry.UserMedia.Rating)
+	}
+	h.sendMessage(ctx, cmd.ChatID, message)
+}
+
+// handleTrash lists archived entries: /trash [page]
+func (h *Handler) handleTrash(ctx context.Context, cmd BotCommand) {
+	page := 1
+	if len(cmd.Args) > 0 {
+		if p, err := strconv.Atoi(cmd.Args[0]); err == nil && p > 0 {
+			page = p
+		}
+	}
+	h.showArchived(ctx, cmd, page, 5)
+}
+
+// handleListArchived shows the user's archived entries: /list archived [page]
+func (h *Handler) handleListArchived(ctx context.Context, cmd BotCommand, limit int) {
+	page := 1
+	if len(cmd.Args) > 1 {
+		if p, err := strconv.Atoi(cmd.Args[1]); err == nil && p > 0 {
+			page = p
+		}
+	}
+	h.showArchived(ctx, cmd, page, limit)
+}
+
+func (h *Handler) showArchived(ctx context.Context, cmd BotCommand, page, limit int) {
+	userList, total, err := h.userService.GetArchivedList(cmd.UserID, page, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get archived list")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your list. Please try again later.")
+		return
+	}
+
+	if len(userList) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "🗑 Your trash is empty. Anime you /remove show up here.")
+		return
+	}
+
+	filter := listFilter{Status: listArchivedKeyword}
+	message := h.formatUserList(userList, filter, page, total, limit)
+	keyboard := h.createPaginationKeyboard(ctx, page, limit, total, filter)
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
+}
+
+// removedMessage confirms a removal, pointing at /undo when the entry was archived.
+func (h *Handler) removedMessage() string {
+	if h.userService.SoftDelete() {
+		return "✅ Removed anime from your list. Changed your mind? Use /undo."
+	}
+	return "✅ Successfully removed anime from your list."
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"sletish/internal/testutil"
+	"testing"
+)
+
+func TestUndoWithoutSoftDelete(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleUndo, "1", "/undo"); !containsAny(replies, "nothing to undo") {
+		t.Errorf("got %q, want nothing to undo", replies)
+	}
+}
+
+func TestArchivedHiddenFromListUntilUndone(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.users.SetSoftDelete(true)
+
+	env.command(t, 1, 1, "/add 5114 watching")
+	if err := env.users.RemoveFromUserList("1", 5114); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+
+	if replies := env.command(t, 1, 1, "/list"); containsAny(replies, fmab.Title) {
+		t.Errorf("/list got %q, want the archived entry hidden", replies)
+	}
+	if replies := env.command(t, 1, 1, "/list archived"); !containsAny(replies, "🗄 Your Archived Anime") || !containsAny(replies, fmab.Title) {
+		t.Errorf("/list archived got %q, want the archived entry", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/undo"); !containsAny(replies, "↩️ Restored <b>"+fmab.Title+"</b>") {
+		t.Errorf("/undo got %q, want the entry restored", replies)
+	}
+	if replies := env.command(t, 1, 1, "/list"); !containsAny(replies, fmab.Title) {
+		t.Errorf("/list got %q, want the restored entry", replies)
+	}
+	if replies := env.command(t, 1, 1, "/list archived"); !containsAny(replies, "trash is empty") {
+		t.Errorf("/list archived got %q, want it empty", replies)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.users.SetSoftDelete(true)
+
+	env.command(t, 1, 1, "/add 5114 completed 9")
+	env.users.RemoveFromUserList("1", 5114)
+
+	if replies := env.command(t, 1, 1, "/trash"); !containsAny(replies, fmab.Title) {
+		t.Errorf("/trash got %q, want the removed entry", replies)
+	}
+	if replies := env.command(t, 1, 1, "/restore 5114"); !containsAny(replies, "as ✅ completed") || !containsAny(replies, "⭐ Your rating: 9.0") {
+		t.Errorf("/restore got %q, want it back completed with its rating", replies)
+	}
+	if replies := env.command(t, 1, 1, "/restore 5114"); !containsAny(replies, "not found in your archive") {
+		t.Errorf("second /restore got %q, want it no longer archived", replies)
+	}
+}
+
+func TestRestoreValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	for text, want := range map[string]string{
+		"/restore":       "Usage:</b> /restore",
+		"/restore abc":   "Invalid anime ID",
+		"/restore 1 2":   "Usage:</b> /restore",
+		"/restore -5114": "Invalid anime ID",
+	} {
+		if replies := env.run(env.handler.handleRestore, "1", text); !containsAny(replies, want) {
+			t.Errorf("%q got %q, want %q", text, replies, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"strings"
+)
+
+// bulkTarget is the status a single-status /list page can be moved to in one go.
+// Nothing is offered on the completed list itself.
+const bulkTarget = models.StatusCompleted
+
+// bulkStatusRow returns the "mark all shown" button for a single-status list page, or nil.
+func (h *Handler) bulkStatusRow(ctx context.Context, page, limit int, filter listFilter) []models.InlineKeyboardButton {
+	from := models.Status(filter.Status)
+	if !isValidStatus(from) || from == bulkTarget {
+		return nil
+	}
+
+	data := h.encodeCallbackData(ctx, models.CallbackData{
+		Action: "bulk_status",
+		Status: filter.Status,
+		Target: string(bulkTarget),
+		Page:   page,
+		Limit:  limit,
+	})
+	return []models.InlineKeyboardButton{
+		{Text: fmt.Sprintf("%s Mark all shown as %s", getStatusEmoji(bulkTarget), bulkTarget), CallbackData: data},
+	}
+}
+
+// handleCallbackBulkStatus asks to confirm moving the entries on a list page to another status.
+// The entries are fixed now, so what's confirmed is what gets moved.
+func (h *Handler) handleCallbackBulkStatus(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	from, to := models.Status(data.Status), models.Status(data.Target)
+	if !isValidStatus(from) || !isValidStatus(to) || from == to || data.Page < 1 || data.Limit < 1 {
+		h.answerCallback(ctx, callback.Id, "❌ Invalid data", false)
+		return
+	}
+
+	userList, _, err := h.userService.GetUserList(userID, data.Status, data.Page, data.Limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get list for bulk status")
+		h.answerCallback(ctx, callback.Id, "❌ Failed to get list.", true)
+		return
+	}
+	if len(userList) == 0 {
+		h.answerCallback(ctx, callback.Id, "Nothing to update, the list has changed.", true)
+		return
+	}
+
+	ids := make([]string, 0, len(userList))
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("⚠️ Move these %d anime from %s to %s?\n\n", len(userList), from, to))
+	for _, item := range userList {
+		ids = append(ids, item.Media.ExternalID)
+		message.WriteString(fmt.Sprintf("• %s\n", esc(item.Media.Title)))
+	}
+
+	pending := models.PendingAction{
+		Type: models.PendingBulkStatus,
+		Data: map[string]string{"from": string(from), "to": string(to), "ids": strings.Join(ids, ",")},
+	}
+	if err := h.pendingStore.Set(callbackPendingKey(callback, chatID), pending); err != nil {
+		h.logger.WithError(err).Error("Failed to store pending bulk status")
+		h.answerCallback(ctx, callback.Id, "❌ Error processing request", true)
+		return
+	}
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: "✅ Yes, move them", CallbackData: h.createCallbackData(ctx, "confirm_bulk_status", "", "")},
+				{Text: "✖️ Cancel", CallbackData: h.createCallbackData(ctx, "cancel_pending", "", "")},
+			},
+		},
+	}
+	h.answerCallback(ctx, callback.Id, "", false)
+	h.sendMessageWithKeyboard(ctx, chatID, message.String(), keyboard)
+}
+
+// handleCallbackConfirmBulkStatus moves the entries once the user confirms,
+// as long as the bulk update is still the user's pending action.
+func (h *Handler) handleCallbackConfirmBulkStatus(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	key := callbackPendingKey(callback, chatID)
+	pending, err := h.pendingStore.Get(key)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get pending action")
+		h.answerCallback(ctx, callback.Id, "❌ Error processing request", true)
+		return
+	}
+
+	if pending == nil || pending.Type != models.PendingBulkStatus {
+		h.answerCallback(ctx, callback.Id, "⌛ This confirmation has expired", true)
+		h.editMessage(ctx, chatID, callback.Message.MessageId, "⌛ Confirmation expired. Open the list again to retry.", nil)
+		return
+	}
+
+	if _, err := h.pendingStore.Clear(key); err != nil {
+		h.logger.WithError(err).Warn("Failed to clear pending action")
+	}
+
+	from, to := models.Status(pending.Data["from"]), models.Status(pending.Data["to"])
+	moved, err := h.userService.MoveStatus(userID, from, to, strings.Split(pending.Data["ids"], ","))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to move status")
+		h.answerCallback(ctx, callback.Id, "❌ Failed to update status", true)
+		return
+	}
+
+	h.answerCallback(ctx, callback.Id, "", false)
+	h.editMessage(ctx, chatID, callback.Message.MessageId,
+		fmt.Sprintf("%s Moved %d anime to %s.", getStatusEmoji(to), moved, to), nil)
+}
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"testing"
+)
+
+func TestBulkStatusRow(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	for _, status := range []string{"", "completed", "nonsense"} {
+		if row := env.handler.bulkStatusRow(ctx, 1, 10, listFilter{Status: status}); row != nil {
+			t.Errorf("filter %q got %+v, want no bulk button", status, row)
+		}
+	}
+
+	row := env.handler.bulkStatusRow(ctx, 2, 10, listFilter{Status: "watchlist"})
+	if len(row) != 1 {
+		t.Fatalf("got %+v, want one bulk button", row)
+	}
+	data, err := env.handler.decodeCallbackData(ctx, row[0].CallbackData)
+	if err != nil {
+		t.Fatalf("failed to decode callback data: %v", err)
+	}
+	want := models.CallbackData{Action: "bulk_status", Status: "watchlist", Target: "completed", Page: 2, Limit: 10}
+	if *data != want {
+		t.Errorf("got %+v, want %+v", *data, want)
+	}
+}
+
+func TestBulkStatusRejectsInvalidStatuses(t *testing.T) {
+	// bad paging is turned away when the callback data is decoded
+	tests := []models.CallbackData{
+		{Action: "bulk_status", Status: "nonsense", Target: "completed", Page: 1, Limit: 10},
+		{Action: "bulk_status", Status: "watchlist", Target: "nonsense", Page: 1, Limit: 10},
+		{Action: "bulk_status", Status: "watchlist", Target: "watchlist", Page: 1, Limit: 10},
+	}
+
+	for _, payload := range tests {
+		env := newTestEnv(t)
+		env.press(1, 1, "cb-1", env.callbackData(t, payload))
+
+		if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "❌ Invalid data" {
+			t.Errorf("%+v got answers %+v, want invalid data", payload, answers)
+		}
+		if pending, _ := env.handler.pendingStore.Get("1"); pending != nil {
+			t.Errorf("%+v left pending action %+v", payload, pending)
+		}
+	}
+}
+
+func TestConfirmBulkStatusWithoutPending(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.press(1, 1, "cb-1", env.callbackData(t, models.CallbackData{Action: "confirm_bulk_status"}))
+
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "⌛ This confirmation has expired" {
+		t.Errorf("got answers %+v, want the confirmation expired", answers)
+	}
+	if len(env.telegram.edits) != 1 || env.telegram.edits[0].Text != "⌛ Confirmation expired. Open the list again to retry." {
+		t.Errorf("got edits %+v, want the expired message", env.telegram.edits)
+	}
+}
+
+// startBulkStatus presses the bulk button for user 1's first watchlist page and
+// returns the confirm and cancel buttons' data.
+func startBulkStatus(t *testing.T, env *testEnv) (confirm, cancel string) {
+	t.Helper()
+
+	data := env.callbackData(t, models.CallbackData{Action: "bulk_status", Status: "watchlist", Target: "completed", Page: 1, Limit: 10})
+	env.press(1, 1, "cb-bulk", data)
+
+	sent := env.telegram.lastSent(t)
+	if sent.Keyboard == nil || len(sent.Keyboard.InlineKeyboard) != 1 || len(sent.Keyboard.InlineKeyboard[0]) != 2 {
+		t.Fatalf("got %q with keyboard %+v, want the confirm and cancel buttons", sent.Text, sent.Keyboard)
+	}
+	row := sent.Keyboard.InlineKeyboard[0]
+	return row[0].CallbackData, row[1].CallbackData
+}
+
+var (
+	steinsGate  = models.AnimeData{MalID: 9253, Title: "Steins;Gate", Type: "TV"}
+	steinsGate0 = models.AnimeData{MalID: 30484, Title: "Steins;Gate 0", Type: "TV"}
+)
+
+func TestBulkStatusConfirmMovesFilteredEntries(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab, steinsGate, steinsGate0)
+	env.command(t, 1, 1, "/add 5114 watchlist")
+	env.command(t, 1, 1, "/add 9253 watching")
+
+	confirm, _ := startBulkStatus(t, env)
+	if sent := env.telegram.lastSent(t); sent.Text != "⚠️ Move these 1 anime from watchlist to completed?\n\n• "+fmab.Title+"\n" {
+		t.Errorf("got %q, want only the watchlist entry listed", sent.Text)
+	}
+
+	// added to the watchlist after the button was pressed, so not part of what was confirmed
+	env.command(t, 1, 1, "/add 30484 watchlist")
+
+	env.press(1, 1, "cb-confirm", confirm)
+	if len(env.telegram.edits) != 1 || env.telegram.edits[0].Text != "✅ Moved 1 anime to completed." {
+		t.Errorf("got edits %+v, want 1 moved", env.telegram.edits)
+	}
+
+	want := map[string]models.Status{"5114": models.StatusCompleted, "9253": models.StatusWatching, "30484": models.StatusWatchlist}
+	entries, _, err := env.users.GetUserList("1", "", 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get list: %v", err)
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for _, entry := range entries {
+		if entry.UserMedia.Status != want[entry.Media.ExternalID] {
+			t.Errorf("%s got status %q, want %q", entry.Media.ExternalID, entry.UserMedia.Status, want[entry.Media.ExternalID])
+		}
+	}
+
+	// confirming twice does nothing more
+	env.press(1, 1, "cb-again", confirm)
+	if answers := env.telegram.answersFor("cb-again"); len(answers) != 1 || answers[0].Text != "⌛ This confirmation has expired" {
+		t.Errorf("got answers %+v, want the confirmation expired", answers)
+	}
+}
+
+func TestBulkStatusCancelLeavesEntries(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.command(t, 1, 1, "/add 5114 watchlist")
+
+	confirm, cancel := startBulkStatus(t, env)
+	env.press(1, 1, "cb-cancel", cancel)
+	env.press(1, 1, "cb-confirm", confirm)
+
+	if answers := env.telegram.answersFor("cb-confirm"); len(answers) != 1 || answers[0].Text != "⌛ This confirmation has expired" {
+		t.Errorf("got answers %+v, want the confirmation expired after cancelling", answers)
+	}
+
+	entries, _, err := env.users.GetUserList("1", "watchlist", 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get list: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d watchlist entries, want the entry left alone", len(entries))
+	}
+}
+
+func TestBulkStatusOnEmptyPage(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+	env.command(t, 1, 1, "/start")
+
+	data := env.callbackData(t, models.CallbackData{Action: "bulk_status", Status: "watchlist", Target: "completed", Page: 1, Limit: 10})
+	env.press(1, 1, "cb-1", data)
+
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "Nothing to update, the list has changed." {
+		t.Errorf("got answers %+v, want nothing to update", answers)
+	}
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"testing"
+)
+
+func TestEncodeDecodeCallbackDataRoundTrip(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	want := models.CallbackData{Action: "list_page", Status: "watching", Page: 2, Limit: 5, Total: 12, Tag: "favourites"}
+	raw := env.handler.encodeCallbackData(ctx, want)
+	if len(raw) > 64 {
+		t.Fatalf("callback_data is %d bytes, Telegram allows 64", len(raw))
+	}
+
+	got, err := env.handler.decodeCallbackData(ctx, raw)
+	if err != nil {
+		t.Fatalf("decodeCallbackData: %v", err)
+	}
+	if *got != want {
+		t.Errorf("decoded %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecodeCallbackDataRejectsInlineJSON(t *testing.T) {
+	env := newTestEnv(t)
+
+	_, err := env.handler.decodeCallbackData(context.Background(), `{"a":"list_page","p":1,"l":5}`)
+	if !errors.Is(err, services.ErrCallbackExpired) {
+		t.Errorf("inline JSON decoded with %v, want ErrCallbackExpired", err)
+	}
+}
+
+func TestDecodeCallbackDataValidatesPaging(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    models.CallbackData
+		wantErr bool
+	}{
+		{"list page", models.CallbackData{Action: "list_page", Page: 1, Limit: 5}, false},
+		{"list page zero", models.CallbackData{Action: "list_page", Page: 0, Limit: 5}, true},
+		{"list limit zero", models.CallbackData{Action: "list_page", Page: 1, Limit: 0}, true},
+		{"list limit too big", models.CallbackData{Action: "list_page", Page: 1, Limit: maxListPageLimit + 1}, true},
+		{"bulk page zero", models.CallbackData{Action: "bulk_status", Page: 0, Limit: 5}, true},
+		{"negative page", models.CallbackData{Action: "char_page", Page: -1}, true},
+		{"character page zero opens the view", models.CallbackData{Action: "char_page", Page: 0}, false},
+		{"no action", models.CallbackData{Page: 1}, true},
+	}
+
+	env := newTestEnv(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := env.callbackData(t, tt.data)
+			_, err := env.handler.decodeCallbackData(context.Background(), raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeCallbackData error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestListPageWithZeroPageIsRejected(t *testing.T) {
+	env := newTestEnv(t)
+
+	raw := env.callbackData(t, models.CallbackData{Action: "list_page", Page: 0, Limit: 0})
+	env.press(42, 42, "cb-1", raw)
+
+	answers := env.telegram.answersFor("cb-1")
+	if len(answers) != 1 || answers[0].Text != "❌ Error processing request" {
+		t.Errorf("answers = %+v, want a single error answer", answers)
+	}
+	if len(env.telegram.edits) != 0 {
+		t.Error("an invalid list page still edited the message")
+	}
+}
+
+func TestExpiredButtonIsAnswered(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.press(42, 42, "cb-1", "t:doesnotexist")
+
+	answers := env.telegram.answersFor("cb-1")
+	if len(answers) != 1 || !answers[0].ShowAlert {
+		t.Errorf("answers = %+v, want a single expiry alert", answers)
+	}
+}
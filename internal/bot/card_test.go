@@ -0,0 +1,16 @@
+//go:build !card
+
+package bot
+
+import "testing"
+
+func TestCardUnavailableWithoutTag(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleCard, "1", "/card"); !containsAny(replies, "Stats cards aren't available right now") {
+		t.Errorf("got %q, want the card reported unavailable", replies)
+	}
+	if len(env.telegram.photos) != 0 {
+		t.Errorf("sent %d photos, want none", len(env.telegram.photos))
+	}
+}
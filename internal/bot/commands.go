@@ -2,41 +2,104 @@ package bot
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sletish/internal/models"
 	"sletish/internal/services"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 )
 
 type BotCommand struct {
-	Command string
-	Args    []string
-	UserID  string
-	ChatID  string
+	Command  string
+	Args     []string
+	UserID   string // whose list the command acts on, the chat's in a shared group chat
+	ChatID   string
+	SenderID string // who sent the command
 }
 
+// pendingKey is where the sender's pending action in this chat is kept.
+func (c BotCommand) pendingKey() string {
+	return pendingKey(c.SenderID, c.ChatID)
+}
+
+const (
+	maxNoteLength          = 500  // characters (runes)
+	detailsSynopsisLength  = 1000 // longer synopses get a "Full synopsis" button
+	titleMatchLimit        = 5    // candidates offered when a title is ambiguous
+	titleMatchButtonLen    = 40
+	searchSynopsisLength   = 200 // characters (runes) of synopsis in normal search results
+	detailedSynopsisLength = 600
+	maxEpisodesWatched     = 10000
+	defaultListGroupLimit  = 10
+	maxListPageLimit       = 50 // largest list page size accepted from a button
+	DefaultBotName         = "Anime Tracker Bot"
+	DefaultBotDescription  = "I can help you search for anime and manage your personal anime list."
+)
+
+// animeServiceDownMessage is shown while Jikan is down and requests fail fast.
+const animeServiceDownMessage = "⚠️ The anime database is temporarily unavailable. Please try again in a minute."
+
 type Handler struct {
-	animeService    *services.Client
+	animeService    services.AnimeProvider
 	userService     *services.UserService
 	reminderService *services.ReminderService
+	callbackStore   *services.CallbackStore
+	pendingStore    *services.PendingActionStore
+	feedbackService *services.FeedbackService
 	logger          *logrus.Logger
-	botToken        string
+	telegram        services.TelegramClient
+	adminIDs        map[string]bool
+	adminChatID     string
+	warmQueries     []string
+	listGroupLimit  int // items shown per status in the grouped /list view, 0 for all
+	mediaService    *services.MediaService
+	botName         string
+	botDescription  string
+	importing       sync.Map // user IDs with an AniList import running
 	// UPDATE WITH MORE SERVICES ADDED IN THE FUTURE
 }
 
-func NewHandler(animeService *services.Client, userService *services.UserService, reminderService *services.ReminderService, logger *logrus.Logger, botToken string) *Handler {
+func NewHandler(animeService services.AnimeProvider, userService *services.UserService, reminderService *services.ReminderService, callbackStore *services.CallbackStore, pendingStore *services.PendingActionStore, feedbackService *services.FeedbackService, logger *logrus.Logger, telegram services.TelegramClient) *Handler {
 	return &Handler{
 		animeService:    animeService,
 		userService:     userService,
 		reminderService: reminderService,
+		callbackStore:   callbackStore,
+		pendingStore:    pendingStore,
+		feedbackService: feedbackService,
 		logger:          logger,
-		botToken:        botToken,
+		telegram:        telegram,
+		listGroupLimit:  defaultListGroupLimit,
+		botName:         DefaultBotName,
+		botDescription:  DefaultBotDescription,
+	}
+}
+
+// SetBranding sets the name and description shown in /start, /help and /about.
+// Empty values keep the defaults.
+func (h *Handler) SetBranding(name, description string) {
+	if name != "" {
+		h.botName = name
 	}
+	if description != "" {
+		h.botDescription = description
+	}
+}
+
+// SetListGroupLimit sets how many items each status shows in the grouped /list view.
+// Zero or less shows every item.
+func (h *Handler) SetListGroupLimit(limit int) {
+	h.listGroupLimit = max(limit, 0)
 }
 
 func (h *Handler) ProcessMessage(ctx context.Context, update *models.Update) {
@@ -46,8 +109,30 @@ func (h *Handler) ProcessMessage(ctx context.Context, update *models.Update) {
 		return
 	}
 
-	// Handle regular messages
+	// Updates we don't handle (my_chat_member, channel_post, edited_message, ...)
+	// arrive without a message or callback query
+	if update.Message == nil {
+		h.logger.WithField("update_id", update.UpdateId).Debug("Ignoring unsupported update")
+		return
+	}
+
+	// No sender means it was posted as a channel, there's no user to act for
+	if update.Message.From == nil {
+		h.logger.WithField("update_id", update.UpdateId).Debug("Ignoring message without a sender")
+		return
+	}
+
+	if update.Message.From.Id <= 0 {
+		h.logger.WithFields(logrus.Fields{
+			"update_id": update.UpdateId,
+			"from_id":   update.Message.From.Id,
+		}).Warn("Ignoring message with invalid sender ID")
+		return
+	}
+
+	// Handle non-text messages (files, photos, stickers, voice)
 	if update.Message.Text == "" {
+		h.handleNonTextMessage(ctx, update.Message)
 		return
 	}
 
@@ -56,15 +141,37 @@ func (h *Handler) ProcessMessage(ctx context.Context, update *models.Update) {
 	chatID := strconv.Itoa(update.Message.Chat.Id)
 
 	// Ensure user exists with proper error handling
-	if err := h.userService.EnsureUserExists(userID, username); err != nil {
+	if err := h.userService.EnsureUserExists(userID, username, update.Message.From.LanguageCode); err != nil {
 		h.logger.WithError(err).Error("failed to ensure user exists")
 		h.sendMessage(ctx, chatID, "Sorry, I'm having trouble accessing your account. Please try again.")
 		return
 	}
 
 	text := strings.TrimSpace(update.Message.Text)
+
+	// Plain text (not a command) is an answer to the sender's wizard step
+	if !strings.HasPrefix(text, "/") {
+		h.handlePlainText(ctx, userID, chatID, text)
+		return
+	}
+
 	command := h.parseCommand(text, userID, chatID)
 
+	// In a shared group chat list commands act on the chat's list
+	if sharedListCommands[command.Command] {
+		listOwner, err := h.userService.ListOwner(userID, chatID)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to get list owner")
+			h.sendMessage(ctx, chatID, "❌ Sorry, I couldn't load this chat's settings. Please try again.")
+			return
+		}
+		command.UserID = listOwner
+	}
+
+	if command.Command != "/add" {
+		h.abandonWizard(command.pendingKey())
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"user_id": userID,
 		"command": command.Command,
@@ -78,34 +185,146 @@ func (h *Handler) ProcessMessage(ctx context.Context, update *models.Update) {
 		h.handleSearch(ctx, command)
 	case "/profile":
 		h.handleProfile(ctx, command)
+	case "/info":
+		h.handleInfo(ctx, command)
+	case "/queue":
+		h.handleQueue(ctx, command)
+	case "/stats":
+		h.handleStats(ctx, command)
+	case "/export":
+		h.handleExport(ctx, command)
+	case "/importanilist":
+		h.handleImportAniList(ctx, command)
+	case "/card":
+		h.handleCard(ctx, command)
 	case "/add":
 		h.handleAdd(ctx, command)
 	case "/remove":
 		h.handleRemove(ctx, command)
+	case "/undo":
+		h.handleUndo(ctx, command)
+	case "/restore":
+		h.handleRestore(ctx, command)
+	case "/trash":
+		h.handleTrash(ctx, command)
 	case "/list":
 		h.handleList(ctx, command)
+	case "/mylists":
+		h.handleMyLists(ctx, command)
+	case "/newlist":
+		h.handleNewList(ctx, command)
+	case "/addto":
+		h.handleAddTo(ctx, command)
+	case "/showlist":
+		h.handleShowList(ctx, command)
 	case "/update":
 		h.handleUpdate(ctx, command)
+	case "/progress":
+		h.handleProgress(ctx, command)
+	case "/tag":
+		h.handleTag(ctx, command)
+	case "/untag":
+		h.handleUntag(ctx, command)
 	case "/help":
 		h.handleHelp(ctx, command)
+	case "/ping":
+		h.handlePing(ctx, command)
+	case "/about":
+		h.handleAbout(ctx, command)
+	case "/whoami":
+		h.handleWhoami(ctx, command)
 	case "/remind":
 		h.handleRemind(ctx, command)
+	case "/remindair":
+		h.handleRemindAir(ctx, command)
+	case "/pause":
+		h.handlePause(ctx, command)
+	case "/resume":
+		h.handleResume(ctx, command)
 	case "/reminders":
 		h.handleReminders(ctx, command)
+	case "/settings":
+		h.handleSettings(ctx, command)
+	case "/groupmode":
+		h.handleGroupMode(ctx, command)
+	case "/verbosity":
+		h.handleVerbosity(ctx, command)
+	case "/silent":
+		h.handleSilent(ctx, command)
+	case "/nsfw":
+		h.handleNSFW(ctx, command)
+	case "/public":
+		h.handlePublic(ctx, command)
+	case "/leaderboard":
+		h.handleLeaderboard(ctx, command)
+	case "/aotd":
+		h.handleAotd(ctx, command)
+	case "/again":
+		h.handleAgain(ctx, command)
+	case "/history":
+		h.handleHistory(ctx, command)
+	case "/trend":
+		h.handleTrend(ctx, command)
+	case "/recommend":
+		h.handleRecommend(ctx, command)
+	case "/similar":
+		h.handleSimilar(ctx, command)
+	case "/top":
+		h.handleTop(ctx, command)
+	case "/recent":
+		h.handleRecent(ctx, command)
+	case "/cancel":
+		h.handleCancel(ctx, command)
+	case "/feedback":
+		h.handleFeedback(ctx, command)
+	case "/mergemedia":
+		h.handleMergeMedia(ctx, command)
+	case "/refreshmedia":
+		h.handleRefreshMedia(ctx, command)
+	case "/syncsearchcache":
+		h.handleSyncSearchCache(ctx, command)
+	case "/feedbacklist":
+		h.handleFeedbackList(ctx, command)
 	default:
 		h.sendMessage(ctx, command.ChatID, "Unknown command. Use /help to see available commands")
 	}
 }
 
+// handleNonTextMessage replies to messages without text. Documents get their own
+// route since they're the only kind we may want to read (e.g. list imports).
+func (h *Handler) handleNonTextMessage(ctx context.Context, message *models.Message) {
+	chatID := strconv.Itoa(message.Chat.Id)
+
+	switch {
+	case message.Document != nil:
+		h.handleDocument(ctx, chatID, message.Document)
+	case len(message.Photo) > 0, message.Sticker != nil, message.Voice != nil:
+		h.sendMessage(ctx, chatID, "🙈 I can only read text for now. Send /help to see what I can do!")
+	}
+}
+
+// handleDocument handles files sent to the bot.
+func (h *Handler) handleDocument(ctx context.Context, chatID string, document *models.Document) {
+	h.logger.WithFields(logrus.Fields{
+		"chat_id":   chatID,
+		"file_name": document.FileName,
+		"mime_type": document.MimeType,
+	}).Info("Received document")
+
+	// NOTE: no importer yet, route here once list imports exist
+	h.sendMessage(ctx, chatID, "📄 Thanks, but I can't import files yet. Use /add to add anime to your list.")
+}
+
 func (h *Handler) handleRemind(ctx context.Context, cmd BotCommand) {
 	if len(cmd.Args) < 3 {
-		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /remind &lt;anime_id&gt; &lt;days&gt; &lt;message&gt;
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /remind &lt;anime_id&gt; &lt;when&gt; &lt;message&gt;
 
 			<b>Examples:</b>
 			• /remind 5114 7 "Check if new episode is out!"
-			• /remind 16498 30 "Time to rewatch this masterpiece"
+			• /remind 5114 12h "Episode drops tonight"
+			• /remind 16498 0.5 "Half a day from now"
 
-			<b>Note:</b> Days IS 1-365`)
+			<b>Note:</b> &lt;when&gt; is days (decimals allowed) or a duration like 30m, 12h or 3d, up to 365 days`)
 		return
 	}
 
@@ -115,23 +334,28 @@ func (h *Handler) handleRemind(ctx context.Context, cmd BotCommand) {
 		return
 	}
 
-	days, err := strconv.Atoi(cmd.Args[1])
-	if err != nil || days < 1 || days > 365 {
-		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid number of days. Please use 1-365 days.")
+	delay, err := parseReminderDelay(cmd.Args[1])
+	if err != nil {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid time. Use days like <code>7</code> or <code>0.5</code>, or a duration like <code>30m</code>, <code>12h</code> or <code>3d</code>, from 1 minute up to 365 days.")
 		return
 	}
 
 	message := strings.Join(cmd.Args[2:], " ")
-	if len(message) > 200 {
+	if utf8.RuneCountInString(message) > 200 {
 		h.sendMessage(ctx, cmd.ChatID, "❌ Message too long. Please keep it under 200 characters.")
 		return
 	}
 
 	h.sendMessage(ctx, cmd.ChatID, "⏳ Setting up your reminder...")
 
-	remindAt := time.Now().AddDate(0, 0, days)
+	remindAt := time.Now().Add(delay)
 
 	if err := h.reminderService.CreateReminder(cmd.UserID, animeID, message, remindAt); err != nil {
+		if errors.Is(err, services.ErrDuplicateReminder) {
+			h.sendMessage(ctx, cmd.ChatID, "ℹ️ You already have a reminder for this anime then. See /reminders.")
+			return
+		}
+
 		h.logger.WithError(err).Error("Failed to create reminder")
 
 		if strings.Contains(err.Error(), "does not exist") {
@@ -144,7 +368,43 @@ func (h *Handler) handleRemind(ctx context.Context, cmd BotCommand) {
 	}
 
 	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Reminder set! I'll remind you on <b>%s</b> with message: \"%s\"",
-		remindAt.Format("January 2, 2006 at 3:04 PM"), message))
+		remindAt.Format("January 2, 2006 at 3:04 PM"), esc(message)))
+}
+
+// Bounds for how far ahead /remind can be set
+const (
+	minReminderDelay = time.Minute
+	maxReminderDelay = 365 * 24 * time.Hour
+)
+
+// parseReminderDelay parses /remind's time argument: a number of days, decimals allowed
+// ("7", "0.5"), a number with a d suffix ("3d"), or a Go duration ("30m", "12h", "1h30m").
+func parseReminderDelay(arg string) (time.Duration, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+
+	var delay time.Duration
+	if days, ok := strings.CutSuffix(arg, "d"); ok || !strings.ContainsAny(arg, "hms") {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return 0, fmt.Errorf("invalid reminder time %q", arg)
+		}
+		// checked before converting, a huge day count would overflow the duration
+		if n*24 > maxReminderDelay.Hours() {
+			return 0, fmt.Errorf("reminder time %q is over %v", arg, maxReminderDelay)
+		}
+		delay = time.Duration(n * float64(24*time.Hour))
+	} else {
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return 0, fmt.Errorf("invalid reminder time %q: %w", arg, err)
+		}
+		delay = d
+	}
+
+	if delay < minReminderDelay || delay > maxReminderDelay {
+		return 0, fmt.Errorf("reminder time %q is out of range", arg)
+	}
+	return delay, nil
 }
 
 func (h *Handler) handleReminders(ctx context.Context, cmd BotCommand) {
@@ -170,10 +430,39 @@ func (h *Handler) handleReminders(ctx context.Context, cmd BotCommand) {
 	}
 
 	message := h.formatReminders(reminders, showAll)
-	keyboard := h.createRemindersKeyboard(reminders)
+	keyboard := h.createRemindersKeyboard(ctx, reminders)
 	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
 }
 
+// reminderGroup is one anime's reminders, in the order they were listed.
+type reminderGroup struct {
+	title     string
+	reminders []models.Reminder
+}
+
+// groupRemindersByAnime groups reminders by anime, keeping the order in which each
+// anime first appears so the soonest reminders still come first.
+func groupRemindersByAnime(reminders []models.Reminder) []reminderGroup {
+	var groups []reminderGroup
+	index := make(map[int]int) // media ID -> position in groups
+
+	for _, reminder := range reminders {
+		pos, ok := index[reminder.MediaID]
+		if !ok {
+			title := reminder.MediaTitle
+			if title == "" {
+				title = fmt.Sprintf("Anime ID: %d", reminder.MediaID)
+			}
+			pos = len(groups)
+			index[reminder.MediaID] = pos
+			groups = append(groups, reminderGroup{title: title})
+		}
+		groups[pos].reminders = append(groups[pos].reminders, reminder)
+	}
+
+	return groups
+}
+
 func (h *Handler) formatReminders(reminders []models.Reminder, showAll bool) string {
 	var message strings.Builder
 
@@ -186,54 +475,83 @@ func (h *Handler) formatReminders(reminders []models.Reminder, showAll bool) str
 	now := time.Now()
 	pending := 0
 	sent := 0
+	failed := 0
+
+	// count everything, not just what fits on screen
+	for _, reminder := range reminders {
+		switch {
+		case reminder.Sent:
+			sent++
+		case reminder.Failed:
+			failed++
+		default:
+			pending++
+		}
+	}
 
-	for i, reminder := range reminders {
-		if i >= 10 { // Limit display to 10 reminders
-			message.WriteString(fmt.Sprintf("... and %d more reminders\n", len(reminders)-10))
+	shown := 0
+	for _, group := range groupRemindersByAnime(reminders) {
+		if shown >= 10 { // Limit display to 10 reminders
 			break
 		}
 
-		status := "📅"
-		statusText := "Pending"
-		timeText := reminder.RemindAt.Format("Jan 2, 2006 3:04 PM")
+		message.WriteString(fmt.Sprintf("🎬 <b>%s</b>\n", esc(group.title)))
 
-		if reminder.Sent {
-			status = "✅"
-			statusText = "Sent"
-			sent++
-		} else {
-			pending++
-			if reminder.RemindAt.Before(now) {
+		for _, reminder := range group.reminders {
+			if shown >= 10 {
+				break
+			}
+			shown++
+
+			status := "📅"
+			statusText := "Pending"
+			timeText := reminder.RemindAt.Format("Jan 2, 2006 3:04 PM")
+
+			if reminder.Sent {
+				status = "✅"
+				statusText = "Sent"
+			} else if reminder.Failed {
+				status = "⚠️"
+				statusText = "Failed"
+			} else if reminder.RemindAt.Before(now) {
 				status = "🔔"
 				statusText = "Due"
 			}
-		}
 
-		message.WriteString(fmt.Sprintf("%s <b>%s</b> - %s\n", status, statusText, timeText))
-
-		if reminder.MediaTitle != "" {
-			message.WriteString(fmt.Sprintf("   🎬 <i>%s</i>\n", reminder.MediaTitle))
-		} else {
-			message.WriteString(fmt.Sprintf("   🆔 Anime ID: %d\n", reminder.MediaID))
+			message.WriteString(fmt.Sprintf("   %s <b>%s</b> - %s\n", status, statusText, timeText))
+			message.WriteString(fmt.Sprintf("      💬 \"%s\"\n", esc(reminder.Message)))
+			if reminder.Failed {
+				message.WriteString(fmt.Sprintf("      ⚠️ <i>Couldn't be delivered after %d attempt(s)</i>\n", reminder.FailureCount))
+			}
+			message.WriteString(fmt.Sprintf("      📅 Created: %s\n", reminder.CreatedAt.Format("Jan 2, 2006")))
 		}
+		message.WriteString("\n")
+	}
 
-		message.WriteString(fmt.Sprintf("   💬 \"%s\"\n", reminder.Message))
-		message.WriteString(fmt.Sprintf("   📅 Created: %s\n\n", reminder.CreatedAt.Format("Jan 2, 2006")))
+	if len(reminders) > shown {
+		message.WriteString(fmt.Sprintf("... and %d more reminders\n\n", len(reminders)-shown))
 	}
 
 	// Summary
 	message.WriteString(fmt.Sprintf("<b>📊 Summary:</b>\n"))
 	if !showAll {
 		message.WriteString(fmt.Sprintf("📅 Pending: %d\n", pending))
+		if failed > 0 {
+			message.WriteString(fmt.Sprintf("⚠️ Failed: %d\n", failed))
+		}
 		message.WriteString(fmt.Sprintf("\n💡 <i>Use /reminders all to see all reminders</i>"))
 	} else {
-		message.WriteString(fmt.Sprintf("📅 Pending: %d | ✅ Sent: %d\n", pending, sent))
+		message.WriteString(fmt.Sprintf("📅 Pending: %d | ✅ Sent: %d", pending, sent))
+		if failed > 0 {
+			message.WriteString(fmt.Sprintf(" | ⚠️ Failed: %d", failed))
+		}
+		message.WriteString("\n")
 	}
 
 	return message.String()
 }
 
-func (h *Handler) createRemindersKeyboard(reminders []models.Reminder) *models.InlineKeyboardMarkup {
+func (h *Handler) createRemindersKeyboard(ctx context.Context, reminders []models.Reminder) *models.InlineKeyboardMarkup {
 	var rows [][]models.InlineKeyboardButton
 
 	// Show first few pending reminders with cancel option
@@ -244,21 +562,13 @@ func (h *Handler) createRemindersKeyboard(reminders []models.Reminder) *models.I
 			if title == "" {
 				title = fmt.Sprintf("Anime ID: %d", reminder.MediaID)
 			}
-			if len(title) > 25 {
-				title = title[:25] + "..."
-			}
-
-			// Create callback data manually since we need reminder ID, not anime ID
-			callbackData := models.CallbackData{
-				Action:  "cancel_reminder",
-				AnimeID: strconv.Itoa(reminder.ID),
-			}
-			jsonData, _ := json.Marshal(callbackData)
+			title = services.TruncateText(title, 25)
 
+			// reminder ID goes in the AnimeID slot, not anime ID
 			cancelRow := []models.InlineKeyboardButton{
 				{
 					Text:         fmt.Sprintf("🗑 Cancel: %s", title),
-					CallbackData: string(jsonData),
+					CallbackData: h.createCallbackData(ctx, "cancel_reminder", strconv.Itoa(reminder.ID), ""),
 				},
 			}
 			rows = append(rows, cancelRow)
@@ -278,29 +588,78 @@ func (h *Handler) handleCallbackQuery(ctx context.Context, callback *models.Call
 		"data":        callback.Data,
 	}).Info("Processing callback query")
 
-	var callbackData models.CallbackData
-	if err := json.Unmarshal([]byte(callback.Data), &callbackData); err != nil {
+	ctx, answer := withCallbackAnswer(ctx, callback.Id)
+	defer h.ensureCallbackAnswered(ctx, answer)
+
+	// page info buttons don't do anything
+	if callback.Data == "noop" {
+		h.answerCallback(ctx, callback.Id, "", false)
+		return
+	}
+
+	callbackData, err := h.decodeCallbackData(ctx, callback.Data)
+	if err != nil {
+		if errors.Is(err, services.ErrCallbackExpired) {
+			h.answerCallback(ctx, callback.Id, "⌛ This button has expired. Please run the command again.", true)
+			return
+		}
 		h.logger.WithError(err).Error("Failed to parse callback data")
 		h.answerCallback(ctx, callback.Id, "❌ Error processing request", false)
 		return
 	}
 
+	if callback.From.Id <= 0 {
+		h.logger.WithField("from_id", callback.From.Id).Warn("Ignoring callback with invalid sender ID")
+		h.answerCallback(ctx, callback.Id, "", false)
+		return
+	}
+
 	userID := strconv.Itoa(callback.From.Id)
 	chatID := strconv.Itoa(callback.Message.Chat.Id)
 
+	if sharedListActions[callbackData.Action] {
+		listOwner, err := h.userService.ListOwner(userID, chatID)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to get list owner")
+			h.answerCallback(ctx, callback.Id, "❌ Error processing request", false)
+			return
+		}
+		userID = listOwner
+	}
+
 	switch callbackData.Action {
 	case "add_anime":
-		h.handleCallbackAddAnime(ctx, callback, &callbackData, userID, chatID)
+		h.handleCallbackAddAnime(ctx, callback, callbackData, userID, chatID)
 	case "update_status":
-		h.handleCallbackUpdateStatus(ctx, callback, &callbackData, userID, chatID)
+		h.handleCallbackUpdateStatus(ctx, callback, callbackData, userID, chatID)
 	case "remove_anime":
-		h.handleCallbackRemoveAnime(ctx, callback, &callbackData, userID, chatID)
+		h.handleCallbackRemoveAnime(ctx, callback, callbackData, userID, chatID)
 	case "view_details":
-		h.handleCallbackViewDetails(ctx, callback, &callbackData, userID, chatID)
+		h.handleCallbackViewDetails(ctx, callback, callbackData, userID, chatID)
 	case "list_page":
-		h.handleCallbackListPage(ctx, callback, &callbackData, userID, chatID)
+		h.handleCallbackListPage(ctx, callback, callbackData, userID, chatID)
 	case "cancel_reminder":
-		h.handleCallbackCancelReminder(ctx, callback, &callbackData, userID, chatID)
+		h.handleCallbackCancelReminder(ctx, callback, callbackData, userID, chatID)
+	case "full_synopsis":
+		h.handleCallbackFullSynopsis(ctx, callback, callbackData, userID, chatID)
+	case "confirm_remove":
+		h.handleCallbackConfirmRemove(ctx, callback, callbackData, userID, chatID)
+	case "bulk_status":
+		h.handleCallbackBulkStatus(ctx, callback, callbackData, userID, chatID)
+	case "confirm_bulk_status":
+		h.handleCallbackConfirmBulkStatus(ctx, callback, callbackData, userID, chatID)
+	case "history_search":
+		h.handleCallbackHistorySearch(ctx, callback, callbackData, userID, chatID)
+	case "cancel_pending":
+		h.handleCallbackCancelPending(ctx, callback, callbackData, userID, chatID)
+	case "wizard_pick":
+		h.handleCallbackWizardPick(ctx, callback, callbackData, userID, chatID)
+	case "char_page":
+		h.handleCallbackCharacterPage(ctx, callback, callbackData, userID, chatID)
+	case "ep_page":
+		h.handleCallbackEpisodePage(ctx, callback, callbackData, userID, chatID)
+	case "toggle_setting":
+		h.handleCallbackToggleSetting(ctx, callback, callbackData, userID, chatID)
 
 	default:
 		h.answerCallback(ctx, callback.Id, "❌ Unknown action", false)
@@ -354,9 +713,11 @@ func (h *Handler) handleCallbackAddAnime(ctx context.Context, callback *models.C
 		return
 	}
 
-	if err := h.userService.AddToUserList(userID, animeID, status); err != nil {
+	if err := h.userService.AddToUserList(userID, animeID, status, nil, nil); err != nil {
 		h.logger.WithError(err).Error("Failed to add anime via callback")
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, services.ErrListFull) {
+			h.answerCallback(ctx, callback.Id, h.listFullMessage(), true)
+		} else if strings.Contains(err.Error(), "not found") {
 			h.answerCallback(ctx, callback.Id, "❌ Anime not found", true)
 		} else {
 			h.answerCallback(ctx, callback.Id, "❌ Failed to add anime", true)
@@ -424,6 +785,10 @@ func (h *Handler) handleCallbackRemoveAnime(ctx context.Context, callback *model
 		return
 	}
 
+	if h.userService.SoftDelete() {
+		h.answerCallback(ctx, callback.Id, "✅ Anime removed from your list! Use /undo to bring it back.", false)
+		return
+	}
 	h.answerCallback(ctx, callback.Id, "✅ Anime removed from your list!", false)
 }
 
@@ -446,16 +811,92 @@ func (h *Handler) handleCallbackViewDetails(ctx context.Context, callback *model
 		return
 	}
 
-	detailsMessage := h.formatAnimeDetails(*anime)
-	keyboard := h.createAnimeDetailsKeyboard(data.AnimeID)
+	if anime.IsAdult() && !h.showsNSFW(userID) {
+		h.answerCallback(ctx, callback.Id, "🔞 This title is hidden by your content filter. Use /nsfw on to show it.", true)
+		return
+	}
+
+	detailsMessage, keyboard := h.detailsView(ctx, userID, anime)
 
+	h.answerCallback(ctx, callback.Id, "", false)
 	h.editMessage(ctx, chatID, callback.Message.MessageId, detailsMessage, keyboard)
+}
+
+// detailsView renders an anime's details with buttons for the user's relation to it.
+func (h *Handler) detailsView(ctx context.Context, userID string, anime *models.AnimeData) (string, *models.InlineKeyboardMarkup) {
+	// buttons depend on whether the anime is already in the list; on error fall back to the add buttons
+	var entry *models.UserMedia
+	var status models.Status
+	listEntry, err := h.userService.GetUserEntry(ctx, userID, anime.MalID)
+	switch {
+	case err == nil:
+		entry, status = &listEntry.UserMedia, listEntry.UserMedia.Status
+	case !errors.Is(err, services.ErrNotInUserList):
+		h.logger.WithError(err).Warn("Failed to get list entry for details")
+	}
+
+	detailsMessage := h.formatAnimeDetails(*anime, entry)
+	if entry != nil {
+		detailsMessage += fmt.Sprintf("\n%s <i>In your list as %s</i>", getStatusEmoji(status), status)
+	}
+	keyboard := h.createAnimeDetailsKeyboard(ctx, strconv.Itoa(anime.MalID), status, len([]rune(anime.Synopsis)) > detailsSynopsisLength)
+
+	return detailsMessage, keyboard
+}
+
+// handleCallbackFullSynopsis sends the complete synopsis, split across messages if needed.
+func (h *Handler) handleCallbackFullSynopsis(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	animeID, err := strconv.Atoi(data.AnimeID)
+	if err != nil {
+		h.answerCallback(ctx, callback.Id, "❌ Invalid anime ID", false)
+		return
+	}
+
+	title, synopsis, err := h.getFullSynopsis(animeID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get anime for full synopsis")
+		h.answerCallback(ctx, callback.Id, "❌ Failed to get synopsis", true)
+		return
+	}
+
+	if synopsis == "" {
+		h.answerCallback(ctx, callback.Id, "No synopsis available", true)
+		return
+	}
+
 	h.answerCallback(ctx, callback.Id, "", false)
+
+	header := fmt.Sprintf("📖 <b>%s</b>\n\n", esc(title))
+	chunks := splitMessage(synopsis, maxMessageLength-len([]rune(header)))
+	for i, chunk := range chunks {
+		chunk = esc(chunk)
+		if i == 0 {
+			chunk = header + chunk
+		}
+		h.sendMessage(ctx, chatID, chunk)
+	}
+}
+
+// getFullSynopsis returns the title and synopsis, preferring the stored media description
+// over a Jikan fetch. Rows saved before descriptions were kept whole end in "..." and are re-fetched.
+func (h *Handler) getFullSynopsis(animeID int) (string, string, error) {
+	media, err := h.userService.GetMediaByAnimeID(animeID)
+	if err == nil && media.Description != "" && !strings.HasSuffix(media.Description, "...") {
+		return media.Title, media.Description, nil
+	}
+
+	anime, err := h.animeService.GetAnimeByID(animeID)
+	if err != nil {
+		return "", "", err
+	}
+	return anime.Title, anime.Synopsis, nil
 }
 
 // handleCallbackListPage processes pagination button clicks for the user's list.
 func (h *Handler) handleCallbackListPage(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
-	userList, total, err := h.userService.GetUserList(userID, data.Status, data.Page, data.Limit)
+	filter := listFilter{Status: data.Status, Genre: data.Genre, Tag: data.Tag, Rating: data.Rating}
+
+	userList, total, err := h.getFilteredList(userID, filter, data.Page, data.Limit)
 	if err != nil {
 		h.answerCallback(ctx, callback.Id, "❌ Failed to get list.", true)
 		return
@@ -466,33 +907,30 @@ func (h *Handler) handleCallbackListPage(ctx context.Context, callback *models.C
 		return
 	}
 
-	message := h.formatUserList(userList, data.Status, data.Page, total, data.Limit)
-	keyboard := h.createPaginationKeyboard(data.Page, data.Limit, total, data.Status)
+	message := h.formatUserList(userList, filter, data.Page, total, data.Limit)
+	keyboard := h.createPaginationKeyboard(ctx, data.Page, data.Limit, total, filter)
 
-	h.editMessage(ctx, chatID, callback.Message.MessageId, message, keyboard)
 	h.answerCallback(ctx, callback.Id, "", false)
+	h.editMessage(ctx, chatID, callback.Message.MessageId, message, keyboard)
 }
 
 func (h *Handler) parseCommand(text, userID, chatID string) BotCommand {
 	parts := strings.Fields(text)
 	if len(parts) == 0 {
-		return BotCommand{UserID: userID, ChatID: chatID}
+		return BotCommand{UserID: userID, ChatID: chatID, SenderID: userID}
 	}
 
 	return BotCommand{
-		Command: parts[0],
-		Args:    parts[1:],
-		UserID:  userID,
-		ChatID:  chatID,
+		Command:  parts[0],
+		Args:     parts[1:],
+		UserID:   userID,
+		ChatID:   chatID,
+		SenderID: userID,
 	}
 }
 
 func (h *Handler) handleStart(ctx context.Context, cmd BotCommand) {
-	welcomeMessage := `<b>Welcome to Anime Tracker Bot!</b>
-
-I can help you search for anime and manage your personal anime list.
-
-<b>Available Commands:</b>
+	welcomeMessage := fmt.Sprintf("<b>Welcome to %s!</b>\n\n%s\n\n", esc(h.botName), esc(h.botDescription)) + `<b>Available Commands:</b>
 • /search &lt;anime_name&gt; - Search for anime
 • /add &lt;anime_id&gt; &lt;status&gt; - Add anime to your list
 • /list [status] - View your anime list
@@ -510,12 +948,66 @@ Get started by searching for an anime with /search!`
 		"chat_id": cmd.ChatID,
 	}).Info("Sending start message")
 
+	// /start after unblocking the bot, notifications can resume
+	if err := h.userService.SetBlocked(cmd.UserID, false); err != nil {
+		h.logger.WithError(err).Warn("Failed to clear blocked flag")
+	}
+
 	h.sendMessage(ctx, cmd.ChatID, welcomeMessage)
 }
 
 func (h *Handler) handleProfile(ctx context.Context, cmd BotCommand) {
-	user, err := h.userService.GetUser(cmd.UserID)
-	if err != nil {
+	var (
+		user             *models.AppUser
+		statusCounts     map[models.Status]int
+		episodes, titles int
+		streak           int
+	)
+
+	// the profile needs the user; the stats are extras, so their errors are only logged.
+	// A failed user fetch cancels the group so the extras that haven't started are skipped.
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		user, err = h.userService.GetUser(cmd.UserID)
+		return err
+	})
+	g.Go(func() error {
+		if gctx.Err() != nil {
+			return nil
+		}
+		counts, err := h.userService.StatusCounts(cmd.UserID)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to get status counts")
+			return nil
+		}
+		statusCounts = counts
+		return nil
+	})
+	g.Go(func() error {
+		if gctx.Err() != nil {
+			return nil
+		}
+		var err error
+		if episodes, titles, err = h.userService.TotalEpisodesWatched(cmd.UserID); err != nil {
+			h.logger.WithError(err).Warn("Failed to get episodes watched")
+			episodes = 0
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if gctx.Err() != nil {
+			return nil
+		}
+		var err error
+		if streak, err = h.userService.ActivityStreak(cmd.UserID); err != nil {
+			h.logger.WithError(err).Warn("Failed to get activity streak")
+			streak = 0
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
 		h.logger.WithFields(logrus.Fields{
 			"user_id": cmd.UserID,
 			"error":   err.Error(),
@@ -525,11 +1017,16 @@ func (h *Handler) handleProfile(ctx context.Context, cmd BotCommand) {
 		return
 	}
 
+	h.sendMessage(ctx, cmd.ChatID, formatProfile(user, statusCounts, episodes, titles, streak))
+}
+
+// formatProfile builds the /profile message from the separately fetched pieces.
+func formatProfile(user *models.AppUser, statusCounts map[models.Status]int, episodes, titles, streak int) string {
 	profileMessage := "<b>📋 Your Profile:</b>\n\n"
 	profileMessage += "🆔 User ID: " + user.ID + "\n"
 
 	if user.Username != nil && *user.Username != "" {
-		profileMessage += "👤 Username: @" + *user.Username + "\n"
+		profileMessage += "👤 Username: @" + esc(*user.Username) + "\n"
 	}
 
 	profileMessage += "📱 Platform: " + user.Platform + "\n"
@@ -539,169 +1036,683 @@ func (h *Handler) handleProfile(ctx context.Context, cmd BotCommand) {
 		profileMessage += "🔄 Last updated: " + user.UpdatedAt.Format("January 2, 2006") + "\n"
 	}
 
-	// Get user's anime stats
-	allList, _, err := h.userService.GetUserList(cmd.UserID, "", 1, 1000) // Get all items for stats (later implementation)
-	if err == nil {
-		statusCounts := make(map[models.Status]int)
-		for _, item := range allList {
-			statusCounts[item.UserMedia.Status]++
+	if len(statusCounts) > 0 {
+		profileMessage += "\n<b>📊 Your Stats:</b>\n"
+		if count := statusCounts[models.StatusWatching]; count > 0 {
+			profileMessage += fmt.Sprintf("👀 Watching: %d\n", count)
+		}
+		if count := statusCounts[models.StatusCompleted]; count > 0 {
+			profileMessage += fmt.Sprintf("✅ Completed: %d\n", count)
+		}
+		if count := statusCounts[models.StatusWatchlist]; count > 0 {
+			profileMessage += fmt.Sprintf("📝 Watchlist: %d\n", count)
 		}
+		if count := statusCounts[models.StatusOnHold]; count > 0 {
+			profileMessage += fmt.Sprintf("⏸ On Hold: %d\n", count)
+		}
+		if count := statusCounts[models.StatusDropped]; count > 0 {
+			profileMessage += fmt.Sprintf("❌ Dropped: %d\n", count)
+		}
+	}
 
-		if len(statusCounts) > 0 {
-			profileMessage += "\n<b>📊 Your Stats:</b>\n"
-			if count := statusCounts[models.StatusWatching]; count > 0 {
-				profileMessage += fmt.Sprintf("👀 Watching: %d\n", count)
-			}
-			if count := statusCounts[models.StatusCompleted]; count > 0 {
-				profileMessage += fmt.Sprintf("✅ Completed: %d\n", count)
-			}
-			if count := statusCounts[models.StatusWatchlist]; count > 0 {
-				profileMessage += fmt.Sprintf("📝 Watchlist: %d\n", count)
-			}
-			if count := statusCounts[models.StatusOnHold]; count > 0 {
-				profileMessage += fmt.Sprintf("⏸ On Hold: %d\n", count)
-			}
-			if count := statusCounts[models.StatusDropped]; count > 0 {
-				profileMessage += fmt.Sprintf("❌ Dropped: %d\n", count)
-			}
+	if episodes > 0 {
+		profileMessage += fmt.Sprintf("\n📺 Episodes watched: %d across %d %s\n", episodes, titles, pluralize(titles, "title", "titles"))
+	}
+
+	if streak > 0 {
+		days := "days"
+		if streak == 1 {
+			days = "day"
 		}
+		profileMessage += fmt.Sprintf("\n🔥 Streak: %d %s in a row\n", streak, days)
 	}
 
-	h.sendMessage(ctx, cmd.ChatID, profileMessage)
+	return profileMessage
 }
 
 func (h *Handler) handleSearch(ctx context.Context, cmd BotCommand) {
-	if len(cmd.Args) == 0 {
+	filters, args, err := parseSearchFlags(cmd.Args)
+	if err != nil {
+		h.sendMessage(ctx, cmd.ChatID, "❌ "+err.Error())
+		return
+	}
+
+	if len(args) == 0 {
 		h.sendMessage(ctx, cmd.ChatID, "Please provide an anime name to search. Example: /search Naruto")
 		return
 	}
 
-	query := strings.Join(cmd.Args, " ")
+	query := services.SanitizeSearchQuery(strings.Join(args, " "))
+	filters.SFW = !h.showsNSFW(cmd.UserID)
 
 	// Input validation
-	if len(query) > 100 {
+	if query == "" {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Your search doesn't contain any searchable text. Try a title, e.g. /search Naruto")
+		return
+	}
+	if utf8.RuneCountInString(query) > 100 {
 		h.sendMessage(ctx, cmd.ChatID, "Search query is too long. Please keep it under 100 characters.")
 		return
 	}
 
-	h.sendMessage(ctx, cmd.ChatID, "🔎 Searching for anime...")
-
-	searchResult, err := h.animeService.SearchAnime(query)
-	if err != nil {
-		h.logger.WithFields(logrus.Fields{
-			"query":   query,
-			"user_id": cmd.UserID,
-			"error":   err.Error(),
-		}).Error("Failed to search anime")
+	// a new search replaces the one /again would repeat, even if it fails
+	if err := h.userService.ClearLastSearch(cmd.UserID); err != nil {
+		h.logger.WithError(err).Warn("Failed to clear last search")
+	}
 
-		h.sendMessage(ctx, cmd.ChatID, "❌ Error occurred while searching. Please try again later.")
-		return
+	// a bare number is most likely a pasted MAL ID, go straight to its details
+	if id, err := strconv.Atoi(query); err == nil && id > 0 && filters.Type == "" && filters.Year == 0 {
+		if h.showAnimeByID(ctx, cmd, id, filters.SFW) {
+			return
+		}
 	}
 
-	// no results found for query
-	if len(searchResult.Data) == 0 {
-		h.sendMessage(ctx, cmd.ChatID, "❌ No anime found matching your search")
+	filters.Page = 1
+	searchResult, ok := h.runSearch(ctx, cmd, query, filters)
+	if !ok {
 		return
 	}
 
-	// Format message with interactive keyboards
-	message := h.formatSearchResults(searchResult.Data)
-	keyboard := h.createSearchResultsKeyboard(searchResult.Data)
-
-	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
+	h.saveLastSearch(cmd.UserID, query, filters, searchResult.HasNext)
+	if err := h.userService.PushSearchHistory(cmd.UserID, searchText(query, filters)); err != nil {
+		h.logger.WithError(err).Warn("Failed to save search history")
+	}
 }
 
-func (h *Handler) handleAdd(ctx context.Context, cmd BotCommand) {
-	if len(cmd.Args) < 2 {
-		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /add &lt;anime_id&gt; &lt;status&gt;
-
-<b>Valid statuses:</b>
-• watching - Currently watching
-• completed - Finished watching
-• on_hold - Paused/on hold
-• dropped - Stopped watching
-• watchlist - Want to watch later
+// showAnimeByID sends the details of the anime with that ID, reporting whether it answered.
+// Titles can be numbers too ("86"), so an unknown ID lets the caller search the text instead.
+func (h *Handler) showAnimeByID(ctx context.Context, cmd BotCommand, animeID int, sfw bool) bool {
+	anime, err := h.animeService.GetAnimeByID(animeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			h.logger.WithField("anime_id", animeID).Debug("No anime with that ID, searching the text instead")
+			return false
+		case errors.Is(err, services.ErrServiceUnavailable):
+			h.sendMessage(ctx, cmd.ChatID, animeServiceDownMessage)
+		default:
+			h.logger.WithError(err).WithField("anime_id", animeID).Error("Failed to look up anime by ID")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Error occurred while searching. Please try again later.")
+		}
+		return true
+	}
 
-<b>Example:</b> /add 5114 watching`)
-		return
+	if sfw && anime.IsAdult() {
+		h.sendMessage(ctx, cmd.ChatID, "🔞 This title is hidden by your content filter. Use /nsfw on to show it.")
+		return true
 	}
 
-	h.sendMessage(ctx, cmd.ChatID, "⏳ Adding anime to your list...")
+	detailsMessage, keyboard := h.detailsView(ctx, cmd.UserID, anime)
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, detailsMessage, keyboard)
+	return true
+}
 
-	animeID, err := strconv.Atoi(cmd.Args[0])
+// handleAgain fetches the next page of the user's last /search.
+func (h *Handler) handleAgain(ctx context.Context, cmd BotCommand) {
+	last, err := h.userService.GetLastSearch(cmd.UserID)
 	if err != nil {
-		h.logger.WithFields(logrus.Fields{
-			"cmd_args": cmd.Args,
-			"user_id":  cmd.UserID,
-			"error":    err.Error(),
-		}).Warn("Invalid anime ID")
+		h.logger.WithError(err).Error("Failed to get last search")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't find your last search. Please try again later.")
+		return
+	}
 
-		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please use a valid numeric ID from search results.")
+	if last == nil {
+		h.sendMessage(ctx, cmd.ChatID, "🔎 No recent search to repeat. Use /search to find anime first.")
 		return
 	}
 
-	status := models.Status(cmd.Args[1])
-	if !isValidStatus(status) {
-		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid status. Valid options are: watching, completed, on_hold, dropped, watchlist")
+	if !last.HasNext {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("🏁 That's all the results for <b>%s</b>.", esc(last.Query)))
 		return
 	}
 
-	// add to user personalized list
-	if err := h.userService.AddToUserList(cmd.UserID, animeID, status); err != nil {
-		h.logger.WithError(err).Error("Failed to add anime to user list")
+	filters := last.Filters
+	filters.SFW = !h.showsNSFW(cmd.UserID)
+	filters.Page = max(last.Page, 1) + 1
 
-		if strings.Contains(err.Error(), "not found") {
-			h.sendMessage(ctx, cmd.ChatID, "❌ Anime with that ID doesn't exist. Please check the ID from search results.")
-		} else {
-			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't add the anime to your list. Please try again later.")
-		}
+	searchResult, ok := h.runSearch(ctx, cmd, last.Query, filters)
+	if !ok {
 		return
 	}
 
-	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Successfully added anime to your list with status: <b>%s</b>", status))
+	h.saveLastSearch(cmd.UserID, last.Query, filters, searchResult.HasNext)
 }
 
-func (h *Handler) handleRemove(ctx context.Context, cmd BotCommand) {
-	if len(cmd.Args) < 1 {
-		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /remove &lt;anime_id&gt;
-
-<b>Example:</b> /remove 5114`)
-		return
-	}
+// runSearch searches for query and sends the results page, reporting whether any were sent.
+func (h *Handler) runSearch(ctx context.Context, cmd BotCommand, query string, filters models.SearchFilters) (*models.SearchResult, bool) {
+	h.sendMessage(ctx, cmd.ChatID, "🔎 Searching for anime...")
 
-	animeID, err := strconv.Atoi(cmd.Args[0])
+	searchResult, err := h.animeService.SearchAnime(query, filters)
 	if err != nil {
-		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please use a valid numeric ID.")
-		return
-	}
+		h.logger.WithFields(logrus.Fields{
+			"query":   query,
+			"page":    filters.Page,
+			"user_id": cmd.UserID,
+			"error":   err.Error(),
+		}).Error("Failed to search anime")
 
-	h.sendMessage(ctx, cmd.ChatID, "⏳ Removing anime from your list...")
+		if errors.Is(err, services.ErrServiceUnavailable) {
+			h.sendMessage(ctx, cmd.ChatID, animeServiceDownMessage)
+			return nil, false
+		}
+		h.sendMessage(ctx, cmd.ChatID, "❌ Error occurred while searching. Please try again later.")
+		return nil, false
+	}
 
-	if err := h.userService.RemoveFromUserList(cmd.UserID, animeID); err != nil {
-		h.logger.WithError(err).Error("Failed to remove anime from user list")
+	if filters.SFW {
+		searchResult.Items = slices.DeleteFunc(searchResult.Items, models.AnimeData.IsAdult)
+	}
 
-		if strings.Contains(err.Error(), "not found") {
-			h.sendMessage(ctx, cmd.ChatID, "❌ Anime not found in your list.")
+	// no results found for query
+	if len(searchResult.Items) == 0 {
+		if filters.Page > 1 {
+			h.sendMessage(ctx, cmd.ChatID, "🏁 No more results for your search.")
 		} else {
-			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't remove the anime from your list. Please try again later.")
+			h.sendMessage(ctx, cmd.ChatID, "❌ No anime found matching your search")
 		}
-		return
+		return nil, false
 	}
 
-	h.sendMessage(ctx, cmd.ChatID, "✅ Successfully removed anime from your list.")
+	// Format message with interactive keyboards
+	message := h.formatSearchResults(searchResult.Items, h.searchVerbosity(cmd.UserID))
+	if searchResult.HasNext {
+		message += "\n\n<i>Use /again for more results.</i>"
+	}
+	keyboard := h.createSearchResultsKeyboard(ctx, searchResult.Items)
+
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
+	return searchResult, true
 }
 
+func (h *Handler) saveLastSearch(userID, query string, filters models.SearchFilters, hasNext bool) {
+	last := models.LastSearch{Query: query, Filters: filters, Page: filters.Page, HasNext: hasNext}
+	if err := h.userService.SaveLastSearch(userID, last); err != nil {
+		h.logger.WithError(err).Warn("Failed to save last search")
+	}
+}
+
+// handleNSFW shows or changes whether adult content is included in results.
+func (h *Handler) handleNSFW(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) == 0 {
+		state := onOff(h.showsNSFW(cmd.UserID))
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("🔞 Adult content is currently <b>%s</b>.\n\n<b>Usage:</b> /nsfw on|off", state))
+		return
+	}
+
+	var show bool
+	switch strings.ToLower(cmd.Args[0]) {
+	case "on":
+		show = true
+	case "off":
+		show = false
+	default:
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /nsfw on|off")
+		return
+	}
+
+	if err := h.userService.SetShowNSFW(cmd.UserID, show); err != nil {
+		h.logger.WithError(err).Error("Failed to update nsfw setting")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update your setting. Please try again later.")
+		return
+	}
+
+	if show {
+		h.sendMessage(ctx, cmd.ChatID, "✅ Adult content will now be shown in results.")
+	} else {
+		h.sendMessage(ctx, cmd.ChatID, "✅ Adult content will now be hidden from results.")
+	}
+}
+
+// handleSilent shows or changes whether reminders arrive without a notification sound.
+func (h *Handler) handleSilent(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) == 0 {
+		settings, err := h.userService.GetSettings(cmd.UserID)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to get settings for silent setting")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't get your setting. Please try again later.")
+			return
+		}
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("🔕 Silent reminders are currently <b>%s</b>.\n\n<b>Usage:</b> /silent on|off", onOff(settings.SilentReminders)))
+		return
+	}
+
+	var silent bool
+	switch strings.ToLower(cmd.Args[0]) {
+	case "on":
+		silent = true
+	case "off":
+		silent = false
+	default:
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /silent on|off")
+		return
+	}
+
+	if err := h.userService.SetSilentReminders(cmd.UserID, silent); err != nil {
+		h.logger.WithError(err).Error("Failed to update silent reminders setting")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update your setting. Please try again later.")
+		return
+	}
+
+	if silent {
+		h.sendMessage(ctx, cmd.ChatID, "🔕 Reminders will now arrive silently.")
+	} else {
+		h.sendMessage(ctx, cmd.ChatID, "🔔 Reminders will now arrive with a notification sound.")
+	}
+}
+
+// handlePause pauses reminders and other background notifications until /resume.
+func (h *Handler) handlePause(ctx context.Context, cmd BotCommand) {
+	if err := h.userService.SetNotificationsPaused(cmd.UserID, true); err != nil {
+		h.logger.WithError(err).Error("Failed to pause notifications")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't pause your notifications. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, "⏸ Notifications paused. Your reminders are kept and will arrive once you /resume.")
+}
+
+func (h *Handler) handleResume(ctx context.Context, cmd BotCommand) {
+	if err := h.userService.SetNotificationsPaused(cmd.UserID, false); err != nil {
+		h.logger.WithError(err).Error("Failed to resume notifications")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't resume your notifications. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, "▶️ Notifications resumed. Any reminders that came due meanwhile will arrive shortly.")
+}
+
+func (h *Handler) handlePublic(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) == 0 {
+		state := "off"
+		settings, err := h.userService.GetSettings(cmd.UserID)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to get settings for public check")
+		} else if settings.IsPublic {
+			state = "on"
+		}
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("🏆 Leaderboard visibility is currently <b>%s</b>.\n\n<b>Usage:</b> /public on|off", state))
+		return
+	}
+
+	var public bool
+	switch strings.ToLower(cmd.Args[0]) {
+	case "on":
+		public = true
+	case "off":
+		public = false
+	default:
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /public on|off")
+		return
+	}
+
+	if err := h.userService.SetPublic(cmd.UserID, public); err != nil {
+		h.logger.WithError(err).Error("Failed to update public setting")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update your setting. Please try again later.")
+		return
+	}
+
+	if public {
+		h.sendMessage(ctx, cmd.ChatID, "✅ You'll now appear on the /leaderboard (by username only).")
+	} else {
+		h.sendMessage(ctx, cmd.ChatID, "✅ You've been removed from the /leaderboard.")
+	}
+}
+
+// showsNSFW reports whether the user opted in to adult content. Defaults to false.
+func (h *Handler) showsNSFW(userID string) bool {
+	settings, err := h.userService.GetSettings(userID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to get settings for nsfw check")
+		return false
+	}
+	return settings.ShowNSFW
+}
+
+// searchVerbosity returns the user's search result verbosity, normal if unknown.
+func (h *Handler) searchVerbosity(userID string) models.SearchVerbosity {
+	settings, err := h.userService.GetSettings(userID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to get settings for search verbosity")
+		return models.VerbosityNormal
+	}
+	if !settings.SearchVerbosity.IsValid() {
+		return models.VerbosityNormal
+	}
+	return settings.SearchVerbosity
+}
+
+// handleVerbosity shows or changes how much detail search results show.
+func (h *Handler) handleVerbosity(ctx context.Context, cmd BotCommand) {
+	const usage = "<b>Usage:</b> /verbosity compact|normal|detailed\n\n• <code>compact</code> - titles and IDs only\n• <code>normal</code> - details for the top result\n• <code>detailed</code> - details for every result"
+
+	if len(cmd.Args) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("🔍 Search results are currently <b>%s</b>.\n\n%s", h.searchVerbosity(cmd.UserID), usage))
+		return
+	}
+
+	verbosity := models.SearchVerbosity(strings.ToLower(cmd.Args[0]))
+	if !verbosity.IsValid() {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Unknown verbosity.\n\n"+usage)
+		return
+	}
+
+	if err := h.userService.SetSearchVerbosity(cmd.UserID, verbosity); err != nil {
+		h.logger.WithError(err).Error("Failed to update search verbosity")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update your setting. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Search results will now be <b>%s</b>.", verbosity))
+}
+
+// parseSearchFlags pulls --type and --year flags out of the search arguments,
+// returning the filters and the remaining words of the query.
+// Accepts both "--type movie" and "--type=movie".
+func parseSearchFlags(args []string) (models.SearchFilters, []string, error) {
+	var filters models.SearchFilters
+	var rest []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !hasValue {
+			if i+1 >= len(args) {
+				return filters, nil, fmt.Errorf("missing value for --%s", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch strings.ToLower(name) {
+		case "type":
+			value = strings.ToLower(value)
+			if !slices.Contains(models.ValidAnimeTypes, value) {
+				return filters, nil, fmt.Errorf("invalid type %q. Valid types: %s", value, strings.Join(models.ValidAnimeTypes, ", "))
+			}
+			filters.Type = value
+		case "year":
+			year, err := strconv.Atoi(value)
+			if err != nil || year < 1917 || year > time.Now().Year()+5 {
+				return filters, nil, fmt.Errorf("invalid year %q", value)
+			}
+			filters.Year = year
+		default:
+			return filters, nil, fmt.Errorf("unknown flag --%s. Supported: --type, --year", name)
+		}
+	}
+
+	return filters, rest, nil
+}
+
+func (h *Handler) handleAdd(ctx context.Context, cmd BotCommand) {
+	// No arguments: walk the user through it
+	if len(cmd.Args) == 0 {
+		h.startAddWizard(ctx, cmd)
+		return
+	}
+
+	if len(cmd.Args) < 2 {
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /add &lt;anime_id&gt; &lt;status&gt; [rating] [note]
+
+<b>Valid statuses:</b>
+• watching - Currently watching
+• completed - Finished watching
+• on_hold - Paused/on hold
+• dropped - Stopped watching
+• watchlist - Want to watch later
+
+<b>Rating:</b> optional, 1-10
+
+<b>Examples:</b>
+• /add 5114 watching
+• /add 5114 completed 9 "great finale"`)
+		return
+	}
+
+	animeID, err := strconv.Atoi(cmd.Args[0])
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"cmd_args": cmd.Args,
+			"user_id":  cmd.UserID,
+			"error":    err.Error(),
+		}).Warn("Invalid anime ID")
+
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please use a valid numeric ID from search results.")
+		return
+	}
+
+	status := models.Status(cmd.Args[1])
+	if !isValidStatus(status) {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid status. Valid options are: watching, completed, on_hold, dropped, watchlist")
+		return
+	}
+
+	// Optional: /add <id> <status> <rating> ["note"]
+	var rating *float64
+	var notes *string
+	if len(cmd.Args) > 2 {
+		r, err := parseRating(cmd.Args[2])
+		if err != nil {
+			h.sendMessage(ctx, cmd.ChatID, "❌ Invalid rating. Please use a number from 1 to 10.")
+			return
+		}
+		rating = &r
+	}
+	if len(cmd.Args) > 3 {
+		note := strings.Trim(strings.Join(cmd.Args[3:], " "), "\"“”")
+		if utf8.RuneCountInString(note) > maxNoteLength {
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ Note too long. Please keep it under %d characters.", maxNoteLength))
+			return
+		}
+		if note != "" {
+			notes = &note
+		}
+	}
+
+	statusMsgID := h.sendStatusMessage(ctx, cmd.ChatID, "⏳ Adding anime to your list...")
+
+	// add to user personalized list
+	if err := h.userService.AddToUserList(cmd.UserID, animeID, status, rating, notes); err != nil {
+		h.logger.WithError(err).Error("Failed to add anime to user list")
+
+		if errors.Is(err, services.ErrListFull) {
+			h.updateStatusMessage(ctx, cmd.ChatID, statusMsgID, "❌ "+h.listFullMessage())
+		} else if errors.Is(err, services.ErrServiceUnavailable) {
+			h.updateStatusMessage(ctx, cmd.ChatID, statusMsgID, animeServiceDownMessage)
+		} else if strings.Contains(err.Error(), "not found") {
+			h.updateStatusMessage(ctx, cmd.ChatID, statusMsgID, "❌ Anime with that ID doesn't exist. Please check the ID from search results.")
+		} else {
+			h.updateStatusMessage(ctx, cmd.ChatID, statusMsgID, "❌ Sorry, I couldn't add the anime to your list. Please try again later.")
+		}
+		return
+	}
+
+	successMessage := fmt.Sprintf("✅ Successfully added anime to your list with status: <b>%s</b>", status)
+	if rating != nil {
+		successMessage += fmt.Sprintf("\n⭐ Your rating: %.1f/10", *rating)
+	}
+	if notes != nil {
+		successMessage += "\n📝 Note saved"
+	}
+	h.updateStatusMessage(ctx, cmd.ChatID, statusMsgID, successMessage)
+}
+
+// listFullMessage explains the list size cap. Plain text, it's also used in callback alerts.
+func (h *Handler) listFullMessage() string {
+	return fmt.Sprintf("Your list is full (%d anime max). Remove something with /remove first.", h.userService.MaxListSize())
+}
+
+// parseRating parses a personal rating and checks it's within range.
+func parseRating(arg string) (float64, error) {
+	rating, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, err
+	}
+	// NaN compares false with everything, so it would pass the range check
+	if math.IsNaN(rating) || rating < models.MinRating || rating > models.MaxRating {
+		return 0, fmt.Errorf("rating out of range: %.1f", rating)
+	}
+	return rating, nil
+}
+
+func (h *Handler) handleRemove(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) < 1 {
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /remove &lt;anime_id&gt;
+
+<b>Example:</b> /remove 5114`)
+		return
+	}
+
+	animeID, err := strconv.Atoi(cmd.Args[0])
+	if err != nil {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please use a valid numeric ID.")
+		return
+	}
+
+	// ask for confirmation first, the Yes button only works while the pending action lives
+	pending := models.PendingAction{
+		Type: models.PendingConfirmRemove,
+		Data: map[string]string{"anime_id": strconv.Itoa(animeID)},
+	}
+	if err := h.pendingStore.Set(cmd.pendingKey(), pending); err != nil {
+		h.logger.WithError(err).Warn("Failed to store pending removal, removing without confirmation")
+		statusMsgID := h.sendStatusMessage(ctx, cmd.ChatID, "⏳ Removing anime from your list...")
+		h.removeAnime(ctx, cmd.ChatID, cmd.UserID, animeID, statusMsgID)
+		return
+	}
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: "🗑 Yes, remove", CallbackData: h.createCallbackData(ctx, "confirm_remove", strconv.Itoa(animeID), "")},
+				{Text: "✖️ Cancel", CallbackData: h.createCallbackData(ctx, "cancel_pending", "", "")},
+			},
+		},
+	}
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, fmt.Sprintf("⚠️ Remove anime <code>%d</code> from your list?", animeID), keyboard)
+}
+
+// removeAnime removes the anime and reports the outcome in the status message.
+func (h *Handler) removeAnime(ctx context.Context, chatID, userID string, animeID, statusMsgID int) {
+	if err := h.userService.RemoveFromUserList(userID, animeID); err != nil {
+		h.logger.WithError(err).Error("Failed to remove anime from user list")
+
+		if strings.Contains(err.Error(), "not found") {
+			h.updateStatusMessage(ctx, chatID, statusMsgID, "❌ Anime not found in your list.")
+		} else {
+			h.updateStatusMessage(ctx, chatID, statusMsgID, "❌ Sorry, I couldn't remove the anime from your list. Please try again later.")
+		}
+		return
+	}
+
+	h.updateStatusMessage(ctx, chatID, statusMsgID, h.removedMessage())
+}
+
+// handleCallbackConfirmRemove removes the anime once the user confirms,
+// as long as the confirmation is still the user's pending action.
+func (h *Handler) handleCallbackConfirmRemove(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	key := callbackPendingKey(callback, chatID)
+	pending, err := h.pendingStore.Get(key)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get pending action")
+		h.answerCallback(ctx, callback.Id, "❌ Error processing request", true)
+		return
+	}
+
+	if pending == nil || pending.Type != models.PendingConfirmRemove || pending.Data["anime_id"] != data.AnimeID {
+		h.answerCallback(ctx, callback.Id, "⌛ This confirmation has expired", true)
+		h.editMessage(ctx, chatID, callback.Message.MessageId, "⌛ Confirmation expired. Use /remove again if you still want to remove it.", nil)
+		return
+	}
+
+	animeID, err := strconv.Atoi(data.AnimeID)
+	if err != nil {
+		h.answerCallback(ctx, callback.Id, "❌ Invalid anime ID", false)
+		return
+	}
+
+	if _, err := h.pendingStore.Clear(key); err != nil {
+		h.logger.WithError(err).Warn("Failed to clear pending action")
+	}
+
+	h.answerCallback(ctx, callback.Id, "", false)
+	h.editMessage(ctx, chatID, callback.Message.MessageId, "⏳ Removing anime from your list...", nil)
+	h.removeAnime(ctx, chatID, userID, animeID, callback.Message.MessageId)
+}
+
+// handleCallbackCancelPending drops the user's pending action from a Cancel button.
+func (h *Handler) handleCallbackCancelPending(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	if _, err := h.pendingStore.Clear(callbackPendingKey(callback, chatID)); err != nil {
+		h.logger.WithError(err).Warn("Failed to clear pending action")
+	}
+
+	h.answerCallback(ctx, callback.Id, "Cancelled", false)
+	h.editMessage(ctx, chatID, callback.Message.MessageId, "✖️ Cancelled.", nil)
+}
+
+// handleCancel aborts whatever interactive flow the user is in.
+func (h *Handler) handleCancel(ctx context.Context, cmd BotCommand) {
+	cleared, err := h.pendingStore.Clear(cmd.pendingKey())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to clear pending action")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't cancel that. Please try again later.")
+		return
+	}
+
+	if !cleared {
+		h.sendMessage(ctx, cmd.ChatID, "Nothing to cancel.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, "✖️ Cancelled.")
+}
+
+// listAllKeyword is accepted by /list in place of a status to mean every status.
+const listAllKeyword = "all"
+
 // handleList fetches and displays the user's anime list with pagination.
 func (h *Handler) handleList(ctx context.Context, cmd BotCommand) {
 	var statusFilter string
 	page := 1
 	limit := 5 // Default limit per page, no more, maybe less
 
-	// Parse arguments: /list [status] [page]
+	// Genre filter: /list genre <name> [page]
+	if len(cmd.Args) > 0 && strings.ToLower(cmd.Args[0]) == "genre" {
+		h.handleListByGenre(ctx, cmd, limit)
+		return
+	}
+
+	// Rating filter: /list rated>=8 [page]
+	if len(cmd.Args) > 0 && strings.HasPrefix(strings.ToLower(cmd.Args[0]), ratingFilterPrefix) {
+		h.handleListByRating(ctx, cmd, limit)
+		return
+	}
+
+	// Archived entries: /list archived [page]
+	if len(cmd.Args) > 0 && strings.ToLower(cmd.Args[0]) == listArchivedKeyword {
+		h.handleListArchived(ctx, cmd, limit)
+		return
+	}
+
+	// Tag filter: /list #tag [page]
+	if len(cmd.Args) > 0 && strings.HasPrefix(cmd.Args[0], "#") {
+		h.handleListByTag(ctx, cmd, limit)
+		return
+	}
+
+	// Parse arguments: /list [status|all] [page]
 	if len(cmd.Args) > 0 {
 		firstArg := strings.ToLower(cmd.Args[0])
-		if isValidStatus(models.Status(firstArg)) {
-			statusFilter = firstArg
+		if firstArg == listAllKeyword || isValidStatus(models.Status(firstArg)) {
+			// "all" is the same as no status filter
+			if firstArg != listAllKeyword {
+				statusFilter = firstArg
+			}
 			// Check if there's a page number after the status
 			if len(cmd.Args) > 1 {
 				if p, err := strconv.Atoi(cmd.Args[1]); err == nil && p > 0 {
@@ -731,26 +1742,96 @@ func (h *Handler) handleList(ctx context.Context, cmd BotCommand) {
 		return
 	}
 
-	message := h.formatUserList(userList, statusFilter, page, total, limit)
-	keyboard := h.createPaginationKeyboard(page, limit, total, statusFilter)
+	filter := listFilter{Status: statusFilter}
+	message := h.formatUserList(userList, filter, page, total, limit)
+	keyboard := h.createPaginationKeyboard(ctx, page, limit, total, filter)
 	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
 }
 
+// handleListByGenre shows the entries of the user's list tagged with a genre.
+// Genres can be several words ("slice of life"), so a trailing number is taken as the page.
+func (h *Handler) handleListByGenre(ctx context.Context, cmd BotCommand, limit int) {
+	args := cmd.Args[1:]
+	page := 1
+
+	if len(args) > 1 {
+		if p, err := strconv.Atoi(args[len(args)-1]); err == nil && p > 0 {
+			page = p
+			args = args[:len(args)-1]
+		}
+	}
+
+	genre := strings.Join(args, " ")
+	if genre == "" {
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /list genre &lt;genre&gt; [page]
+
+<b>Example:</b> /list genre action`)
+		return
+	}
+
+	userList, total, err := h.userService.GetUserListByGenre(cmd.UserID, genre, page, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get user list by genre")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your list. Please try again later.")
+		return
+	}
+
+	if len(userList) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("No anime in your list with the genre <b>%s</b>.", esc(genre)))
+		return
+	}
+
+	filter := listFilter{Genre: genre}
+	message := h.formatUserList(userList, filter, page, total, limit)
+	keyboard := h.createPaginationKeyboard(ctx, page, limit, total, filter)
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
+}
+
+// listFilter narrows /list down to a status, a genre, a tag or a rating. At most one is set.
+type listFilter struct {
+	Status string
+	Genre  string
+	Tag    string
+	Rating string // as typed, e.g. "rated>=8"
+}
+
+// getFilteredList fetches a page of the user's list matching the filter.
+func (h *Handler) getFilteredList(userID string, filter listFilter, page, limit int) ([]models.UserMediaWithDetails, int, error) {
+	switch {
+	case filter.Genre != "":
+		return h.userService.GetUserListByGenre(userID, filter.Genre, page, limit)
+	case filter.Tag != "":
+		return h.userService.GetUserListByTag(userID, filter.Tag, page, limit)
+	case filter.Rating != "":
+		ratingFilter, ok := parseRatingFilter(filter.Rating)
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid rating filter %q", filter.Rating)
+		}
+		return h.userService.GetUserListByRating(userID, ratingFilter, page, limit)
+	case filter.Status == listArchivedKeyword:
+		return h.userService.GetArchivedList(userID, page, limit)
+	default:
+		return h.userService.GetUserList(userID, filter.Status, page, limit)
+	}
+}
+
 // createPaginationKeyboard generates an inline keyboard with pagination buttons.
-func (h *Handler) createPaginationKeyboard(currentPage, limit, total int, statusFilter string) *models.InlineKeyboardMarkup {
+func (h *Handler) createPaginationKeyboard(ctx context.Context, currentPage, limit, total int, filter listFilter) *models.InlineKeyboardMarkup {
 	var buttons []models.InlineKeyboardButton
 
 	// Previous page button
 	if currentPage > 1 {
-		callbackData := models.CallbackData{
+		data := h.encodeCallbackData(ctx, models.CallbackData{
 			Action: "list_page",
 			Page:   currentPage - 1,
 			Limit:  limit,
 			Total:  total,
-			Status: statusFilter,
-		}
-		data, _ := json.Marshal(callbackData)
-		buttons = append(buttons, models.InlineKeyboardButton{Text: "⬅️ Previous", CallbackData: string(data)})
+			Status: filter.Status,
+			Genre:  filter.Genre,
+			Tag:    filter.Tag,
+			Rating: filter.Rating,
+		})
+		buttons = append(buttons, models.InlineKeyboardButton{Text: "⬅️ Previous", CallbackData: data})
 	}
 
 	// Current page info
@@ -760,80 +1841,322 @@ func (h *Handler) createPaginationKeyboard(currentPage, limit, total int, status
 
 	// Next page button
 	if currentPage*limit < total {
-		callbackData := models.CallbackData{
+		data := h.encodeCallbackData(ctx, models.CallbackData{
 			Action: "list_page",
 			Page:   currentPage + 1,
 			Limit:  limit,
 			Total:  total,
-			Status: statusFilter,
-		}
-		data, _ := json.Marshal(callbackData)
-		buttons = append(buttons, models.InlineKeyboardButton{Text: "Next ➡️", CallbackData: string(data)})
+			Status: filter.Status,
+			Genre:  filter.Genre,
+			Tag:    filter.Tag,
+			Rating: filter.Rating,
+		})
+		buttons = append(buttons, models.InlineKeyboardButton{Text: "Next ➡️", CallbackData: data})
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	if len(buttons) > 1 { // more than just the page info button
+		rows = append(rows, buttons)
+	}
+	if bulk := h.bulkStatusRow(ctx, currentPage, limit, filter); bulk != nil {
+		rows = append(rows, bulk)
 	}
 
-	if len(buttons) <= 1 { // Only page info button
+	if len(rows) == 0 {
 		return nil
 	}
 
 	keyboard := models.InlineKeyboardMarkup{
-		InlineKeyboard: [][]models.InlineKeyboardButton{buttons},
+		InlineKeyboard: rows,
 	}
 	return &keyboard
 }
 
 func (h *Handler) handleUpdate(ctx context.Context, cmd BotCommand) {
 	if len(cmd.Args) < 2 {
-		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /update &lt;anime_id&gt; &lt;new_status&gt;
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /update &lt;anime_id or title&gt; &lt;new_status&gt;
 
 <b>Valid statuses:</b>
 • watching, completed, on_hold, dropped, watchlist
 
-<b>Example:</b> /update 5114 completed`)
-		return
-	}
-
-	animeID, err := strconv.Atoi(cmd.Args[0])
-	if err != nil {
-		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please use a valid numeric ID.")
+<b>Examples:</b>
+/update 5114 completed
+/update steins gate completed`)
 		return
 	}
 
-	status := models.Status(cmd.Args[1])
+	// the status is always last, so titles can have spaces
+	status := models.Status(cmd.Args[len(cmd.Args)-1])
 	if !isValidStatus(status) {
 		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid status. Valid options are: watching, completed, on_hold, dropped, watchlist")
 		return
 	}
 
-	h.sendMessage(ctx, cmd.ChatID, "⏳ Updating anime status...")
+	target := strings.Join(cmd.Args[:len(cmd.Args)-1], " ")
+	animeID, err := strconv.Atoi(target)
+	if err != nil {
+		var ok bool
+		animeID, ok = h.resolveListEntryByTitle(ctx, cmd, target, "update_status", string(status))
+		if !ok {
+			return
+		}
+	}
+
+	statusMsgID := h.sendStatusMessage(ctx, cmd.ChatID, "⏳ Updating anime status...")
 
 	if err := h.userService.UpdateAnimeStatus(cmd.UserID, animeID, status); err != nil {
 		h.logger.WithError(err).Error("Failed to update anime status")
 
+		if strings.Contains(err.Error(), "not found") {
+			h.updateStatusMessage(ctx, cmd.ChatID, statusMsgID, "❌ Anime not found in your list. Use /add to add it first.")
+		} else {
+			h.updateStatusMessage(ctx, cmd.ChatID, statusMsgID, "❌ Sorry, I couldn't update the anime status. Please try again later.")
+		}
+		return
+	}
+
+	h.updateStatusMessage(ctx, cmd.ChatID, statusMsgID, fmt.Sprintf("✅ Successfully updated anime status to: <b>%s</b>", status))
+}
+
+// handleWhoami echoes the caller's identity as stored, to help sort out
+// private vs group chat confusion.
+func (h *Handler) handleWhoami(ctx context.Context, cmd BotCommand) {
+	user, err := h.userService.GetUser(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get user for whoami")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't look you up. Please try again later.")
+		return
+	}
+
+	settings, err := h.userService.GetSettings(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get settings for whoami")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't look you up. Please try again later.")
+		return
+	}
+
+	// says which list the sender's list commands act on here
+	mode, err := h.userService.GetChatMode(cmd.ChatID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get chat mode for whoami")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't look you up. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, formatWhoami(user, settings, cmd.ChatID, mode))
+}
+
+func formatWhoami(user *models.AppUser, settings *models.UserSettings, chatID string, mode models.ChatMode) string {
+	var message strings.Builder
+	message.WriteString("<b>🪪 Who You Are</b>\n\n")
+	message.WriteString(fmt.Sprintf("🆔 User ID: <code>%s</code>\n", esc(user.ID)))
+
+	username := "<i>not set</i>"
+	if user.Username != nil && *user.Username != "" {
+		username = "@" + esc(*user.Username)
+	}
+	message.WriteString(fmt.Sprintf("👤 Username: %s\n", username))
+	message.WriteString(fmt.Sprintf("📱 Platform: %s\n", esc(user.Platform)))
+
+	chatKind := "private chat"
+	switch {
+	case chatID == user.ID:
+	case mode == models.ChatModeShared:
+		chatKind = "shared group chat, /add, /list and the other list commands use the group's list"
+	default:
+		chatKind = "group chat, your list commands use your own list"
+	}
+	message.WriteString(fmt.Sprintf("💬 Chat ID: <code>%s</code> (%s)\n", esc(chatID), chatKind))
+
+	message.WriteString(fmt.Sprintf("🕐 Time zone: %s\n", esc(settings.Timezone)))
+	if settings.LanguageCode != "" {
+		message.WriteString(fmt.Sprintf("🌐 Language: %s\n", esc(settings.LanguageCode)))
+	}
+	message.WriteString(fmt.Sprintf("🔞 Adult content: %s\n", onOff(settings.ShowNSFW)))
+	message.WriteString(fmt.Sprintf("🏆 On leaderboard: %s\n", onOff(settings.IsPublic)))
+	message.WriteString(fmt.Sprintf("🔔 Notifications: %s\n", onOff(!settings.NotificationsPaused)))
+
+	return message.String()
+}
+
+// onOff renders a setting as "on" or "off".
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
+
+// resolveListEntryByTitle finds the anime in the user's list matching a title.
+// When there's exactly one match, or one exact title match, its ID is returned.
+// Otherwise the user is told there's no match, or offered buttons for the candidates
+// that run the given callback action with status, and ok is false.
+func (h *Handler) resolveListEntryByTitle(ctx context.Context, cmd BotCommand, title, action, status string) (int, bool) {
+	matches, err := h.userService.FindInUserListByTitle(cmd.UserID, title, titleMatchLimit+1)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to find anime in user list by title")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't search your list. Please try again later.")
+		return 0, false
+	}
+
+	if len(matches) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ No anime matching \"%s\" in your list. Check /list for the title or ID.", esc(title)))
+		return 0, false
+	}
+
+	// exact matches are sorted first, a single one wins even if other titles contain it
+	exact := strings.EqualFold(matches[0].Media.Title, title)
+	if len(matches) == 1 || (exact && !strings.EqualFold(matches[1].Media.Title, title)) {
+		animeID, err := strconv.Atoi(matches[0].Media.ExternalID)
+		if err != nil {
+			h.logger.WithError(err).Error("Invalid external ID in user list")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, something went wrong. Please use the anime ID instead.")
+			return 0, false
+		}
+		return animeID, true
+	}
+
+	var message strings.Builder
+	var rows [][]models.InlineKeyboardButton
+
+	message.WriteString(fmt.Sprintf("🤔 More than one anime in your list matches \"%s\". Which one did you mean?\n", esc(title)))
+	for i, match := range matches {
+		if i == titleMatchLimit {
+			message.WriteString("\n<i>More matches not shown, try a longer title or use the ID.</i>")
+			break
+		}
+		rows = append(rows, []models.InlineKeyboardButton{
+			{
+				Text:         services.TruncateText(match.Media.Title, titleMatchButtonLen),
+				CallbackData: h.createCallbackData(ctx, action, match.Media.ExternalID, status),
+			},
+		})
+	}
+
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message.String(), &models.InlineKeyboardMarkup{InlineKeyboard: rows})
+	return 0, false
+}
+
+// handleProgress records episodes watched: /progress <anime_id> <episodes>
+func (h *Handler) handleProgress(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) < 2 {
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /progress &lt;anime_id&gt; &lt;episodes_watched&gt;
+
+<b>Example:</b> /progress 5114 12`)
+		return
+	}
+
+	animeID, err := strconv.Atoi(cmd.Args[0])
+	if err != nil {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please use a valid numeric ID.")
+		return
+	}
+
+	episodes, err := strconv.Atoi(cmd.Args[1])
+	if err != nil || episodes < 0 || episodes > maxEpisodesWatched {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid episode count. Please use a whole number, e.g. 12.")
+		return
+	}
+
+	if err := h.userService.SetProgress(cmd.UserID, animeID, episodes); err != nil {
+		h.logger.WithError(err).Error("Failed to update progress")
 		if strings.Contains(err.Error(), "not found") {
 			h.sendMessage(ctx, cmd.ChatID, "❌ Anime not found in your list. Use /add to add it first.")
 		} else {
-			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update the anime status. Please try again later.")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update your progress. Please try again later.")
 		}
 		return
 	}
 
-	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Successfully updated anime status to: <b>%s</b>", status))
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Progress saved: %d %s watched.", episodes, pluralize(episodes, "episode", "episodes")))
 }
 
-func (h *Handler) handleHelp(ctx context.Context, cmd BotCommand) {
-	helpMessage := `<b>🤖 Anime Tracker Bot - Help</b>
+// pluralize picks the singular or plural word for n.
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// handlePing replies straight away, then edits the reply with how long sending it
+// took and the database/Redis latencies. No external APIs are involved.
+func (h *Handler) handlePing(ctx context.Context, cmd BotCommand) {
+	start := time.Now()
+	msgID := h.sendStatusMessage(ctx, cmd.ChatID, "🏓 pong")
+	roundTrip := time.Since(start)
+
+	dbLatency, redisLatency, err := h.userService.Ping()
+
+	var message strings.Builder
+	message.WriteString("🏓 <b>pong</b>\n\n")
+	message.WriteString(fmt.Sprintf("📨 Telegram: %d ms\n", roundTrip.Milliseconds()))
+	if err != nil {
+		h.logger.WithError(err).Warn("Ping failed")
+		message.WriteString("⚠️ Storage: unreachable")
+	} else {
+		message.WriteString(fmt.Sprintf("🗄 Database: %d ms\n", dbLatency.Milliseconds()))
+		message.WriteString(fmt.Sprintf("⚡ Redis: %d ms", redisLatency.Milliseconds()))
+	}
 
-<b>📝 Commands:</b>
+	h.updateStatusMessage(ctx, cmd.ChatID, msgID, message.String())
+}
+
+func (h *Handler) handleHelp(ctx context.Context, cmd BotCommand) {
+	helpMessage := fmt.Sprintf("<b>🤖 %s - Help</b>\n\n", esc(h.botName)) + `<b>📝 Commands:</b>
 
 <b>/start</b> - Show welcome message
-<b>/search</b> &lt;anime_name&gt; - Search for anime
-<b>/add</b> &lt;anime_id&gt; &lt;status&gt; - Add anime to your list
-<b>/list</b> [status] [page] - View your anime list (all or by status)
-<b>/update</b> &lt;anime_id&gt; &lt;new_status&gt; - Update anime status
+<b>/search</b> [--type tv|movie|ova|special|ona|music] [--year YYYY] &lt;anime_name&gt; - Search for anime
+<b>/again</b> - Next page of results for your last search
+<b>/history</b> - Your recent searches, tap one to run it again
+<b>/add</b> &lt;anime_id&gt; &lt;status&gt; [rating] [note] - Add anime to your list (or just /add for a guided add)
+<b>/list</b> [status|all] [page] - View your anime list (all or by status)
+<b>/list genre</b> &lt;genre&gt; - View your anime of a genre
+<b>/mylists</b> - View your custom lists
+<b>/newlist</b> &lt;name&gt; - Create a custom list
+<b>/addto</b> &lt;name&gt; &lt;anime_id&gt; - Add anime to a custom list
+<b>/showlist</b> &lt;name&gt; - View a custom list
+<b>/update</b> &lt;anime_id or title&gt; &lt;new_status&gt; - Update anime status
+<b>/info</b> &lt;anime_id&gt; - View an anime with your status, rating and notes
+<b>/progress</b> &lt;anime_id&gt; &lt;episodes&gt; - Record episodes watched
+<b>/tag</b> &lt;anime_id&gt; &lt;tag&gt; - Label an anime in your list
+<b>/untag</b> &lt;anime_id&gt; &lt;tag&gt; - Remove a label
+<b>/list</b> #&lt;tag&gt; - View your anime with a label
+<b>/list</b> rated&gt;=8 - View your anime by your rating (&gt;=, &lt;=, &gt;, &lt;, =)
 <b>/remove</b> &lt;anime_id&gt; - Remove anime from your list
+<b>/queue</b> [add|next|move|remove] - Order what to watch next
+<b>/undo</b> - Bring back the anime you last removed
+<b>/trash</b> - View anime you removed
+<b>/restore</b> &lt;anime_id&gt; - Bring back a removed anime
 <b>/profile</b> - View your profile and stats
-<b>/remind</b> &lt;anime_id&gt; &lt;days&gt; &lt;message&gt; - Set reminder
+<b>/stats</b> - View your list stats and top genres
+<b>/trend</b> - Your completions per month over the last year
+<b>/aotd</b> on|off [timezone] - A daily anime suggestion
+<b>/stats genres</b> - Average rating per genre
+<b>/card</b> - Get your stats as a shareable image
+<b>/export</b> [json|csv] - Download your list
+<b>/importanilist</b> &lt;username&gt; - Add the anime from a public AniList list
+<b>/remind</b> &lt;anime_id&gt; &lt;when&gt; &lt;message&gt; - Set reminder
+<b>/remindair</b> &lt;anime_id&gt; &lt;days_before&gt; - Reminder before an anime starts airing
 <b>/reminders</b> [all] - View your reminders
+<b>/settings</b> - View and change your preferences
+<b>/groupmode</b> personal|shared - In a group, share one list between everyone (admins only)
+<b>/pause</b> - Pause reminders and notifications
+<b>/resume</b> - Turn notifications back on
+<b>/nsfw</b> on|off - Show or hide adult content
+<b>/silent</b> on|off - Get reminders without a notification sound
+<b>/verbosity</b> compact|normal|detailed - How much search results show
+<b>/public</b> on|off - Show or hide yourself on the leaderboard
+<b>/leaderboard</b> - Top completers this week
+<b>/recent</b> - Anime most added by everyone this week
+<b>/top</b> [airing|upcoming] [page] - Top rated anime
+<b>/recommend</b> [--include-dropped] - Suggestions based on your favourites
+<b>/similar</b> &lt;anime_id&gt; [--include-dropped] - Anime like this one
+<b>/cancel</b> - Cancel the current action
+<b>/feedback</b> &lt;message&gt; - Report a bug or suggest something
+<b>/ping</b> - Check that the bot is alive
+<b>/about</b> - Bot version and uptime
+<b>/whoami</b> - Show how the bot sees you
 <b>/help</b> - Show this help message
 
 <b>📊 Valid Statuses:</b>
@@ -845,11 +2168,14 @@ func (h *Handler) handleHelp(ctx context.Context, cmd BotCommand) {
 
 <b>💡 Examples:</b>
 <code>/search Attack on Titan</code>
+<code>/search --type movie --year 2019 weathering</code>
 <code>/add 16498 watching</code>
 <code>/list completed</code>
 <code>/list watching 2</code>
+<code>/list genre action</code>
 <code>/update 16498 completed</code>
 <code>/remind 16498 30 "Time to rewatch!"</code>
+<code>/remind 5114 12h "New episode tonight"</code>
 <code>/reminders</code>
 
 Need more help? Just ask!`
@@ -858,7 +2184,7 @@ Need more help? Just ask!`
 }
 
 // Keyboard creation methods
-func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models.InlineKeyboardMarkup {
+func (h *Handler) createSearchResultsKeyboard(ctx context.Context, animes []models.AnimeData) *models.InlineKeyboardMarkup {
 	var rows [][]models.InlineKeyboardButton
 
 	// Add quick action buttons for first result
@@ -870,11 +2196,11 @@ func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models
 		statusRow := []models.InlineKeyboardButton{
 			{
 				Text:         "📝 Watchlist",
-				CallbackData: h.createCallbackData("add_anime", animeID, "watchlist"),
+				CallbackData: h.createCallbackData(ctx, "add_anime", animeID, "watchlist"),
 			},
 			{
 				Text:         "👀 Watching",
-				CallbackData: h.createCallbackData("add_anime", animeID, "watching"),
+				CallbackData: h.createCallbackData(ctx, "add_anime", animeID, "watching"),
 			},
 		}
 		rows = append(rows, statusRow)
@@ -883,11 +2209,11 @@ func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models
 		statusRow2 := []models.InlineKeyboardButton{
 			{
 				Text:         "✅ Completed",
-				CallbackData: h.createCallbackData("add_anime", animeID, "completed"),
+				CallbackData: h.createCallbackData(ctx, "add_anime", animeID, "completed"),
 			},
 			{
 				Text:         "⏸ On Hold",
-				CallbackData: h.createCallbackData("add_anime", animeID, "on_hold"),
+				CallbackData: h.createCallbackData(ctx, "add_anime", animeID, "on_hold"),
 			},
 		}
 		rows = append(rows, statusRow2)
@@ -896,7 +2222,7 @@ func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models
 		detailsRow := []models.InlineKeyboardButton{
 			{
 				Text:         "📖 Details",
-				CallbackData: h.createCallbackData("view_details", animeID, ""),
+				CallbackData: h.createCallbackData(ctx, "view_details", animeID, ""),
 			},
 			{
 				Text: "🔗 MyAnimeList",
@@ -932,7 +2258,7 @@ func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models
 // 			statusRow := []models.InlineKeyboardButton{
 // 				{
 // 					Text:         fmt.Sprintf("📝 %s", title),
-// 					CallbackData: h.createCallbackData("view_details", animeID, ""),
+// 					CallbackData: h.createCallbackData(ctx, "view_details", animeID, ""),
 // 				},
 // 			}
 
@@ -941,17 +2267,17 @@ func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models
 // 			case models.StatusWatching:
 // 				statusRow = append(statusRow, models.InlineKeyboardButton{
 // 					Text:         "✅ Complete",
-// 					CallbackData: h.createCallbackData("update_status", animeID, "completed"),
+// 					CallbackData: h.createCallbackData(ctx, "update_status", animeID, "completed"),
 // 				})
 // 			case models.StatusWatchlist:
 // 				statusRow = append(statusRow, models.InlineKeyboardButton{
 // 					Text:         "👀 Start Watching",
-// 					CallbackData: h.createCallbackData("update_status", animeID, "watching"),
+// 					CallbackData: h.createCallbackData(ctx, "update_status", animeID, "watching"),
 // 				})
 // 			case models.StatusCompleted:
 // 				statusRow = append(statusRow, models.InlineKeyboardButton{
 // 					Text:         "🗑 Remove",
-// 					CallbackData: h.createCallbackData("remove_anime", animeID, ""),
+// 					CallbackData: h.createCallbackData(ctx, "remove_anime", animeID, ""),
 // 				})
 // 			}
 
@@ -964,11 +2290,11 @@ func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models
 // 		filterRow := []models.InlineKeyboardButton{
 // 			{
 // 				Text:         "👀 Watching",
-// 				CallbackData: h.createCallbackData("list_page", "", "watching"),
+// 				CallbackData: h.createCallbackData(ctx, "list_page", "", "watching"),
 // 			},
 // 			{
 // 				Text:         "✅ Completed",
-// 				CallbackData: h.createCallbackData("list_page", "", "completed"),
+// 				CallbackData: h.createCallbackData(ctx, "list_page", "", "completed"),
 // 			},
 // 		}
 // 		rows = append(rows, filterRow)
@@ -976,11 +2302,11 @@ func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models
 // 		filterRow2 := []models.InlineKeyboardButton{
 // 			{
 // 				Text:         "📝 Watchlist",
-// 				CallbackData: h.createCallbackData("list_page", "", "watchlist"),
+// 				CallbackData: h.createCallbackData(ctx, "list_page", "", "watchlist"),
 // 			},
 // 			{
 // 				Text:         "⏸ On Hold",
-// 				CallbackData: h.createCallbackData("list_page", "", "on_hold"),
+// 				CallbackData: h.createCallbackData(ctx, "list_page", "", "on_hold"),
 // 			},
 // 		}
 // 		rows = append(rows, filterRow2)
@@ -991,49 +2317,172 @@ func (h *Handler) createSearchResultsKeyboard(animes []models.AnimeData) *models
 // 	}
 // }
 
-func (h *Handler) createAnimeDetailsKeyboard(animeID string) *models.InlineKeyboardMarkup {
-	rows := [][]models.InlineKeyboardButton{
-		{
+// createAnimeDetailsKeyboard builds the details buttons. An empty status means the anime
+// isn't in the user's list, so it offers to add it; otherwise it offers the next moves.
+func (h *Handler) createAnimeDetailsKeyboard(ctx context.Context, animeID string, status models.Status, longSynopsis bool) *models.InlineKeyboardMarkup {
+	var rows [][]models.InlineKeyboardButton
+	if status == "" {
+		rows = [][]models.InlineKeyboardButton{
 			{
-				Text:         "📝 Add to Watchlist",
-				CallbackData: h.createCallbackData("add_anime", animeID, "watchlist"),
+				{
+					Text:         "📝 Add to Watchlist",
+					CallbackData: h.createCallbackData(ctx, "add_anime", animeID, "watchlist"),
+				},
+				{
+					Text:         "👀 Start Watching",
+					CallbackData: h.createCallbackData(ctx, "add_anime", animeID, "watching"),
+				},
 			},
 			{
-				Text:         "👀 Start Watching",
-				CallbackData: h.createCallbackData("add_anime", animeID, "watching"),
+				{
+					Text:         "✅ Mark Completed",
+					CallbackData: h.createCallbackData(ctx, "add_anime", animeID, "completed"),
+				},
 			},
-		},
-		{
+		}
+	} else {
+		var moves []models.InlineKeyboardButton
+		for _, move := range statusMoves[status] {
+			moves = append(moves, models.InlineKeyboardButton{
+				Text:         move.label,
+				CallbackData: h.createCallbackData(ctx, "update_status", animeID, string(move.status)),
+			})
+		}
+		if len(moves) > 0 {
+			rows = append(rows, moves)
+		}
+		rows = append(rows, []models.InlineKeyboardButton{
 			{
-				Text:         "✅ Mark Completed",
-				CallbackData: h.createCallbackData("add_anime", animeID, "completed"),
+				Text:         "🗑 Remove",
+				CallbackData: h.createCallbackData(ctx, "remove_anime", animeID, ""),
 			},
-		},
+		})
+	}
+
+	if longSynopsis {
+		rows = append(rows, []models.InlineKeyboardButton{
+			{
+				Text:         "📖 Full synopsis",
+				CallbackData: h.createCallbackData(ctx, "full_synopsis", animeID, ""),
+			},
+		})
 	}
 
+	// page 0 opens the view in a new message, see handleCallbackCharacterPage
+	rows = append(rows, []models.InlineKeyboardButton{
+		{
+			Text:         "👥 Characters",
+			CallbackData: h.createCallbackData(ctx, "char_page", animeID, ""),
+		},
+		{
+			Text:         "📺 Episodes",
+			CallbackData: h.createCallbackData(ctx, "ep_page", animeID, ""),
+		},
+	})
+
 	return &models.InlineKeyboardMarkup{
 		InlineKeyboard: rows,
 	}
 }
 
-func (h *Handler) createCallbackData(action, animeID, status string) string {
-	data := models.CallbackData{
+// statusMove is a status change offered on the details view.
+type statusMove struct {
+	label  string
+	status models.Status
+}
+
+// statusMoves lists the changes that make sense from each status.
+var statusMoves = map[models.Status][]statusMove{
+	models.StatusWatchlist: {
+		{"👀 Start Watching", models.StatusWatching},
+		{"✅ Mark Completed", models.StatusCompleted},
+	},
+	models.StatusWatching: {
+		{"✅ Mark Completed", models.StatusCompleted},
+		{"⏸ Move to On Hold", models.StatusOnHold},
+		{"❌ Drop", models.StatusDropped},
+	},
+	models.StatusOnHold: {
+		{"👀 Resume Watching", models.StatusWatching},
+		{"❌ Drop", models.StatusDropped},
+	},
+	models.StatusDropped: {
+		{"👀 Start Watching", models.StatusWatching},
+		{"📝 Back to Watchlist", models.StatusWatchlist},
+	},
+	models.StatusCompleted: {
+		{"👀 Rewatch", models.StatusWatching},
+	},
+}
+
+func (h *Handler) createCallbackData(ctx context.Context, action, animeID, status string) string {
+	return h.encodeCallbackData(ctx, models.CallbackData{
 		Action:  action,
 		AnimeID: animeID,
 		Status:  status,
+	})
+}
+
+// encodeCallbackData stores the payload in the callback store and returns its token.
+// If the store is unavailable the button gets expiredCallbackData instead, so pressing
+// it asks the user to run the command again.
+func (h *Handler) encodeCallbackData(ctx context.Context, data models.CallbackData) string {
+	if h.callbackStore == nil {
+		return expiredCallbackData
+	}
+
+	token, err := h.callbackStore.Save(ctx, data)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to store callback data")
+		return expiredCallbackData
+	}
+	return token
+}
+
+// expiredCallbackData is a callback_data that never resolves, for buttons whose
+// payload couldn't be stored.
+const expiredCallbackData = "expired"
+
+// decodeCallbackData resolves a button's callback_data token and checks the payload's
+// paging fields. Anything but a token (e.g. inline JSON from old buttons) is treated
+// as expired.
+func (h *Handler) decodeCallbackData(ctx context.Context, raw string) (*models.CallbackData, error) {
+	if h.callbackStore == nil || !services.IsCallbackToken(raw) {
+		return nil, services.ErrCallbackExpired
 	}
 
-	jsonData, err := json.Marshal(data)
+	data, err := h.callbackStore.Load(ctx, raw)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to marshal callback data")
-		return "{}"
+		return nil, err
+	}
+
+	if err := validateCallbackData(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// validateCallbackData rejects payloads whose paging would make no sense, e.g. a
+// list page below 1 that would turn into a negative OFFSET.
+func validateCallbackData(data *models.CallbackData) error {
+	if data.Action == "" {
+		return fmt.Errorf("callback data has no action")
+	}
+	if data.Page < 0 || data.Limit < 0 || data.Total < 0 {
+		return fmt.Errorf("invalid callback paging: page %d, limit %d, total %d", data.Page, data.Limit, data.Total)
 	}
 
-	return string(jsonData)
+	switch data.Action {
+	case "list_page", "bulk_status":
+		if data.Page < 1 || data.Limit < 1 || data.Limit > maxListPageLimit {
+			return fmt.Errorf("invalid list page: page %d, limit %d", data.Page, data.Limit)
+		}
+	}
+	return nil
 }
 
 // Enhanced formatting methods
-func (h *Handler) formatSearchResults(animes []models.AnimeData) string {
+func (h *Handler) formatSearchResults(animes []models.AnimeData, verbosity models.SearchVerbosity) string {
 	if len(animes) == 0 {
 		return "No anime found for your search query."
 	}
@@ -1041,9 +2490,54 @@ func (h *Handler) formatSearchResults(animes []models.AnimeData) string {
 	var message strings.Builder
 	message.WriteString("<b>🔍 Search Results</b>\n\n")
 
+	if verbosity == models.VerbosityCompact {
+		for _, anime := range animes {
+			message.WriteString(fmt.Sprintf("• %s (ID: <code>%d</code>)\n", esc(anime.Title), anime.MalID))
+		}
+		return message.String()
+	}
+
+	synopsisLength := searchSynopsisLength
+	if verbosity == models.VerbosityDetailed {
+		synopsisLength = detailedSynopsisLength
+	}
+
 	// Show detailed info for first result
-	anime := animes[0]
-	message.WriteString(fmt.Sprintf("<b>%s</b>\n", anime.Title))
+	writeSearchResultDetails(&message, animes[0], synopsisLength)
+
+	if verbosity == models.VerbosityDetailed {
+		for _, anime := range animes[1:] {
+			message.WriteString("\n")
+			writeSearchResultDetails(&message, anime, synopsisLength)
+		}
+		message.WriteString("\n💡 <i>Use the buttons below to quickly add the top result to your list!</i>")
+		return message.String()
+	}
+
+	// Show other results briefly
+	if len(animes) > 1 {
+		message.WriteString(fmt.Sprintf("\n<b>Other Results (%d more):</b>\n", len(animes)-1))
+		for i, otherAnime := range animes[1:] {
+			if i >= 4 { // Show max 5 more
+				message.WriteString(fmt.Sprintf("... and %d more results\n", len(animes)-6))
+				break
+			}
+			message.WriteString(fmt.Sprintf("• %s (ID: %d)", esc(otherAnime.Title), otherAnime.MalID))
+			if otherAnime.Score > 0 {
+				message.WriteString(fmt.Sprintf(" - ⭐ %.1f", otherAnime.Score))
+			}
+			message.WriteString("\n")
+		}
+	}
+
+	message.WriteString("\n💡 <i>Use the buttons below to quickly add the top result to your list!</i>")
+	return message.String()
+}
+
+// writeSearchResultDetails writes one search result with its stats, type, airing status
+// and synopsis cut to synopsisLength characters.
+func writeSearchResultDetails(message *strings.Builder, anime models.AnimeData, synopsisLength int) {
+	message.WriteString(fmt.Sprintf("<b>%s</b>\n", esc(anime.Title)))
 	message.WriteString(fmt.Sprintf("🆔 ID: <code>%d</code>", anime.MalID))
 
 	if anime.Score > 0 {
@@ -1060,10 +2554,10 @@ func (h *Handler) formatSearchResults(animes []models.AnimeData) string {
 	// Type and Status
 	var details []string
 	if anime.Type != "" {
-		details = append(details, fmt.Sprintf("📱 %s", anime.Type))
+		details = append(details, fmt.Sprintf("📱 %s", esc(anime.Type)))
 	}
 	if anime.Status != "" {
-		details = append(details, fmt.Sprintf("📊 %s", anime.Status))
+		details = append(details, fmt.Sprintf("📊 %s", esc(anime.Status)))
 	}
 	if len(details) > 0 {
 		message.WriteString(strings.Join(details, " | ") + "\n")
@@ -1071,41 +2565,25 @@ func (h *Handler) formatSearchResults(animes []models.AnimeData) string {
 
 	// Synopsis (shortened)
 	if anime.Synopsis != "" {
-		synopsis := anime.Synopsis
-		if len(synopsis) > 200 {
-			synopsis = synopsis[:200] + "..."
-		}
-		message.WriteString(fmt.Sprintf("📝 %s\n", synopsis))
+		synopsis := services.TruncateText(anime.Synopsis, synopsisLength)
+		message.WriteString(fmt.Sprintf("📝 %s\n", esc(synopsis)))
 	}
-
-	// Show other results briefly
-	if len(animes) > 1 {
-		message.WriteString(fmt.Sprintf("\n<b>Other Results (%d more):</b>\n", len(animes)-1))
-		for i, otherAnime := range animes[1:] {
-			if i >= 4 { // Show max 5 more
-				message.WriteString(fmt.Sprintf("... and %d more results\n", len(animes)-6))
-				break
-			}
-			message.WriteString(fmt.Sprintf("• %s (ID: %d)", otherAnime.Title, otherAnime.MalID))
-			if otherAnime.Score > 0 {
-				message.WriteString(fmt.Sprintf(" - ⭐ %.1f", otherAnime.Score))
-			}
-			message.WriteString("\n")
-		}
-	}
-
-	message.WriteString("\n💡 <i>Use the buttons below to quickly add the top result to your list!</i>")
-	return message.String()
 }
 
-func (h *Handler) formatAnimeDetails(anime models.AnimeData) string {
+// formatAnimeDetails renders an anime's public details. With the user's entry for it,
+// the community score is shown next to their own rating so the two aren't confused.
+func (h *Handler) formatAnimeDetails(anime models.AnimeData, entry *models.UserMedia) string {
 	var message strings.Builder
-	message.WriteString(fmt.Sprintf("<b>📺 %s</b>\n\n", anime.Title))
+	message.WriteString(fmt.Sprintf("<b>📺 %s</b>\n\n", esc(anime.Title)))
 
 	message.WriteString(fmt.Sprintf("🆔 ID: <code>%d</code>\n", anime.MalID))
 
-	if anime.Score > 0 {
-		message.WriteString(fmt.Sprintf("⭐ Rating: %.1f/10\n", anime.Score))
+	if entry != nil {
+		if scores := formatScores(anime.Score, entry.Rating); scores != "" {
+			message.WriteString(scores + "\n")
+		}
+	} else if anime.Score > 0 {
+		message.WriteString(fmt.Sprintf("⭐ Community score: %.1f/10\n", anime.Score))
 	}
 
 	if anime.Episodes > 0 {
@@ -1117,25 +2595,25 @@ func (h *Handler) formatAnimeDetails(anime models.AnimeData) string {
 	}
 
 	if anime.Type != "" {
-		message.WriteString(fmt.Sprintf("📱 Type: %s\n", anime.Type))
+		message.WriteString(fmt.Sprintf("📱 Type: %s\n", esc(anime.Type)))
 	}
 
 	if anime.Status != "" {
-		message.WriteString(fmt.Sprintf("📊 Status: %s\n", anime.Status))
+		message.WriteString(fmt.Sprintf("📊 Status: %s\n", esc(anime.Status)))
 	}
 
 	// Genres
 	if len(anime.Genres) > 0 {
 		genres := make([]string, 0, len(anime.Genres))
 		for _, genre := range anime.Genres {
-			genres = append(genres, genre.Name)
+			genres = append(genres, esc(genre.Name))
 		}
 		message.WriteString(fmt.Sprintf("🏷 Genres: %s\n", strings.Join(genres, ", ")))
 	}
 
-	// Synopsis
+	// Synopsis, the rest is behind the "Full synopsis" button
 	if anime.Synopsis != "" {
-		message.WriteString(fmt.Sprintf("\n📝 <b>Synopsis:</b>\n%s\n", anime.Synopsis))
+		message.WriteString(fmt.Sprintf("\n📝 <b>Synopsis:</b>\n%s\n", esc(services.TruncateText(anime.Synopsis, detailsSynopsisLength))))
 	}
 
 	message.WriteString(fmt.Sprintf("\n🔗 <a href=\"https://myanimelist.net/anime/%d\">View on MyAnimeList</a>", anime.MalID))
@@ -1143,6 +2621,21 @@ func (h *Handler) formatAnimeDetails(anime models.AnimeData) string {
 	return message.String()
 }
 
+// formatScores puts the community score and the user's own rating on one line,
+// e.g. "Community ⭐ 8.2 / Your ⭐ 9.0". Zero means there's no score; with neither it's empty.
+func formatScores(community, personal float64) string {
+	var parts []string
+	if community > 0 {
+		parts = append(parts, fmt.Sprintf("Community ⭐ %.1f", community))
+	}
+	if personal > 0 {
+		parts = append(parts, fmt.Sprintf("Your ⭐ %.1f", personal))
+	} else if community > 0 {
+		parts = append(parts, "Your ⭐ not rated")
+	}
+	return strings.Join(parts, " / ")
+}
+
 // Helper functions to safely get float64 value from pointer
 func getFloatValue(f *float64) float64 {
 	if f == nil {
@@ -1160,7 +2653,9 @@ func getStringValue(s *string) string {
 
 // End
 
-func (h *Handler) formatUserList(userList []models.UserMediaWithDetails, statusFilter string, page, total, limit int) string {
+func (h *Handler) formatUserList(userList []models.UserMediaWithDetails, filter listFilter, page, total, limit int) string {
+	statusFilter := filter.Status
+
 	var message strings.Builder
 
 	// Calculate pagination info
@@ -1168,8 +2663,16 @@ func (h *Handler) formatUserList(userList []models.UserMediaWithDetails, statusF
 	start := (page-1)*limit + 1
 	end := start + len(userList) - 1
 
-	if statusFilter != "" {
+	if statusFilter == listArchivedKeyword {
+		message.WriteString("<b>🗄 Your Archived Anime</b>\n")
+	} else if statusFilter != "" {
 		message.WriteString(fmt.Sprintf("<b>📋 Your %s Anime List</b>\n", strings.Title(statusFilter)))
+	} else if filter.Genre != "" {
+		message.WriteString(fmt.Sprintf("<b>🏷 Your %s Anime</b>\n", esc(strings.Title(filter.Genre))))
+	} else if filter.Tag != "" {
+		message.WriteString(fmt.Sprintf("<b>🔖 Your #%s Anime</b>\n", esc(filter.Tag)))
+	} else if filter.Rating != "" {
+		message.WriteString(fmt.Sprintf("<b>⭐ Your Anime %s</b>\n", esc(filter.Rating)))
 	} else {
 		message.WriteString("<b>📋 Your Anime List</b>\n")
 	}
@@ -1201,9 +2704,16 @@ func (h *Handler) formatUserList(userList []models.UserMediaWithDetails, statusF
 			statusEmoji := getStatusEmoji(status)
 			message.WriteString(fmt.Sprintf("<b>%s %s (%d):</b>\n", statusEmoji, strings.Title(string(status)), len(items)))
 
-			for _, item := range items {
+			shown := items
+			if h.listGroupLimit > 0 && len(shown) > h.listGroupLimit {
+				shown = shown[:h.listGroupLimit]
+			}
+			for _, item := range shown {
 				message.WriteString(fmt.Sprintf("   • %s (ID: %s)\n",
-					item.Media.Title, item.Media.ExternalID))
+					esc(item.Media.Title), item.Media.ExternalID))
+			}
+			if hidden := len(items) - len(shown); hidden > 0 {
+				message.WriteString(fmt.Sprintf("   <i>...and %d more, use /list %s</i>\n", hidden, status))
 			}
 			message.WriteString("\n")
 		}
@@ -1211,7 +2721,7 @@ func (h *Handler) formatUserList(userList []models.UserMediaWithDetails, statusF
 		// Show detailed list for specific status
 		statusEmoji := getStatusEmoji(models.Status(statusFilter))
 		for _, item := range userList {
-			message.WriteString(fmt.Sprintf("%s <b>%s</b>\n", statusEmoji, item.Media.Title))
+			message.WriteString(fmt.Sprintf("%s <b>%s</b>\n", statusEmoji, esc(item.Media.Title)))
 			message.WriteString(fmt.Sprintf("   🆔 ID: %s", item.Media.ExternalID))
 
 			// Handle nullable rating for Media
@@ -1221,11 +2731,23 @@ func (h *Handler) formatUserList(userList []models.UserMediaWithDetails, statusF
 
 			// Handle nullable release date
 			if item.Media.ReleaseDate != nil && *item.Media.ReleaseDate != "" {
-				message.WriteString(fmt.Sprintf(" | 📅 %s", *item.Media.ReleaseDate))
+				message.WriteString(fmt.Sprintf(" | 📅 %s", esc(*item.Media.ReleaseDate)))
 			}
 
-			message.WriteString(fmt.Sprintf("\n   📝 Added: %s\n\n",
-				item.UserMedia.CreatedAt.Format("Jan 2, 2006")))
+			message.WriteString(fmt.Sprintf("\n   📝 Added: %s", item.UserMedia.CreatedAt.Format("Jan 2, 2006")))
+			if item.UserMedia.StartedAt != nil {
+				message.WriteString(fmt.Sprintf(" | ▶️ Started: %s", item.UserMedia.StartedAt.Format("Jan 2, 2006")))
+			}
+			if item.UserMedia.EpisodesWatched > 0 {
+				message.WriteString(fmt.Sprintf(" | 📺 Ep %d", item.UserMedia.EpisodesWatched))
+			}
+			if item.UserMedia.DeletedAt != nil {
+				message.WriteString(fmt.Sprintf(" | 🗑 Removed: %s", item.UserMedia.DeletedAt.Format("Jan 2, 2006")))
+			}
+			if len(item.UserMedia.Tags) > 0 {
+				message.WriteString("\n   🔖 #" + esc(strings.Join(item.UserMedia.Tags, " #")))
+			}
+			message.WriteString("\n\n")
 		}
 	}
 
@@ -1259,22 +2781,50 @@ func (h *Handler) sendMessage(ctx context.Context, chatID, text string) {
 }
 
 func (h *Handler) sendMessageWithKeyboard(ctx context.Context, chatID, text string, keyboard *models.InlineKeyboardMarkup) {
+	h.sendMessageWithKeyboardID(ctx, chatID, text, keyboard)
+}
+
+// sendMessageWithKeyboardID sends a message and returns its ID, or 0 if sending failed.
+func (h *Handler) sendMessageWithKeyboardID(ctx context.Context, chatID, text string, keyboard *models.InlineKeyboardMarkup) int {
 	chatIDInt, err := strconv.Atoi(chatID)
 	if err != nil {
 		h.logger.WithError(err).Error("Invalid chat ID")
-		return
+		return 0
 	}
 
-	if err := services.SendTelegramMessageWithKeyboard(ctx, h.botToken, chatIDInt, text, keyboard); err != nil {
+	keyboard = h.limitKeyboard(keyboard)
+
+	messageID, err := h.telegram.SendMessage(ctx, chatIDInt, text, keyboard)
+	if err != nil {
 		h.logger.WithFields(logrus.Fields{
 			"chat_id": chatIDInt,
 			"error":   err.Error(),
 		}).Error("Failed to send message")
-	} else {
-		h.logger.WithFields(logrus.Fields{
-			"chat_id": chatIDInt,
-		}).Debug("Message sent successfully")
+		return 0
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"chat_id":    chatIDInt,
+		"message_id": messageID,
+	}).Debug("Message sent successfully")
+
+	return messageID
+}
+
+// sendStatusMessage sends a progress message (e.g. "⏳ Adding...") and returns its ID
+// so the outcome can replace it in place.
+func (h *Handler) sendStatusMessage(ctx context.Context, chatID, text string) int {
+	return h.sendMessageWithKeyboardID(ctx, chatID, text, nil)
+}
+
+// updateStatusMessage replaces a progress message with the final result.
+// If the progress message couldn't be sent, the result goes out as a new message.
+func (h *Handler) updateStatusMessage(ctx context.Context, chatID string, messageID int, text string) {
+	if messageID == 0 {
+		h.sendMessage(ctx, chatID, text)
+		return
 	}
+	h.editMessage(ctx, chatID, messageID, text, nil)
 }
 
 func (h *Handler) editMessage(ctx context.Context, chatID string, messageID int, text string, keyboard *models.InlineKeyboardMarkup) {
@@ -1284,7 +2834,9 @@ func (h *Handler) editMessage(ctx context.Context, chatID string, messageID int,
 		return
 	}
 
-	if err := services.EditTelegramMessage(ctx, h.botToken, chatIDInt, messageID, text, keyboard); err != nil {
+	keyboard = h.limitKeyboard(keyboard)
+
+	if err := h.telegram.EditMessage(ctx, chatIDInt, messageID, text, keyboard); err != nil {
 		h.logger.WithFields(logrus.Fields{
 			"chat_id":    chatIDInt,
 			"message_id": messageID,
@@ -1301,8 +2853,44 @@ func (h *Handler) editMessage(ctx context.Context, chatID string, messageID int,
 	}
 }
 
+// callbackAnswerKey is the context key for the callbackAnswer of the query being handled.
+type callbackAnswerKey struct{}
+
+// callbackAnswer records whether the callback query being handled has been answered,
+// so handleCallbackQuery can answer the ones its handlers didn't, and only once.
+type callbackAnswer struct {
+	id       string
+	answered atomic.Bool
+}
+
+// withCallbackAnswer returns ctx carrying a fresh callbackAnswer for callbackID.
+func withCallbackAnswer(ctx context.Context, callbackID string) (context.Context, *callbackAnswer) {
+	answer := &callbackAnswer{id: callbackID}
+	return context.WithValue(ctx, callbackAnswerKey{}, answer), answer
+}
+
+// ensureCallbackAnswered sends an empty answer if the handler returned without one,
+// so the button stops spinning.
+func (h *Handler) ensureCallbackAnswered(ctx context.Context, answer *callbackAnswer) {
+	if answer.answered.Load() {
+		return
+	}
+
+	h.logger.WithField("callback_id", answer.id).Warn("Callback handler returned without answering")
+	h.answerCallback(ctx, answer.id, "", false)
+}
+
+// answerCallback answers a callback query. Telegram only takes one answer per query,
+// so later answers to the query being handled are dropped.
 func (h *Handler) answerCallback(ctx context.Context, callbackID, text string, showAlert bool) {
-	if err := services.AnswerCallbackQuery(ctx, h.botToken, callbackID, text, showAlert); err != nil {
+	if answer, ok := ctx.Value(callbackAnswerKey{}).(*callbackAnswer); ok && answer.id == callbackID {
+		if answer.answered.Swap(true) {
+			h.logger.WithField("callback_id", callbackID).Debug("Callback query already answered")
+			return
+		}
+	}
+
+	if err := h.telegram.AnswerCallbackQuery(ctx, callbackID, text, showAlert); err != nil {
 		h.logger.WithFields(logrus.Fields{
 			"callback_id": callbackID,
 			"error":       err.Error(),
@@ -1311,18 +2899,5 @@ func (h *Handler) answerCallback(ctx context.Context, callbackID, text string, s
 }
 
 func isValidStatus(status models.Status) bool {
-	validStatuses := []models.Status{
-		models.StatusCompleted,
-		models.StatusDropped,
-		models.StatusOnHold,
-		models.StatusWatching,
-		models.StatusWatchlist,
-	}
-
-	for _, validStatus := range validStatuses {
-		if status == validStatus {
-			return true
-		}
-	}
-	return false
+	return status.IsValid()
 }
@@ -0,0 +1,577 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"sletish/internal/testutil"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestAddNoteLengthCountsCharacters(t *testing.T) {
+	tests := []struct {
+		name    string
+		note    string
+		tooLong bool
+	}{
+		{"ascii at limit", strings.Repeat("a", maxNoteLength), false},
+		{"ascii over limit", strings.Repeat("a", maxNoteLength+1), true},
+		// 3 bytes each, over the limit in bytes but not in characters
+		{"japanese at limit", strings.Repeat("鋼", maxNoteLength), false},
+		{"japanese over limit", strings.Repeat("鋼", maxNoteLength+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, fmab)
+
+			replies := env.run(env.handler.handleAdd, "1", "/add 5114 watching 9 "+tt.note)
+			if got := containsAny(replies, "Note too long"); got != tt.tooLong {
+				t.Errorf("got replies %q, want note too long: %v", replies, tt.tooLong)
+			}
+		})
+	}
+}
+
+func TestHelp(t *testing.T) {
+	env := newTestEnv(t)
+
+	replies := env.run(env.handler.handleHelp, "42", "/help")
+	if len(replies) != 1 {
+		t.Fatalf("got %d messages, want 1", len(replies))
+	}
+
+	sent := env.telegram.lastSent(t)
+	if sent.ChatID != 42 {
+		t.Errorf("sent to chat %d, want 42", sent.ChatID)
+	}
+	for _, want := range []string{
+		"<b>🤖 Anime Tracker Bot - Help</b>",
+		"<b>/search</b>",
+		"<b>/add</b> &lt;anime_id&gt; &lt;status&gt;",
+		"<code>watchlist</code>",
+	} {
+		if !strings.Contains(sent.Text, want) {
+			t.Errorf("help is missing %q", want)
+		}
+	}
+}
+
+func TestHelpEscapesBotName(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetBranding("<Tracker & Co>", "")
+
+	replies := env.run(env.handler.handleHelp, "1", "/help")
+	if !containsAny(replies, "&lt;Tracker &amp; Co&gt; - Help") {
+		t.Errorf("got %q, want the bot name escaped", replies)
+	}
+}
+
+func TestBrandingInStartAndHelp(t *testing.T) {
+	env := newTestEnv(t)
+
+	start := env.run(env.handler.handleStart, "1", "/start")
+	help := env.run(env.handler.handleHelp, "1", "/help")
+	if !containsAny(start, "<b>Welcome to "+DefaultBotName+"!</b>\n\n"+DefaultBotDescription) {
+		t.Errorf("/start got %q, want the default name and description", start)
+	}
+	if !containsAny(help, "<b>🤖 "+DefaultBotName+" - Help</b>") {
+		t.Errorf("/help got %q, want the default name", help)
+	}
+
+	env.handler.SetBranding("Otaku Log", "Your seasonal watch diary.")
+	start = env.run(env.handler.handleStart, "1", "/start")
+	help = env.run(env.handler.handleHelp, "1", "/help")
+	if !containsAny(start, "<b>Welcome to Otaku Log!</b>\n\nYour seasonal watch diary.") {
+		t.Errorf("/start got %q, want the configured name and description", start)
+	}
+	if !containsAny(help, "<b>🤖 Otaku Log - Help</b>") {
+		t.Errorf("/help got %q, want the configured name", help)
+	}
+	if containsAny(append(start, help...), DefaultBotName) {
+		t.Error("the default name is still shown after configuring one")
+	}
+
+	// empty values keep what's set
+	env.handler.SetBranding("", "")
+	if start = env.run(env.handler.handleStart, "1", "/start"); !containsAny(start, "Welcome to Otaku Log!") {
+		t.Errorf("/start got %q, want the configured name kept", start)
+	}
+}
+
+func TestProcessMessageCommands(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+
+	if replies := env.command(t, 1, 1, "/help"); !containsAny(replies, "Anime Tracker Bot - Help") {
+		t.Errorf("/help got %q, want the help text", replies)
+	}
+	if replies := env.command(t, 1, 1, "/nope"); !containsAny(replies, "Unknown command") {
+		t.Errorf("/nope got %q, want the unknown command reply", replies)
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    float64
+		wantErr bool
+	}{
+		{"9", 9, false},
+		{"7.5", 7.5, false},
+		{"1", 1, false},
+		{"10", 10, false},
+		{"0", 0, true},
+		{"10.5", 0, true},
+		{"-3", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"great", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := parseRating(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRating(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseRating(%q) = %v, want %v", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRejectsInvalidRating(t *testing.T) {
+	for _, rating := range []string{"11", "0", "NaN", "nine"} {
+		t.Run(rating, func(t *testing.T) {
+			env := newTestEnv(t, fmab)
+
+			replies := env.run(env.handler.handleAdd, "1", "/add 5114 completed "+rating)
+			if len(replies) != 1 || !strings.HasPrefix(replies[0], "❌ Invalid rating") {
+				t.Errorf("got %q, want only the invalid rating message", replies)
+			}
+		})
+	}
+}
+
+func TestAddWithRatingAndNote(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	tests := []struct {
+		name       string
+		command    string
+		wantRating float64
+		wantNotes  string
+	}{
+		{"status only", "/add 5114 watching", 0, ""},
+		{"rating", "/add 5114 completed 9", 9, ""},
+		{"rating and note", `/add 5114 completed 8.5 "great finale"`, 8.5, "great finale"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := i + 1
+			if replies := env.command(t, userID, userID, tt.command); !containsAny(replies, "✅ Successfully added") {
+				t.Fatalf("got %q, want the anime added", replies)
+			}
+
+			entry, err := env.users.GetUserEntry(context.Background(), strconv.Itoa(userID), 5114)
+			if err != nil {
+				t.Fatalf("failed to get entry: %v", err)
+			}
+			if entry.UserMedia.Rating != tt.wantRating || entry.UserMedia.Notes != tt.wantNotes {
+				t.Errorf("got rating %v and notes %q, want %v and %q", entry.UserMedia.Rating, entry.UserMedia.Notes, tt.wantRating, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestFullSynopsisIsSentInChunks(t *testing.T) {
+	anime := fmab
+	anime.Synopsis = longSynopsis()
+	env := newTestEnv(t, anime)
+
+	env.press(1, 1, "cb-1", env.callbackData(t, models.CallbackData{Action: "full_synopsis", AnimeID: "5114"}))
+
+	env.telegram.mu.Lock()
+	sent := slices.Clone(env.telegram.sent)
+	env.telegram.mu.Unlock()
+
+	if len(sent) != 2 {
+		t.Fatalf("got %d messages, want the synopsis in 2", len(sent))
+	}
+	if !strings.HasPrefix(sent[0].Text, "📖 <b>Fullmetal Alchemist: Brotherhood</b>\n\n") {
+		t.Errorf("first chunk doesn't start with the title: %q", sent[0].Text[:50])
+	}
+	// Telegram counts the text after parsing the HTML
+	for i, m := range sent {
+		text := html.UnescapeString(strings.NewReplacer("<b>", "", "</b>", "").Replace(m.Text))
+		if n := utf8.RuneCountInString(text); n > maxMessageLength {
+			t.Errorf("message %d has %d characters, limit %d", i, n, maxMessageLength)
+		}
+	}
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 {
+		t.Errorf("got %d answers, want 1", len(answers))
+	}
+}
+
+func TestDetailsShortenLongSynopsis(t *testing.T) {
+	anime := fmab
+	anime.Synopsis = longSynopsis()
+	env := newTestEnv(t, anime)
+
+	message, keyboard := env.handler.detailsView(context.Background(), "1", &anime)
+
+	if strings.Contains(message, esc(anime.Synopsis)) {
+		t.Error("details show the whole synopsis, want it shortened")
+	}
+	if !strings.Contains(message, esc(services.TruncateText(anime.Synopsis, detailsSynopsisLength))) {
+		t.Error("details don't show the start of the synopsis")
+	}
+
+	var hasButton bool
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			hasButton = hasButton || button.Text == "📖 Full synopsis"
+		}
+	}
+	if !hasButton {
+		t.Error("got no full synopsis button for a long synopsis")
+	}
+}
+
+func TestFullSynopsisUsesStoredDescription(t *testing.T) {
+	anime := fmab
+	anime.Synopsis = longSynopsis()
+	env := newTestEnvWithDB(t, testutil.DB(t), anime)
+
+	env.command(t, 1, 1, "/add 5114 watching")
+	media, err := env.users.GetMediaByAnimeID(5114)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	if media.Description != anime.Synopsis {
+		t.Fatalf("stored %d characters of the synopsis, want all %d", len(media.Description), len(anime.Synopsis))
+	}
+
+	// Jikan is down, the stored description is enough
+	env.anime.err = errors.New("jikan down")
+	before := len(env.telegram.messages())
+	env.press(1, 1, "cb-1", env.callbackData(t, models.CallbackData{Action: "full_synopsis", AnimeID: "5114"}))
+
+	got := strings.Join(env.telegram.messages()[before:], "")
+	if !strings.Contains(got, "search for the Philosopher") || strings.Contains(got, "❌") {
+		t.Errorf("got %q, want the stored synopsis", got[:min(len(got), 200)])
+	}
+}
+
+func TestListShowsAddedAndStarted(t *testing.T) {
+	env := newTestEnv(t)
+
+	added := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	started := time.Date(2024, time.April, 2, 12, 0, 0, 0, time.UTC)
+	list := []models.UserMediaWithDetails{
+		{
+			UserMedia: models.UserMedia{Status: models.StatusWatching, CreatedAt: added, StartedAt: &started},
+			Media:     models.Media{ExternalID: "5114", Title: fmab.Title},
+		},
+		{
+			UserMedia: models.UserMedia{Status: models.StatusWatching, CreatedAt: added},
+			Media:     models.Media{ExternalID: "1", Title: "Never started"},
+		},
+	}
+
+	message := env.handler.formatUserList(list, listFilter{Status: "watching"}, 1, 2, 5)
+
+	if !strings.Contains(message, "📝 Added: Mar 1, 2024 | ▶️ Started: Apr 2, 2024") {
+		t.Errorf("got %q, want both dates for the started entry", message)
+	}
+	if strings.Count(message, "Started:") != 1 {
+		t.Errorf("got %q, want no start date for the entry that never started", message)
+	}
+}
+
+func TestStartClearsBlocked(t *testing.T) {
+	db := testutil.DB(t)
+	env := newTestEnvWithDB(t, db)
+
+	env.command(t, 1, 1, "/help")
+	if err := env.users.SetBlocked("1", true); err != nil {
+		t.Fatalf("SetBlocked failed: %v", err)
+	}
+
+	env.command(t, 1, 1, "/start")
+
+	var blocked bool
+	if err := db.QueryRow(context.Background(), "SELECT blocked FROM users WHERE id = '1'").Scan(&blocked); err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if blocked {
+		t.Error("user still blocked after /start")
+	}
+}
+
+func TestUpdateRejectsInvalidStatus(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleUpdate, "1", "/update steins gate finished"); !containsAny(replies, "Invalid status") {
+		t.Errorf("got %q, want the status rejected before looking up the title", replies)
+	}
+}
+
+func TestUpdateByTitle(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t),
+		fmab,
+		models.AnimeData{MalID: 9253, Title: "Steins;Gate", Type: "TV"},
+		models.AnimeData{MalID: 30484, Title: "Steins;Gate 0", Type: "TV"},
+	)
+	for _, id := range []string{"5114", "9253", "30484"} {
+		env.command(t, 1, 1, "/add "+id+" watching")
+	}
+
+	t.Run("single match", func(t *testing.T) {
+		if replies := env.command(t, 1, 1, "/update fullmetal completed"); !containsAny(replies, "updated anime status to: <b>completed</b>") {
+			t.Fatalf("got %q, want the status updated", replies)
+		}
+		entries, _, err := env.users.GetUserList("1", "completed", 1, 10)
+		if err != nil || len(entries) != 1 || entries[0].Media.ExternalID != "5114" {
+			t.Errorf("got completed %+v (err %v), want only 5114", entries, err)
+		}
+	})
+
+	t.Run("exact match wins", func(t *testing.T) {
+		if replies := env.command(t, 1, 1, "/update Steins;Gate on_hold"); !containsAny(replies, "updated anime status to: <b>on_hold</b>") {
+			t.Errorf("got %q, want the exact title updated", replies)
+		}
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		env.command(t, 1, 1, "/update gate dropped")
+		sent := env.telegram.lastSent(t)
+		if !strings.Contains(sent.Text, "More than one anime in your list matches \"gate\"") {
+			t.Fatalf("got %q, want the candidates offered", sent.Text)
+		}
+		if sent.Keyboard == nil || len(sent.Keyboard.InlineKeyboard) != 2 {
+			t.Fatalf("got keyboard %+v, want a button per match", sent.Keyboard)
+		}
+		for _, row := range sent.Keyboard.InlineKeyboard {
+			data, err := env.handler.decodeCallbackData(context.Background(), row[0].CallbackData)
+			if err != nil {
+				t.Fatalf("failed to decode button: %v", err)
+			}
+			if data.Action != "update_status" || data.Status != "dropped" {
+				t.Errorf("button %q got %+v, want update_status to dropped", row[0].Text, data)
+			}
+		}
+		checkKeyboardLimits(t, sent.Keyboard)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		if replies := env.command(t, 1, 1, "/update <b>naruto completed"); !containsAny(replies, "No anime matching \"&lt;b&gt;naruto\" in your list") {
+			t.Errorf("got %q, want no match reported with the title escaped", replies)
+		}
+	})
+}
+
+func TestFormatWhoami(t *testing.T) {
+	username := "<b>tester"
+	user := &models.AppUser{ID: "1", Username: &username, Platform: "telegram"}
+	settings := models.DefaultUserSettings()
+	settings.LanguageCode = "de"
+	settings.IsPublic = true
+
+	message := formatWhoami(user, &settings, "1", models.ChatModePersonal)
+	for _, want := range []string{
+		"🆔 User ID: <code>1</code>",
+		"👤 Username: @&lt;b&gt;tester",
+		"📱 Platform: telegram",
+		"💬 Chat ID: <code>1</code> (private chat)",
+		"🕐 Time zone: UTC",
+		"🌐 Language: de",
+		"🔞 Adult content: off",
+		"🏆 On leaderboard: on",
+		"🔔 Notifications: on",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("got %q, want it to contain %q", message, want)
+		}
+	}
+
+	user.Username = nil
+	message = formatWhoami(user, &models.UserSettings{Timezone: "UTC"}, "-1001", models.ChatModePersonal)
+	if !strings.Contains(message, "👤 Username: <i>not set</i>") || !strings.Contains(message, "(group chat, your list commands use your own list)") {
+		t.Errorf("got %q, want no username and the group chat explained", message)
+	}
+	if strings.Contains(message, "Language") {
+		t.Errorf("got %q, want no language line without one", message)
+	}
+
+	message = formatWhoami(user, &models.UserSettings{Timezone: "UTC"}, "-1001", models.ChatModeShared)
+	if !strings.Contains(message, "(shared group chat, /add, /list and the other list commands use the group's list)") {
+		t.Errorf("got %q, want the shared list explained", message)
+	}
+}
+
+func TestWhoami(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+
+	replies := env.command(t, 1, testGroupChat, "/whoami")
+	if !containsAny(replies, "🆔 User ID: <code>1</code>") || !containsAny(replies, "👤 Username: @tester") {
+		t.Errorf("got %q, want the stored user", replies)
+	}
+	if !containsAny(replies, "💬 Chat ID: <code>-1001</code> (group chat") {
+		t.Errorf("got %q, want the group chat noted", replies)
+	}
+}
+
+func TestWhoamiInSharedChat(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+	env.telegram.members[1] = "administrator"
+	env.command(t, 1, testGroupChat, "/groupmode shared")
+
+	replies := env.command(t, 2, testGroupChat, "/whoami")
+	if !containsAny(replies, "🆔 User ID: <code>2</code>") || !containsAny(replies, "(shared group chat,") {
+		t.Errorf("got %q, want the member told the group's list is used", replies)
+	}
+}
+
+// listItems returns n list entries with the given status, titled "<status> N".
+func listItems(status models.Status, n int) []models.UserMediaWithDetails {
+	items := make([]models.UserMediaWithDetails, n)
+	for i := range items {
+		items[i] = models.UserMediaWithDetails{
+			UserMedia: models.UserMedia{Status: status},
+			Media:     models.Media{ExternalID: strconv.Itoa(i + 1), Title: fmt.Sprintf("%s %d", status, i+1)},
+		}
+	}
+	return items
+}
+
+func TestGroupedListCapsEachStatus(t *testing.T) {
+	env := newTestEnv(t)
+
+	list := append(listItems(models.StatusWatching, 12), listItems(models.StatusCompleted, 3)...)
+	message := env.handler.formatUserList(list, listFilter{}, 1, len(list), 50)
+
+	if !strings.Contains(message, "watching 10 (") || strings.Contains(message, "watching 11 (") {
+		t.Errorf("got %q, want the first 10 watching entries", message)
+	}
+	if !strings.Contains(message, "<i>...and 2 more, use /list watching</i>") {
+		t.Errorf("got %q, want the hint for the 2 hidden watching entries", message)
+	}
+	if !strings.Contains(message, "completed 3 (") || strings.Contains(message, "use /list completed") {
+		t.Errorf("got %q, want every completed entry without a hint", message)
+	}
+	if !strings.Contains(message, "Watching (12):") {
+		t.Errorf("got %q, want the group's full count in its heading", message)
+	}
+}
+
+func TestListGroupLimitConfigurable(t *testing.T) {
+	env := newTestEnv(t)
+	list := listItems(models.StatusWatching, 12)
+
+	env.handler.SetListGroupLimit(3)
+	if message := env.handler.formatUserList(list, listFilter{}, 1, len(list), 50); !strings.Contains(message, "...and 9 more") {
+		t.Errorf("limit 3 got %q, want 9 hidden", message)
+	}
+
+	env.handler.SetListGroupLimit(0)
+	if message := env.handler.formatUserList(list, listFilter{}, 1, len(list), 50); strings.Contains(message, "more, use /list") {
+		t.Errorf("limit 0 got %q, want every entry", message)
+	}
+
+	// a status filter lists every entry on the page
+	env.handler.SetListGroupLimit(3)
+	if message := env.handler.formatUserList(list, listFilter{Status: "watching"}, 1, len(list), 50); strings.Contains(message, "more, use /list") {
+		t.Errorf("filtered list got %q, want no cap", message)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+
+	if replies := env.command(t, 1, 1, "/pause"); !containsAny(replies, "Notifications paused") {
+		t.Errorf("got %q, want notifications paused", replies)
+	}
+	if replies := env.command(t, 1, 1, "/whoami"); !containsAny(replies, "🔔 Notifications: off") {
+		t.Errorf("got %q, want notifications off", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/resume"); !containsAny(replies, "Notifications resumed") {
+		t.Errorf("got %q, want notifications resumed", replies)
+	}
+	if replies := env.command(t, 1, 1, "/whoami"); !containsAny(replies, "🔔 Notifications: on") {
+		t.Errorf("got %q, want notifications on", replies)
+	}
+}
+
+func TestSilentReminders(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+
+	if replies := env.command(t, 1, 1, "/silent"); !containsAny(replies, "Silent reminders are currently <b>off</b>") {
+		t.Errorf("got %q, want silent reminders off by default", replies)
+	}
+	if replies := env.command(t, 1, 1, "/silent ON"); !containsAny(replies, "🔕 Reminders will now arrive silently.") {
+		t.Errorf("got %q, want silent reminders turned on", replies)
+	}
+	if replies := env.command(t, 1, 1, "/silent"); !containsAny(replies, "Silent reminders are currently <b>on</b>") {
+		t.Errorf("got %q, want silent reminders on", replies)
+	}
+	if replies := env.command(t, 1, 1, "/silent off"); !containsAny(replies, "🔔 Reminders will now arrive with a notification sound.") {
+		t.Errorf("got %q, want silent reminders turned off", replies)
+	}
+}
+
+func TestSilentRejectsUnknownValue(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleSilent, "1", "/silent maybe"); len(replies) != 1 || replies[0] != "<b>Usage:</b> /silent on|off" {
+		t.Errorf("got %q, want the usage", replies)
+	}
+}
+
+func TestListAllKeyword(t *testing.T) {
+	var anime []models.AnimeData
+	for id := 1; id <= 6; id++ {
+		anime = append(anime, models.AnimeData{MalID: id, Title: fmt.Sprintf("Anime %d", id), Type: "TV"})
+	}
+	env := newTestEnvWithDB(t, testutil.DB(t), anime...)
+
+	for id := 1; id <= 5; id++ {
+		if err := env.users.AddToUserList("1", id, models.StatusWatching, nil, nil); err != nil {
+			t.Fatalf("failed to add anime %d: %v", id, err)
+		}
+	}
+	env.users.AddToUserList("1", 6, models.StatusCompleted, nil, nil)
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"/list all", []string{"<b>📋 Your Anime List</b>", "Page 1 of 2"}},
+		{"/list ALL 2", []string{"<b>📋 Your Anime List</b>", "Page 2 of 2"}},
+		{"/list completed", []string{"<b>📋 Your Completed Anime List</b>", "Page 1 of 1", "Anime 6"}},
+	}
+
+	for _, tt := range tests {
+		got := strings.Join(env.command(t, 1, 1, tt.text), "")
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%q got %q, want it to contain %q", tt.text, got, want)
+			}
+		}
+	}
+
+	if got := strings.Join(env.command(t, 1, 1, "/list completed"), ""); strings.Contains(got, "Anime 1 ") {
+		t.Errorf("got %q, want only completed entries", got)
+	}
+}
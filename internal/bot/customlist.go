@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func (h *Handler) handleNewList(ctx context.Context, cmd BotCommand) {
+	name := strings.TrimSpace(strings.Join(cmd.Args, " "))
+	if name == "" {
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /newlist &lt;name&gt;\n<b>Example:</b> <code>/newlist Movie Night</code>")
+		return
+	}
+
+	if utf8.RuneCountInString(name) > models.MaxCustomListNameLength {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ List names can be at most %d characters.", models.MaxCustomListNameLength))
+		return
+	}
+
+	list, err := h.userService.CreateCustomList(cmd.UserID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCustomListExists):
+			h.sendMessage(ctx, cmd.ChatID, "❌ You already have a list with that name.")
+		case errors.Is(err, services.ErrCustomListLimit):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ You can have at most %d lists.", models.MaxCustomListsPerUser))
+		default:
+			h.logger.WithError(err).Error("Failed to create custom list")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't create your list. Please try again later.")
+		}
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Created list <b>%s</b>.\n\nAdd anime with <code>/addto %s &lt;anime_id&gt;</code>",
+		esc(list.Name), esc(list.Name)))
+}
+
+// handleAddTo adds an anime to a custom list. The anime ID is the last argument so
+// list names can contain spaces: /addto Movie Night 5114
+func (h *Handler) handleAddTo(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) < 2 {
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /addto &lt;list name&gt; &lt;anime_id&gt;\n<b>Example:</b> <code>/addto Movie Night 5114</code>")
+		return
+	}
+
+	animeID, err := strconv.Atoi(cmd.Args[len(cmd.Args)-1])
+	if err != nil || animeID <= 0 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please provide a valid number.")
+		return
+	}
+	name := strings.Join(cmd.Args[:len(cmd.Args)-1], " ")
+
+	media, err := h.userService.AddToCustomList(cmd.UserID, name, animeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCustomListNotFound):
+			h.sendMessage(ctx, cmd.ChatID, "❌ You don't have a list with that name. See /mylists or create one with /newlist.")
+		case errors.Is(err, services.ErrAlreadyInList):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("ℹ️ <b>%s</b> is already in that list.", esc(media.Title)))
+		case strings.Contains(err.Error(), "not found"):
+			h.sendMessage(ctx, cmd.ChatID, "❌ Anime not found. Please check the ID and try again.")
+		default:
+			h.logger.WithError(err).Error("Failed to add to custom list")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't add that anime. Please try again later.")
+		}
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Added <b>%s</b> to <b>%s</b>.", esc(media.Title), esc(name)))
+}
+
+func (h *Handler) handleShowList(ctx context.Context, cmd BotCommand) {
+	name := strings.TrimSpace(strings.Join(cmd.Args, " "))
+	if name == "" {
+		h.handleMyLists(ctx, cmd)
+		return
+	}
+
+	list, items, err := h.userService.GetCustomListItems(cmd.UserID, name)
+	if err != nil {
+		if errors.Is(err, services.ErrCustomListNotFound) {
+			h.sendMessage(ctx, cmd.ChatID, "❌ You don't have a list with that name. See /mylists.")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to get custom list")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve that list. Please try again later.")
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("<b>📂 %s</b> (%d)\n\n", esc(list.Name), len(items)))
+
+	if len(items) == 0 {
+		message.WriteString(fmt.Sprintf("This list is empty. Add anime with <code>/addto %s &lt;anime_id&gt;</code>", esc(list.Name)))
+	}
+	for _, item := range items {
+		message.WriteString(fmt.Sprintf("• %s (ID: %s)\n", esc(item.Title), item.ExternalID))
+	}
+
+	for _, chunk := range splitMessage(message.String(), maxMessageLength) {
+		h.sendMessage(ctx, cmd.ChatID, chunk)
+	}
+}
+
+func (h *Handler) handleMyLists(ctx context.Context, cmd BotCommand) {
+	lists, err := h.userService.GetCustomLists(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get custom lists")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your lists. Please try again later.")
+		return
+	}
+
+	if len(lists) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "📂 You don't have any custom lists yet.\n\nCreate one with <code>/newlist Movie Night</code>")
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString("<b>📂 Your Lists</b>\n\n")
+	for _, list := range lists {
+		message.WriteString(fmt.Sprintf("• <b>%s</b> (%d)\n", esc(list.Name), list.ItemCount))
+	}
+	message.WriteString("\n<i>Use /showlist &lt;name&gt; to open one.</i>")
+
+	h.sendMessage(ctx, cmd.ChatID, message.String())
+}
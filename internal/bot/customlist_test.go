@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"context"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestCustomListValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	tests := []struct {
+		handle func(context.Context, BotCommand)
+		text   string
+		want   string
+	}{
+		{env.handler.handleNewList, "/newlist", "Usage:</b> /newlist"},
+		{env.handler.handleNewList, "/newlist " + strings.Repeat("a", models.MaxCustomListNameLength+1), "at most 50 characters"},
+		{env.handler.handleAddTo, "/addto 5114", "Usage:</b> /addto"},
+		{env.handler.handleAddTo, "/addto Movie Night abc", "Invalid anime ID"},
+		{env.handler.handleAddTo, "/addto Movie Night -1", "Invalid anime ID"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(tt.handle, "1", tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%q got %q, want %q", tt.text, replies, tt.want)
+		}
+	}
+}
+
+func TestCustomListCommands(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	if replies := env.command(t, 1, 1, "/mylists"); !containsAny(replies, "don't have any custom lists") {
+		t.Errorf("got %q, want no lists yet", replies)
+	}
+	if replies := env.command(t, 1, 1, "/newlist Movie Night"); !containsAny(replies, "Created list <b>Movie Night</b>") {
+		t.Fatalf("got %q, want the list created", replies)
+	}
+	if replies := env.command(t, 1, 1, "/newlist movie night"); !containsAny(replies, "already have a list") {
+		t.Errorf("got %q, want the duplicate rejected", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/addto Movie Night 5114"); !containsAny(replies, "Added <b>"+fmab.Title+"</b>") {
+		t.Errorf("got %q, want the anime added", replies)
+	}
+	if replies := env.command(t, 1, 1, "/addto Movie Night 5114"); !containsAny(replies, "already in that list") {
+		t.Errorf("got %q, want it already in the list", replies)
+	}
+	if replies := env.command(t, 1, 1, "/addto Nope 5114"); !containsAny(replies, "don't have a list with that name") {
+		t.Errorf("got %q, want the missing list reported", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/showlist movie night"); !containsAny(replies, fmab.Title+" (ID: 5114)") {
+		t.Errorf("got %q, want the list's anime", replies)
+	}
+	if replies := env.command(t, 1, 1, "/mylists"); !containsAny(replies, "<b>Movie Night</b> (1)") {
+		t.Errorf("got %q, want the list with its count", replies)
+	}
+}
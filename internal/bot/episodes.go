@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+)
+
+const (
+	charactersPerPage = 10
+	// Jikan pages hold 100 episodes, too long for one message, so each is shown in parts
+	episodesPerPage = 25
+)
+
+// handleCallbackCharacterPage shows a page of an anime's cast. Page 0 comes from the
+// details keyboard and opens the first page in a new message; the pager buttons
+// carry real page numbers and edit that message in place.
+func (h *Handler) handleCallbackCharacterPage(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	animeID, err := strconv.Atoi(data.AnimeID)
+	if err != nil {
+		h.answerCallback(ctx, callback.Id, "❌ Invalid anime ID", false)
+		return
+	}
+
+	characters, err := h.animeService.GetAnimeCharacters(animeID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get anime characters")
+		h.answerCallback(ctx, callback.Id, pageLoadError(err, "characters"), true)
+		return
+	}
+
+	if len(characters) == 0 {
+		h.answerCallback(ctx, callback.Id, "No characters listed for this anime", true)
+		return
+	}
+
+	totalPages := (len(characters) + charactersPerPage - 1) / charactersPerPage
+	page := min(max(data.Page, 1), totalPages)
+	start := (page - 1) * charactersPerPage
+	end := min(start+charactersPerPage, len(characters))
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("<b>👥 Characters</b> - %s\n\n", esc(h.animeTitle(animeID))))
+	for i, character := range characters[start:end] {
+		role := ""
+		if character.Role == "Main" {
+			role = " ⭐"
+		}
+		message.WriteString(fmt.Sprintf("%d. %s%s\n", start+i+1, esc(character.Name), role))
+	}
+
+	keyboard := h.createPagerKeyboard(ctx, "char_page", data.AnimeID, page, totalPages, page < totalPages)
+	h.showPage(ctx, callback, data.Page, chatID, message.String(), keyboard)
+}
+
+// handleCallbackEpisodePage shows a page of an anime's episodes. Like characters, page 0
+// opens the view in a new message. Each Jikan page is split into episodesPerPage parts.
+func (h *Handler) handleCallbackEpisodePage(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	animeID, err := strconv.Atoi(data.AnimeID)
+	if err != nil {
+		h.answerCallback(ctx, callback.Id, "❌ Invalid anime ID", false)
+		return
+	}
+
+	page := max(data.Page, 1)
+	partsPerJikanPage := models.JikanEpisodesPageSize / episodesPerPage
+	jikanPage := (page-1)/partsPerJikanPage + 1
+	offset := (page - 1) % partsPerJikanPage * episodesPerPage
+
+	episodes, err := h.animeService.GetAnimeEpisodes(animeID, jikanPage)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get anime episodes")
+		h.answerCallback(ctx, callback.Id, pageLoadError(err, "episodes"), true)
+		return
+	}
+
+	if offset >= len(episodes.Episodes) {
+		if page == 1 {
+			h.answerCallback(ctx, callback.Id, "No episodes listed for this anime", true)
+		} else {
+			h.answerCallback(ctx, callback.Id, "No more episodes", false)
+		}
+		return
+	}
+
+	shown := episodes.Episodes[offset:min(offset+episodesPerPage, len(episodes.Episodes))]
+	hasNext := offset+episodesPerPage < len(episodes.Episodes) || episodes.HasNext
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("<b>📺 Episodes</b> - %s\n\n", esc(h.animeTitle(animeID))))
+	for _, episode := range shown {
+		title := episode.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		message.WriteString(fmt.Sprintf("<code>%4d</code> %s", episode.MalID, esc(title)))
+		if episode.Filler {
+			message.WriteString(" <i>(filler)</i>")
+		} else if episode.Recap {
+			message.WriteString(" <i>(recap)</i>")
+		}
+		message.WriteString("\n")
+	}
+
+	// the total is only known once the last Jikan page is reached
+	totalPages := 0
+	if !episodes.HasNext {
+		totalPages = (jikanPage-1)*partsPerJikanPage + (len(episodes.Episodes)+episodesPerPage-1)/episodesPerPage
+	}
+
+	keyboard := h.createPagerKeyboard(ctx, "ep_page", data.AnimeID, page, totalPages, hasNext)
+	h.showPage(ctx, callback, data.Page, chatID, message.String(), keyboard)
+}
+
+// createPagerKeyboard builds a previous/page/next row for the paged detail views.
+// totalPages is 0 when unknown; the next button only shows when hasNext is set.
+func (h *Handler) createPagerKeyboard(ctx context.Context, action, animeID string, page, totalPages int, hasNext bool) *models.InlineKeyboardMarkup {
+	var buttons []models.InlineKeyboardButton
+
+	if page > 1 {
+		data := h.encodeCallbackData(ctx, models.CallbackData{Action: action, AnimeID: animeID, Page: page - 1})
+		buttons = append(buttons, models.InlineKeyboardButton{Text: "⬅️ Previous", CallbackData: data})
+	}
+
+	pageInfo := fmt.Sprintf("📄 %d", page)
+	if totalPages > 0 {
+		pageInfo = fmt.Sprintf("📄 %d/%d", page, totalPages)
+	}
+	buttons = append(buttons, models.InlineKeyboardButton{Text: pageInfo, CallbackData: "noop"})
+
+	if hasNext {
+		data := h.encodeCallbackData(ctx, models.CallbackData{Action: action, AnimeID: animeID, Page: page + 1})
+		buttons = append(buttons, models.InlineKeyboardButton{Text: "Next ➡️", CallbackData: data})
+	}
+
+	if len(buttons) <= 1 { // Only page info button
+		return nil
+	}
+
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{buttons},
+	}
+}
+
+// showPage sends the first page of a view as a new message, leaving the details
+// message it was opened from alone, and edits later pages in place.
+func (h *Handler) showPage(ctx context.Context, callback *models.CallbackQuery, requestedPage int, chatID, message string, keyboard *models.InlineKeyboardMarkup) {
+	h.answerCallback(ctx, callback.Id, "", false)
+	if requestedPage == 0 {
+		h.sendMessageWithKeyboard(ctx, chatID, message, keyboard)
+	} else {
+		h.editMessage(ctx, chatID, callback.Message.MessageId, message, keyboard)
+	}
+}
+
+// animeTitle returns the anime's title for headings, falling back to its ID.
+func (h *Handler) animeTitle(animeID int) string {
+	anime, err := h.animeService.GetAnimeByID(animeID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to get anime title")
+		return fmt.Sprintf("Anime #%d", animeID)
+	}
+	return anime.Title
+}
+
+func pageLoadError(err error, what string) string {
+	if errors.Is(err, services.ErrServiceUnavailable) {
+		return animeServiceDownMessage
+	}
+	return "❌ Failed to get " + what
+}
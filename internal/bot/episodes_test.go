@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// pagerButtons returns the pager row's button texts, and the page each of its
+// previous and next buttons leads to, 0 when there's no such button.
+func pagerButtons(t *testing.T, env *testEnv, keyboard *models.InlineKeyboardMarkup) (texts []string, prev, next int) {
+	t.Helper()
+
+	if keyboard == nil {
+		return nil, 0, 0
+	}
+	for _, button := range keyboard.InlineKeyboard[0] {
+		texts = append(texts, button.Text)
+		if button.CallbackData == "noop" {
+			continue
+		}
+		data, err := env.handler.decodeCallbackData(context.Background(), button.CallbackData)
+		if err != nil {
+			t.Fatalf("failed to decode %q: %v", button.Text, err)
+		}
+		if strings.Contains(button.Text, "Previous") {
+			prev = data.Page
+		} else {
+			next = data.Page
+		}
+	}
+	return texts, prev, next
+}
+
+// openPage presses a pager button for page of action, returning the message it sent or edited.
+func openPage(t *testing.T, env *testEnv, action string, page int) sentMessage {
+	t.Helper()
+
+	callback := &models.CallbackQuery{Id: "cb-1", Message: models.Message{MessageId: 10}}
+	data := &models.CallbackData{Action: action, AnimeID: "5114", Page: page}
+	sent, edits := len(env.telegram.sent), len(env.telegram.edits)
+
+	if action == "char_page" {
+		env.handler.handleCallbackCharacterPage(context.Background(), callback, data, "1", "1")
+	} else {
+		env.handler.handleCallbackEpisodePage(context.Background(), callback, data, "1", "1")
+	}
+
+	switch {
+	case len(env.telegram.sent) > sent:
+		if page != 0 {
+			t.Errorf("page %d sent a new message, want the view edited", page)
+		}
+		return env.telegram.sent[len(env.telegram.sent)-1]
+	case len(env.telegram.edits) > edits:
+		return env.telegram.edits[len(env.telegram.edits)-1]
+	}
+	return sentMessage{}
+}
+
+func TestCharacterPages(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.anime.cast = map[int][]models.Character{5114: make([]models.Character, 23)}
+	for i := range env.anime.cast[5114] {
+		env.anime.cast[5114][i] = models.Character{MalID: i + 1, Name: fmt.Sprintf("Character %d", i+1)}
+	}
+
+	tests := []struct {
+		page       int
+		first      string
+		last       string
+		wantPager  string
+		prev, next int
+	}{
+		{0, "1. Character 1\n", "10. Character 10\n", "📄 1/3", 0, 2},
+		{2, "11. Character 11\n", "20. Character 20\n", "📄 2/3", 1, 3},
+		{3, "21. Character 21\n", "23. Character 23\n", "📄 3/3", 2, 0},
+		{99, "21. Character 21\n", "23. Character 23\n", "📄 3/3", 2, 0}, // past the end shows the last page
+	}
+
+	for _, tt := range tests {
+		msg := openPage(t, env, "char_page", tt.page)
+		if !strings.Contains(msg.Text, fmab.Title) || !strings.Contains(msg.Text, tt.first) || !strings.Contains(msg.Text, tt.last) {
+			t.Errorf("page %d got %q, want %q to %q", tt.page, msg.Text, tt.first, tt.last)
+		}
+		texts, prev, next := pagerButtons(t, env, msg.Keyboard)
+		if !slices.Contains(texts, tt.wantPager) || prev != tt.prev || next != tt.next {
+			t.Errorf("page %d got buttons %q leading to %d and %d, want %s leading to %d and %d", tt.page, texts, prev, next, tt.wantPager, tt.prev, tt.next)
+		}
+	}
+}
+
+func TestCharacterPagesSingle(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.anime.cast = map[int][]models.Character{5114: {{MalID: 1, Name: "Edward Elric", Role: "Main"}}}
+
+	msg := openPage(t, env, "char_page", 0)
+	if !strings.Contains(msg.Text, "1. Edward Elric ⭐") || msg.Keyboard != nil {
+		t.Errorf("got %q with keyboard %+v, want the one character and no pager", msg.Text, msg.Keyboard)
+	}
+}
+
+func TestEpisodePages(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	// two Jikan pages, so the total is unknown until the second is fetched
+	env.anime.eps = map[int][]models.Episode{5114: make([]models.Episode, 130)}
+	for i := range env.anime.eps[5114] {
+		env.anime.eps[5114][i] = models.Episode{MalID: i + 1, Title: fmt.Sprintf("Episode %d", i+1)}
+	}
+
+	tests := []struct {
+		page       int
+		first      string
+		last       string
+		wantPager  string
+		prev, next int
+	}{
+		{0, "   1</code> Episode 1\n", "  25</code> Episode 25\n", "📄 1", 0, 2},
+		{4, "  76</code> Episode 76\n", " 100</code> Episode 100\n", "📄 4", 3, 5},
+		{5, " 101</code> Episode 101\n", " 125</code> Episode 125\n", "📄 5/6", 4, 6},
+		{6, " 126</code> Episode 126\n", " 130</code> Episode 130\n", "📄 6/6", 5, 0},
+	}
+
+	for _, tt := range tests {
+		msg := openPage(t, env, "ep_page", tt.page)
+		if !strings.Contains(msg.Text, tt.first) || !strings.Contains(msg.Text, tt.last) {
+			t.Errorf("page %d got %q, want %q to %q", tt.page, msg.Text, tt.first, tt.last)
+		}
+		texts, prev, next := pagerButtons(t, env, msg.Keyboard)
+		if !slices.Contains(texts, tt.wantPager) || prev != tt.prev || next != tt.next {
+			t.Errorf("page %d got buttons %q leading to %d and %d, want %s leading to %d and %d", tt.page, texts, prev, next, tt.wantPager, tt.prev, tt.next)
+		}
+	}
+
+	if msg := openPage(t, env, "ep_page", 7); msg.Text != "" {
+		t.Errorf("got %q past the last page, want nothing shown", msg.Text)
+	}
+	if answers := env.telegram.answersFor("cb-1"); answers[len(answers)-1].Text != "No more episodes" {
+		t.Errorf("got answer %+v, want no more episodes", answers[len(answers)-1])
+	}
+}
+
+func TestEpisodePagesEmpty(t *testing.T) {
+	env := newTestEnv(t, fmab)
+
+	openPage(t, env, "ep_page", 0)
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "No episodes listed for this anime" {
+		t.Errorf("got answers %+v, want no episodes listed", answers)
+	}
+}
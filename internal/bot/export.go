@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+)
+
+// handleExport sends the user's list as a file: /export [json|csv]
+func (h *Handler) handleExport(ctx context.Context, cmd BotCommand) {
+	format := "json"
+	if len(cmd.Args) > 0 {
+		format = strings.ToLower(cmd.Args[0])
+	}
+
+	var data []byte
+	var count int
+	var err error
+	switch format {
+	case "json":
+		data, count, err = h.userService.ExportJSON(cmd.UserID)
+	case "csv":
+		data, count, err = h.userService.ExportCSV(cmd.UserID)
+	default:
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /export [json|csv]\n\nJSON keeps notes and tags, CSV opens in any spreadsheet.")
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to export user list")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't export your list. Please try again later.")
+		return
+	}
+
+	if count == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "📭 Your list is empty, there's nothing to export yet.")
+		return
+	}
+
+	chatID, err := strconv.Atoi(cmd.ChatID)
+	if err != nil {
+		h.logger.WithError(err).Error("Invalid chat ID")
+		return
+	}
+
+	caption := fmt.Sprintf("💾 Your anime list, %d %s", count, pluralize(count, "entry", "entries"))
+	if err := h.telegram.SendDocument(ctx, chatID, data, services.ExportFilename(format), caption); err != nil {
+		h.logger.WithError(err).Error("Failed to send export")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't send your export. Please try again later.")
+	}
+}
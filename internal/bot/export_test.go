@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleExport, "1", "/export xml"); !containsAny(replies, "Usage:</b> /export [json|csv]") {
+		t.Errorf("got %q, want the usage", replies)
+	}
+	if len(env.telegram.documents) != 0 {
+		t.Errorf("sent %q, want no document", env.telegram.documents)
+	}
+}
+
+func TestExportSendsDocument(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	if replies := env.command(t, 1, 1, "/export csv"); !containsAny(replies, "nothing to export") {
+		t.Errorf("got %q, want the empty list message", replies)
+	}
+
+	env.command(t, 1, 1, "/add 5114 watching")
+	env.command(t, 1, 1, "/export csv")
+	env.command(t, 1, 1, "/export")
+
+	if len(env.telegram.documents) != 2 || !strings.HasSuffix(env.telegram.documents[0], ".csv") || !strings.HasSuffix(env.telegram.documents[1], ".json") {
+		t.Errorf("got documents %q, want a CSV then a JSON export", env.telegram.documents)
+	}
+}
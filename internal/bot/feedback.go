@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/services"
+	"strings"
+	"unicode/utf8"
+)
+
+const feedbackListLimit = 20
+
+// SetAdmins sets which users may run admin commands and the chat new feedback is forwarded to.
+// An empty chat ID disables forwarding.
+func (h *Handler) SetAdmins(userIDs []string, chatID string) {
+	h.adminIDs = make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		h.adminIDs[id] = true
+	}
+	h.adminChatID = chatID
+}
+
+func (h *Handler) isAdmin(userID string) bool {
+	return h.adminIDs[userID]
+}
+
+func (h *Handler) handleFeedback(ctx context.Context, cmd BotCommand) {
+	message := strings.TrimSpace(strings.Join(cmd.Args, " "))
+	if message == "" {
+		h.sendMessage(ctx, cmd.ChatID, "💬 Please include your message.\n\n<b>Usage:</b> /feedback &lt;message&gt;\n<b>Example:</b> <code>/feedback The search doesn't find sequels</code>")
+		return
+	}
+
+	if utf8.RuneCountInString(message) > services.MaxFeedbackLength {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ Feedback is too long (max %d characters).", services.MaxFeedbackLength))
+		return
+	}
+
+	feedback, err := h.feedbackService.Submit(cmd.UserID, message)
+	if err != nil {
+		if errors.Is(err, services.ErrFeedbackRateLimited) {
+			h.sendMessage(ctx, cmd.ChatID, "⏳ You've just sent feedback. Please wait a minute before sending more.")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to save feedback")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't save your feedback. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, "🙏 Thanks! Your feedback has been sent.")
+
+	if h.adminChatID != "" {
+		h.sendMessage(ctx, h.adminChatID, fmt.Sprintf("💬 <b>New feedback #%d</b> from <code>%s</code>\n\n%s",
+			feedback.ID, cmd.UserID, esc(message)))
+	}
+}
+
+func (h *Handler) handleFeedbackList(ctx context.Context, cmd BotCommand) {
+	if !h.isAdmin(cmd.UserID) {
+		h.sendMessage(ctx, cmd.ChatID, "Unknown command. Use /help to see available commands")
+		return
+	}
+
+	feedback, err := h.feedbackService.List(feedbackListLimit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list feedback")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve feedback. Please try again later.")
+		return
+	}
+
+	if len(feedback) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "💬 No feedback yet.")
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("<b>💬 Latest Feedback (%d)</b>\n\n", len(feedback)))
+
+	for _, f := range feedback {
+		from := f.UserID
+		if f.Username != "" {
+			from = "@" + f.Username
+		}
+		message.WriteString(fmt.Sprintf("<b>#%d</b> %s · %s\n%s\n\n",
+			f.ID, esc(from), f.CreatedAt.Format("Jan 2, 2006 15:04"), esc(f.Message)))
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, message.String())
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"sletish/internal/services"
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestFeedbackValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleFeedback, "1", "/feedback"); !containsAny(replies, "Please include your message") {
+		t.Errorf("got %q, want the usage", replies)
+	}
+
+	long := "/feedback " + strings.Repeat("鋼", services.MaxFeedbackLength+1)
+	if replies := env.run(env.handler.handleFeedback, "1", long); !containsAny(replies, "Feedback is too long") {
+		t.Errorf("got %q, want it rejected as too long", replies)
+	}
+}
+
+func TestFeedbackListIsAdminOnly(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetAdmins([]string{"99"}, "")
+
+	if replies := env.run(env.handler.handleFeedbackList, "1", "/feedbacklist"); !containsAny(replies, "Unknown command") {
+		t.Errorf("got %q, want it hidden from non-admins", replies)
+	}
+}
+
+func TestFeedbackIsForwardedAndListed(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+	env.handler.SetAdmins([]string{"99"}, "-500")
+
+	replies := env.command(t, 1, 1, "/feedback the <b>search</b> misses sequels")
+	if !containsAny(replies, "Thanks! Your feedback has been sent.") {
+		t.Fatalf("got %q, want the feedback acknowledged", replies)
+	}
+
+	forwarded := env.telegram.lastSent(t)
+	if forwarded.ChatID != -500 || !strings.Contains(forwarded.Text, "the &lt;b&gt;search&lt;/b&gt; misses sequels") {
+		t.Errorf("got %q in chat %d, want the escaped feedback in the admin chat", forwarded.Text, forwarded.ChatID)
+	}
+
+	if replies := env.command(t, 1, 1, "/feedback again"); !containsAny(replies, "Please wait a minute") {
+		t.Errorf("got %q, want the second message rate limited", replies)
+	}
+
+	replies = env.command(t, 99, 99, "/feedbacklist")
+	if !containsAny(replies, "Latest Feedback (1)") || !containsAny(replies, "@tester") {
+		t.Errorf("got %q, want the one feedback listed with its sender", replies)
+	}
+}
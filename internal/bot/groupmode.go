@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+)
+
+// sharedListCommands are the commands that act on the chat's list instead of the
+// sender's when the chat is in shared mode.
+var sharedListCommands = map[string]bool{
+	"/add":      true,
+	"/remove":   true,
+	"/undo":     true,
+	"/restore":  true,
+	"/trash":    true,
+	"/list":     true,
+	"/info":     true,
+	"/update":   true,
+	"/progress": true,
+	"/tag":      true,
+	"/untag":    true,
+}
+
+// sharedListActions are the callback actions that do the same for their buttons.
+var sharedListActions = map[string]bool{
+	"add_anime":           true,
+	"update_status":       true,
+	"remove_anime":        true,
+	"view_details":        true,
+	"list_page":           true,
+	"confirm_remove":      true,
+	"bulk_status":         true,
+	"confirm_bulk_status": true,
+}
+
+// pendingKey returns where a sender's pending action is kept: their user ID in a private
+// chat, and the chat and user together in a group. Confirmations and the add wizard are
+// always the sender's own, even in a shared chat where the list they act on is the chat's.
+func pendingKey(senderID, chatID string) string {
+	if senderID == chatID {
+		return senderID
+	}
+	return chatID + ":" + senderID
+}
+
+// callbackPendingKey is pendingKey for whoever pressed the button.
+func callbackPendingKey(callback *models.CallbackQuery, chatID string) string {
+	return pendingKey(strconv.Itoa(callback.From.Id), chatID)
+}
+
+const groupModeUsage = `<b>Usage:</b> /groupmode personal|shared
+
+• <b>personal</b> - everyone keeps their own list
+• <b>shared</b> - /add, /list, /update and the other list commands use one list for the whole group
+
+Only the group's admins can change it.`
+
+// handleGroupMode shows or changes whether a group chat shares one list.
+func (h *Handler) handleGroupMode(ctx context.Context, cmd BotCommand) {
+	if !services.IsGroupChat(cmd.ChatID) {
+		h.sendMessage(ctx, cmd.ChatID, "ℹ️ /groupmode only works in group chats.")
+		return
+	}
+
+	if len(cmd.Args) == 0 {
+		mode, err := h.userService.GetChatMode(cmd.ChatID)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to get chat mode")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't get this chat's mode. Please try again later.")
+			return
+		}
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("👥 This chat is in <b>%s</b> mode.\n\n%s", mode, groupModeUsage))
+		return
+	}
+
+	mode := models.ChatMode(strings.ToLower(cmd.Args[0]))
+	if len(cmd.Args) != 1 || !mode.IsValid() {
+		h.sendMessage(ctx, cmd.ChatID, groupModeUsage)
+		return
+	}
+
+	if !h.isChatAdmin(ctx, cmd) {
+		return
+	}
+
+	if err := h.userService.SetChatMode(cmd.ChatID, cmd.UserID, mode); err != nil {
+		h.logger.WithError(err).WithField("chat_id", cmd.ChatID).Error("Failed to update chat mode")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't change this chat's mode. Please try again later.")
+		return
+	}
+
+	if mode == models.ChatModeShared {
+		h.sendMessage(ctx, cmd.ChatID, "👥 This chat now shares one list. Anything added here with /add is visible to everyone, see it with /list.")
+	} else {
+		h.sendMessage(ctx, cmd.ChatID, "👤 Everyone here is back to their own list. The shared list is kept if you switch back.")
+	}
+}
+
+// isChatAdmin reports whether the sender is an admin or the creator of the chat, replying
+// with why not if they aren't.
+func (h *Handler) isChatAdmin(ctx context.Context, cmd BotCommand) bool {
+	chatID, err := strconv.Atoi(cmd.ChatID)
+	if err != nil {
+		h.logger.WithError(err).Error("Invalid chat ID for admin check")
+		return false
+	}
+	userID, err := strconv.Atoi(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Invalid user ID for admin check")
+		return false
+	}
+
+	member, err := h.telegram.GetChatMember(ctx, chatID, userID)
+	if err != nil {
+		h.logger.WithError(err).WithField("chat_id", cmd.ChatID).Error("Failed to get chat member")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't check your permissions in this chat. Please try again later.")
+		return false
+	}
+
+	if !member.IsAdmin() {
+		h.sendMessage(ctx, cmd.ChatID, "🔒 Only the group's admins can change its mode.")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"context"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"testing"
+)
+
+const testGroupChat = -1001
+
+func TestGroupModeRejectsNonAdmins(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.handler.handleGroupMode(context.Background(), BotCommand{
+		Command: "/groupmode",
+		Args:    []string{"shared"},
+		UserID:  "1",
+		ChatID:  "-1001",
+	})
+
+	if sent := env.telegram.lastSent(t); sent.Text != "🔒 Only the group's admins can change its mode." {
+		t.Errorf("got %q, want the admins only message", sent.Text)
+	}
+}
+
+func TestGroupModeAdminCanShare(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+
+	for _, status := range []string{"creator", "administrator"} {
+		t.Run(status, func(t *testing.T) {
+			env.telegram.members[1] = status
+			replies := env.command(t, 1, testGroupChat, "/groupmode shared")
+			if !containsAny(replies, "now shares one list") {
+				t.Fatalf("got %q, want the mode changed", replies)
+			}
+
+			mode, err := env.users.GetChatMode("-1001")
+			if err != nil {
+				t.Fatalf("failed to get chat mode: %v", err)
+			}
+			if mode != models.ChatModeShared {
+				t.Errorf("got mode %q, want shared", mode)
+			}
+
+			env.command(t, 1, testGroupChat, "/groupmode personal")
+		})
+	}
+}
+
+func TestSharedGroupCommandsUseChatList(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.telegram.members[1] = "administrator"
+
+	env.command(t, 1, testGroupChat, "/groupmode shared")
+	env.command(t, 1, testGroupChat, "/add 5114 watching")
+
+	owner, err := env.users.GetUser("-1001")
+	if err != nil {
+		t.Fatalf("failed to get the chat's list owner: %v", err)
+	}
+	if owner.Platform != "telegram_chat" {
+		t.Errorf("got owner platform %q, want telegram_chat", owner.Platform)
+	}
+
+	entries, _, err := env.users.GetUserList("-1001", "", 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get shared list: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Media.Title != fmab.Title {
+		t.Errorf("got shared list %+v, want only %q", entries, fmab.Title)
+	}
+
+	if own, _, err := env.users.GetUserList("1", "", 1, 10); err != nil || len(own) != 0 {
+		t.Errorf("got personal list %+v (err %v), want it empty", own, err)
+	}
+
+	if replies := env.command(t, 2, testGroupChat, "/list"); !containsAny(replies, fmab.Title) {
+		t.Errorf("another member's /list got %q, want the shared entry", replies)
+	}
+}
+
+func TestPersonalGroupCommandsUseSendersList(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	env.command(t, 1, testGroupChat, "/add 5114 watching")
+
+	if replies := env.command(t, 2, testGroupChat, "/list"); containsAny(replies, fmab.Title) {
+		t.Errorf("another member's /list got %q, want their own empty list", replies)
+	}
+
+	entries, _, err := env.users.GetUserList("1", "", 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get personal list: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d entries in the sender's list, want 1", len(entries))
+	}
+}
+
+func TestPendingKey(t *testing.T) {
+	tests := []struct {
+		senderID, chatID string
+		want             string
+	}{
+		{"1", "1", "1"},
+		{"1", "-1001", "-1001:1"},
+		{"2", "-1001", "-1001:2"},
+	}
+
+	for _, tt := range tests {
+		if got := pendingKey(tt.senderID, tt.chatID); got != tt.want {
+			t.Errorf("pendingKey(%q, %q) = %q, want %q", tt.senderID, tt.chatID, got, tt.want)
+		}
+	}
+}
+
+func TestGroupWizardBelongsToSender(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.anime.results = []models.AnimeData{fmab}
+	ctx := context.Background()
+
+	// in a shared chat /add acts on the chat's list, but the wizard is the sender's
+	env.handler.handleAdd(ctx, BotCommand{Command: "/add", UserID: "-1001", ChatID: "-1001", SenderID: "1"})
+	if step := wizardStep(t, env, "-1001:1"); step != models.WizardStepAwaitQuery {
+		t.Fatalf("got step %q for the sender, want %q", step, models.WizardStepAwaitQuery)
+	}
+
+	env.handler.handlePlainText(ctx, "2", "-1001", "lol")
+	if len(env.anime.queries) != 0 {
+		t.Errorf("another member's message searched %q, want it ignored", env.anime.queries)
+	}
+	if sent := env.telegram.lastSent(t); sent.Keyboard != nil {
+		t.Errorf("another member's message got results %+v, want none", sent.Keyboard)
+	}
+
+	env.handler.handlePlainText(ctx, "1", "-1001", "fullmetal")
+	if len(env.anime.queries) != 1 || env.anime.queries[0] != "fullmetal" {
+		t.Fatalf("got searches %q, want the sender's answer searched", env.anime.queries)
+	}
+	pick := env.telegram.lastSent(t).Keyboard.InlineKeyboard[0][0].CallbackData
+
+	env.press(2, testGroupChat, "cb-other", pick)
+	if answers := env.telegram.answersFor("cb-other"); len(answers) != 1 || answers[0].Text != "⌛ This menu has expired. Use /add to start again." {
+		t.Errorf("got answers %+v, want another member's pick refused", answers)
+	}
+	if step := wizardStep(t, env, "-1001:1"); step != models.WizardStepAwaitPick {
+		t.Errorf("got step %q after another member's pick, want %q", step, models.WizardStepAwaitPick)
+	}
+
+	env.press(1, testGroupChat, "cb-sender", pick)
+	if step := wizardStep(t, env, "-1001:1"); step != "" {
+		t.Errorf("got step %q after the sender's pick, want the wizard finished", step)
+	}
+}
+
+func TestSharedGroupCommandsLeaveOthersWizard(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.anime.results = []models.AnimeData{fmab}
+	env.telegram.members[1] = "administrator"
+	env.command(t, 1, testGroupChat, "/groupmode shared")
+
+	env.command(t, 1, testGroupChat, "/add")
+	env.command(t, 2, testGroupChat, "/list")
+	if replies := env.command(t, 2, testGroupChat, "lol"); !containsAny(replies, "Unknown command") {
+		t.Errorf("another member's message got %q, want it not taken as an answer", replies)
+	}
+
+	// another member's commands don't cancel it either
+	env.command(t, 1, testGroupChat, "fullmetal")
+	if len(env.anime.queries) != 1 || env.anime.queries[0] != "fullmetal" {
+		t.Errorf("got searches %q, want the sender's wizard still running", env.anime.queries)
+	}
+}
+
+func TestSharedGroupConfirmationIsSenders(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.telegram.members[1] = "administrator"
+	env.command(t, 1, testGroupChat, "/groupmode shared")
+	env.command(t, 1, testGroupChat, "/add 5114 watching")
+
+	env.command(t, 1, testGroupChat, "/remove 5114")
+	confirm := env.telegram.lastSent(t).Keyboard.InlineKeyboard[0][0].CallbackData
+
+	env.press(2, testGroupChat, "cb-other", confirm)
+	if answers := env.telegram.answersFor("cb-other"); len(answers) != 1 || answers[0].Text != "⌛ This confirmation has expired" {
+		t.Errorf("got answers %+v, want another member's confirmation refused", answers)
+	}
+
+	env.press(1, testGroupChat, "cb-sender", confirm)
+	if entries, _, err := env.users.GetUserList("-1001", "", 1, 10); err != nil || len(entries) != 0 {
+		t.Errorf("got shared list %+v (err %v), want the entry removed from it", entries, err)
+	}
+}
@@ -0,0 +1,424 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// sentMessage is a message the fake Telegram client was asked to send or edit.
+type sentMessage struct {
+	ChatID    int
+	MessageID int
+	Text      string
+	Keyboard  *models.InlineKeyboardMarkup
+}
+
+// answeredCallback is an answerCallbackQuery call made to the fake client.
+type answeredCallback struct {
+	ID        string
+	Text      string
+	ShowAlert bool
+}
+
+// fakeTelegram records what the handler sends instead of calling the Bot API.
+type fakeTelegram struct {
+	mu        sync.Mutex
+	nextID    int
+	sent      []sentMessage
+	edits     []sentMessage
+	answers   []answeredCallback
+	documents []string // file names
+	photos    []string
+	deleted   []int
+	members   map[int]string // chat member status by user ID, "member" if missing
+	texts     []string       // text of every message sent or edited, in order
+}
+
+func (f *fakeTelegram) SendMessage(ctx context.Context, chatId int, text string, keyboard *models.InlineKeyboardMarkup) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	f.sent = append(f.sent, sentMessage{ChatID: chatId, MessageID: f.nextID, Text: text, Keyboard: keyboard})
+	f.texts = append(f.texts, text)
+	return f.nextID, nil
+}
+
+func (f *fakeTelegram) SendPhoto(ctx context.Context, chatId int, photo []byte, filename, caption string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.photos = append(f.photos, filename)
+	return nil
+}
+
+func (f *fakeTelegram) SendDocument(ctx context.Context, chatId int, document []byte, filename, caption string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.documents = append(f.documents, filename)
+	return nil
+}
+
+func (f *fakeTelegram) EditMessage(ctx context.Context, chatId int, messageId int, text string, keyboard *models.InlineKeyboardMarkup) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.edits = append(f.edits, sentMessage{ChatID: chatId, MessageID: messageId, Text: text, Keyboard: keyboard})
+	f.texts = append(f.texts, text)
+	return nil
+}
+
+func (f *fakeTelegram) EditMessageKeyboard(ctx context.Context, chatId int, messageId int, keyboard *models.InlineKeyboardMarkup) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.edits = append(f.edits, sentMessage{ChatID: chatId, MessageID: messageId, Keyboard: keyboard})
+	return nil
+}
+
+func (f *fakeTelegram) DeleteMessage(ctx context.Context, chatId int, messageId int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deleted = append(f.deleted, messageId)
+	return nil
+}
+
+func (f *fakeTelegram) AnswerCallbackQuery(ctx context.Context, callbackQueryId string, text string, showAlert bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.answers = append(f.answers, answeredCallback{ID: callbackQueryId, Text: text, ShowAlert: showAlert})
+	return nil
+}
+
+func (f *fakeTelegram) GetChatMember(ctx context.Context, chatId int, userId int) (*models.ChatMember, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, ok := f.members[userId]
+	if !ok {
+		status = "member"
+	}
+	return &models.ChatMember{Status: status, User: models.User{Id: userId}}, nil
+}
+
+func (f *fakeTelegram) SendTypingAction(ctx context.Context, chatId int) error {
+	return nil
+}
+
+// messages returns the text of every message sent or edited, in order.
+func (f *fakeTelegram) messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.texts)
+}
+
+// lastSent returns the last message sent, failing the test if there is none.
+func (f *fakeTelegram) lastSent(t *testing.T) sentMessage {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.sent) == 0 {
+		t.Fatal("no message was sent")
+	}
+	return f.sent[len(f.sent)-1]
+}
+
+// answersFor returns the answers given to the callback query with the given ID.
+func (f *fakeTelegram) answersFor(id string) []answeredCallback {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var answers []answeredCallback
+	for _, a := range f.answers {
+		if a.ID == id {
+			answers = append(answers, a)
+		}
+	}
+	return answers
+}
+
+// fmab is the anime most tests add and search for.
+var fmab = models.AnimeData{MalID: 5114, Title: "Fullmetal Alchemist: Brotherhood", Type: "TV", Episodes: 64}
+
+// fakeAnime serves canned anime instead of Jikan.
+type fakeAnime struct {
+	mu      sync.Mutex
+	anime   map[int]models.AnimeData
+	results []models.AnimeData // returned by every search
+	hasNext bool               // whether every search says there's another page
+	err     error              // returned by every call when set
+	queries []string
+	filters []models.SearchFilters // of every search, in order
+	recs    map[int][]models.Recommendation
+	cast    map[int][]models.Character
+	eps     map[int][]models.Episode // every episode, served a Jikan page at a time
+}
+
+func newFakeAnime(anime ...models.AnimeData) *fakeAnime {
+	f := &fakeAnime{anime: make(map[int]models.AnimeData)}
+	for _, a := range anime {
+		f.anime[a.MalID] = a
+	}
+	return f
+}
+
+func (f *fakeAnime) SearchAnime(query string, filters models.SearchFilters) (*models.SearchResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queries = append(f.queries, query)
+	f.filters = append(f.filters, filters)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.SearchResult{Items: f.results, Total: len(f.results), HasNext: f.hasNext}, nil
+}
+
+func (f *fakeAnime) GetAnimeByID(id int) (*models.AnimeData, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	anime, ok := f.anime[id]
+	if !ok {
+		return nil, fmt.Errorf("failed to get anime by ID %d: %w", id, services.ErrNotFound)
+	}
+	return &anime, nil
+}
+
+func (f *fakeAnime) GetTopAnime(filter string, page int, sfw bool) (*models.SearchResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.SearchResult{Items: f.results, Total: len(f.results)}, nil
+}
+
+func (f *fakeAnime) WarmCache(ctx context.Context, queries []string) (int, error) {
+	return len(queries), f.err
+}
+
+func (f *fakeAnime) GetRecommendations(animeID int) ([]models.Recommendation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.recs[animeID], nil
+}
+
+func (f *fakeAnime) GetAnimeCharacters(animeID int) ([]models.Character, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.cast[animeID], nil
+}
+
+func (f *fakeAnime) GetAnimeEpisodes(animeID, page int) (*models.EpisodePage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	episodes := f.eps[animeID]
+	lastPage := max((len(episodes)+models.JikanEpisodesPageSize-1)/models.JikanEpisodesPageSize, 1)
+	start := min((page-1)*models.JikanEpisodesPageSize, len(episodes))
+	end := min(start+models.JikanEpisodesPageSize, len(episodes))
+	return &models.EpisodePage{Episodes: episodes[start:end], Page: page, LastPage: lastPage, HasNext: page < lastPage}, nil
+}
+
+// newJikanStub returns a Jikan client whose anime lookups are served from anime, for
+// the services that fetch anime themselves.
+func newJikanStub(t *testing.T, anime *fakeAnime, logger *logrus.Logger) *services.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/anime/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := anime.GetAnimeByID(id)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	t.Cleanup(server.Close)
+
+	return services.NewClientWithConfig(&services.ClientConfig{
+		BaseURL:    server.URL,
+		Timeout:    5 * time.Second,
+		RateLimit:  time.Millisecond,
+		MaxRetries: 1,
+		RetryDelay: time.Millisecond,
+		Logger:     logger,
+	})
+}
+
+// testEnv is a Handler wired to fakes and an in-memory Redis.
+type testEnv struct {
+	handler  *Handler
+	telegram *fakeTelegram
+	anime    *fakeAnime
+	redis    *redis.Client
+	mredis   *miniredis.Miniredis
+	users    *services.UserService
+}
+
+// newTestEnv builds a handler whose database is unreachable, so anything that needs
+// Postgres fails fast the way it would during an outage. Redis is in memory.
+func newTestEnv(t *testing.T, anime ...models.AnimeData) *testEnv {
+	t.Helper()
+
+	pool, err := pgxpool.New(context.Background(), "postgres://test@127.0.0.1:1/test?connect_timeout=1&pool_max_conns=1")
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return newTestEnvWithDB(t, pool, anime...)
+}
+
+// newTestEnvWithDB builds a handler using the given database.
+func newTestEnvWithDB(t *testing.T, db *pgxpool.Pool, anime ...models.AnimeData) *testEnv {
+	t.Helper()
+
+	mredis := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mredis.Addr()})
+	t.Cleanup(func() { redisClient.Close() })
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	telegram := &fakeTelegram{members: make(map[int]string)}
+	fakeAnime := newFakeAnime(anime...)
+	users := services.NewUserService(db, redisClient, logger, newJikanStub(t, fakeAnime, logger))
+
+	handler := NewHandler(
+		fakeAnime,
+		users,
+		services.NewReminderService(db, logger, redisClient, "", services.NewClient(), 0),
+		services.NewCallbackStore(redisClient, logger),
+		services.NewPendingActionStore(redisClient, logger),
+		services.NewFeedbackService(db, redisClient, logger),
+		logger,
+		telegram,
+	)
+
+	return &testEnv{
+		handler:  handler,
+		telegram: telegram,
+		anime:    fakeAnime,
+		redis:    redisClient,
+		mredis:   mredis,
+		users:    users,
+	}
+}
+
+// cacheSettings puts settings in the settings cache for userID, so handlers see them
+// without the database.
+func (e *testEnv) cacheSettings(t *testing.T, userID string, settings models.UserSettings) {
+	t.Helper()
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		t.Fatalf("failed to marshal settings: %v", err)
+	}
+	if err := e.mredis.Set("user:settings:"+userID, string(data)); err != nil {
+		t.Fatalf("failed to cache settings: %v", err)
+	}
+}
+
+// command runs text as if userID sent it in chatID, and returns what was sent back.
+func (e *testEnv) command(t *testing.T, userID, chatID int, text string) []string {
+	t.Helper()
+
+	before := len(e.telegram.messages())
+	e.handler.ProcessMessage(context.Background(), &models.Update{
+		UpdateId: 1,
+		Message: &models.Message{
+			MessageId: 1,
+			From:      &models.User{Id: userID, FirstName: "Test", Username: "tester"},
+			Chat:      models.Chat{Id: chatID},
+			Text:      text,
+		},
+	})
+	return e.telegram.messages()[before:]
+}
+
+// run calls a handler directly with a command, skipping ProcessMessage's user
+// bookkeeping, which needs the database.
+func (e *testEnv) run(handle func(context.Context, BotCommand), userID, text string) []string {
+	before := len(e.telegram.messages())
+
+	fields := strings.Fields(text)
+	handle(context.Background(), BotCommand{Command: fields[0], Args: fields[1:], UserID: userID, ChatID: userID, SenderID: userID})
+	return e.telegram.messages()[before:]
+}
+
+// callbackData stores payload the way a button would and returns its callback_data.
+func (e *testEnv) callbackData(t *testing.T, payload models.CallbackData) string {
+	t.Helper()
+
+	token, err := e.handler.callbackStore.Save(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("failed to store callback data: %v", err)
+	}
+	return token
+}
+
+// press sends a callback query for data as userID pressing a button in chatID.
+func (e *testEnv) press(userID, chatID int, id, data string) {
+	e.handler.ProcessMessage(context.Background(), &models.Update{
+		UpdateId: 2,
+		CallbackQuery: &models.CallbackQuery{
+			Id:      id,
+			From:    models.User{Id: userID, FirstName: "Test"},
+			Message: models.Message{MessageId: 10, Chat: models.Chat{Id: chatID}},
+			Data:    data,
+		},
+	})
+}
+
+// containsAny reports whether any of texts contains substr.
+func containsAny(texts []string, substr string) bool {
+	for _, text := range texts {
+		if strings.Contains(text, substr) {
+			return true
+		}
+	}
+	return false
+}
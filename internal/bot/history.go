@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+)
+
+// searchText rebuilds a search as it would be typed after /search, flags included,
+// so a history entry can be shown and run again as is.
+func searchText(query string, filters models.SearchFilters) string {
+	text := query
+	if filters.Type != "" {
+		text += " --type " + filters.Type
+	}
+	if filters.Year > 0 {
+		text += " --year " + strconv.Itoa(filters.Year)
+	}
+	return text
+}
+
+// handleHistory lists the user's recent searches with a button to run each again.
+func (h *Handler) handleHistory(ctx context.Context, cmd BotCommand) {
+	history, err := h.userService.GetSearchHistory(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get search history")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't get your search history. Please try again later.")
+		return
+	}
+
+	if len(history) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "🔎 No recent searches. Use /search to find anime.")
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString("<b>🕘 Your Recent Searches</b>\n\n")
+
+	var rows [][]models.InlineKeyboardButton
+	for i, search := range history {
+		message.WriteString(fmt.Sprintf("%d. %s\n", i+1, esc(search)))
+		rows = append(rows, []models.InlineKeyboardButton{
+			{
+				Text:         "🔎 " + services.TruncateText(search, 40),
+				CallbackData: h.encodeCallbackData(ctx, models.CallbackData{Action: "history_search", Query: search}),
+			},
+		})
+	}
+	message.WriteString("\n<i>Tap a search to run it again.</i>")
+
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message.String(), &models.InlineKeyboardMarkup{InlineKeyboard: rows})
+}
+
+// handleCallbackHistorySearch runs a search from /history again, as if it were typed.
+func (h *Handler) handleCallbackHistorySearch(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	if data.Query == "" {
+		h.answerCallback(ctx, callback.Id, "❌ Invalid data", false)
+		return
+	}
+
+	h.answerCallback(ctx, callback.Id, "", false)
+	h.handleSearch(ctx, BotCommand{
+		Command:  "/search",
+		Args:     strings.Fields(data.Query),
+		UserID:   userID,
+		ChatID:   chatID,
+		SenderID: userID,
+	})
+}
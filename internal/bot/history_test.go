@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"context"
+	"sletish/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestHistoryEmpty(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleHistory, "1", "/history"); !containsAny(replies, "No recent searches") {
+		t.Errorf("got %q, want no recent searches", replies)
+	}
+}
+
+func TestHistoryRerunsSearch(t *testing.T) {
+	env := newTestEnv(t)
+	env.anime.results = []models.AnimeData{fmab}
+
+	env.run(env.handler.handleSearch, "1", "/search fullmetal --type tv --year 2009")
+	env.run(env.handler.handleSearch, "1", "/search <oshi>")
+
+	env.run(env.handler.handleHistory, "1", "/history")
+	sent := env.telegram.lastSent(t)
+	if !strings.Contains(sent.Text, "1. &lt;oshi&gt;\n2. fullmetal --type tv --year 2009\n") {
+		t.Errorf("got %q, want both searches newest first", sent.Text)
+	}
+	if sent.Keyboard == nil || len(sent.Keyboard.InlineKeyboard) != 2 {
+		t.Fatalf("got keyboard %+v, want a button per search", sent.Keyboard)
+	}
+
+	button := sent.Keyboard.InlineKeyboard[1][0]
+	data, err := env.handler.decodeCallbackData(context.Background(), button.CallbackData)
+	if err != nil {
+		t.Fatalf("failed to decode callback data: %v", err)
+	}
+	if data.Action != "history_search" || data.Query != "fullmetal --type tv --year 2009" {
+		t.Errorf("got %+v, want a history search for the older query", data)
+	}
+
+	env.press(1, 1, "cb-1", button.CallbackData)
+
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "" {
+		t.Errorf("got answers %+v, want one silent answer", answers)
+	}
+	last := len(env.anime.queries) - 1
+	if env.anime.queries[last] != "fullmetal" {
+		t.Errorf("searched for %q, want fullmetal again", env.anime.queries[last])
+	}
+	if filters := env.anime.filters[last]; filters.Type != "tv" || filters.Year != 2009 {
+		t.Errorf("got filters %+v, want the original type and year", filters)
+	}
+	if !strings.Contains(env.telegram.lastSent(t).Text, fmab.Title) {
+		t.Errorf("got %q, want the search results", env.telegram.lastSent(t).Text)
+	}
+}
+
+func TestHistorySearchWithoutQuery(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.press(1, 1, "cb-1", env.callbackData(t, models.CallbackData{Action: "history_search"}))
+
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "❌ Invalid data" {
+		t.Errorf("got answers %+v, want invalid data", answers)
+	}
+	if len(env.anime.queries) != 0 {
+		t.Errorf("searched for %q, want no search", env.anime.queries)
+	}
+}
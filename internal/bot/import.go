@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"time"
+)
+
+// aniListUsernamePattern matches AniList usernames: letters, digits and a few symbols.
+var aniListUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{2,20}$`)
+
+// handleImportAniList imports a public AniList list: /importanilist <username>
+func (h *Handler) handleImportAniList(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) != 1 || !aniListUsernamePattern.MatchString(cmd.Args[0]) {
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /importanilist &lt;anilist_username&gt;
+
+Adds the anime from a public AniList profile to your list. Anime already in your list are left as they are.`)
+		return
+	}
+	username := cmd.Args[0]
+
+	// a second run would race the first over the same list and double the Jikan requests
+	if _, running := h.importing.LoadOrStore(cmd.UserID, struct{}{}); running {
+		h.sendMessage(ctx, cmd.ChatID, "⏳ Your last AniList import is still running. I'll report when it's done.")
+		return
+	}
+
+	statusMsgID := h.sendStatusMessage(ctx, cmd.ChatID, fmt.Sprintf("⏳ Importing the AniList list of <b>%s</b>. Big lists can take a few minutes...", esc(username)))
+
+	// this outlives the update's context, every new anime is fetched through the rate limiter
+	go func() {
+		defer h.importing.Delete(cmd.UserID)
+
+		result, err := h.userService.ImportAniList(context.Background(), cmd.UserID, username)
+
+		reportCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		switch {
+		case errors.Is(err, services.ErrAniListUserNotFound):
+			h.updateStatusMessage(reportCtx, cmd.ChatID, statusMsgID, fmt.Sprintf("❌ No AniList user named <b>%s</b>.", esc(username)))
+		case errors.Is(err, services.ErrAniListPrivate):
+			h.updateStatusMessage(reportCtx, cmd.ChatID, statusMsgID, fmt.Sprintf("🔒 <b>%s</b>'s AniList list is private. Make it public in AniList's settings and try again.", esc(username)))
+		case err != nil && result.Imported == 0 && !errors.Is(err, services.ErrListFull):
+			h.logger.WithError(err).WithField("anilist_user", username).Error("Failed to import AniList list")
+			h.updateStatusMessage(reportCtx, cmd.ChatID, statusMsgID, "❌ Sorry, I couldn't import the list. Please try again later.")
+		default:
+			h.updateStatusMessage(reportCtx, cmd.ChatID, statusMsgID, formatImportResult(result, err, h.listFullMessage()))
+		}
+	}()
+}
+
+// formatImportResult reports an import's counts, and why it stopped early if it did.
+func formatImportResult(result models.ImportResult, err error, listFullMessage string) string {
+	message := fmt.Sprintf("✅ Imported %d anime.", result.Imported)
+	if result.Skipped > 0 {
+		message += fmt.Sprintf("\n⏭ Skipped %d already in your list or not on MyAnimeList.", result.Skipped)
+	}
+	if result.Failed > 0 {
+		message += fmt.Sprintf("\n⚠️ %d couldn't be added.", result.Failed)
+	}
+
+	switch {
+	case errors.Is(err, services.ErrListFull):
+		message += "\n\n❌ Stopped early: " + listFullMessage
+	case err != nil:
+		message += "\n\n⚠️ Stopped early, the anime database is unavailable. Run the import again later to add the rest."
+	}
+
+	if result.Imported > 0 {
+		message += "\n\nSee them with /list"
+	}
+	return message
+}
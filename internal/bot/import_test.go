@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"errors"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strings"
+	"testing"
+)
+
+func TestImportAniListValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, text := range []string{"/importanilist", "/importanilist a", "/importanilist two names", "/importanilist bad!name", "/importanilist " + strings.Repeat("a", 21)} {
+		msgs := env.run(env.handler.handleImportAniList, "1", text)
+		if len(msgs) != 1 || !strings.Contains(msgs[0], "<b>Usage:</b> /importanilist") {
+			t.Errorf("%q got %q, want the usage", text, msgs)
+		}
+	}
+}
+
+func TestImportAniListOneAtATime(t *testing.T) {
+	env := newTestEnv(t)
+	// as if an import for user 1 were still running
+	env.handler.importing.Store("1", struct{}{})
+
+	msgs := env.run(env.handler.handleImportAniList, "1", "/importanilist someone")
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "still running") {
+		t.Errorf("got %q, want the second import refused", msgs)
+	}
+}
+
+func TestFormatImportResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result models.ImportResult
+		err    error
+		want   string
+	}{
+		{"imported", models.ImportResult{Imported: 3}, nil, "✅ Imported 3 anime.\n\nSee them with /list"},
+		{"nothing new", models.ImportResult{Skipped: 2}, nil, "✅ Imported 0 anime.\n⏭ Skipped 2 already in your list or not on MyAnimeList."},
+		{"some failed", models.ImportResult{Imported: 1, Failed: 4}, nil, "✅ Imported 1 anime.\n⚠️ 4 couldn't be added.\n\nSee them with /list"},
+		{"list full", models.ImportResult{Imported: 1}, services.ErrListFull, "✅ Imported 1 anime.\n\n❌ Stopped early: full\n\nSee them with /list"},
+		{"service down", models.ImportResult{Imported: 1}, errors.New("jikan down"), "✅ Imported 1 anime.\n\n⚠️ Stopped early, the anime database is unavailable. Run the import again later to add the rest.\n\nSee them with /list"},
+	}
+
+	for _, tt := range tests {
+		if got := formatImportResult(tt.result, tt.err, "full"); got != tt.want {
+			t.Errorf("%s got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
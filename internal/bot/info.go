@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+)
+
+// handleInfo shows an anime's details merged with the user's own entry: /info <anime_id>
+// Anime that aren't in the list get the public details, like view_details.
+func (h *Handler) handleInfo(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) != 1 {
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /info &lt;anime_id&gt;\n<b>Example:</b> <code>/info 16498</code>")
+		return
+	}
+
+	animeID, err := strconv.Atoi(cmd.Args[0])
+	if err != nil || animeID <= 0 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please provide a valid number.")
+		return
+	}
+
+	anime, err := h.animeService.GetAnimeByID(animeID)
+	if err != nil {
+		if errors.Is(err, services.ErrServiceUnavailable) {
+			h.sendMessage(ctx, cmd.ChatID, animeServiceDownMessage)
+			return
+		}
+		h.logger.WithError(err).WithField("anime_id", animeID).Error("Failed to get anime for info")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Anime not found. Please check the ID and try again.")
+		return
+	}
+
+	entry, err := h.userService.GetUserEntry(ctx, cmd.UserID, animeID)
+	if err != nil {
+		if !errors.Is(err, services.ErrNotInUserList) {
+			h.logger.WithError(err).Error("Failed to get user entry for info")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your list. Please try again later.")
+			return
+		}
+
+		detailsMessage, keyboard := h.detailsView(ctx, cmd.UserID, anime)
+		detailsMessage += fmt.Sprintf("\n<i>Not in your list yet. Add it with</i> <code>/add %d watchlist</code>", animeID)
+		h.sendMessageWithKeyboard(ctx, cmd.ChatID, detailsMessage, keyboard)
+		return
+	}
+
+	message := h.formatAnimeDetails(*anime, &entry.UserMedia) + formatEntryInfo(entry.UserMedia, anime.Episodes)
+	keyboard := h.createAnimeDetailsKeyboard(ctx, strconv.Itoa(animeID), entry.UserMedia.Status, len([]rune(anime.Synopsis)) > detailsSynopsisLength)
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
+}
+
+// formatEntryInfo renders the user's own data for an anime, appended under its public details.
+// Their rating isn't repeated here, formatAnimeDetails shows it next to the community score.
+func formatEntryInfo(entry models.UserMedia, totalEpisodes int) string {
+	var message strings.Builder
+	message.WriteString("\n<b>👤 Your entry</b>\n")
+	message.WriteString(fmt.Sprintf("%s Status: %s\n", getStatusEmoji(entry.Status), entry.Status))
+
+	if entry.EpisodesWatched > 0 {
+		if totalEpisodes > 0 {
+			message.WriteString(fmt.Sprintf("📺 Progress: %d/%d episodes\n", entry.EpisodesWatched, totalEpisodes))
+		} else {
+			message.WriteString(fmt.Sprintf("📺 Progress: %d episodes\n", entry.EpisodesWatched))
+		}
+	}
+
+	message.WriteString(fmt.Sprintf("📝 Added: %s\n", entry.CreatedAt.Format("Jan 2, 2006")))
+	if entry.StartedAt != nil {
+		message.WriteString(fmt.Sprintf("▶️ Started: %s\n", entry.StartedAt.Format("Jan 2, 2006")))
+	}
+	if entry.CompletedAt != nil && entry.Status == models.StatusCompleted {
+		message.WriteString(fmt.Sprintf("🏁 Completed: %s\n", entry.CompletedAt.Format("Jan 2, 2006")))
+	}
+
+	if len(entry.Tags) > 0 {
+		message.WriteString("🔖 #" + esc(strings.Join(entry.Tags, " #")) + "\n")
+	}
+
+	if notes := strings.TrimSpace(entry.Notes); notes != "" {
+		message.WriteString(fmt.Sprintf("🗒 Notes: %s\n", esc(notes)))
+	}
+
+	return message.String()
+}
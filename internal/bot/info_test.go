@@ -0,0 +1,221 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatEntryInfo(t *testing.T) {
+	added := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+	completed := time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		entry         models.UserMedia
+		totalEpisodes int
+		want          string
+	}{
+		{
+			"everything",
+			models.UserMedia{
+				Status: models.StatusCompleted, EpisodesWatched: 64, CreatedAt: added, StartedAt: &added, CompletedAt: &completed,
+				Tags: []string{"rewatch", "cozy"}, Notes: " <3 the ending ",
+			},
+			64,
+			"\n<b>👤 Your entry</b>\n" +
+				"✅ Status: completed\n" +
+				"📺 Progress: 64/64 episodes\n" +
+				"📝 Added: Mar 5, 2024\n" +
+				"▶️ Started: Mar 5, 2024\n" +
+				"🏁 Completed: Apr 1, 2024\n" +
+				"🔖 #rewatch #cozy\n" +
+				"🗒 Notes: &lt;3 the ending\n",
+		},
+		{
+			"episode count unknown",
+			models.UserMedia{Status: models.StatusWatching, EpisodesWatched: 3, CreatedAt: added},
+			0,
+			"\n<b>👤 Your entry</b>\n" +
+				"👀 Status: watching\n" +
+				"📺 Progress: 3 episodes\n" +
+				"📝 Added: Mar 5, 2024\n",
+		},
+		{
+			// completed once, now rewatching
+			"no progress",
+			models.UserMedia{Status: models.StatusWatchlist, CreatedAt: added, CompletedAt: &completed, Notes: "   "},
+			12,
+			"\n<b>👤 Your entry</b>\n" +
+				"📝 Status: watchlist\n" +
+				"📝 Added: Mar 5, 2024\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := formatEntryInfo(tt.entry, tt.totalEpisodes); got != tt.want {
+			t.Errorf("%s got\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInfoValidation(t *testing.T) {
+	env := newTestEnv(t, fmab)
+
+	tests := []struct {
+		text, want string
+	}{
+		{"/info", "Usage:</b> /info"},
+		{"/info 5114 9253", "Usage:</b> /info"},
+		{"/info abc", "Invalid anime ID"},
+		{"/info -1", "Invalid anime ID"},
+		{"/info 404", "Anime not found"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(env.handler.handleInfo, "1", tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%q got %q, want %q", tt.text, replies, tt.want)
+		}
+	}
+
+	env.anime.err = fmt.Errorf("lookup failed: %w", services.ErrServiceUnavailable)
+	if replies := env.run(env.handler.handleInfo, "1", "/info 5114"); !containsAny(replies, animeServiceDownMessage) {
+		t.Errorf("got %q, want the service down message", replies)
+	}
+}
+
+func TestInfoMergesUsersEntry(t *testing.T) {
+	env := newTestEnv(t, fmab)
+
+	// the entry is read from the user's entry cache, ahead of the database
+	entry := models.UserMediaWithDetails{
+		UserMedia: models.UserMedia{Status: models.StatusWatching, EpisodesWatched: 12, Rating: 9, Notes: "Ed & Al", CreatedAt: time.Now()},
+		Media:     models.Media{ExternalID: "5114", Title: fmab.Title},
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("failed to marshal entry: %v", err)
+	}
+	env.mredis.HSet("user:entries:1", "5114", string(data))
+
+	env.run(env.handler.handleInfo, "1", "/info 5114")
+
+	sent := env.telegram.lastSent(t)
+	for _, want := range []string{fmab.Title, "<b>👤 Your entry</b>", "👀 Status: watching", "📺 Progress: 12/64 episodes", "🗒 Notes: Ed &amp; Al"} {
+		if !strings.Contains(sent.Text, want) {
+			t.Errorf("info is missing %q:\n%s", want, sent.Text)
+		}
+	}
+	if strings.Contains(sent.Text, "Not in your list yet") {
+		t.Errorf("got %q, want no add hint for a listed anime", sent.Text)
+	}
+	checkTelegramHTML(t, sent.Text)
+	if sent.Keyboard == nil {
+		t.Error("info was sent without a keyboard")
+	}
+}
+
+func TestInfoListLookupFails(t *testing.T) {
+	// no database and nothing cached, so the entry can't be looked up
+	env := newTestEnv(t, fmab)
+
+	if replies := env.run(env.handler.handleInfo, "1", "/info 5114"); !containsAny(replies, "couldn't retrieve your list") {
+		t.Errorf("got %q, want the list error", replies)
+	}
+}
+
+func TestInfoNotInList(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.command(t, 1, 1, "/start")
+
+	replies := env.command(t, 1, 1, "/info 5114")
+	if !containsAny(replies, fmab.Title) || !containsAny(replies, "Not in your list yet. Add it with</i> <code>/add 5114 watchlist</code>") {
+		t.Errorf("got %q, want the public details with an add hint", replies)
+	}
+	if containsAny(replies, "Your entry") {
+		t.Errorf("got %q, want no entry section", replies)
+	}
+
+	env.command(t, 1, 1, "/add 5114 completed 10 best show")
+	replies = env.command(t, 1, 1, "/info 5114")
+	if !containsAny(replies, "✅ Status: completed") || !containsAny(replies, "🗒 Notes: best show") {
+		t.Errorf("got %q, want the user's entry merged in", replies)
+	}
+}
+
+func TestFormatScores(t *testing.T) {
+	tests := []struct {
+		community, personal float64
+		want                string
+	}{
+		{8.24, 9, "Community ⭐ 8.2 / Your ⭐ 9.0"},
+		{8.24, 0, "Community ⭐ 8.2 / Your ⭐ not rated"},
+		{0, 9, "Your ⭐ 9.0"},
+		{0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		if got := formatScores(tt.community, tt.personal); got != tt.want {
+			t.Errorf("formatScores(%v, %v) = %q, want %q", tt.community, tt.personal, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAnimeDetailsScores(t *testing.T) {
+	env := newTestEnv(t)
+	scored := models.AnimeData{MalID: 5114, Title: fmab.Title, Score: 9.1}
+	unscored := models.AnimeData{MalID: 5114, Title: fmab.Title}
+
+	tests := []struct {
+		name   string
+		anime  models.AnimeData
+		entry  *models.UserMedia
+		want   string
+		absent string
+	}{
+		{"not listed", scored, nil, "⭐ Community score: 9.1/10\n", "Your ⭐"},
+		{"listed and rated", scored, &models.UserMedia{Rating: 7}, "Community ⭐ 9.1 / Your ⭐ 7.0\n", "Community score"},
+		{"listed, not rated", scored, &models.UserMedia{}, "Community ⭐ 9.1 / Your ⭐ not rated\n", "Community score"},
+		{"rated, no community score", unscored, &models.UserMedia{Rating: 7}, "Your ⭐ 7.0\n", "Community"},
+		{"no scores", unscored, &models.UserMedia{}, "", "⭐"},
+	}
+
+	for _, tt := range tests {
+		got := env.handler.formatAnimeDetails(tt.anime, tt.entry)
+		if !strings.Contains(got, tt.want) || strings.Contains(got, tt.absent) {
+			t.Errorf("%s got:\n%s\nwant %q without %q", tt.name, got, tt.want, tt.absent)
+		}
+	}
+}
+
+func TestInfoShowsBothScores(t *testing.T) {
+	scored := fmab
+	scored.Score = 9.1
+	env := newTestEnv(t, scored)
+
+	entry := models.UserMediaWithDetails{
+		UserMedia: models.UserMedia{Status: models.StatusCompleted, Rating: 9, CreatedAt: time.Now()},
+		Media:     models.Media{ExternalID: "5114", Title: fmab.Title},
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("failed to marshal entry: %v", err)
+	}
+	env.mredis.HSet("user:entries:1", "5114", string(data))
+
+	env.run(env.handler.handleInfo, "1", "/info 5114")
+
+	sent := env.telegram.lastSent(t)
+	if !strings.Contains(sent.Text, "Community ⭐ 9.1 / Your ⭐ 9.0") {
+		t.Errorf("got:\n%s\nwant both scores on one line", sent.Text)
+	}
+	// the rating isn't repeated under the entry
+	if strings.Count(sent.Text, "9.0") != 1 {
+		t.Errorf("got:\n%s\nwant the rating shown once", sent.Text)
+	}
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"sletish/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Telegram inline keyboard limits
+const (
+	maxButtonsPerRow    = 8
+	maxKeyboardButtons  = 100
+	maxCallbackDataSize = 64 // bytes
+)
+
+// limitKeyboard makes sure a keyboard stays within Telegram's limits before it is sent.
+// Rows with too many buttons are wrapped onto new rows, buttons past the total cap are
+// dropped, and so are buttons whose callback data is too long (Telegram rejects the whole
+// message otherwise).
+func (h *Handler) limitKeyboard(keyboard *models.InlineKeyboardMarkup) *models.InlineKeyboardMarkup {
+	if keyboard == nil {
+		return nil
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	total := 0
+
+	for _, row := range keyboard.InlineKeyboard {
+		var current []models.InlineKeyboardButton
+
+		for _, button := range row {
+			if total >= maxKeyboardButtons {
+				break
+			}
+
+			if len(button.CallbackData) > maxCallbackDataSize {
+				h.logger.WithFields(logrus.Fields{
+					"text": button.Text,
+					"size": len(button.CallbackData),
+				}).Warn("Dropping button with oversized callback data")
+				continue
+			}
+
+			if len(current) == maxButtonsPerRow {
+				rows = append(rows, current)
+				current = nil
+			}
+
+			current = append(current, button)
+			total++
+		}
+
+		if len(current) > 0 {
+			rows = append(rows, current)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: rows,
+	}
+}
@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// checkKeyboardLimits fails the test if keyboard breaks one of Telegram's limits.
+func checkKeyboardLimits(t *testing.T, keyboard *models.InlineKeyboardMarkup) {
+	t.Helper()
+
+	if keyboard == nil {
+		return
+	}
+
+	total := 0
+	for i, row := range keyboard.InlineKeyboard {
+		if len(row) > maxButtonsPerRow {
+			t.Errorf("row %d has %d buttons, max %d", i, len(row), maxButtonsPerRow)
+		}
+		for _, button := range row {
+			if size := len(button.CallbackData); size > maxCallbackDataSize {
+				t.Errorf("button %q has %d bytes of callback data, max %d", button.Text, size, maxCallbackDataSize)
+			}
+			if button.CallbackData == expiredCallbackData {
+				t.Errorf("button %q couldn't store its payload", button.Text)
+			}
+		}
+		total += len(row)
+	}
+	if total > maxKeyboardButtons {
+		t.Errorf("keyboard has %d buttons, max %d", total, maxKeyboardButtons)
+	}
+}
+
+func TestKeyboardsStayWithinTelegramLimits(t *testing.T) {
+	env := newTestEnv(t)
+	h := env.handler
+	ctx := context.Background()
+
+	longTitle := strings.Repeat("鋼の錬金術師 ", 40)
+	longFilter := listFilter{
+		Status: "watching",
+		Genre:  strings.Repeat("Slice of Life ", 10),
+		Tag:    strings.Repeat("rewatch", 10),
+		Rating: "rated>=8",
+	}
+
+	var manyAnime []models.AnimeData
+	for i := range 50 {
+		manyAnime = append(manyAnime, models.AnimeData{MalID: 2_000_000_000 - i, Title: longTitle})
+	}
+
+	var manyReminders []models.Reminder
+	for i := range 200 {
+		manyReminders = append(manyReminders, models.Reminder{ID: 2_000_000_000 - i, MediaTitle: longTitle})
+	}
+
+	settings := models.DefaultUserSettings()
+	allOn := models.UserSettings{ShowNSFW: true, IsPublic: true, NotificationsPaused: true, AotdEnabled: true, SilentReminders: true, SearchVerbosity: models.VerbosityDetailed}
+
+	tests := []struct {
+		name     string
+		keyboard *models.InlineKeyboardMarkup
+	}{
+		{"search results", h.createSearchResultsKeyboard(ctx, manyAnime)},
+		{"search result", h.createSearchResultsKeyboard(ctx, manyAnime[:1])},
+		{"list first page", h.createPaginationKeyboard(ctx, 1, maxListPageLimit, 1_000_000, longFilter)},
+		{"list middle page", h.createPaginationKeyboard(ctx, 500, maxListPageLimit, 1_000_000, longFilter)},
+		{"list last page", h.createPaginationKeyboard(ctx, 20_000, maxListPageLimit, 1_000_000, listFilter{})},
+		{"settings", h.createSettingsKeyboard(ctx, &settings)},
+		{"settings all on", h.createSettingsKeyboard(ctx, &allOn)},
+		{"reminders", h.createRemindersKeyboard(ctx, manyReminders)},
+		{"details", h.createAnimeDetailsKeyboard(ctx, "2000000000", models.StatusWatching, true)},
+		{"episodes", h.createPagerKeyboard(ctx, "episodes", "2000000000", 500, 1000, true)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkKeyboardLimits(t, tt.keyboard)
+		})
+	}
+}
+
+func TestLimitKeyboard(t *testing.T) {
+	env := newTestEnv(t)
+
+	var row []models.InlineKeyboardButton
+	for i := range 150 {
+		row = append(row, models.InlineKeyboardButton{Text: fmt.Sprint(i), CallbackData: fmt.Sprint(i)})
+	}
+	row = append(row, models.InlineKeyboardButton{Text: "too long", CallbackData: strings.Repeat("x", maxCallbackDataSize+1)})
+
+	limited := env.handler.limitKeyboard(&models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{row}})
+	checkKeyboardLimits(t, limited)
+
+	total := 0
+	for _, row := range limited.InlineKeyboard {
+		total += len(row)
+	}
+	if total != maxKeyboardButtons {
+		t.Errorf("got %d buttons, want the first %d kept", total, maxKeyboardButtons)
+	}
+	if first := limited.InlineKeyboard[0][0].Text; first != "0" {
+		t.Errorf("first button is %q, want 0", first)
+	}
+}
+
+// keyboardActions returns "text=action:status" for every button of keyboard, decoding stored payloads.
+func keyboardActions(t *testing.T, env *testEnv, keyboard *models.InlineKeyboardMarkup) []string {
+	t.Helper()
+
+	var actions []string
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			data, err := env.handler.decodeCallbackData(context.Background(), button.CallbackData)
+			if err != nil {
+				t.Fatalf("failed to decode %q: %v", button.Text, err)
+			}
+			actions = append(actions, fmt.Sprintf("%s=%s:%s", button.Text, data.Action, data.Status))
+		}
+	}
+	return actions
+}
+
+func TestDetailsKeyboardByStatus(t *testing.T) {
+	env := newTestEnv(t)
+	extras := []string{"👥 Characters=char_page:", "📺 Episodes=ep_page:"}
+
+	tests := []struct {
+		status models.Status
+		want   []string
+	}{
+		{"", []string{"📝 Add to Watchlist=add_anime:watchlist", "👀 Start Watching=add_anime:watching", "✅ Mark Completed=add_anime:completed"}},
+		{models.StatusWatchlist, []string{"👀 Start Watching=update_status:watching", "✅ Mark Completed=update_status:completed", "🗑 Remove=remove_anime:"}},
+		{models.StatusWatching, []string{"✅ Mark Completed=update_status:completed", "⏸ Move to On Hold=update_status:on_hold", "❌ Drop=update_status:dropped", "🗑 Remove=remove_anime:"}},
+		{models.StatusCompleted, []string{"👀 Rewatch=update_status:watching", "🗑 Remove=remove_anime:"}},
+		{models.StatusDropped, []string{"👀 Start Watching=update_status:watching", "📝 Back to Watchlist=update_status:watchlist", "🗑 Remove=remove_anime:"}},
+	}
+
+	for _, tt := range tests {
+		keyboard := env.handler.createAnimeDetailsKeyboard(context.Background(), "5114", tt.status, false)
+		checkKeyboardLimits(t, keyboard)
+		got := keyboardActions(t, env, keyboard)
+		if want := append(slices.Clone(tt.want), extras...); !slices.Equal(got, want) {
+			t.Errorf("status %q got %q, want %q", tt.status, got, want)
+		}
+	}
+}
+
+func TestDetailsViewFallsBackToAddButtons(t *testing.T) {
+	env := newTestEnv(t)
+
+	// the list entry can't be looked up while the database is down
+	message, keyboard := env.handler.detailsView(context.Background(), "1", &fmab)
+	if strings.Contains(message, "In your list") {
+		t.Errorf("got %q, want no list status", message)
+	}
+	if got := keyboardActions(t, env, keyboard); !slices.Contains(got, "👀 Start Watching=add_anime:watching") {
+		t.Errorf("got %q, want the add buttons", got)
+	}
+}
+
+func TestDetailsViewFollowsListEntry(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.command(t, 1, 1, "/help")
+
+	_, keyboard := env.handler.detailsView(context.Background(), "1", &fmab)
+	if got := keyboardActions(t, env, keyboard); !slices.Contains(got, "📝 Add to Watchlist=add_anime:watchlist") {
+		t.Errorf("got %q before adding, want the add buttons", got)
+	}
+
+	env.command(t, 1, 1, "/add 5114 on_hold")
+	message, keyboard := env.handler.detailsView(context.Background(), "1", &fmab)
+	if !strings.Contains(message, "<i>In your list as on_hold</i>") {
+		t.Errorf("got %q, want the list status", message)
+	}
+	got := keyboardActions(t, env, keyboard)
+	if slices.Contains(got, "📝 Add to Watchlist=add_anime:watchlist") || !slices.Contains(got, "🗑 Remove=remove_anime:") {
+		t.Errorf("got %q, want status changes instead of add buttons", got)
+	}
+}
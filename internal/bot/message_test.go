@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"testing"
+)
+
+func TestNonTextMessagesGetAHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string // the message object of the update, as Telegram sends it
+		want    string
+	}{
+		{"photo", `{"photo":[{"file_id":"p1","width":90,"height":90},{"file_id":"p2","width":800,"height":800}]}`, "🙈 I can only read text for now. Send /help to see what I can do!"},
+		{"sticker", `{"sticker":{"file_id":"s1","emoji":"😀"}}`, "🙈 I can only read text for now. Send /help to see what I can do!"},
+		{"voice", `{"voice":{"file_id":"v1","duration":3}}`, "🙈 I can only read text for now. Send /help to see what I can do!"},
+		{"document", `{"document":{"file_id":"d1","file_name":"list.csv","mime_type":"text/csv"}}`, "📄 Thanks, but I can't import files yet. Use /add to add anime to your list."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			raw := `{"update_id":1,"message":{"message_id":1,"chat":{"id":7},"from":{"id":7,"first_name":"Test"},` + tt.message[1:] + `}`
+			var update models.Update
+			if err := json.Unmarshal([]byte(raw), &update); err != nil {
+				t.Fatalf("failed to decode update: %v", err)
+			}
+
+			env.handler.ProcessMessage(context.Background(), &update)
+
+			sent := env.telegram.lastSent(t)
+			if sent.ChatID != 7 || sent.Text != tt.want {
+				t.Errorf("got %q in chat %d, want %q in chat 7", sent.Text, sent.ChatID, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallbackOnlyUpdateSkipsMessage(t *testing.T) {
+	env := newTestEnv(t)
+
+	// no Message at all, only the callback query
+	env.press(1, 1, "cb-1", "noop")
+
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 {
+		t.Errorf("got %d answers, want the callback answered once", len(answers))
+	}
+}
+
+func TestUnhandledUpdatesAreIgnored(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"my_chat_member", `{"update_id":1,"my_chat_member":{"chat":{"id":7},"from":{"id":7}}}`},
+		{"channel_post", `{"update_id":1,"channel_post":{"message_id":1,"chat":{"id":-100},"text":"/help"}}`},
+		{"message without sender", `{"update_id":1,"message":{"message_id":1,"chat":{"id":-100},"text":"/help"}}`},
+		{"sender ID 0", `{"update_id":1,"message":{"message_id":1,"chat":{"id":7},"from":{"id":0},"text":"/help"}}`},
+		{"message without content", `{"update_id":1,"message":{"message_id":1,"chat":{"id":7},"from":{"id":7}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			var update models.Update
+			if err := json.Unmarshal([]byte(tt.raw), &update); err != nil {
+				t.Fatalf("failed to decode update: %v", err)
+			}
+
+			env.handler.ProcessMessage(context.Background(), &update)
+
+			if got := env.telegram.messages(); len(got) != 0 {
+				t.Errorf("got replies %q, want the update ignored", got)
+			}
+		})
+	}
+}
+
+func TestSenderIDZeroCreatesNoUser(t *testing.T) {
+	db := testutil.DB(t)
+	env := newTestEnvWithDB(t, db)
+
+	env.command(t, 0, 7, "/start")
+
+	var exists bool
+	if err := db.QueryRow(context.Background(), "SELECT EXISTS (SELECT 1 FROM users WHERE id = '0')").Scan(&exists); err != nil {
+		t.Fatalf("failed to check users: %v", err)
+	}
+	if exists {
+		t.Error("created a user with ID 0")
+	}
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"sletish/internal/models"
+	"testing"
+)
+
+var adultAnime = models.AnimeData{MalID: 9999, Title: "Adult", Rating: "Rx - Hentai"}
+
+func TestSearchIsSFWUnlessOptedIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *models.UserSettings // nil when they can't be loaded
+		wantSFW  bool
+	}{
+		{"settings unavailable", nil, true},
+		{"default", &models.UserSettings{}, true},
+		{"opted in", &models.UserSettings{ShowNSFW: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.anime.results = []models.AnimeData{fmab, adultAnime}
+			if tt.settings != nil {
+				env.cacheSettings(t, "1", *tt.settings)
+			}
+
+			replies := env.run(env.handler.handleSearch, "1", "/search anything")
+
+			if len(env.anime.filters) != 1 || env.anime.filters[0].SFW != tt.wantSFW {
+				t.Fatalf("searched with %+v, want SFW %v", env.anime.filters, tt.wantSFW)
+			}
+			// the adult result is also dropped locally in case Jikan lets one through
+			if shown := containsAny(replies, "(ID: 9999)"); shown == tt.wantSFW {
+				t.Errorf("adult result shown: %v, want %v", shown, !tt.wantSFW)
+			}
+		})
+	}
+}
+
+func TestAdultAnimeByIDIsHidden(t *testing.T) {
+	env := newTestEnv(t, adultAnime)
+
+	env.run(env.handler.handleSearch, "1", "/search 9999")
+
+	if sent := env.telegram.lastSent(t); sent.Text != "🔞 This title is hidden by your content filter. Use /nsfw on to show it." {
+		t.Errorf("got %q, want the content filter message", sent.Text)
+	}
+}
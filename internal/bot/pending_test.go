@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"sletish/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCancelClearsPendingRemove(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.run(env.handler.handleRemove, "1", "/remove 5114")
+	if pending, _ := env.handler.pendingStore.Get("1"); pending == nil || pending.Type != models.PendingConfirmRemove {
+		t.Fatalf("got pending action %+v, want a confirm remove", pending)
+	}
+
+	if replies := env.run(env.handler.handleCancel, "1", "/cancel"); !containsAny(replies, "✖️ Cancelled.") {
+		t.Errorf("got %q, want the cancellation acknowledged", replies)
+	}
+	if pending, _ := env.handler.pendingStore.Get("1"); pending != nil {
+		t.Errorf("got pending action %+v after /cancel, want none", pending)
+	}
+
+	// the old Yes button no longer removes anything
+	env.press(1, 1, "cb-1", env.callbackData(t, models.CallbackData{Action: "confirm_remove", AnimeID: "5114"}))
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "⌛ This confirmation has expired" {
+		t.Errorf("got answers %+v, want the confirmation expired", answers)
+	}
+
+	if replies := env.run(env.handler.handleCancel, "1", "/cancel"); !containsAny(replies, "Nothing to cancel.") {
+		t.Errorf("got %q, want nothing to cancel", replies)
+	}
+}
+
+func TestConfirmIgnoresStalePendingAction(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(env *testEnv)
+	}{
+		{"replaced by another removal", func(env *testEnv) {
+			env.run(env.handler.handleRemove, "1", "/remove 5114")
+			env.run(env.handler.handleRemove, "1", "/remove 1")
+		}},
+		{"different kind of action", func(env *testEnv) {
+			env.handler.pendingStore.Set("1", models.PendingAction{
+				Type: models.PendingBulkStatus,
+				Data: map[string]string{"anime_id": "5114"},
+			})
+		}},
+		{"expired", func(env *testEnv) {
+			env.run(env.handler.handleRemove, "1", "/remove 5114")
+			env.mredis.FastForward(time.Hour)
+		}},
+		{"another user's", func(env *testEnv) {
+			env.run(env.handler.handleRemove, "2", "/remove 5114")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			tt.setup(env)
+
+			env.press(1, 1, "cb-1", env.callbackData(t, models.CallbackData{Action: "confirm_remove", AnimeID: "5114"}))
+
+			if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "⌛ This confirmation has expired" {
+				t.Errorf("got answers %+v, want the confirmation expired", answers)
+			}
+			if containsAny(env.telegram.messages(), "Removing anime") {
+				t.Error("tried to remove the anime on a stale confirmation")
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"regexp"
+	"sletish/internal/testutil"
+	"testing"
+)
+
+func TestPingReportsLatencies(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+
+	replies := env.run(env.handler.handlePing, "1", "/ping")
+
+	latencies := regexp.MustCompile(`📨 Telegram: \d+ ms\n🗄 Database: \d+ ms\n⚡ Redis: \d+ ms$`)
+	if len(replies) != 2 || replies[0] != "🏓 pong" || !latencies.MatchString(replies[1]) {
+		t.Errorf("got %q, want pong and then every latency", replies)
+	}
+}
+
+func TestPingWithStorageDown(t *testing.T) {
+	env := newTestEnv(t)
+
+	replies := env.run(env.handler.handlePing, "1", "/ping")
+
+	if len(replies) != 2 {
+		t.Fatalf("got %q, want pong and then the latencies", replies)
+	}
+	if !regexp.MustCompile(`📨 Telegram: \d+ ms\n⚠️ Storage: unreachable$`).MatchString(replies[1]) {
+		t.Errorf("got %q, want the Telegram latency and storage unreachable", replies[1])
+	}
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+)
+
+const queueUsage = `<b>Usage:</b>
+• /queue - Show what you'll watch next
+• /queue add &lt;anime_id&gt; - Add to the end
+• /queue next - Take the top anime off the queue
+• /queue move &lt;anime_id&gt; &lt;position&gt; - Reorder
+• /queue remove &lt;anime_id&gt; - Take an anime off`
+
+// handleQueue dispatches the /queue subcommands, showing the queue without one.
+func (h *Handler) handleQueue(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) == 0 {
+		h.showQueue(ctx, cmd)
+		return
+	}
+
+	args := cmd.Args[1:]
+	switch strings.ToLower(cmd.Args[0]) {
+	case "add":
+		h.handleQueueAdd(ctx, cmd, args)
+	case "next":
+		h.handleQueueNext(ctx, cmd)
+	case "move":
+		h.handleQueueMove(ctx, cmd, args)
+	case "remove":
+		h.handleQueueRemove(ctx, cmd, args)
+	default:
+		h.sendMessage(ctx, cmd.ChatID, queueUsage)
+	}
+}
+
+func (h *Handler) showQueue(ctx context.Context, cmd BotCommand) {
+	queue, err := h.userService.GetQueue(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get watch queue")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your queue. Please try again later.")
+		return
+	}
+
+	if len(queue) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "📑 Your watch queue is empty.\n\nAdd anime with <code>/queue add &lt;anime_id&gt;</code>")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, formatQueue(queue))
+}
+
+func formatQueue(queue []models.QueueItem) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("<b>📑 Watch Queue</b> (%d/%d)\n\n", len(queue), models.MaxQueueSize))
+
+	for _, item := range queue {
+		marker := fmt.Sprintf("%d.", item.Position)
+		if item.Position == 1 {
+			marker = "▶️"
+		}
+		message.WriteString(fmt.Sprintf("%s <b>%s</b> (ID: %s)\n", marker, esc(item.Media.Title), item.Media.ExternalID))
+	}
+
+	message.WriteString("\n<i>Use /queue next when you start the top one.</i>")
+	return message.String()
+}
+
+func (h *Handler) handleQueueAdd(ctx context.Context, cmd BotCommand, args []string) {
+	animeID, ok := h.parseQueueAnimeID(ctx, cmd, args, 1)
+	if !ok {
+		return
+	}
+
+	item, err := h.userService.AddToQueue(cmd.UserID, animeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlreadyQueued):
+			h.sendMessage(ctx, cmd.ChatID, "ℹ️ That anime is already in your queue. See /queue.")
+		case errors.Is(err, services.ErrQueueFull):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ Your queue is full (%d anime max). Take something off with /queue remove first.", models.MaxQueueSize))
+		case errors.Is(err, services.ErrServiceUnavailable):
+			h.sendMessage(ctx, cmd.ChatID, animeServiceDownMessage)
+		default:
+			h.logger.WithError(err).Error("Failed to add to watch queue")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't add that to your queue. Please check the ID and try again.")
+		}
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("📑 Queued <b>%s</b> at position %d.", esc(item.Media.Title), item.Position))
+}
+
+func (h *Handler) handleQueueNext(ctx context.Context, cmd BotCommand) {
+	item, err := h.userService.PopQueue(cmd.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrQueueEmpty) {
+			h.sendMessage(ctx, cmd.ChatID, "📑 Your watch queue is empty.")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to pop watch queue")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update your queue. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("▶️ Up now: <b>%s</b>\n\nTrack it with <code>/add %s watching</code>. See what's after it with /queue.",
+		esc(item.Media.Title), item.Media.ExternalID))
+}
+
+func (h *Handler) handleQueueMove(ctx context.Context, cmd BotCommand, args []string) {
+	animeID, ok := h.parseQueueAnimeID(ctx, cmd, args, 2)
+	if !ok {
+		return
+	}
+
+	position, err := strconv.Atoi(args[1])
+	if err != nil || position < 1 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid position. Use 1 for the top of the queue.")
+		return
+	}
+
+	position, err = h.userService.MoveInQueue(cmd.UserID, animeID, position)
+	if err != nil {
+		if errors.Is(err, services.ErrNotQueued) {
+			h.sendMessage(ctx, cmd.ChatID, "❌ That anime isn't in your queue. See /queue.")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to move watch queue item")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't reorder your queue. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Moved to position %d. See /queue.", position))
+}
+
+func (h *Handler) handleQueueRemove(ctx context.Context, cmd BotCommand, args []string) {
+	animeID, ok := h.parseQueueAnimeID(ctx, cmd, args, 1)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RemoveFromQueue(cmd.UserID, animeID); err != nil {
+		if errors.Is(err, services.ErrNotQueued) {
+			h.sendMessage(ctx, cmd.ChatID, "❌ That anime isn't in your queue. See /queue.")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to remove from watch queue")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't update your queue. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, "✅ Removed from your queue.")
+}
+
+// parseQueueAnimeID checks a subcommand got want arguments and parses the first as an
+// anime ID, replying with usage or an error if not.
+func (h *Handler) parseQueueAnimeID(ctx context.Context, cmd BotCommand, args []string, want int) (int, bool) {
+	if len(args) != want {
+		h.sendMessage(ctx, cmd.ChatID, queueUsage)
+		return 0, false
+	}
+
+	animeID, err := strconv.Atoi(args[0])
+	if err != nil || animeID <= 0 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please provide a valid number.")
+		return 0, false
+	}
+
+	return animeID, true
+}
@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestFormatQueue(t *testing.T) {
+	queue := []models.QueueItem{
+		{Position: 1, Media: models.Media{ExternalID: "5114", Title: fmab.Title}},
+		{Position: 2, Media: models.Media{ExternalID: "9253", Title: "Steins;Gate <2011>"}},
+	}
+
+	message := formatQueue(queue)
+	for _, want := range []string{
+		"<b>📑 Watch Queue</b> (2/50)",
+		"▶️ <b>" + fmab.Title + "</b> (ID: 5114)\n2. <b>Steins;Gate &lt;2011&gt;</b> (ID: 9253)\n",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("queue is missing %q:\n%s", want, message)
+		}
+	}
+	checkTelegramHTML(t, message)
+}
+
+func TestQueueValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/queue shuffle", "<b>Usage:</b>"},
+		{"/queue add", "<b>Usage:</b>"},
+		{"/queue add 1 2", "<b>Usage:</b>"},
+		{"/queue add abc", "Invalid anime ID"},
+		{"/queue remove 0", "Invalid anime ID"},
+		{"/queue move 5114", "<b>Usage:</b>"},
+		{"/queue move 5114 0", "Invalid position"},
+		{"/queue move 5114 top", "Invalid position"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(env.handler.handleQueue, "1", tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%q got %q, want %q", tt.text, replies, tt.want)
+		}
+	}
+}
+
+func TestQueueAddShowAndNext(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab, steinsGate, steinsGate0)
+	env.command(t, 1, 1, "/start")
+
+	if replies := env.command(t, 1, 1, "/queue"); !containsAny(replies, "Your watch queue is empty") {
+		t.Errorf("got %q, want an empty queue", replies)
+	}
+
+	for i, id := range []string{"9253", "5114", "30484"} {
+		want := fmt.Sprintf("at position %d", i+1)
+		if replies := env.command(t, 1, 1, "/queue add "+id); !containsAny(replies, want) {
+			t.Errorf("/queue add %s got %q, want %q", id, replies, want)
+		}
+	}
+	if replies := env.command(t, 1, 1, "/queue add 5114"); !containsAny(replies, "already in your queue") {
+		t.Errorf("got %q, want the duplicate refused", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/queue move 30484 1"); !containsAny(replies, "Moved to position 1") {
+		t.Errorf("got %q, want it moved to the top", replies)
+	}
+	replies := env.command(t, 1, 1, "/queue")
+	if !containsAny(replies, "▶️ <b>"+steinsGate0.Title+"</b> (ID: 30484)\n2. <b>"+steinsGate.Title+"</b> (ID: 9253)\n3. <b>"+fmab.Title+"</b>") {
+		t.Errorf("got %q, want Steins;Gate 0 on top", replies)
+	}
+
+	// next takes the top off and the rest move up
+	if replies := env.command(t, 1, 1, "/queue next"); !containsAny(replies, "Up now: <b>"+steinsGate0.Title+"</b>") || !containsAny(replies, "/add 30484 watching") {
+		t.Errorf("got %q, want Steins;Gate 0 up now", replies)
+	}
+	if replies := env.command(t, 1, 1, "/queue next"); !containsAny(replies, "Up now: <b>"+steinsGate.Title+"</b>") {
+		t.Errorf("got %q, want Steins;Gate up next", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/queue remove 9253"); !containsAny(replies, "isn't in your queue") {
+		t.Errorf("got %q, want a popped anime gone from the queue", replies)
+	}
+	if replies := env.command(t, 1, 1, "/queue remove 5114"); !containsAny(replies, "Removed from your queue") {
+		t.Errorf("got %q, want it removed", replies)
+	}
+	if replies := env.command(t, 1, 1, "/queue next"); !containsAny(replies, "Your watch queue is empty") {
+		t.Errorf("got %q, want nothing left", replies)
+	}
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"strconv"
+	"strings"
+)
+
+// ratingFilterPrefix starts a /list rating filter, e.g. "rated>=8".
+const ratingFilterPrefix = "rated"
+
+// parseRatingFilter parses "rated<op><value>", e.g. "rated>=8" or "rated=7.5".
+func parseRatingFilter(arg string) (models.RatingFilter, bool) {
+	expr, ok := strings.CutPrefix(strings.ToLower(arg), ratingFilterPrefix)
+	if !ok {
+		return models.RatingFilter{}, false
+	}
+
+	for _, op := range models.RatingOperators {
+		rest, found := strings.CutPrefix(expr, op)
+		if !found {
+			continue
+		}
+		value, err := strconv.ParseFloat(rest, 64)
+		if err != nil || value < 0 || value > models.MaxRating {
+			return models.RatingFilter{}, false
+		}
+		return models.RatingFilter{Op: op, Value: value}, true
+	}
+
+	return models.RatingFilter{}, false
+}
+
+// handleListByRating shows the entries of the user's list matching a rating filter:
+// /list rated>=8 [page]
+func (h *Handler) handleListByRating(ctx context.Context, cmd BotCommand, limit int) {
+	ratingFilter, ok := parseRatingFilter(cmd.Args[0])
+	if !ok {
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /list rated&lt;op&gt;&lt;rating&gt; [page]
+
+<b>Operators:</b> &gt;=, &lt;=, &gt;, &lt;, =
+
+<b>Example:</b> /list rated&gt;=8`)
+		return
+	}
+
+	page := 1
+	if len(cmd.Args) > 1 {
+		if p, err := strconv.Atoi(cmd.Args[1]); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	userList, total, err := h.userService.GetUserListByRating(cmd.UserID, ratingFilter, page, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get user list by rating")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your list. Please try again later.")
+		return
+	}
+
+	if len(userList) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("No rated anime in your list match <b>%s</b>.", esc(ratingFilter.String())))
+		return
+	}
+
+	filter := listFilter{Rating: ratingFilter.String()}
+	message := h.formatUserList(userList, filter, page, total, limit)
+	keyboard := h.createPaginationKeyboard(ctx, page, limit, total, filter)
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestParseRatingFilter(t *testing.T) {
+	tests := []struct {
+		arg    string
+		want   models.RatingFilter
+		wantOK bool
+	}{
+		{"rated>=8", models.RatingFilter{Op: ">=", Value: 8}, true},
+		{"rated<=5", models.RatingFilter{Op: "<=", Value: 5}, true},
+		{"rated>7.5", models.RatingFilter{Op: ">", Value: 7.5}, true},
+		{"rated<3", models.RatingFilter{Op: "<", Value: 3}, true},
+		{"rated=10", models.RatingFilter{Op: "=", Value: 10}, true},
+		{"RATED>=0", models.RatingFilter{Op: ">=", Value: 0}, true},
+		{"rated", models.RatingFilter{}, false},
+		{"rated8", models.RatingFilter{}, false},
+		{"rated!=8", models.RatingFilter{}, false},
+		{"rated=>8", models.RatingFilter{}, false},
+		{"rated>=", models.RatingFilter{}, false},
+		{"rated>=eight", models.RatingFilter{}, false},
+		{"rated>=11", models.RatingFilter{}, false},
+		{"rated>=-1", models.RatingFilter{}, false},
+		{"score>=8", models.RatingFilter{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRatingFilter(tt.arg)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRatingFilter(%q) = %+v, %v, want %+v, %v", tt.arg, got, ok, tt.want, tt.wantOK)
+		}
+		if ok && got.String() != strings.ToLower(tt.arg) {
+			t.Errorf("%+v renders as %q, want %q", got, got.String(), strings.ToLower(tt.arg))
+		}
+	}
+}
+
+func TestListByRatingUsage(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, text := range []string{"/list rated", "/list rated!=8", "/list rated>=11"} {
+		if replies := env.run(env.handler.handleList, "1", text); !containsAny(replies, "Usage:</b> /list rated&lt;op&gt;&lt;rating&gt;") {
+			t.Errorf("%q got %q, want the usage", text, replies)
+		}
+	}
+}
+
+func TestListByRating(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab, steinsGate, steinsGate0)
+	env.command(t, 1, 1, "/add 5114 completed 9.5")
+	env.command(t, 1, 1, "/add 9253 completed 7")
+	env.command(t, 1, 1, "/add 30484 watching")
+
+	replies := env.command(t, 1, 1, "/list rated>=8")
+	if !containsAny(replies, "<b>⭐ Your Anime rated&gt;=8</b>") || !containsAny(replies, fmab.Title) {
+		t.Errorf("got %q, want the highly rated anime", replies)
+	}
+	if containsAny(replies, steinsGate.Title) {
+		t.Errorf("got %q, want the lower rated and unrated anime left out", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/list rated<8"); !containsAny(replies, steinsGate.Title) || containsAny(replies, fmab.Title) {
+		t.Errorf("got %q, want only the anime rated below 8", replies)
+	}
+	if replies := env.command(t, 1, 1, "/list rated=10"); !containsAny(replies, "No rated anime in your list match <b>rated=10</b>") {
+		t.Errorf("got %q, want nothing matching", replies)
+	}
+}
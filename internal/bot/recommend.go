@@ -0,0 +1,170 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	recommendLimit       = 10
+	recommendSeeds       = 3  // liked anime /recommend draws from
+	recommendTitleLen    = 40 // button text
+	includeDroppedFlag   = "--include-dropped"
+	recommendUsageSuffix = " [--include-dropped]"
+)
+
+// handleSimilar suggests anime like the given one: /similar <anime_id> [--include-dropped]
+func (h *Handler) handleSimilar(ctx context.Context, cmd BotCommand) {
+	args, includeDropped := takeIncludeDropped(cmd.Args)
+	if len(args) < 1 {
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /similar &lt;anime_id&gt;"+recommendUsageSuffix+"\n\n<b>Example:</b> /similar 5114")
+		return
+	}
+
+	animeID, err := strconv.Atoi(args[0])
+	if err != nil || animeID <= 0 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please use a valid numeric ID.")
+		return
+	}
+
+	recs, err := h.animeService.GetRecommendations(animeID)
+	if err != nil {
+		h.sendRecommendError(ctx, cmd.ChatID, err)
+		return
+	}
+
+	listed, ok := h.listedExternalIDs(ctx, cmd, includeDropped)
+	if !ok {
+		return
+	}
+
+	h.sendRecommendations(ctx, cmd.ChatID, "<b>🎯 Similar Anime</b>", excludeListed(recs, listed))
+}
+
+// handleRecommend suggests anime based on the user's favourites: /recommend [--include-dropped]
+func (h *Handler) handleRecommend(ctx context.Context, cmd BotCommand) {
+	_, includeDropped := takeIncludeDropped(cmd.Args)
+
+	seeds, err := h.userService.RecommendationSeeds(cmd.UserID, recommendSeeds)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get recommendation seeds")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't get recommendations. Please try again later.")
+		return
+	}
+	if len(seeds) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "💡 Add some anime as watching or completed (and rate them) first, then I can recommend more like them.")
+		return
+	}
+
+	// votes add up across seeds, so titles recommended for several favourites rise
+	votes := make(map[int]*models.Recommendation)
+	for _, seed := range seeds {
+		animeID, err := strconv.Atoi(seed)
+		if err != nil {
+			continue
+		}
+		recs, err := h.animeService.GetRecommendations(animeID)
+		if err != nil {
+			if errors.Is(err, services.ErrServiceUnavailable) {
+				h.sendRecommendError(ctx, cmd.ChatID, err)
+				return
+			}
+			h.logger.WithError(err).WithField("anime_id", animeID).Warn("Failed to get recommendations for seed")
+			continue
+		}
+		for _, rec := range recs {
+			if existing, ok := votes[rec.MalID]; ok {
+				existing.Votes += rec.Votes
+				continue
+			}
+			rec := rec
+			votes[rec.MalID] = &rec
+		}
+	}
+
+	merged := make([]models.Recommendation, 0, len(votes))
+	for _, rec := range votes {
+		merged = append(merged, *rec)
+	}
+	slices.SortFunc(merged, func(a, b models.Recommendation) int {
+		if a.Votes != b.Votes {
+			return b.Votes - a.Votes
+		}
+		return strings.Compare(a.Title, b.Title)
+	})
+
+	listed, ok := h.listedExternalIDs(ctx, cmd, includeDropped)
+	if !ok {
+		return
+	}
+
+	h.sendRecommendations(ctx, cmd.ChatID, "<b>💡 Recommended For You</b>", excludeListed(merged, listed))
+}
+
+// takeIncludeDropped removes the --include-dropped flag from args, reporting whether it was there.
+func takeIncludeDropped(args []string) ([]string, bool) {
+	rest := slices.DeleteFunc(slices.Clone(args), func(arg string) bool {
+		return strings.EqualFold(arg, includeDroppedFlag)
+	})
+	return rest, len(rest) != len(args)
+}
+
+// excludeListed drops recommendations whose MAL ID is in listed.
+func excludeListed(recs []models.Recommendation, listed map[string]bool) []models.Recommendation {
+	return slices.DeleteFunc(slices.Clone(recs), func(rec models.Recommendation) bool {
+		return listed[strconv.Itoa(rec.MalID)]
+	})
+}
+
+func (h *Handler) listedExternalIDs(ctx context.Context, cmd BotCommand, includeDropped bool) (map[string]bool, bool) {
+	listed, err := h.userService.ListedExternalIDs(cmd.UserID, includeDropped)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get listed anime")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't get recommendations. Please try again later.")
+		return nil, false
+	}
+	return listed, true
+}
+
+func (h *Handler) sendRecommendError(ctx context.Context, chatID string, err error) {
+	h.logger.WithError(err).Error("Failed to get recommendations")
+	if errors.Is(err, services.ErrServiceUnavailable) {
+		h.sendMessage(ctx, chatID, animeServiceDownMessage)
+		return
+	}
+	h.sendMessage(ctx, chatID, "❌ Sorry, I couldn't get recommendations. Please try again later.")
+}
+
+func (h *Handler) sendRecommendations(ctx context.Context, chatID, heading string, recs []models.Recommendation) {
+	if len(recs) == 0 {
+		h.sendMessage(ctx, chatID, "🤷 No new suggestions, you've already got everything recommended in your list!")
+		return
+	}
+	if len(recs) > recommendLimit {
+		recs = recs[:recommendLimit]
+	}
+
+	var message strings.Builder
+	var rows [][]models.InlineKeyboardButton
+
+	message.WriteString(heading + "\n\n")
+	for i, rec := range recs {
+		animeID := strconv.Itoa(rec.MalID)
+		message.WriteString(fmt.Sprintf("%d. <b>%s</b> (ID: <code>%s</code>) - 👍 %d\n", i+1, esc(rec.Title), animeID, rec.Votes))
+		rows = append(rows, []models.InlineKeyboardButton{
+			{
+				Text:         "➕ " + services.TruncateText(rec.Title, recommendTitleLen),
+				CallbackData: h.createCallbackData(ctx, "add_anime", animeID, string(models.StatusWatchlist)),
+			},
+		})
+	}
+	message.WriteString("\n<i>Anime already in your list are left out. Tap a title to add it to your watchlist.</i>")
+
+	h.sendMessageWithKeyboard(ctx, chatID, message.String(), &models.InlineKeyboardMarkup{InlineKeyboard: rows})
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTakeIncludeDropped(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantArgs []string
+		want     bool
+	}{
+		{nil, nil, false},
+		{[]string{"5114"}, []string{"5114"}, false},
+		{[]string{"5114", "--include-dropped"}, []string{"5114"}, true},
+		{[]string{"--Include-Dropped", "5114"}, []string{"5114"}, true},
+	}
+
+	for _, tt := range tests {
+		args, got := takeIncludeDropped(tt.args)
+		if got != tt.want || !slices.Equal(args, tt.wantArgs) {
+			t.Errorf("%q got %q and %v, want %q and %v", tt.args, args, got, tt.wantArgs, tt.want)
+		}
+	}
+}
+
+func TestExcludeListed(t *testing.T) {
+	recs := []models.Recommendation{{MalID: 1}, {MalID: 5114}, {MalID: 2}}
+
+	got := excludeListed(recs, map[string]bool{"5114": true})
+	if len(got) != 2 || got[0].MalID != 1 || got[1].MalID != 2 {
+		t.Errorf("got %+v, want 5114 left out", got)
+	}
+	if len(recs) != 3 {
+		t.Errorf("excludeListed changed its input to %+v", recs)
+	}
+}
+
+func TestSimilarSkipsListedAnime(t *testing.T) {
+	steinsGate := models.AnimeData{MalID: 9253, Title: "Steins;Gate", Type: "TV"}
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab, steinsGate)
+	env.anime.recs = map[int][]models.Recommendation{
+		1: {
+			{MalID: 5114, Title: fmab.Title, Votes: 30},
+			{MalID: 9253, Title: steinsGate.Title, Votes: 20},
+			{MalID: 2, Title: "Not Yet Listed", Votes: 10},
+		},
+	}
+
+	env.command(t, 1, 1, "/add 5114 watching")
+	env.command(t, 1, 1, "/add 9253 dropped")
+
+	replies := env.command(t, 1, 1, "/similar 1")
+	if !containsAny(replies, "Not Yet Listed") || containsAny(replies, fmab.Title) || containsAny(replies, steinsGate.Title) {
+		t.Errorf("got %q, want only the anime not in the list", replies)
+	}
+
+	replies = env.command(t, 1, 1, "/similar 1 --include-dropped")
+	got := strings.Join(replies, "")
+	if !strings.Contains(got, steinsGate.Title) || !strings.Contains(got, "Not Yet Listed") || strings.Contains(got, fmab.Title) {
+		t.Errorf("got %q, want the dropped anime back but not the watching one", got)
+	}
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/services"
+	"strconv"
+	"time"
+)
+
+// maxDaysBeforeAiring caps /remindair's lead time, like /remind's 365 days.
+const maxDaysBeforeAiring = 365
+
+// handleRemindAir sets a reminder relative to an upcoming anime's air date:
+// /remindair <anime_id> <days_before>
+func (h *Handler) handleRemindAir(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) != 2 {
+		h.sendMessage(ctx, cmd.ChatID, `<b>Usage:</b> /remindair &lt;anime_id&gt; &lt;days_before&gt;
+
+<b>Example:</b> <code>/remindair 52991 1</code> reminds you the day before it starts airing. Use 0 for the day itself.`)
+		return
+	}
+
+	animeID, err := strconv.Atoi(cmd.Args[0])
+	if err != nil || animeID <= 0 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please use a valid numeric ID from search results.")
+		return
+	}
+
+	daysBefore, err := strconv.Atoi(cmd.Args[1])
+	if err != nil || daysBefore < 0 || daysBefore > maxDaysBeforeAiring {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ Invalid number of days. Please use 0-%d days.", maxDaysBeforeAiring))
+		return
+	}
+
+	anime, err := h.animeService.GetAnimeByID(animeID)
+	if err != nil {
+		if errors.Is(err, services.ErrServiceUnavailable) {
+			h.sendMessage(ctx, cmd.ChatID, animeServiceDownMessage)
+			return
+		}
+		h.logger.WithError(err).WithField("anime_id", animeID).Error("Failed to get anime for air date reminder")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Anime with that ID doesn't exist. Please check the ID from search results.")
+		return
+	}
+
+	remindAt, err := services.AirDateReminderTime(anime.Aired, daysBefore, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAirDateUnknown):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ <b>%s</b> doesn't have an air date yet. Try again once it's announced.", esc(anime.Title)))
+		case errors.Is(err, services.ErrAlreadyAired):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("ℹ️ <b>%s</b> already started airing on %s. Use /remind for a reminder from today.", esc(anime.Title), anime.Aired.From.Format("January 2, 2006")))
+		default:
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ <b>%s</b> airs on %s, less than %d days from now. Pick fewer days.", esc(anime.Title), anime.Aired.From.Format("January 2, 2006"), daysBefore))
+		}
+		return
+	}
+
+	message := fmt.Sprintf("%s starts airing on %s!", anime.Title, anime.Aired.From.Format("January 2, 2006"))
+	if err := h.reminderService.CreateReminder(cmd.UserID, animeID, message, remindAt); err != nil {
+		if errors.Is(err, services.ErrDuplicateReminder) {
+			h.sendMessage(ctx, cmd.ChatID, "ℹ️ You already have a reminder for this anime then. See /reminders.")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to create air date reminder")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't create the reminder. Please try again later.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Reminder set! <b>%s</b> airs on %s, I'll remind you on <b>%s</b> (UTC).",
+		esc(anime.Title), anime.Aired.From.Format("January 2, 2006"), remindAt.Format("January 2, 2006")))
+}
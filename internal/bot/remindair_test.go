@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// airingIn returns an anime that starts airing at midnight UTC, days from today.
+func airingIn(id, days int) models.AnimeData {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, days)
+	return models.AnimeData{MalID: id, Title: "Upcoming", Type: "TV", Aired: models.Aired{From: &from}}
+}
+
+func TestRemindAirValidation(t *testing.T) {
+	env := newTestEnv(t, airingIn(1, 30))
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/remindair", "<b>Usage:</b> /remindair"},
+		{"/remindair 1", "<b>Usage:</b> /remindair"},
+		{"/remindair abc 1", "Invalid anime ID"},
+		{"/remindair 0 1", "Invalid anime ID"},
+		{"/remindair 1 -1", "Invalid number of days"},
+		{"/remindair 1 366", "Invalid number of days"},
+		{"/remindair 1 one", "Invalid number of days"},
+		{"/remindair 404 1", "doesn't exist"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(env.handler.handleRemindAir, "1", tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%q got %q, want %q", tt.text, replies, tt.want)
+		}
+	}
+}
+
+func TestRemindAirRejectsUnusableDates(t *testing.T) {
+	unannounced := models.AnimeData{MalID: 3, Title: "Unannounced", Type: "TV"}
+	env := newTestEnv(t, airingIn(1, 3), airingIn(2, -10), unannounced)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/remindair 3 1", "doesn't have an air date yet"},
+		{"/remindair 2 1", "already started airing on"},
+		{"/remindair 1 5", "less than 5 days from now"},
+	}
+
+	for _, tt := range tests {
+		replies := env.run(env.handler.handleRemindAir, "1", tt.text)
+		if !containsAny(replies, tt.want) {
+			t.Errorf("%q got %q, want %q", tt.text, replies, tt.want)
+		}
+		if containsAny(replies, "Reminder set") {
+			t.Errorf("%q got %q, want no reminder", tt.text, replies)
+		}
+	}
+}
+
+func TestRemindAirSetsReminderBeforeAirDate(t *testing.T) {
+	anime := airingIn(1, 10)
+	env := newTestEnvWithDB(t, testutil.DB(t), anime)
+	env.command(t, 1, 1, "/start")
+
+	remindAt := anime.Aired.From.AddDate(0, 0, -2)
+	replies := env.command(t, 1, 1, "/remindair 1 2")
+	if !containsAny(replies, "I'll remind you on <b>"+remindAt.Format("January 2, 2006")+"</b>") {
+		t.Fatalf("got %q, want the reminder set two days before", replies)
+	}
+
+	reminders, err := env.handler.reminderService.GetUserReminders("1", false)
+	if err != nil {
+		t.Fatalf("failed to get reminders: %v", err)
+	}
+	if len(reminders) != 1 || !reminders[0].RemindAt.Equal(remindAt) || !strings.Contains(reminders[0].Message, "starts airing on") {
+		t.Errorf("got %+v, want one reminder at %v", reminders, remindAt)
+	}
+
+	if replies := env.command(t, 1, 1, "/remindair 1 2"); !containsAny(replies, "already have a reminder") {
+		t.Errorf("got %q, want the duplicate refused", replies)
+	}
+}
@@ -0,0 +1,211 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRemindersShowFailures(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+
+	reminders := []models.Reminder{
+		{ID: 1, Message: "pending", MediaTitle: "A", RemindAt: now.Add(time.Hour), CreatedAt: now},
+		{ID: 2, Message: "blocked", MediaTitle: "B", RemindAt: now.Add(-time.Hour), CreatedAt: now, Failed: true, FailureCount: 5},
+	}
+
+	message := env.handler.formatReminders(reminders, false)
+	if !strings.Contains(message, "Couldn't be delivered after 5 attempt(s)") {
+		t.Errorf("got %q, want the failed reminder marked", message)
+	}
+	if !strings.Contains(message, "📅 Pending: 1\n⚠️ Failed: 1\n") {
+		t.Errorf("got %q, want the failure counted apart from pending", message)
+	}
+
+	message = env.handler.formatReminders(reminders[:1], false)
+	if strings.Contains(message, "Failed") {
+		t.Errorf("got %q, want no failures mentioned without any", message)
+	}
+}
+
+func TestRemindersGroupedByAnime(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+
+	reminders := []models.Reminder{
+		{ID: 1, MediaID: 10, MediaTitle: "Frieren", Message: "first", RemindAt: now.Add(time.Hour), CreatedAt: now},
+		{ID: 2, MediaID: 20, MediaTitle: "Mushishi", Message: "second", RemindAt: now.Add(2 * time.Hour), CreatedAt: now},
+		{ID: 3, MediaID: 10, MediaTitle: "Frieren", Message: "third", RemindAt: now.Add(-time.Hour), CreatedAt: now, Sent: true},
+	}
+
+	message := env.handler.formatReminders(reminders, true)
+
+	if n := strings.Count(message, "🎬 <b>Frieren</b>"); n != 1 {
+		t.Errorf("got Frieren's heading %d times, want once", n)
+	}
+	// each anime's reminders sit under its heading, in the order the anime first appears
+	order := []string{"🎬 <b>Frieren</b>", `"first"`, `"third"`, "🎬 <b>Mushishi</b>", `"second"`}
+	last := -1
+	for _, part := range order {
+		i := strings.Index(message, part)
+		if i < last {
+			t.Fatalf("got %q out of order in %q", part, message)
+		}
+		last = i
+	}
+	if !strings.Contains(message, "📅 Pending: 2 | ✅ Sent: 1") {
+		t.Errorf("got %q, want 2 pending and 1 sent", message)
+	}
+
+	// one cancel button per unsent reminder, pointing at that reminder
+	keyboard := env.handler.createRemindersKeyboard(context.Background(), reminders)
+	var cancelled []string
+	for _, row := range keyboard.InlineKeyboard {
+		data, err := env.handler.decodeCallbackData(context.Background(), row[0].CallbackData)
+		if err != nil {
+			t.Fatalf("failed to decode button: %v", err)
+		}
+		cancelled = append(cancelled, data.AnimeID)
+	}
+	if !slices.Equal(cancelled, []string{"1", "2"}) {
+		t.Errorf("got cancel buttons for %v, want reminders 1 and 2", cancelled)
+	}
+}
+
+func TestRemindersCountBeyondShown(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+
+	var reminders []models.Reminder
+	for i := range 12 {
+		reminders = append(reminders, models.Reminder{
+			ID: i + 1, MediaID: i % 2, MediaTitle: fmt.Sprintf("Anime %d", i%2),
+			Message: "test", RemindAt: now.Add(time.Hour), CreatedAt: now,
+		})
+	}
+
+	message := env.handler.formatReminders(reminders, false)
+
+	if !strings.Contains(message, "... and 2 more reminders") {
+		t.Errorf("got %q, want the 2 hidden reminders mentioned", message)
+	}
+	if !strings.Contains(message, "📅 Pending: 12\n") {
+		t.Errorf("got %q, want every pending reminder counted", message)
+	}
+}
+
+func TestRemindersEscapeUserText(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+
+	reminders := []models.Reminder{
+		{ID: 1, MediaTitle: "Kaguya-sama <Love Is War>", Message: "<b>evil</b>", RemindAt: now.Add(time.Hour), CreatedAt: now},
+	}
+
+	message := env.handler.formatReminders(reminders, false)
+	if !strings.Contains(message, "🎬 <b>Kaguya-sama &lt;Love Is War&gt;</b>") || !strings.Contains(message, `"&lt;b&gt;evil&lt;/b&gt;"`) {
+		t.Errorf("got %q, want the title and message escaped", message)
+	}
+}
+
+func TestRemindMessageLimitCountsCharacters(t *testing.T) {
+	env := newTestEnv(t)
+
+	// 200 two-byte characters are within the limit, so the reminder is attempted
+	replies := env.run(env.handler.handleRemind, "1", "/remind 5114 7 "+strings.Repeat("é", 200))
+	if containsAny(replies, "Message too long") || !containsAny(replies, "Setting up your reminder") {
+		t.Errorf("got %q, want 200 characters accepted", replies)
+	}
+
+	replies = env.run(env.handler.handleRemind, "1", "/remind 5114 7 "+strings.Repeat("é", 201))
+	if !containsAny(replies, "Message too long") {
+		t.Errorf("got %q, want 201 characters rejected", replies)
+	}
+}
+
+func TestParseReminderDelay(t *testing.T) {
+	day := 24 * time.Hour
+
+	tests := []struct {
+		arg     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7", 7 * day, false},
+		{"0.5", 12 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"30m", 30 * time.Minute, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"3d", 3 * day, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"12H", 12 * time.Hour, false},
+		{"365", 365 * day, false},
+		{"8760h", 365 * day, false},
+		{"1m", time.Minute, false},
+		{"366", 0, true},
+		{"8761h", 0, true},
+		{"1e9", 0, true},
+		{"30s", 0, true},
+		{"0", 0, true},
+		{"0.0001", 0, true},
+		{"-1", 0, true},
+		{"-12h", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"d", 0, true},
+		{"12x", 0, true},
+		{"twelve", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseReminderDelay(tt.arg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseReminderDelay(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseReminderDelay(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestRemindAcceptsDurations(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, when := range []string{"12h", "0.5", "30m"} {
+		replies := env.run(env.handler.handleRemind, "1", "/remind 5114 "+when+" soon")
+		if containsAny(replies, "Invalid time") || !containsAny(replies, "Setting up your reminder") {
+			t.Errorf("%q got %q, want the reminder attempted", when, replies)
+		}
+	}
+
+	for _, when := range []string{"366", "400d", "9000h", "0", "5s"} {
+		replies := env.run(env.handler.handleRemind, "1", "/remind 5114 "+when+" later")
+		if !containsAny(replies, "Invalid time") || containsAny(replies, "Setting up your reminder") {
+			t.Errorf("%q got %q, want it rejected", when, replies)
+		}
+	}
+}
+
+func TestRemindRejectsDuplicate(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.command(t, 1, 1, "/start")
+
+	if replies := env.command(t, 1, 1, "/remind 5114 7 rewatch"); !containsAny(replies, "Reminder set") {
+		t.Fatalf("got %q, want the reminder set", replies)
+	}
+	// the same minute a moment later is the same reminder
+	if replies := env.command(t, 1, 1, "/remind 5114 7 rewatch"); !containsAny(replies, "already have a reminder for this anime then") {
+		t.Errorf("got %q, want the duplicate refused", replies)
+	}
+	if replies := env.command(t, 1, 1, "/remind 5114 8 rewatch"); !containsAny(replies, "Reminder set") {
+		t.Errorf("got %q, want another day allowed", replies)
+	}
+}
@@ -0,0 +1,402 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strings"
+	"testing"
+)
+
+func TestSearchFormatsResults(t *testing.T) {
+	env := newTestEnv(t)
+	top := fmab
+	top.Score = 9.1
+	top.Year = 2009
+	top.Synopsis = strings.Repeat("鋼", searchSynopsisLength+50)
+	env.anime.results = []models.AnimeData{
+		top,
+		{MalID: 52034, Title: "<Oshi no Ko>", Type: "TV"},
+	}
+
+	replies := env.run(env.handler.handleSearch, "1", "/search fullmetal")
+	if len(replies) != 2 || replies[0] != "🔎 Searching for anime..." {
+		t.Fatalf("got %q, want the searching notice and the results", replies)
+	}
+	if len(env.anime.queries) != 1 || env.anime.queries[0] != "fullmetal" {
+		t.Errorf("searched for %q, want fullmetal", env.anime.queries)
+	}
+
+	sent := env.telegram.lastSent(t)
+	for _, want := range []string{
+		"<b>🔍 Search Results</b>",
+		"<b>Fullmetal Alchemist: Brotherhood</b>",
+		"🆔 ID: <code>5114</code> | ⭐ 9.1 | 📺 64 eps | 📅 2009",
+		"📝 " + strings.Repeat("鋼", searchSynopsisLength) + "...\n",
+		"&lt;Oshi no Ko&gt;",
+	} {
+		if !strings.Contains(sent.Text, want) {
+			t.Errorf("results are missing %q:\n%s", want, sent.Text)
+		}
+	}
+	if strings.Contains(sent.Text, "<Oshi no Ko>") {
+		t.Error("title wasn't escaped")
+	}
+
+	if sent.Keyboard == nil {
+		t.Fatal("results were sent without a keyboard")
+	}
+	var buttons int
+	for _, row := range sent.Keyboard.InlineKeyboard {
+		buttons += len(row)
+	}
+	if buttons == 0 {
+		t.Error("results keyboard has no buttons")
+	}
+}
+
+func TestSearchNoResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []models.AnimeData
+	}{
+		{"empty", nil},
+		{"only adult", []models.AnimeData{{MalID: 1, Title: "Adult", Rating: "Rx - Hentai"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.anime.results = tt.results
+
+			env.run(env.handler.handleSearch, "1", "/search nothing")
+
+			sent := env.telegram.lastSent(t)
+			if sent.Text != "❌ No anime found matching your search" {
+				t.Errorf("got %q, want the no results message", sent.Text)
+			}
+			if sent.Keyboard != nil {
+				t.Error("no results message has a keyboard")
+			}
+		})
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("search failed: %w", services.ErrServiceUnavailable), animeServiceDownMessage},
+		{errors.New("boom"), "❌ Error occurred while searching. Please try again later."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			env := newTestEnv(t)
+			env.anime.err = tt.err
+
+			env.run(env.handler.handleSearch, "1", "/search naruto")
+
+			if sent := env.telegram.lastSent(t); sent.Text != tt.want {
+				t.Errorf("got %q, want %q", sent.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchNeedsQuery(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.run(env.handler.handleSearch, "1", "/search")
+
+	if sent := env.telegram.lastSent(t); !strings.HasPrefix(sent.Text, "Please provide an anime name") {
+		t.Errorf("got %q, want the usage", sent.Text)
+	}
+	if len(env.anime.queries) != 0 {
+		t.Error("searched without a query")
+	}
+}
+
+func TestParseSearchFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		want     models.SearchFilters
+		wantRest string
+		wantErr  bool
+	}{
+		{"no flags", "akira", models.SearchFilters{}, "akira", false},
+		{"type and year", "--type movie --year 2019 weathering with you", models.SearchFilters{Type: "movie", Year: 2019}, "weathering with you", false},
+		{"equals form", "--type=OVA --year=1988 akira", models.SearchFilters{Type: "ova", Year: 1988}, "akira", false},
+		{"flags after the query", "akira --type movie", models.SearchFilters{Type: "movie"}, "akira", false},
+		{"invalid type", "--type series akira", models.SearchFilters{}, "", true},
+		{"invalid year", "--year 19x8 akira", models.SearchFilters{}, "", true},
+		{"year too early", "--year 1800 akira", models.SearchFilters{}, "", true},
+		{"missing value", "akira --year", models.SearchFilters{}, "", true},
+		{"unknown flag", "--genre action akira", models.SearchFilters{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, rest, err := parseSearchFlags(strings.Fields(tt.args))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSearchFlags(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if filters != tt.want {
+				t.Errorf("got filters %+v, want %+v", filters, tt.want)
+			}
+			if got := strings.Join(rest, " "); got != tt.wantRest {
+				t.Errorf("got query %q, want %q", got, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestSearchRejectsInvalidFlags(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.run(env.handler.handleSearch, "1", "/search --type series akira")
+
+	if sent := env.telegram.lastSent(t); !strings.HasPrefix(sent.Text, `❌ invalid type "series"`) {
+		t.Errorf("got %q, want the invalid type error", sent.Text)
+	}
+	if len(env.anime.queries) != 0 {
+		t.Error("searched despite the invalid flag")
+	}
+}
+
+func TestSearchSanitizesQuery(t *testing.T) {
+	env := newTestEnv(t)
+	env.anime.results = []models.AnimeData{fmab}
+
+	env.handler.handleSearch(context.Background(), BotCommand{Command: "/search", Args: []string{"full\x00metal", "\u200b"}, UserID: "1", ChatID: "1"})
+	if len(env.anime.queries) != 1 || env.anime.queries[0] != "fullmetal" {
+		t.Errorf("got searches %q, want the sanitized query", env.anime.queries)
+	}
+
+	env.handler.handleSearch(context.Background(), BotCommand{Command: "/search", Args: []string{"\x00\x07", "\u200b"}, UserID: "1", ChatID: "1"})
+	if sent := env.telegram.lastSent(t); !strings.Contains(sent.Text, "doesn't contain any searchable text") {
+		t.Errorf("got %q, want the query rejected", sent.Text)
+	}
+	if len(env.anime.queries) != 1 {
+		t.Error("searched for a query that was empty once sanitized")
+	}
+}
+
+func TestFormatSearchResultsVerbosity(t *testing.T) {
+	env := newTestEnv(t)
+	results := []models.AnimeData{
+		{MalID: 5114, Title: "Fullmetal Alchemist: Brotherhood", Score: 9.1, Episodes: 64, Synopsis: strings.Repeat("鋼", detailedSynopsisLength+50)},
+		{MalID: 121, Title: "Fullmetal Alchemist", Score: 8.1, Episodes: 51, Synopsis: "The first adaptation."},
+	}
+
+	tests := []struct {
+		verbosity models.SearchVerbosity
+		want      []string
+		notWant   []string
+	}{
+		{
+			models.VerbosityCompact,
+			[]string{"• Fullmetal Alchemist: Brotherhood (ID: <code>5114</code>)\n", "• Fullmetal Alchemist (ID: <code>121</code>)\n"},
+			[]string{"📝", "⭐", "📺"},
+		},
+		{
+			models.VerbosityNormal,
+			[]string{
+				"🆔 ID: <code>5114</code> | ⭐ 9.1 | 📺 64 eps",
+				"📝 " + strings.Repeat("鋼", searchSynopsisLength) + "...\n",
+				"<b>Other Results (1 more):</b>\n• Fullmetal Alchemist (ID: 121) - ⭐ 8.1",
+			},
+			[]string{"The first adaptation.", "🆔 ID: <code>121</code>"},
+		},
+		{
+			models.VerbosityDetailed,
+			[]string{
+				"📝 " + strings.Repeat("鋼", detailedSynopsisLength) + "...\n",
+				"🆔 ID: <code>121</code> | ⭐ 8.1 | 📺 51 eps",
+				"📝 The first adaptation.",
+			},
+			[]string{"Other Results"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.verbosity), func(t *testing.T) {
+			message := env.handler.formatSearchResults(results, tt.verbosity)
+			for _, want := range tt.want {
+				if !strings.Contains(message, want) {
+					t.Errorf("got %q, want it to contain %q", message, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(message, notWant) {
+					t.Errorf("got %q, want no %q", message, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchUsesUsersVerbosity(t *testing.T) {
+	env := newTestEnv(t)
+	env.anime.results = []models.AnimeData{fmab}
+	settings := models.DefaultUserSettings()
+	settings.SearchVerbosity = models.VerbosityCompact
+	env.cacheSettings(t, "1", settings)
+
+	env.run(env.handler.handleSearch, "1", "/search fullmetal")
+	if sent := env.telegram.lastSent(t); !strings.Contains(sent.Text, "• Fullmetal Alchemist: Brotherhood (ID: <code>5114</code>)") {
+		t.Errorf("got %q, want compact results", sent.Text)
+	}
+}
+
+func TestVerbosityRejectsUnknown(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleVerbosity, "1", "/verbosity loud"); !containsAny(replies, "Unknown verbosity") {
+		t.Errorf("got %q, want the level rejected", replies)
+	}
+}
+
+func TestAgainFetchesNextPage(t *testing.T) {
+	env := newTestEnv(t)
+	env.anime.results = []models.AnimeData{fmab}
+	env.anime.hasNext = true
+
+	if replies := env.run(env.handler.handleAgain, "1", "/again"); !containsAny(replies, "No recent search to repeat") {
+		t.Errorf("got %q before any search, want nothing to repeat", replies)
+	}
+
+	replies := env.run(env.handler.handleSearch, "1", "/search --type tv fullmetal")
+	if !containsAny(replies, "Use /again for more results") {
+		t.Errorf("got %q, want the /again hint", replies)
+	}
+
+	last, err := env.users.GetLastSearch("1")
+	if err != nil || last == nil {
+		t.Fatalf("got %+v (err %v), want the search stored", last, err)
+	}
+	if last.Query != "fullmetal" || last.Page != 1 || !last.HasNext || last.Filters.Type != "tv" {
+		t.Errorf("got %+v, want page 1 of the tv search for fullmetal", last)
+	}
+
+	env.run(env.handler.handleAgain, "1", "/again")
+	env.run(env.handler.handleAgain, "1", "/again")
+
+	if len(env.anime.queries) != 3 || env.anime.queries[2] != "fullmetal" {
+		t.Fatalf("got queries %q, want the search repeated", env.anime.queries)
+	}
+	for i, want := range []int{1, 2, 3} {
+		if got := env.anime.filters[i]; got.Page != want || got.Type != "tv" {
+			t.Errorf("search %d got %+v, want page %d of the tv search", i+1, got, want)
+		}
+	}
+
+	// the last page ends it
+	env.anime.hasNext = false
+	env.run(env.handler.handleAgain, "1", "/again")
+	if replies := env.run(env.handler.handleAgain, "1", "/again"); !containsAny(replies, "That's all the results for <b>fullmetal</b>") {
+		t.Errorf("got %q, want the end of the results", replies)
+	}
+	if len(env.anime.queries) != 4 {
+		t.Errorf("made %d searches, want none past the last page", len(env.anime.queries))
+	}
+}
+
+func TestNewSearchReplacesLast(t *testing.T) {
+	env := newTestEnv(t)
+	env.anime.results = []models.AnimeData{fmab}
+	env.anime.hasNext = true
+
+	env.run(env.handler.handleSearch, "1", "/search fullmetal")
+	env.run(env.handler.handleAgain, "1", "/again")
+	env.run(env.handler.handleSearch, "1", "/search steins gate")
+
+	last, err := env.users.GetLastSearch("1")
+	if err != nil || last == nil || last.Query != "steins gate" || last.Page != 1 {
+		t.Errorf("got %+v (err %v), want page 1 of the new search", last, err)
+	}
+
+	// a search that finds nothing leaves nothing to repeat
+	env.anime.results = nil
+	env.run(env.handler.handleSearch, "1", "/search nothing")
+	if last, err := env.users.GetLastSearch("1"); err != nil || last != nil {
+		t.Errorf("got %+v (err %v), want the last search cleared", last, err)
+	}
+}
+
+func TestSearchByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantDetail bool
+		wantQuery  string
+	}{
+		{"known ID", "/search 5114", true, ""},
+		{"unknown ID", "/search 404", false, "404"},
+		{"ID with filters", "/search 5114 --type tv", false, "5114"},
+		{"zero", "/search 0", false, "0"},
+		{"text", "/search fullmetal", false, "fullmetal"},
+		{"text with a number", "/search fullmetal 2009", false, "fullmetal 2009"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, fmab)
+			env.anime.results = []models.AnimeData{fmab}
+
+			env.run(env.handler.handleSearch, "1", tt.text)
+
+			sent := env.telegram.lastSent(t)
+			if detail := !strings.Contains(sent.Text, "Search Results"); detail != tt.wantDetail {
+				t.Errorf("got %q, want detail view %v", sent.Text, tt.wantDetail)
+			}
+			if tt.wantDetail {
+				if len(env.anime.queries) != 0 {
+					t.Errorf("searched for %q, want only the ID looked up", env.anime.queries)
+				}
+				if !strings.Contains(sent.Text, fmab.Title) {
+					t.Errorf("got %q, want the anime's details", sent.Text)
+				}
+				return
+			}
+			if len(env.anime.queries) != 1 || env.anime.queries[0] != tt.wantQuery {
+				t.Errorf("searched for %q, want %q", env.anime.queries, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestSearchByIDLookupFails(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.anime.err = errors.New("API returned status code 500")
+
+	env.run(env.handler.handleSearch, "1", "/search 5114")
+
+	if sent := env.telegram.lastSent(t); !strings.Contains(sent.Text, "Error occurred while searching") {
+		t.Errorf("got %q, want the search error", sent.Text)
+	}
+	if len(env.anime.queries) != 0 {
+		t.Errorf("searched for %q, want no text search after a failed lookup", env.anime.queries)
+	}
+}
+
+func TestSearchByIDServiceDown(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.anime.err = fmt.Errorf("lookup failed: %w", services.ErrServiceUnavailable)
+
+	env.run(env.handler.handleSearch, "1", "/search 5114")
+
+	if sent := env.telegram.lastSent(t); sent.Text != animeServiceDownMessage {
+		t.Errorf("got %q, want the service down message", sent.Text)
+	}
+	if len(env.anime.queries) != 0 {
+		t.Errorf("searched for %q, want no text search while Jikan is down", env.anime.queries)
+	}
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+	"strings"
+)
+
+// handleSettings shows the user's preferences with a button to change each.
+func (h *Handler) handleSettings(ctx context.Context, cmd BotCommand) {
+	settings, err := h.userService.GetSettings(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get settings")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't load your settings. Please try again later.")
+		return
+	}
+
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, formatSettings(settings), h.createSettingsKeyboard(ctx, settings))
+}
+
+// handleCallbackToggleSetting flips the setting named in data.Query (or moves verbosity
+// to the next level), then redraws the settings message.
+func (h *Handler) handleCallbackToggleSetting(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	settings, err := h.userService.GetSettings(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get settings")
+		h.answerCallback(ctx, callback.Id, "❌ Failed to load settings", true)
+		return
+	}
+
+	var value any
+	switch data.Query {
+	case models.SettingNSFW:
+		settings.ShowNSFW = !settings.ShowNSFW
+		value = settings.ShowNSFW
+	case models.SettingPublic:
+		settings.IsPublic = !settings.IsPublic
+		value = settings.IsPublic
+	case models.SettingNotifications:
+		settings.NotificationsPaused = !settings.NotificationsPaused
+		value = settings.NotificationsPaused
+	case models.SettingAotd:
+		settings.AotdEnabled = !settings.AotdEnabled
+		value = settings.AotdEnabled
+	case models.SettingSilent:
+		settings.SilentReminders = !settings.SilentReminders
+		value = settings.SilentReminders
+	case models.SettingVerbosity:
+		settings.SearchVerbosity = models.NextVerbosity(settings.SearchVerbosity)
+		value = settings.SearchVerbosity
+	default:
+		h.answerCallback(ctx, callback.Id, "❌ Unknown setting", false)
+		return
+	}
+
+	err = h.userService.UpdateSetting(userID, data.Query, value)
+	if err != nil {
+		h.logger.WithError(err).WithField("setting", data.Query).Error("Failed to update setting")
+		h.answerCallback(ctx, callback.Id, "❌ Failed to update setting", true)
+		return
+	}
+
+	h.answerCallback(ctx, callback.Id, "✅ Saved", false)
+	h.editMessage(ctx, chatID, callback.Message.MessageId, formatSettings(settings), h.createSettingsKeyboard(ctx, settings))
+}
+
+func formatSettings(settings *models.UserSettings) string {
+	var message strings.Builder
+	message.WriteString("<b>⚙️ Your Settings</b>\n\n")
+
+	message.WriteString(fmt.Sprintf("🕒 Time zone: %s\n", esc(settings.Timezone)))
+	if settings.LanguageCode != "" {
+		message.WriteString(fmt.Sprintf("🌐 Language: %s\n", esc(settings.LanguageCode)))
+	}
+	message.WriteString(fmt.Sprintf("🔎 Search results: %s\n", settings.SearchVerbosity))
+	message.WriteString(fmt.Sprintf("🔔 Notifications: %s\n", onOff(!settings.NotificationsPaused)))
+	message.WriteString(fmt.Sprintf("🔕 Silent reminders: %s\n", onOff(settings.SilentReminders)))
+	message.WriteString(fmt.Sprintf("🌅 Anime of the day: %s\n", onOff(settings.AotdEnabled)))
+	message.WriteString(fmt.Sprintf("🔞 Adult content: %s\n", onOff(settings.ShowNSFW)))
+	message.WriteString(fmt.Sprintf("🏆 On the leaderboard: %s\n", onOff(settings.IsPublic)))
+
+	message.WriteString("\n<i>Tap a button to change a setting. Change the time zone with /aotd on &lt;timezone&gt;.</i>")
+
+	return message.String()
+}
+
+// createSettingsKeyboard has one button per setting, labelled with what tapping it does.
+func (h *Handler) createSettingsKeyboard(ctx context.Context, settings *models.UserSettings) *models.InlineKeyboardMarkup {
+	button := func(text, setting string) models.InlineKeyboardButton {
+		return models.InlineKeyboardButton{
+			Text:         text,
+			CallbackData: h.encodeCallbackData(ctx, models.CallbackData{Action: "toggle_setting", Query: setting}),
+		}
+	}
+
+	notifications := "🔕 Pause notifications"
+	if settings.NotificationsPaused {
+		notifications = "🔔 Resume notifications"
+	}
+
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{button("🔎 Search: "+string(models.NextVerbosity(settings.SearchVerbosity)), models.SettingVerbosity)},
+			{
+				button(notifications, models.SettingNotifications),
+				button("Silent reminders "+onOff(!settings.SilentReminders), models.SettingSilent),
+			},
+			{
+				button("Anime of the day "+onOff(!settings.AotdEnabled), models.SettingAotd),
+				button("Adult content "+onOff(!settings.ShowNSFW), models.SettingNSFW),
+			},
+			{button("Leaderboard "+onOff(!settings.IsPublic), models.SettingPublic)},
+		},
+	}
+}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestFormatSettings(t *testing.T) {
+	settings := models.DefaultUserSettings()
+
+	want := "<b>⚙️ Your Settings</b>\n\n" +
+		"🕒 Time zone: UTC\n" +
+		"🔎 Search results: normal\n" +
+		"🔔 Notifications: on\n" +
+		"🔕 Silent reminders: off\n" +
+		"🌅 Anime of the day: off\n" +
+		"🔞 Adult content: off\n" +
+		"🏆 On the leaderboard: off\n" +
+		"\n<i>Tap a button to change a setting. Change the time zone with /aotd on &lt;timezone&gt;.</i>"
+	if got := formatSettings(&settings); got != want {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+
+	settings.LanguageCode = "de"
+	settings.NotificationsPaused = true
+	settings.ShowNSFW = true
+	got := formatSettings(&settings)
+	for _, want := range []string{"🌐 Language: de\n", "🔔 Notifications: off\n", "🔞 Adult content: on\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("settings are missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSettingsShowsCurrentPreferences(t *testing.T) {
+	env := newTestEnv(t)
+	settings := models.DefaultUserSettings()
+	settings.AotdEnabled = true
+	settings.SearchVerbosity = models.VerbosityDetailed
+	env.cacheSettings(t, "1", settings)
+
+	env.run(env.handler.handleSettings, "1", "/settings")
+
+	sent := env.telegram.lastSent(t)
+	if !strings.Contains(sent.Text, "🌅 Anime of the day: on") || !strings.Contains(sent.Text, "🔎 Search results: detailed") {
+		t.Errorf("got %q, want the cached preferences", sent.Text)
+	}
+	if sent.Keyboard == nil {
+		t.Fatal("settings were sent without a keyboard")
+	}
+
+	// each button says what tapping it does
+	actions := keyboardActions(t, env, sent.Keyboard)
+	for _, want := range []string{
+		"🔎 Search: compact=toggle_setting:",
+		"Anime of the day off=toggle_setting:",
+		"Adult content on=toggle_setting:",
+		"🔕 Pause notifications=toggle_setting:",
+	} {
+		if !containsAny(actions, want) {
+			t.Errorf("got buttons %q, want %q", actions, want)
+		}
+	}
+}
+
+func TestSettingsWithoutDatabase(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleSettings, "1", "/settings"); !containsAny(replies, "couldn't load your settings") {
+		t.Errorf("got %q, want the settings error", replies)
+	}
+}
+
+func TestToggleSettingRejectsUnknown(t *testing.T) {
+	env := newTestEnv(t)
+	env.cacheSettings(t, "1", models.DefaultUserSettings())
+
+	env.press(1, 1, "cb-1", env.callbackData(t, models.CallbackData{Action: "toggle_setting", Query: "timezone"}))
+
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "❌ Unknown setting" {
+		t.Errorf("got answers %+v, want unknown setting", answers)
+	}
+	if len(env.telegram.edits) != 0 {
+		t.Errorf("got edits %+v, want the message left alone", env.telegram.edits)
+	}
+}
+
+func TestToggleSettingUpdatesOne(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t))
+	env.command(t, 1, 1, "/start")
+
+	toggle := func(setting string) {
+		t.Helper()
+		id := "cb-" + setting
+		env.press(1, 1, id, env.callbackData(t, models.CallbackData{Action: "toggle_setting", Query: setting}))
+		if answers := env.telegram.answersFor(id); len(answers) != 1 || answers[0].Text != "✅ Saved" {
+			t.Fatalf("got answers %+v, want saved", answers)
+		}
+	}
+
+	toggle(models.SettingNSFW)
+	edit := env.telegram.edits[len(env.telegram.edits)-1]
+	if !strings.Contains(edit.Text, "🔞 Adult content: on") || edit.Keyboard == nil {
+		t.Errorf("got %q, want the settings redrawn with adult content on", edit.Text)
+	}
+
+	settings, err := env.users.GetSettings("1")
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	want := models.DefaultUserSettings()
+	want.ShowNSFW = true
+	want.UpdatedAt = settings.UpdatedAt
+	if *settings != want {
+		t.Errorf("got %+v, want only adult content changed", *settings)
+	}
+
+	// verbosity cycles through the levels
+	toggle(models.SettingVerbosity)
+	if settings, _ := env.users.GetSettings("1"); settings.SearchVerbosity != models.VerbosityDetailed {
+		t.Errorf("got verbosity %q, want detailed after normal", settings.SearchVerbosity)
+	}
+	toggle(models.SettingVerbosity)
+	if settings, _ := env.users.GetSettings("1"); settings.SearchVerbosity != models.VerbosityCompact {
+		t.Errorf("got verbosity %q, want compact after detailed", settings.SearchVerbosity)
+	}
+}
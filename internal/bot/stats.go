@@ -0,0 +1,323 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	topGenresLimit    = 5
+	genreStatsLimit   = 20
+	ratingBarWidth    = 10 // blocks in the longest histogram bar
+	leaderboardLimit  = 10
+	leaderboardPeriod = 7 * 24 * time.Hour
+	trendingLimit     = 10
+	trendingPeriod    = 7 * 24 * time.Hour
+	trendingTitleLen  = 40 // button text
+)
+
+// sparkLevels are the bar heights of the /trend sparkline, lowest first.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+func (h *Handler) handleStats(ctx context.Context, cmd BotCommand) {
+	if len(cmd.Args) > 0 && strings.EqualFold(cmd.Args[0], "genres") {
+		h.handleGenreStats(ctx, cmd)
+		return
+	}
+
+	counts, err := h.userService.StatusCounts(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get status counts")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your stats. Please try again later.")
+		return
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	if total == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "📊 No stats yet! Use /search to find anime and add them to your list.")
+		return
+	}
+
+	genres, err := h.userService.TopGenres(cmd.UserID, topGenresLimit)
+	if err != nil {
+		// stats are still useful without genres
+		h.logger.WithError(err).Warn("Failed to get top genres")
+	}
+
+	ratings, err := h.userService.RatingDistribution(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to get rating distribution")
+	}
+
+	watchMinutes, watchFormats, err := h.userService.WatchTime(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to get watch time")
+	}
+
+	message := h.formatStats(counts, total, genres, ratings)
+	if watchMinutes > 0 {
+		message += formatWatchTime(watchMinutes, watchFormats)
+	}
+	h.sendMessage(ctx, cmd.ChatID, message)
+}
+
+// formatWatchTime renders the watch time estimate and its split by format.
+func formatWatchTime(total int, formats []models.FormatWatchTime) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("\n<b>⏱ Watch Time:</b> ~%s\n", formatDuration(time.Duration(total)*time.Minute)))
+	for _, format := range formats {
+		message.WriteString(fmt.Sprintf("   • %s: %s\n", esc(format.Format), formatDuration(time.Duration(format.Minutes)*time.Minute)))
+	}
+	return message.String()
+}
+
+func (h *Handler) formatStats(counts map[models.Status]int, total int, genres []models.GenreCount, ratings map[string]int) string {
+	var message strings.Builder
+	message.WriteString("<b>📊 Your Stats</b>\n\n")
+	message.WriteString(fmt.Sprintf("📺 Total anime: %d\n", total))
+
+	orderedStatuses := []models.Status{
+		models.StatusWatching,
+		models.StatusCompleted,
+		models.StatusWatchlist,
+		models.StatusOnHold,
+		models.StatusDropped,
+	}
+	for _, status := range orderedStatuses {
+		if count := counts[status]; count > 0 {
+			message.WriteString(fmt.Sprintf("%s %s: %d\n", getStatusEmoji(status), strings.Title(string(status)), count))
+		}
+	}
+
+	if len(genres) > 0 {
+		message.WriteString("\n<b>🏷 Top Genres:</b>\n")
+		for i, genre := range genres {
+			message.WriteString(fmt.Sprintf("%d. %s (%d)\n", i+1, esc(genre.Name), genre.Count))
+		}
+	}
+
+	if len(ratings) > 0 {
+		message.WriteString("\n<b>⭐ Your Ratings:</b>\n")
+		message.WriteString(formatRatingChart(ratings))
+	}
+
+	return message.String()
+}
+
+func (h *Handler) handleGenreStats(ctx context.Context, cmd BotCommand) {
+	genres, err := h.userService.GenreRatingBreakdown(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get genre ratings")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your genre stats. Please try again later.")
+		return
+	}
+
+	if len(genres) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "🏷 No genre stats yet! Add anime with a status other than watchlist using /add.")
+		return
+	}
+
+	if len(genres) > genreStatsLimit {
+		genres = genres[:genreStatsLimit]
+	}
+
+	var message strings.Builder
+	message.WriteString("<b>🏷 Your Genres by Rating</b>\n\n")
+	for i, genre := range genres {
+		titles := "titles"
+		if genre.Count == 1 {
+			titles = "title"
+		}
+		average := "not rated"
+		if genre.AverageRating != nil {
+			average = fmt.Sprintf("⭐ %.1f avg (%d rated)", *genre.AverageRating, genre.Rated)
+		}
+		message.WriteString(fmt.Sprintf("%d. <b>%s</b> - %d %s, %s\n", i+1, esc(genre.Name), genre.Count, titles, average))
+	}
+	message.WriteString("\n<i>Watchlist entries aren't counted.</i>")
+
+	h.sendMessage(ctx, cmd.ChatID, message.String())
+}
+
+// formatRatingChart renders the rating histogram as monospace text bars, scaled to the fullest bucket.
+func formatRatingChart(ratings map[string]int) string {
+	highest := 0
+	for _, count := range ratings {
+		highest = max(highest, count)
+	}
+
+	var chart strings.Builder
+	chart.WriteString("<code>")
+	for _, bucket := range models.RatingBuckets {
+		count := ratings[bucket]
+		bar := 0
+		if highest > 0 {
+			bar = count * ratingBarWidth / highest
+		}
+		if count > 0 && bar == 0 {
+			bar = 1 // don't hide buckets that have entries
+		}
+		chart.WriteString(fmt.Sprintf("%-4s %s%s %d\n", bucket,
+			strings.Repeat("█", bar), strings.Repeat("░", ratingBarWidth-bar), count))
+	}
+	chart.WriteString("</code>")
+
+	return chart.String()
+}
+
+// handleTrend shows the user's completions per month over the last year as a sparkline.
+func (h *Handler) handleTrend(ctx context.Context, cmd BotCommand) {
+	months, err := h.userService.MonthlyCompletions(cmd.UserID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get monthly completions")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your trend. Please try again later.")
+		return
+	}
+
+	total := 0
+	best := months[0]
+	for _, month := range months {
+		total += month.Count
+		if month.Count > best.Count {
+			best = month
+		}
+	}
+
+	if total == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "📈 No completions in the last 12 months. Mark anime as completed with /update to see your trend.")
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString("<b>📈 Completions, Last 12 Months</b>\n\n")
+	message.WriteString(fmt.Sprintf("<code>%s</code>\n", formatSparkline(months)))
+	message.WriteString(fmt.Sprintf("<i>%s to %s</i>\n\n", months[0].Month.Format("Jan 2006"), months[len(months)-1].Month.Format("Jan 2006")))
+
+	for _, month := range months {
+		message.WriteString(fmt.Sprintf("<code>%s</code> %d\n", month.Month.Format("Jan 2006"), month.Count))
+	}
+
+	message.WriteString(fmt.Sprintf("\n✅ Total: %d · 🏆 Best month: %s (%d)", total, best.Month.Format("January 2006"), best.Count))
+
+	h.sendMessage(ctx, cmd.ChatID, message.String())
+}
+
+// formatSparkline renders one bar per month, scaled to the busiest month. Months without
+// completions get the lowest bar and every month with some gets at least the next one up.
+func formatSparkline(months []models.MonthlyCount) string {
+	highest := 0
+	for _, month := range months {
+		highest = max(highest, month.Count)
+	}
+
+	var line strings.Builder
+	for _, month := range months {
+		level := 0
+		if month.Count > 0 && highest > 0 {
+			level = (month.Count*(len(sparkLevels)-1) + highest - 1) / highest
+		}
+		line.WriteRune(sparkLevels[level])
+	}
+
+	return line.String()
+}
+
+func (h *Handler) handleCard(ctx context.Context, cmd BotCommand) {
+	card, err := h.userService.RenderStatsCard(cmd.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrStatsCardUnavailable) {
+			h.sendMessage(ctx, cmd.ChatID, "🖼 Stats cards aren't available right now. Use /stats instead.")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to render stats card")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't create your stats card. Please try again later.")
+		return
+	}
+
+	chatID, err := strconv.Atoi(cmd.ChatID)
+	if err != nil {
+		h.logger.WithError(err).Error("Invalid chat ID")
+		return
+	}
+
+	if err := h.telegram.SendPhoto(ctx, chatID, card, "stats.png", "📊 Your anime stats"); err != nil {
+		h.logger.WithError(err).Error("Failed to send stats card")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't send your stats card. Please try again later.")
+	}
+}
+
+func (h *Handler) handleLeaderboard(ctx context.Context, cmd BotCommand) {
+	entries, err := h.userService.Leaderboard(time.Now().Add(-leaderboardPeriod), leaderboardLimit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get leaderboard")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve the leaderboard. Please try again later.")
+		return
+	}
+
+	if len(entries) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "🏆 No completions on the leaderboard this week yet.\n\nUse <code>/public on</code> to take part!")
+		return
+	}
+
+	medals := []string{"🥇", "🥈", "🥉"}
+
+	var message strings.Builder
+	message.WriteString("<b>🏆 This Week's Top Completers</b>\n\n")
+	for i, entry := range entries {
+		rank := fmt.Sprintf("%d.", i+1)
+		if i < len(medals) {
+			rank = medals[i]
+		}
+		message.WriteString(fmt.Sprintf("%s @%s - %d completed\n", rank, esc(entry.Username), entry.Completions))
+	}
+	message.WriteString("\n<i>Only users who opted in with /public appear here.</i>")
+
+	h.sendMessage(ctx, cmd.ChatID, message.String())
+}
+
+func (h *Handler) handleRecent(ctx context.Context, cmd BotCommand) {
+	trending, err := h.userService.TrendingMedia(time.Now().Add(-trendingPeriod), trendingLimit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get trending media")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve recent activity. Please try again later.")
+		return
+	}
+
+	if len(trending) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "🔥 Nothing has been added this week yet. Be the first with /search!")
+		return
+	}
+
+	var message strings.Builder
+	var rows [][]models.InlineKeyboardButton
+
+	message.WriteString("<b>🔥 Most Added This Week</b>\n\n")
+	for i, item := range trending {
+		users := "users"
+		if item.Adds == 1 {
+			users = "user"
+		}
+		message.WriteString(fmt.Sprintf("%d. <b>%s</b> (ID: %s) - %d %s\n",
+			i+1, esc(item.Media.Title), item.Media.ExternalID, item.Adds, users))
+
+		rows = append(rows, []models.InlineKeyboardButton{
+			{
+				Text:         "➕ " + services.TruncateText(item.Media.Title, trendingTitleLen),
+				CallbackData: h.createCallbackData(ctx, "add_anime", item.Media.ExternalID, string(models.StatusWatchlist)),
+			},
+		})
+	}
+	message.WriteString("\n<i>Tap a title to add it to your watchlist.</i>")
+
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message.String(), &models.InlineKeyboardMarkup{InlineKeyboard: rows})
+}
@@ -0,0 +1,249 @@
+package bot
+
+import (
+	"encoding/json"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"strings"
+	"testing"
+)
+
+func TestFormatRatingChart(t *testing.T) {
+	chart := formatRatingChart(map[string]int{"1-2": 1, "7-8": 20, "9-10": 10})
+
+	want := "<code>" +
+		"1-2  █░░░░░░░░░ 1\n" + // rounds down to nothing, but still shown
+		"3-4  ░░░░░░░░░░ 0\n" +
+		"5-6  ░░░░░░░░░░ 0\n" +
+		"7-8  ██████████ 20\n" +
+		"9-10 █████░░░░░ 10\n" +
+		"</code>"
+	if chart != want {
+		t.Errorf("got\n%s\nwant\n%s", chart, want)
+	}
+}
+
+func TestFormatWatchTime(t *testing.T) {
+	message := formatWatchTime(1590, []models.FormatWatchTime{
+		{Format: "TV", Minutes: 1440},
+		{Format: "Movie", Minutes: 110},
+		{Format: "<OVA>", Minutes: 40},
+	})
+
+	want := "\n<b>⏱ Watch Time:</b> ~1d 2h 30m\n" +
+		"   • TV: 1d 0h 0m\n" +
+		"   • Movie: 1h 50m\n" +
+		"   • &lt;OVA&gt;: 40m\n"
+	if message != want {
+		t.Errorf("got %q, want %q", message, want)
+	}
+}
+
+func TestStatsRatingsSection(t *testing.T) {
+	env := newTestEnv(t)
+	counts := map[models.Status]int{models.StatusCompleted: 2}
+
+	if message := env.handler.formatStats(counts, 2, nil, nil); strings.Contains(message, "Your Ratings") {
+		t.Errorf("got %q, want no ratings section without ratings", message)
+	}
+
+	message := env.handler.formatStats(counts, 2, nil, map[string]int{"9-10": 2})
+	if !strings.Contains(message, "<b>⭐ Your Ratings:</b>\n<code>") {
+		t.Errorf("got %q, want the ratings chart", message)
+	}
+}
+
+func TestLeaderboardShowsOnlyPublicUsers(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	if replies := env.command(t, 1, 1, "/leaderboard"); !containsAny(replies, "No completions on the leaderboard this week yet") {
+		t.Errorf("got %q, want an empty leaderboard", replies)
+	}
+
+	env.command(t, 1, 1, "/public on")
+	env.command(t, 1, 1, "/add 5114 completed")
+	env.command(t, 2, 2, "/add 5114 completed") // private by default
+
+	replies := env.command(t, 3, 3, "/leaderboard")
+	if !containsAny(replies, "🥇 @tester - 1 completed") {
+		t.Errorf("got %q, want the public user first", replies)
+	}
+	if containsAny(replies, "🥈") {
+		t.Errorf("got %q, want the private user left out", replies)
+	}
+}
+
+func TestRecentOffersAddButtons(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	if replies := env.command(t, 1, 1, "/recent"); !containsAny(replies, "Nothing has been added this week yet") {
+		t.Errorf("got %q, want nothing trending", replies)
+	}
+
+	env.command(t, 1, 1, "/add 5114 watching")
+	env.command(t, 2, 2, "/add 5114 completed")
+
+	env.command(t, 3, 3, "/recent")
+	sent := env.telegram.lastSent(t)
+	if !strings.Contains(sent.Text, "1. <b>Fullmetal Alchemist: Brotherhood</b> (ID: 5114) - 2 users") {
+		t.Errorf("got %q, want the anime with its 2 adds", sent.Text)
+	}
+	if sent.Keyboard == nil || len(sent.Keyboard.InlineKeyboard) != 1 {
+		t.Fatalf("got keyboard %+v, want one add button", sent.Keyboard)
+	}
+	checkKeyboardLimits(t, sent.Keyboard)
+}
+
+func TestGenreStats(t *testing.T) {
+	mecha := models.AnimeData{MalID: 1, Title: "Mecha One", Type: "TV", Genres: []models.Genre{{Name: "Mecha"}, {Name: "Sci-Fi & Fantasy"}}}
+	env := newTestEnvWithDB(t, testutil.DB(t), mecha)
+
+	if replies := env.command(t, 1, 1, "/stats genres"); !containsAny(replies, "No genre stats yet") {
+		t.Errorf("got %q, want no genre stats", replies)
+	}
+
+	rating := 8.0
+	if err := env.users.AddToUserList("1", 1, models.StatusCompleted, &rating, nil); err != nil {
+		t.Fatalf("failed to add anime: %v", err)
+	}
+
+	replies := env.command(t, 1, 1, "/stats genres")
+	if !containsAny(replies, "<b>Mecha</b> - 1 title, ⭐ 8.0 avg (1 rated)") {
+		t.Errorf("got %q, want Mecha with its average", replies)
+	}
+	if !containsAny(replies, "<b>Sci-Fi &amp; Fantasy</b>") {
+		t.Errorf("got %q, want genre names escaped", replies)
+	}
+}
+
+func TestProfileShowsStreak(t *testing.T) {
+	user := &models.AppUser{ID: "1", Platform: "telegram"}
+
+	tests := []struct {
+		streak int
+		want   string
+	}{
+		{0, ""},
+		{1, "🔥 Streak: 1 day in a row"},
+		{3, "🔥 Streak: 3 days in a row"},
+	}
+
+	for _, tt := range tests {
+		profile := formatProfile(user, nil, 0, 0, tt.streak)
+		if tt.want == "" {
+			if strings.Contains(profile, "Streak") {
+				t.Errorf("streak 0 got %q, want no streak line", profile)
+			}
+		} else if !strings.Contains(profile, tt.want) {
+			t.Errorf("streak %d got %q, want %q", tt.streak, profile, tt.want)
+		}
+	}
+}
+
+func TestProfileShowsEpisodesWatched(t *testing.T) {
+	user := &models.AppUser{ID: "1", Platform: "telegram"}
+
+	if profile := formatProfile(user, nil, 0, 0, 0); strings.Contains(profile, "Episodes watched") {
+		t.Errorf("got %q, want no episodes line without progress", profile)
+	}
+	if profile := formatProfile(user, nil, 342, 57, 0); !strings.Contains(profile, "📺 Episodes watched: 342 across 57 titles") {
+		t.Errorf("got %q, want the total", profile)
+	}
+	if profile := formatProfile(user, nil, 12, 1, 0); !strings.Contains(profile, "across 1 title\n") {
+		t.Errorf("got %q, want a singular title", profile)
+	}
+}
+
+func TestProgressValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/progress 5114", "Usage:</b> /progress"},
+		{"/progress abc 12", "Invalid anime ID"},
+		{"/progress 5114 -1", "Invalid episode count"},
+		{"/progress 5114 twelve", "Invalid episode count"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(env.handler.handleProgress, "1", tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%q got %q, want %q", tt.text, replies, tt.want)
+		}
+	}
+}
+
+func TestFormatSparkline(t *testing.T) {
+	counts := []int{0, 1, 0, 0, 4, 8, 0, 2, 0, 0, 0, 7}
+	months := make([]models.MonthlyCount, len(counts))
+	for i, count := range counts {
+		months[i] = models.MonthlyCount{Count: count}
+	}
+
+	// bars round up, so any completions show above the empty months' bar
+	if got, want := formatSparkline(months), "▁▂▁▁▅█▁▃▁▁▁█"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got, want := formatSparkline(make([]models.MonthlyCount, 12)), "▁▁▁▁▁▁▁▁▁▁▁▁"; got != want {
+		t.Errorf("got %q for no completions, want %q", got, want)
+	}
+}
+
+func TestProfileFailsWithoutUser(t *testing.T) {
+	env := newTestEnv(t)
+
+	if replies := env.run(env.handler.handleProfile, "1", "/profile"); !containsAny(replies, "couldn't retrieve your profile") {
+		t.Errorf("got %q, want the user fetch error surfaced", replies)
+	}
+}
+
+func TestProfileShownWhenStatsFail(t *testing.T) {
+	env := newTestEnv(t)
+
+	// the user comes from the cache, the stats need the database that's down
+	user, err := json.Marshal(models.AppUser{ID: "1", Platform: "telegram"})
+	if err != nil {
+		t.Fatalf("failed to marshal user: %v", err)
+	}
+	env.mredis.Set("user:info:1", string(user))
+
+	replies := env.run(env.handler.handleProfile, "1", "/profile")
+	if !containsAny(replies, "🆔 User ID: 1") || containsAny(replies, "couldn't retrieve") {
+		t.Errorf("got %q, want the profile without its stats", replies)
+	}
+}
+
+func TestProfileMatchesSequentialFetch(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	env.command(t, 1, 1, "/add 5114 watching")
+	env.command(t, 1, 1, "/progress 5114 12")
+	replies := env.command(t, 1, 1, "/profile")
+
+	user, err := env.users.GetUser("1")
+	if err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	counts, err := env.users.StatusCounts("1")
+	if err != nil {
+		t.Fatalf("StatusCounts failed: %v", err)
+	}
+	episodes, titles, err := env.users.TotalEpisodesWatched("1")
+	if err != nil {
+		t.Fatalf("TotalEpisodesWatched failed: %v", err)
+	}
+	streak, err := env.users.ActivityStreak("1")
+	if err != nil {
+		t.Fatalf("ActivityStreak failed: %v", err)
+	}
+
+	want := formatProfile(user, counts, episodes, titles, streak)
+	if len(replies) != 1 || replies[0] != want {
+		t.Errorf("got %q, want %q", replies, want)
+	}
+	if !strings.Contains(want, "Episodes watched: 12 across 1 title") {
+		t.Errorf("got %q, want the progress included", want)
+	}
+}
@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"strconv"
+	"strings"
+)
+
+func (h *Handler) handleTag(ctx context.Context, cmd BotCommand) {
+	animeID, tag, ok := h.parseTagArgs(ctx, cmd, "/tag")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.AddTag(cmd.UserID, animeID, tag); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTagExists):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("ℹ️ It's already tagged <b>#%s</b>.", esc(tag)))
+		case errors.Is(err, services.ErrTooManyTags):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ An anime can have at most %d tags.", models.MaxTagsPerEntry))
+		case strings.Contains(err.Error(), "not found"):
+			h.sendMessage(ctx, cmd.ChatID, "❌ Anime not found in your list. Use /add to add it first.")
+		default:
+			h.logger.WithError(err).Error("Failed to add tag")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't add the tag. Please try again later.")
+		}
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("🔖 Tagged <b>#%s</b>. See all with <code>/list #%s</code>", esc(tag), esc(tag)))
+}
+
+func (h *Handler) handleUntag(ctx context.Context, cmd BotCommand) {
+	animeID, tag, ok := h.parseTagArgs(ctx, cmd, "/untag")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RemoveTag(cmd.UserID, animeID, tag); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTagNotFound):
+			h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("ℹ️ It isn't tagged <b>#%s</b>.", esc(tag)))
+		case strings.Contains(err.Error(), "not found"):
+			h.sendMessage(ctx, cmd.ChatID, "❌ Anime not found in your list.")
+		default:
+			h.logger.WithError(err).Error("Failed to remove tag")
+			h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't remove the tag. Please try again later.")
+		}
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("✅ Removed tag <b>#%s</b>.", esc(tag)))
+}
+
+// parseTagArgs parses "<anime_id> <tag>", replying with usage or an error if they're invalid.
+func (h *Handler) parseTagArgs(ctx context.Context, cmd BotCommand, command string) (int, string, bool) {
+	if len(cmd.Args) != 2 {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("<b>Usage:</b> %s &lt;anime_id&gt; &lt;tag&gt;\n<b>Example:</b> <code>%s 16498 rewatch</code>", command, command))
+		return 0, "", false
+	}
+
+	animeID, err := strconv.Atoi(cmd.Args[0])
+	if err != nil || animeID <= 0 {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Invalid anime ID. Please provide a valid number.")
+		return 0, "", false
+	}
+
+	tag, ok := models.NormalizeTag(cmd.Args[1])
+	if !ok {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("❌ Tags can only contain letters, numbers, _ and - (max %d characters).", models.MaxTagLength))
+		return 0, "", false
+	}
+
+	return animeID, tag, true
+}
+
+func (h *Handler) handleListByTag(ctx context.Context, cmd BotCommand, limit int) {
+	page := 1
+	if len(cmd.Args) > 1 {
+		if p, err := strconv.Atoi(cmd.Args[1]); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	tag, ok := models.NormalizeTag(cmd.Args[0])
+	if !ok {
+		h.sendMessage(ctx, cmd.ChatID, "<b>Usage:</b> /list #&lt;tag&gt; [page]\n\n<b>Example:</b> /list #rewatch")
+		return
+	}
+
+	userList, total, err := h.userService.GetUserListByTag(cmd.UserID, tag, page, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get user list by tag")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't retrieve your list. Please try again later.")
+		return
+	}
+
+	if len(userList) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, fmt.Sprintf("No anime in your list tagged <b>#%s</b>.", esc(tag)))
+		return
+	}
+
+	filter := listFilter{Tag: tag}
+	message := h.formatUserList(userList, filter, page, total, limit)
+	keyboard := h.createPaginationKeyboard(ctx, page, limit, total, filter)
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, message, keyboard)
+}
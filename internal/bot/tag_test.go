@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"context"
+	"sletish/internal/testutil"
+	"testing"
+)
+
+func TestTagValidation(t *testing.T) {
+	env := newTestEnv(t)
+
+	tests := []struct {
+		handle func(context.Context, BotCommand)
+		text   string
+		want   string
+	}{
+		{env.handler.handleTag, "/tag 5114", "Usage:</b> /tag"},
+		{env.handler.handleUntag, "/untag", "Usage:</b> /untag"},
+		{env.handler.handleTag, "/tag abc rewatch", "Invalid anime ID"},
+		{env.handler.handleTag, "/tag 5114 <b>", "Tags can only contain"},
+		{env.handler.handleList, "/list #", "Usage:</b> /list #"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(tt.handle, "1", tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%q got %q, want %q", tt.text, replies, tt.want)
+		}
+	}
+}
+
+func TestTagAndFilterList(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+
+	if replies := env.command(t, 1, 1, "/tag 5114 rewatch"); !containsAny(replies, "not found in your list") {
+		t.Errorf("got %q, want the anime reported missing from the list", replies)
+	}
+
+	env.command(t, 1, 1, "/add 5114 completed")
+	if replies := env.command(t, 1, 1, "/tag 5114 #Rewatch"); !containsAny(replies, "Tagged <b>#rewatch</b>") {
+		t.Fatalf("got %q, want the anime tagged", replies)
+	}
+	if replies := env.command(t, 1, 1, "/tag 5114 rewatch"); !containsAny(replies, "already tagged") {
+		t.Errorf("got %q, want it already tagged", replies)
+	}
+
+	replies := env.command(t, 1, 1, "/list #rewatch")
+	if !containsAny(replies, "Your #rewatch Anime") || !containsAny(replies, fmab.Title) {
+		t.Errorf("got %q, want the tagged anime listed", replies)
+	}
+	if replies := env.command(t, 1, 1, "/list #cozy"); !containsAny(replies, "No anime in your list tagged <b>#cozy</b>") {
+		t.Errorf("got %q, want nothing tagged #cozy", replies)
+	}
+
+	if replies := env.command(t, 1, 1, "/untag 5114 rewatch"); !containsAny(replies, "Removed tag <b>#rewatch</b>") {
+		t.Errorf("got %q, want the tag removed", replies)
+	}
+	if replies := env.command(t, 1, 1, "/list #rewatch"); containsAny(replies, fmab.Title) {
+		t.Errorf("got %q, want the anime gone from #rewatch", replies)
+	}
+}
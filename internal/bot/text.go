@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"html"
+	"strings"
+)
+
+// esc escapes text for Telegram's HTML parse mode. Anything not written by the bot
+// itself (titles, synopses, genres, notes, user input) goes through it before being
+// put in a message, otherwise a stray "<" gets the whole message rejected.
+func esc(text string) string {
+	return html.EscapeString(text)
+}
+
+// Telegram rejects messages longer than 4096 characters.
+const maxMessageLength = 4096
+
+// splitMessage breaks text into chunks of at most limit characters (runes), preferring
+// to cut at paragraph breaks, then line breaks, then spaces, so words stay whole.
+func splitMessage(text string, limit int) []string {
+	var chunks []string
+	runes := []rune(text)
+
+	for len(runes) > limit {
+		cut := findCut(runes, limit)
+		chunk := strings.TrimSpace(string(runes[:cut]))
+		if chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " \n"))
+	}
+
+	if rest := strings.TrimSpace(string(runes)); rest != "" {
+		chunks = append(chunks, rest)
+	}
+
+	return chunks
+}
+
+// findCut picks where to split runes so the first part fits in limit.
+// Only looks back as far as half the limit to avoid tiny chunks; hard cuts otherwise.
+func findCut(runes []rune, limit int) int {
+	window := string(runes[limit/2 : limit])
+
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if i := strings.LastIndex(window, sep); i >= 0 {
+			return limit/2 + len([]rune(window[:i])) + len([]rune(sep))
+		}
+	}
+
+	return limit
+}
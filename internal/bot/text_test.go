@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"sletish/internal/models"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// longSynopsis is about 5000 characters of paragraphs and sentences.
+func longSynopsis() string {
+	var b strings.Builder
+	for i := 0; b.Len() < 5000; i++ {
+		b.WriteString("The brothers search for the Philosopher's Stone to restore their bodies. ")
+		if i%6 == 5 {
+			b.WriteString("\n\n")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+	}{
+		{"long synopsis", longSynopsis(), maxMessageLength},
+		{"small limit", longSynopsis(), 300},
+		{"no spaces", strings.Repeat("x", 5000), maxMessageLength},
+		{"multibyte", strings.Repeat("鋼の錬金術師 ", 1000), maxMessageLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitMessage(tt.text, tt.limit)
+			if len(chunks) < 2 {
+				t.Fatalf("got %d chunks, want the text split", len(chunks))
+			}
+
+			for i, chunk := range chunks {
+				if n := utf8.RuneCountInString(chunk); n > tt.limit {
+					t.Errorf("chunk %d has %d characters, limit %d", i, n, tt.limit)
+				}
+				if !utf8.ValidString(chunk) {
+					t.Errorf("chunk %d isn't valid UTF-8", i)
+				}
+				if chunk != strings.TrimSpace(chunk) || chunk == "" {
+					t.Errorf("chunk %d isn't trimmed: %q", i, chunk)
+				}
+			}
+
+			// nothing but the whitespace at the cuts is lost
+			if got, want := strings.Join(strings.Fields(strings.Join(chunks, "")), ""), strings.Join(strings.Fields(tt.text), ""); got != want {
+				t.Error("joined chunks don't match the text")
+			}
+		})
+	}
+}
+
+func TestSplitMessagePrefersParagraphs(t *testing.T) {
+	first := strings.Repeat("word ", 15) + "end."
+	text := first + "\n\n" + strings.Repeat("more ", 10)
+
+	chunks := splitMessage(text, 100)
+	if len(chunks) != 2 || chunks[0] != first {
+		t.Errorf("got %q, want the cut at the paragraph break", chunks)
+	}
+}
+
+func TestSplitMessageShortText(t *testing.T) {
+	if chunks := splitMessage("  short  ", 100); len(chunks) != 1 || chunks[0] != "short" {
+		t.Errorf("got %q, want one trimmed chunk", chunks)
+	}
+	if chunks := splitMessage("   ", 100); len(chunks) != 0 {
+		t.Errorf("got %q, want no chunks for blank text", chunks)
+	}
+}
+
+// telegramTags are the tags Telegram's HTML parse mode accepts.
+var telegramTags = map[string]bool{"b": true, "i": true, "u": true, "s": true, "code": true, "pre": true, "a": true, "blockquote": true, "tg-spoiler": true}
+
+// checkTelegramHTML fails the test if text isn't well formed HTML for Telegram: every
+// "<" and "&" part of a supported tag or an entity, and every tag closed.
+func checkTelegramHTML(t *testing.T, text string) {
+	t.Helper()
+
+	decoder := xml.NewDecoder(strings.NewReader("<message>" + text + "</message>"))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			t.Errorf("got invalid HTML (%v): %q", err, text)
+			return
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local != "message" && !telegramTags[start.Name.Local] {
+			t.Errorf("got unsupported tag <%s> in %q", start.Name.Local, text)
+		}
+	}
+}
+
+func TestFormattingEscapesAnimeData(t *testing.T) {
+	env := newTestEnv(t)
+
+	anime := models.AnimeData{
+		MalID:    31240,
+		Title:    "Re:Zero <Director's Cut>",
+		Type:     "TV",
+		Status:   "Finished <Airing>",
+		Synopsis: "Subaru learns that a < b && c > d. <i>Returns by Death</i>",
+		Genres:   []models.Genre{{Name: "Sci-Fi & Fantasy"}},
+	}
+
+	details := env.handler.formatAnimeDetails(anime, nil)
+	checkTelegramHTML(t, details)
+	if !strings.Contains(details, "Re:Zero &lt;Director&#39;s Cut&gt;") || !strings.Contains(details, "Sci-Fi &amp; Fantasy") {
+		t.Errorf("got %q, want the title and genre escaped", details)
+	}
+
+	for _, verbosity := range []models.SearchVerbosity{models.VerbosityCompact, models.VerbosityNormal, models.VerbosityDetailed} {
+		results := env.handler.formatSearchResults([]models.AnimeData{anime, anime}, verbosity)
+		checkTelegramHTML(t, results)
+		if strings.Contains(results, "<Director") {
+			t.Errorf("%s results got %q, want the title escaped", verbosity, results)
+		}
+	}
+
+	list := []models.UserMediaWithDetails{{
+		UserMedia: models.UserMedia{Status: models.StatusWatching, Notes: "<3 Rem & Ram", Tags: []string{"isekai"}},
+		Media:     models.Media{ExternalID: "31240", Title: anime.Title},
+	}}
+	for _, filter := range []listFilter{{}, {Status: "watching"}} {
+		message := env.handler.formatUserList(list, filter, 1, 1, 5)
+		checkTelegramHTML(t, message)
+		if strings.Contains(message, "<Director") || strings.Contains(message, "<3") {
+			t.Errorf("list %+v got %q, want the title and notes escaped", filter, message)
+		}
+	}
+}
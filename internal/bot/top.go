@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// topFilterTitles are the headings for each /top filter keyword.
+var topFilterTitles = map[string]string{
+	"":                         "🏆 Top Anime",
+	services.TopFilterAiring:   "📡 Top Airing Anime",
+	services.TopFilterUpcoming: "🗓 Top Upcoming Anime",
+}
+
+// handleTop shows Jikan's top anime: /top [airing|upcoming] [page]
+func (h *Handler) handleTop(ctx context.Context, cmd BotCommand) {
+	filter := ""
+	page := 1
+
+	args := cmd.Args
+	if len(args) > 0 {
+		if _, err := strconv.Atoi(args[0]); err != nil {
+			filter = strings.ToLower(args[0])
+			args = args[1:]
+		}
+	}
+	if _, ok := topFilterTitles[filter]; !ok {
+		h.sendMessage(ctx, cmd.ChatID, "❌ Unknown filter. <b>Usage:</b> /top [airing|upcoming] [page]")
+		return
+	}
+	if len(args) > 0 {
+		p, err := strconv.Atoi(args[0])
+		if err != nil || p < 1 {
+			h.sendMessage(ctx, cmd.ChatID, "❌ Invalid page number. <b>Usage:</b> /top [airing|upcoming] [page]")
+			return
+		}
+		page = p
+	}
+
+	sfw := !h.showsNSFW(cmd.UserID)
+	result, err := h.animeService.GetTopAnime(filter, page, sfw)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get top anime")
+		if errors.Is(err, services.ErrServiceUnavailable) {
+			h.sendMessage(ctx, cmd.ChatID, animeServiceDownMessage)
+			return
+		}
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, I couldn't get the top anime. Please try again later.")
+		return
+	}
+
+	if sfw {
+		result.Items = slices.DeleteFunc(result.Items, models.AnimeData.IsAdult)
+	}
+
+	if len(result.Items) == 0 {
+		h.sendMessage(ctx, cmd.ChatID, "📭 Nothing to show on this page.")
+		return
+	}
+
+	h.sendMessageWithKeyboard(ctx, cmd.ChatID, formatTopAnime(result, filter, page), h.createSearchResultsKeyboard(ctx, result.Items))
+}
+
+func formatTopAnime(result *models.SearchResult, filter string, page int) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("<b>%s</b> (page %d)\n\n", topFilterTitles[filter], page))
+
+	offset := (page - 1) * services.TopPageSize
+	for i, anime := range result.Items {
+		message.WriteString(fmt.Sprintf("%d. <b>%s</b> (ID: <code>%d</code>)", offset+i+1, esc(anime.Title), anime.MalID))
+		if anime.Score > 0 {
+			message.WriteString(fmt.Sprintf(" ⭐ %.1f", anime.Score))
+		}
+		message.WriteString("\n")
+	}
+
+	if result.HasNext {
+		next := strconv.Itoa(page + 1)
+		if filter != "" {
+			next = filter + " " + next
+		}
+		message.WriteString(fmt.Sprintf("\n<i>More with</i> <code>/top %s</code>", next))
+	}
+
+	return message.String()
+}
@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"sletish/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestTopRejectsInvalidArgs(t *testing.T) {
+	env := newTestEnv(t)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"/top bypopularity", "Unknown filter"},
+		{"/top airing soon", "Invalid page number"},
+		{"/top airing 0", "Invalid page number"},
+	}
+
+	for _, tt := range tests {
+		if replies := env.run(env.handler.handleTop, "1", tt.text); !containsAny(replies, tt.want) {
+			t.Errorf("%q got %q, want %q", tt.text, replies, tt.want)
+		}
+	}
+}
+
+func TestFormatTopAnime(t *testing.T) {
+	result := &models.SearchResult{
+		Items:   []models.AnimeData{{MalID: 5114, Title: "Fullmetal Alchemist: Brotherhood", Score: 9.1}, {MalID: 1, Title: "<Unscored>"}},
+		HasNext: true,
+	}
+
+	message := formatTopAnime(result, "airing", 2)
+	for _, want := range []string{
+		"<b>📡 Top Airing Anime</b> (page 2)",
+		"11. <b>Fullmetal Alchemist: Brotherhood</b> (ID: <code>5114</code>) ⭐ 9.1",
+		"12. <b>&lt;Unscored&gt;</b> (ID: <code>1</code>)\n",
+		"<code>/top airing 3</code>",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("got %q, want it to contain %q", message, want)
+		}
+	}
+
+	if message := formatTopAnime(&models.SearchResult{Items: result.Items}, "", 1); strings.Contains(message, "More with") {
+		t.Errorf("got %q, want no next page hint on the last page", message)
+	}
+}
@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"slices"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+const wizardMaxResults = 5
+
+// The add wizard: /add -> "What anime?" -> user types a title -> pick a result -> pick a status.
+// State lives in the pending action store, so an abandoned wizard expires on its own.
+
+func (h *Handler) startAddWizard(ctx context.Context, cmd BotCommand) {
+	action := models.PendingAction{
+		Type: models.PendingAddWizard,
+		Data: map[string]string{"step": models.WizardStepAwaitQuery},
+	}
+	if err := h.pendingStore.Set(cmd.pendingKey(), action); err != nil {
+		h.logger.WithError(err).Error("Failed to start add wizard")
+		h.sendMessage(ctx, cmd.ChatID, "❌ Sorry, something went wrong. Try /add &lt;anime_id&gt; &lt;status&gt; instead.")
+		return
+	}
+
+	h.sendMessage(ctx, cmd.ChatID, `🧙 <b>What anime do you want to add?</b>
+
+Just send me its name. Use /cancel to stop.
+
+💡 <i>Know the ID already? /add &lt;anime_id&gt; &lt;status&gt; is quicker.</i>`)
+}
+
+// handlePlainText routes a non-command message to the sender's active wizard step in this chat.
+func (h *Handler) handlePlainText(ctx context.Context, userID, chatID, text string) {
+	pending, err := h.pendingStore.Get(pendingKey(userID, chatID))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get pending action")
+	}
+
+	if pending == nil || pending.Type != models.PendingAddWizard {
+		h.sendMessage(ctx, chatID, "Unknown command. Use /help to see available commands")
+		return
+	}
+
+	switch pending.Data["step"] {
+	case models.WizardStepAwaitQuery, models.WizardStepAwaitPick:
+		// typing again while results are shown just searches again
+		h.wizardSearch(ctx, userID, chatID, text)
+	default:
+		h.abandonWizard(pendingKey(userID, chatID))
+		h.sendMessage(ctx, chatID, "Unknown command. Use /help to see available commands")
+	}
+}
+
+// wizardSearch searches for the typed title and shows the results as buttons.
+func (h *Handler) wizardSearch(ctx context.Context, userID, chatID, query string) {
+	if len(query) > 100 {
+		h.sendMessage(ctx, chatID, "Search query is too long. Please keep it under 100 characters.")
+		return
+	}
+
+	filters := models.SearchFilters{SFW: !h.showsNSFW(userID)}
+	searchResult, err := h.animeService.SearchAnime(query, filters)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"query":   query,
+			"user_id": userID,
+			"error":   err.Error(),
+		}).Error("Failed to search anime in add wizard")
+		if errors.Is(err, services.ErrServiceUnavailable) {
+			h.sendMessage(ctx, chatID, animeServiceDownMessage)
+			return
+		}
+		h.sendMessage(ctx, chatID, "❌ Error occurred while searching. Please try again, or /cancel.")
+		return
+	}
+
+	results := searchResult.Items
+	if filters.SFW {
+		results = slices.DeleteFunc(results, models.AnimeData.IsAdult)
+	}
+
+	if len(results) == 0 {
+		h.sendMessage(ctx, chatID, "❌ No anime found with that name. Try another name, or /cancel.")
+		return
+	}
+
+	action := models.PendingAction{
+		Type: models.PendingAddWizard,
+		Data: map[string]string{"step": models.WizardStepAwaitPick},
+	}
+	if err := h.pendingStore.Set(pendingKey(userID, chatID), action); err != nil {
+		h.logger.WithError(err).Error("Failed to advance add wizard")
+	}
+
+	var rows [][]models.InlineKeyboardButton
+	for i, anime := range results {
+		if i >= wizardMaxResults {
+			break
+		}
+
+		label := services.TruncateText(anime.Title, 40)
+		if anime.Year > 0 {
+			label = fmt.Sprintf("%s (%d)", label, anime.Year)
+		}
+
+		rows = append(rows, []models.InlineKeyboardButton{
+			{Text: label, CallbackData: h.createCallbackData(ctx, "wizard_pick", strconv.Itoa(anime.MalID), "")},
+		})
+	}
+	rows = append(rows, []models.InlineKeyboardButton{
+		{Text: "✖️ Cancel", CallbackData: h.createCallbackData(ctx, "cancel_pending", "", "")},
+	})
+
+	h.sendMessageWithKeyboard(ctx, chatID, "🧙 <b>Which one?</b>\n\nNot there? Send another name.", &models.InlineKeyboardMarkup{InlineKeyboard: rows})
+}
+
+// handleCallbackWizardPick ends the wizard on the picked anime and offers the status buttons.
+func (h *Handler) handleCallbackWizardPick(ctx context.Context, callback *models.CallbackQuery, data *models.CallbackData, userID, chatID string) {
+	key := callbackPendingKey(callback, chatID)
+	pending, err := h.pendingStore.Get(key)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get pending action")
+	}
+
+	if pending == nil || pending.Type != models.PendingAddWizard || pending.Data["step"] != models.WizardStepAwaitPick {
+		h.answerCallback(ctx, callback.Id, "⌛ This menu has expired. Use /add to start again.", true)
+		return
+	}
+
+	animeID, err := strconv.Atoi(data.AnimeID)
+	if err != nil {
+		h.answerCallback(ctx, callback.Id, "❌ Invalid anime ID", false)
+		return
+	}
+
+	anime, err := h.animeService.GetAnimeByID(animeID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get anime in add wizard")
+		h.answerCallback(ctx, callback.Id, "❌ Failed to get anime details", true)
+		return
+	}
+
+	h.abandonWizard(key)
+	h.answerCallback(ctx, callback.Id, "", false)
+
+	id := strconv.Itoa(anime.MalID)
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: "📝 Watchlist", CallbackData: h.createCallbackData(ctx, "add_anime", id, "watchlist")},
+				{Text: "👀 Watching", CallbackData: h.createCallbackData(ctx, "add_anime", id, "watching")},
+			},
+			{
+				{Text: "✅ Completed", CallbackData: h.createCallbackData(ctx, "add_anime", id, "completed")},
+				{Text: "⏸ On Hold", CallbackData: h.createCallbackData(ctx, "add_anime", id, "on_hold")},
+			},
+		},
+	}
+
+	text := fmt.Sprintf("🧙 <b>%s</b>\n\nWhich list should it go on?", esc(anime.Title))
+	h.editMessage(ctx, chatID, callback.Message.MessageId, text, keyboard)
+}
+
+// abandonWizard clears the add wizard kept under key, if there is one. Other pending actions are left alone.
+func (h *Handler) abandonWizard(key string) {
+	pending, err := h.pendingStore.Get(key)
+	if err != nil || pending == nil || pending.Type != models.PendingAddWizard {
+		return
+	}
+
+	if _, err := h.pendingStore.Clear(key); err != nil {
+		h.logger.WithError(err).Warn("Failed to clear add wizard")
+	}
+}
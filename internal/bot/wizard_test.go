@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"context"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"testing"
+	"time"
+)
+
+// wizardStep returns the step of userID's add wizard, or "" if they aren't in one.
+func wizardStep(t *testing.T, env *testEnv, userID string) string {
+	t.Helper()
+
+	pending, err := env.handler.pendingStore.Get(userID)
+	if err != nil {
+		t.Fatalf("failed to get pending action: %v", err)
+	}
+	if pending == nil || pending.Type != models.PendingAddWizard {
+		return ""
+	}
+	return pending.Data["step"]
+}
+
+func TestAddWizard(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.anime.results = []models.AnimeData{fmab}
+
+	if replies := env.run(env.handler.handleAdd, "1", "/add"); !containsAny(replies, "What anime do you want to add?") {
+		t.Fatalf("got %q, want the wizard's first question", replies)
+	}
+	if step := wizardStep(t, env, "1"); step != models.WizardStepAwaitQuery {
+		t.Fatalf("got step %q after /add, want %q", step, models.WizardStepAwaitQuery)
+	}
+
+	env.handler.handlePlainText(context.Background(), "1", "1", "fullmetal")
+	if len(env.anime.queries) != 1 || env.anime.queries[0] != "fullmetal" {
+		t.Fatalf("got searches %q, want the typed title searched", env.anime.queries)
+	}
+	if step := wizardStep(t, env, "1"); step != models.WizardStepAwaitPick {
+		t.Fatalf("got step %q after the search, want %q", step, models.WizardStepAwaitPick)
+	}
+
+	results := env.telegram.lastSent(t)
+	if results.Keyboard == nil || len(results.Keyboard.InlineKeyboard) != 2 {
+		t.Fatalf("got keyboard %+v, want one result and a cancel button", results.Keyboard)
+	}
+	checkKeyboardLimits(t, results.Keyboard)
+
+	pick := results.Keyboard.InlineKeyboard[0][0]
+	env.press(1, 1, "cb-1", pick.CallbackData)
+
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "" {
+		t.Errorf("got answers %+v, want one silent answer", answers)
+	}
+	if step := wizardStep(t, env, "1"); step != "" {
+		t.Errorf("got step %q after the pick, want the wizard finished", step)
+	}
+
+	env.telegram.mu.Lock()
+	edit := env.telegram.edits[len(env.telegram.edits)-1]
+	env.telegram.mu.Unlock()
+	if edit.Text != "🧙 <b>Fullmetal Alchemist: Brotherhood</b>\n\nWhich list should it go on?" {
+		t.Errorf("got %q, want the status question", edit.Text)
+	}
+	if edit.Keyboard == nil || len(edit.Keyboard.InlineKeyboard) != 2 {
+		t.Errorf("got keyboard %+v, want the status buttons", edit.Keyboard)
+	}
+}
+
+func TestAddWizardNoResultsKeepsAsking(t *testing.T) {
+	env := newTestEnv(t)
+	env.run(env.handler.handleAdd, "1", "/add")
+
+	env.handler.handlePlainText(context.Background(), "1", "1", "nothing like this")
+
+	if sent := env.telegram.lastSent(t); sent.Text != "❌ No anime found with that name. Try another name, or /cancel." {
+		t.Errorf("got %q, want no results", sent.Text)
+	}
+	if step := wizardStep(t, env, "1"); step != models.WizardStepAwaitQuery {
+		t.Errorf("got step %q, want still waiting for a title", step)
+	}
+}
+
+func TestAddWizardExpires(t *testing.T) {
+	env := newTestEnv(t, fmab)
+	env.anime.results = []models.AnimeData{fmab}
+
+	env.run(env.handler.handleAdd, "1", "/add")
+	env.handler.handlePlainText(context.Background(), "1", "1", "fullmetal")
+	pick := env.telegram.lastSent(t).Keyboard.InlineKeyboard[0][0]
+
+	// abandoned for longer than a pending action lives
+	env.mredis.FastForward(time.Hour)
+
+	env.handler.handlePlainText(context.Background(), "1", "1", "fullmetal")
+	if sent := env.telegram.lastSent(t); sent.Text != "Unknown command. Use /help to see available commands" {
+		t.Errorf("got %q, want plain text ignored once the wizard expired", sent.Text)
+	}
+	if len(env.anime.queries) != 1 {
+		t.Errorf("got %d searches, want none after expiry", len(env.anime.queries)-1)
+	}
+
+	env.press(1, 1, "cb-1", pick.CallbackData)
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "⌛ This menu has expired. Use /add to start again." {
+		t.Errorf("got answers %+v, want the menu expired", answers)
+	}
+}
+
+func TestAddWizardPickNeedsResults(t *testing.T) {
+	env := newTestEnv(t, fmab)
+
+	// still waiting for a title, a pick button from an old wizard is stale
+	env.run(env.handler.handleAdd, "1", "/add")
+	env.press(1, 1, "cb-1", env.callbackData(t, models.CallbackData{Action: "wizard_pick", AnimeID: "5114"}))
+
+	if answers := env.telegram.answersFor("cb-1"); len(answers) != 1 || answers[0].Text != "⌛ This menu has expired. Use /add to start again." {
+		t.Errorf("got answers %+v, want the menu expired", answers)
+	}
+	if step := wizardStep(t, env, "1"); step != models.WizardStepAwaitQuery {
+		t.Errorf("got step %q, want the wizard left as it was", step)
+	}
+}
+
+func TestAddWizardRoutingAndAbandon(t *testing.T) {
+	env := newTestEnvWithDB(t, testutil.DB(t), fmab)
+	env.anime.results = []models.AnimeData{fmab}
+
+	if replies := env.command(t, 1, 1, "hello"); !containsAny(replies, "Unknown command") {
+		t.Errorf("got %q, want plain text outside the wizard rejected", replies)
+	}
+
+	env.command(t, 1, 1, "/add")
+	if replies := env.command(t, 1, 1, "fullmetal"); !containsAny(replies, "Which one?") {
+		t.Errorf("got %q, want the plain text routed to the wizard", replies)
+	}
+
+	// any other command leaves the wizard
+	env.command(t, 1, 1, "/help")
+	if step := wizardStep(t, env, "1"); step != "" {
+		t.Errorf("got step %q after another command, want the wizard abandoned", step)
+	}
+}
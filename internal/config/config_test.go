@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetEnvInt(t *testing.T) {
+	t.Setenv("TEST_INT", "")
+	if got, err := GetEnvInt("TEST_INT", 25); err != nil || got != 25 {
+		t.Errorf("unset got %d, %v, want the default 25", got, err)
+	}
+
+	t.Setenv("TEST_INT", "40")
+	if got, err := GetEnvInt("TEST_INT", 25); err != nil || got != 40 {
+		t.Errorf("got %d, %v, want 40", got, err)
+	}
+
+	t.Setenv("TEST_INT", "forty")
+	if _, err := GetEnvInt("TEST_INT", 25); err == nil {
+		t.Error("got no error for a non-number")
+	}
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	t.Setenv("TEST_DURATION", "")
+	if got, err := GetEnvDuration("TEST_DURATION", time.Hour); err != nil || got != time.Hour {
+		t.Errorf("unset got %v, %v, want the default 1h", got, err)
+	}
+
+	t.Setenv("TEST_DURATION", "90s")
+	if got, err := GetEnvDuration("TEST_DURATION", time.Hour); err != nil || got != 90*time.Second {
+		t.Errorf("got %v, %v, want 1m30s", got, err)
+	}
+
+	t.Setenv("TEST_DURATION", "90")
+	if _, err := GetEnvDuration("TEST_DURATION", time.Hour); err == nil {
+		t.Error("got no error for a duration without a unit")
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sletish/internal/config"
 	"sletish/internal/logger"
 	"sletish/internal/services"
+	"sletish/migrations"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -20,18 +23,67 @@ type Container struct {
 	AnimeService    *services.Client
 	UserService     *services.UserService
 	ReminderService *services.ReminderService
+	AiringService   *services.AiringService
+	AotdService     *services.AnimeOfTheDayService
+	MediaService    *services.MediaService
+	CallbackStore   *services.CallbackStore
+	PendingStore    *services.PendingActionStore
+	FeedbackService *services.FeedbackService
+	AdminUserIDs    []string
+	AdminChatID     string
+	WarmQueries     []string // popular searches for /syncsearchcache
+	ListGroupLimit  int      // items per status in the grouped /list view
+	BotName         string   // shown in /start, /help and /about, empty for the default
+	BotDescription  string
 }
 
 func New(ctx context.Context) (*Container, error) {
 	// Initialize logger first
 	logger := logger.Get()
 
+	// read settings up front so a typo fails before any connection is opened
+	maxListSize, err := config.GetEnvInt("MAX_LIST_SIZE", 0)
+	if err != nil {
+		return nil, err
+	}
+	listGroupLimit, err := config.GetEnvInt("LIST_GROUP_LIMIT", 10)
+	if err != nil {
+		return nil, err
+	}
+	// how often airing anime are polled for changes, separate from reminders to spare Jikan
+	episodeCheckInterval, err := config.GetEnvDuration("EPISODE_CHECK_INTERVAL", services.DefaultEpisodeCheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	if episodeCheckInterval < time.Minute {
+		return nil, fmt.Errorf("invalid EPISODE_CHECK_INTERVAL %q: must be at least 1m", episodeCheckInterval)
+	}
+	// how often due reminders are sent; lower means less delay past the reminder time
+	reminderCheckInterval, err := config.GetEnvDuration("REMINDER_CHECK_INTERVAL", services.DefaultReminderCheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	if reminderCheckInterval < 10*time.Second {
+		return nil, fmt.Errorf("invalid REMINDER_CHECK_INTERVAL %q: must be at least 10s", reminderCheckInterval)
+	}
+	// archive removed entries so /undo can bring them back, purged after 30 days
+	softDelete, err := config.GetEnvBool("SOFT_DELETE", false)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize database
 	db, err := newDatabase(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
+	// Bring the schema up to date before anything queries it
+	if err := migrations.Run(ctx, db, logger); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run database migrations: %w", err)
+	}
+
 	// Initialize Redis
 	redisClient, err := newRedis(ctx)
 	if err != nil {
@@ -51,17 +103,80 @@ func New(ctx context.Context) (*Container, error) {
 		Redis:      redisClient,
 	}
 
+	userService := services.NewUserService(db, redisClient, logger, services.NewClient())
+	userService.SetMaxListSize(maxListSize)
+	userService.SetSoftDelete(softDelete)
+
+	// a 403 from Telegram on a private chat means the user blocked the bot
+	services.SetBlockedHandler(func(chatId int) {
+		if chatId <= 0 {
+			return
+		}
+		if err := userService.SetBlocked(strconv.Itoa(chatId), true); err != nil {
+			logger.WithError(err).Warn("Failed to mark user as blocked")
+		}
+	})
+
 	return &Container{
 		DB:              db,
 		Redis:           redisClient,
 		Logger:          logger,
 		AnimeService:    services.NewClientWithConfig(animeConfig),
-		UserService:     services.NewUserService(db, redisClient, logger, services.NewClient()),
-		ReminderService: services.NewReminderService(db, logger, redisClient, "", services.NewClientWithConfig(animeConfig)),
+		UserService:     userService,
+		ReminderService: services.NewReminderService(db, logger, redisClient, "", services.NewClientWithConfig(animeConfig), reminderCheckInterval),
+		AiringService:   services.NewAiringService(db, logger, "", services.NewClientWithConfig(animeConfig), episodeCheckInterval),
+		AotdService:     services.NewAnimeOfTheDayService(db, logger, "", services.NewClientWithConfig(animeConfig)),
+		MediaService:    services.NewMediaService(db, redisClient, logger, services.NewClientWithConfig(animeConfig)),
+		CallbackStore:   services.NewCallbackStore(redisClient, logger),
+		PendingStore:    services.NewPendingActionStore(redisClient, logger),
+		FeedbackService: services.NewFeedbackService(db, redisClient, logger),
+		AdminUserIDs:    config.GetEnvList("ADMIN_USER_IDS"),
+		AdminChatID:     os.Getenv("ADMIN_CHAT_ID"),
+		WarmQueries:     config.GetEnvList("WARM_SEARCH_QUERIES"),
+		ListGroupLimit:  listGroupLimit,
+		BotName:         os.Getenv("BOT_NAME"),
+		BotDescription:  os.Getenv("BOT_DESCRIPTION"),
 	}, nil
 }
 
+// StartWorkers starts the background workers; they stop when ctx is cancelled.
+// Each service runs at most one worker, however often this is called.
+func (c *Container) StartWorkers(ctx context.Context) {
+	c.ReminderService.Start(ctx)
+	c.AiringService.Start(ctx)
+	c.AotdService.Start(ctx)
+	c.MediaService.Start(ctx)
+	if c.UserService.SoftDelete() {
+		c.UserService.StartPurgeWorker(ctx)
+	}
+}
+
+// WaitForWorkers blocks until the workers started by StartWorkers have stopped, after
+// their context is cancelled, or until ctx is done. Call it before Close so no worker
+// is left running against closed connections.
+func (c *Container) WaitForWorkers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.ReminderService.Wait()
+		c.AiringService.Wait()
+		c.AotdService.Wait()
+		c.MediaService.Wait()
+		c.UserService.WaitPurgeWorker()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (c *Container) Close() {
+	if c.ReminderService != nil {
+		c.ReminderService.ReleaseLeaderLock()
+	}
 	if c.Redis != nil {
 		c.Redis.Close()
 		c.Logger.Info("Redis connection closed")
@@ -81,11 +196,9 @@ func newDatabase(ctx context.Context) (*pgxpool.Pool, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse DATABASE_URL: %w", err)
 	}
-	config.MaxConns = 25
-	config.MinConns = 5
-	config.MaxConnLifetime = time.Hour
-	config.MaxConnIdleTime = time.Minute * 30
-	config.HealthCheckPeriod = time.Minute
+	if err := applyPoolEnv(config); err != nil {
+		return nil, err
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -99,6 +212,47 @@ func newDatabase(ctx context.Context) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
+// applyPoolEnv sets the pool limits from DB_* env vars, falling back to the defaults below.
+func applyPoolEnv(poolConfig *pgxpool.Config) error {
+	maxConns, err := config.GetEnvInt("DB_MAX_CONNS", 25)
+	if err != nil {
+		return err
+	}
+	minConns, err := config.GetEnvInt("DB_MIN_CONNS", 5)
+	if err != nil {
+		return err
+	}
+	if maxConns < 1 || minConns < 0 || minConns > maxConns {
+		return fmt.Errorf("invalid DB_MIN_CONNS/DB_MAX_CONNS: need 0 <= min (%d) <= max (%d) and max >= 1", minConns, maxConns)
+	}
+
+	maxConnLifetime, err := config.GetEnvDuration("DB_MAX_CONN_LIFETIME", time.Hour)
+	if err != nil {
+		return err
+	}
+	maxConnIdleTime, err := config.GetEnvDuration("DB_MAX_CONN_IDLE_TIME", 30*time.Minute)
+	if err != nil {
+		return err
+	}
+	healthCheckPeriod, err := config.GetEnvDuration("DB_HEALTH_CHECK_PERIOD", time.Minute)
+	if err != nil {
+		return err
+	}
+
+	poolConfig.MaxConns = int32(maxConns)
+	poolConfig.MinConns = int32(minConns)
+	poolConfig.MaxConnLifetime = maxConnLifetime
+	poolConfig.MaxConnIdleTime = maxConnIdleTime
+	poolConfig.HealthCheckPeriod = healthCheckPeriod
+
+	logger.Get().WithFields(logrus.Fields{
+		"max_conns": maxConns,
+		"min_conns": minConns,
+	}).Info("Database pool configured")
+
+	return nil
+}
+
 func newRedis(ctx context.Context) (*redis.Client, error) {
 	redisURL := os.Getenv("REDIS_URL")
 	if redisURL == "" {
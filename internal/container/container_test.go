@@ -0,0 +1,102 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sletish/internal/services"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+func TestApplyPoolEnv(t *testing.T) {
+	poolConfig, err := pgxpool.ParseConfig("postgres://test@localhost/test")
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	t.Setenv("DB_MAX_CONNS", "")
+	t.Setenv("DB_MIN_CONNS", "")
+	t.Setenv("DB_MAX_CONN_LIFETIME", "")
+	t.Setenv("DB_MAX_CONN_IDLE_TIME", "")
+	t.Setenv("DB_HEALTH_CHECK_PERIOD", "")
+	if err := applyPoolEnv(poolConfig); err != nil {
+		t.Fatalf("applyPoolEnv with defaults failed: %v", err)
+	}
+	if poolConfig.MaxConns != 25 || poolConfig.MinConns != 5 || poolConfig.MaxConnLifetime != time.Hour {
+		t.Errorf("got max %d, min %d and lifetime %v, want the defaults", poolConfig.MaxConns, poolConfig.MinConns, poolConfig.MaxConnLifetime)
+	}
+
+	t.Setenv("DB_MAX_CONNS", "50")
+	t.Setenv("DB_MIN_CONNS", "0")
+	t.Setenv("DB_MAX_CONN_LIFETIME", "2h")
+	t.Setenv("DB_MAX_CONN_IDLE_TIME", "5m")
+	t.Setenv("DB_HEALTH_CHECK_PERIOD", "10s")
+	if err := applyPoolEnv(poolConfig); err != nil {
+		t.Fatalf("applyPoolEnv failed: %v", err)
+	}
+	if poolConfig.MaxConns != 50 || poolConfig.MinConns != 0 {
+		t.Errorf("got max %d and min %d, want 50 and 0", poolConfig.MaxConns, poolConfig.MinConns)
+	}
+	if poolConfig.MaxConnLifetime != 2*time.Hour || poolConfig.MaxConnIdleTime != 5*time.Minute || poolConfig.HealthCheckPeriod != 10*time.Second {
+		t.Errorf("got lifetime %v, idle %v and health check %v, want 2h, 5m and 10s",
+			poolConfig.MaxConnLifetime, poolConfig.MaxConnIdleTime, poolConfig.HealthCheckPeriod)
+	}
+}
+
+func TestApplyPoolEnvRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		key, value string
+	}{
+		{"DB_MAX_CONNS", "lots"},
+		{"DB_MAX_CONNS", "0"},
+		{"DB_MIN_CONNS", "-1"},
+		{"DB_MIN_CONNS", "30"}, // above the default max
+		{"DB_MAX_CONN_LIFETIME", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			poolConfig, _ := pgxpool.ParseConfig("postgres://test@localhost/test")
+			if err := applyPoolEnv(poolConfig); err == nil {
+				t.Errorf("got no error for %s=%q", tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func TestWaitForWorkers(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	users := services.NewUserService(nil, nil, logger, nil)
+	users.SetSoftDelete(true)
+	c := &Container{
+		Logger:          logger,
+		UserService:     users,
+		ReminderService: services.NewReminderService(nil, logger, nil, "", nil, time.Hour),
+		AiringService:   services.NewAiringService(nil, logger, "", nil, time.Hour),
+		AotdService:     services.NewAnimeOfTheDayService(nil, logger, "", nil),
+		MediaService:    services.NewMediaService(nil, nil, logger, nil),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.StartWorkers(ctx)
+
+	// still running, so waiting gives up when its own context does
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	if err := c.WaitForWorkers(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v waiting on running workers, want the deadline exceeded", err)
+	}
+
+	cancel()
+	waitCtx, waitCancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+	if err := c.WaitForWorkers(waitCtx); err != nil {
+		t.Errorf("got %v, want the workers stopped once their context was cancelled", err)
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sletish/internal/container"
+	"sletish/internal/models"
+	"strconv"
+)
+
+const (
+	apiKeyHeader    = "X-API-Key"
+	apiDefaultLimit = 20
+	apiMaxLimit     = 100
+)
+
+// APIHandler serves the read-only JSON API for the web dashboard under /api/.
+// Every request must carry the configured key in the X-API-Key header.
+func APIHandler(container *container.Container, apiKey string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/users/{id}/list", func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("id")
+
+		status := r.URL.Query().Get("status")
+		if status != "" && !models.Status(status).IsValid() {
+			writeJSONError(w, http.StatusBadRequest, "invalid status")
+			return
+		}
+
+		page, ok := queryInt(r, "page", 1)
+		if !ok || page < 1 {
+			writeJSONError(w, http.StatusBadRequest, "invalid page")
+			return
+		}
+
+		limit, ok := queryInt(r, "limit", apiDefaultLimit)
+		if !ok || limit < 1 || limit > apiMaxLimit {
+			writeJSONError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+
+		if _, err := container.UserService.GetUser(userID); err != nil {
+			writeJSONError(w, http.StatusNotFound, "user not found")
+			return
+		}
+
+		list, total, err := container.UserService.GetUserList(userID, status, page, limit)
+		if err != nil {
+			container.Logger.WithError(err).Error("API: failed to get user list")
+			writeJSONError(w, http.StatusInternalServerError, "failed to get list")
+			return
+		}
+
+		if list == nil {
+			list = []models.UserMediaWithDetails{}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"items": list,
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		})
+	})
+
+	mux.HandleFunc("GET /api/users/{id}/reminders", func(w http.ResponseWriter, r *http.Request) {
+		userID := r.PathValue("id")
+		includeSent := r.URL.Query().Get("all") == "true"
+
+		if _, err := container.UserService.GetUser(userID); err != nil {
+			writeJSONError(w, http.StatusNotFound, "user not found")
+			return
+		}
+
+		reminders, err := container.ReminderService.GetUserReminders(userID, includeSent)
+		if err != nil {
+			container.Logger.WithError(err).Error("API: failed to get reminders")
+			writeJSONError(w, http.StatusInternalServerError, "failed to get reminders")
+			return
+		}
+
+		if reminders == nil {
+			reminders = []models.Reminder{}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"items": reminders,
+		})
+	})
+
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, http.StatusNotFound, "not found")
+	})
+
+	return requireAPIKey(apiKey, mux)
+}
+
+// requireAPIKey rejects requests without the right API key. An empty key disables the API.
+func requireAPIKey(apiKey string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey == "" {
+			writeJSONError(w, http.StatusServiceUnavailable, "api disabled")
+			return
+		}
+
+		provided := r.Header.Get(apiKeyHeader)
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
+			writeJSONError(w, http.StatusUnauthorized, "invalid or missing api key")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func queryInt(r *http.Request, key string, defaultValue int) (int, bool) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return defaultValue, true
+	}
+	value, err := strconv.Atoi(raw)
+	return value, err == nil
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func writeJSONError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]string{"error": message})
+}
+
+// writeJSONErrorCode writes an error with a stable machine-readable code alongside the message.
+func writeJSONErrorCode(w http.ResponseWriter, statusCode int, code, message string) {
+	writeJSON(w, statusCode, map[string]string{"error": message, "code": code})
+}
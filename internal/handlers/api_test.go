@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sletish/internal/container"
+	"sletish/internal/models"
+	"sletish/internal/services"
+	"sletish/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+const testAPIKey = "secret"
+
+// newTestContainer returns a container on db whose Jikan lookups get a made up anime.
+func newTestContainer(t *testing.T, db *pgxpool.Pool) *container.Container {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	jikan := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"data": models.AnimeData{MalID: 5114, Title: "Fullmetal Alchemist: Brotherhood", Type: "TV"}})
+	}))
+	t.Cleanup(jikan.Close)
+	client := services.NewClientWithConfig(&services.ClientConfig{
+		BaseURL:    jikan.URL,
+		Timeout:    5 * time.Second,
+		RateLimit:  time.Millisecond,
+		RetryDelay: time.Millisecond,
+		Logger:     logger,
+	})
+
+	return &container.Container{
+		DB:              db,
+		Logger:          logger,
+		UserService:     services.NewUserService(db, nil, logger, client),
+		ReminderService: services.NewReminderService(db, logger, nil, "", client, time.Hour),
+		AiringService:   services.NewAiringService(db, logger, "", client, time.Hour),
+		AotdService:     services.NewAnimeOfTheDayService(db, logger, "", client),
+	}
+}
+
+// apiGet requests path from the API with the given key, and decodes the JSON reply into body.
+func apiGet(t *testing.T, handler http.Handler, path, key string, body any) int {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if key != "" {
+		req.Header.Set(apiKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+	if err := json.NewDecoder(rec.Body).Decode(body); err != nil {
+		t.Fatalf("failed to decode reply: %v", err)
+	}
+	return rec.Code
+}
+
+func TestAPIRejectsMissingOrWrongKey(t *testing.T) {
+	handler := APIHandler(newTestContainer(t, nil), testAPIKey)
+
+	for _, key := range []string{"", "wrong", testAPIKey + "x"} {
+		var body map[string]string
+		if code := apiGet(t, handler, "/api/users/1/list", key, &body); code != http.StatusUnauthorized {
+			t.Errorf("key %q: got status %d, want 401", key, code)
+		}
+		if body["error"] == "" {
+			t.Errorf("key %q: got %v, want a JSON error", key, body)
+		}
+	}
+}
+
+func TestAPIDisabledWithoutKey(t *testing.T) {
+	handler := APIHandler(newTestContainer(t, nil), "")
+
+	var body map[string]string
+	if code := apiGet(t, handler, "/api/users/1/list", "", &body); code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want 503 with no key configured", code)
+	}
+}
+
+func TestAPIValidatesListParams(t *testing.T) {
+	handler := APIHandler(newTestContainer(t, nil), testAPIKey)
+
+	tests := []struct {
+		query, want string
+	}{
+		{"status=binging", "invalid status"},
+		{"page=0", "invalid page"},
+		{"page=x", "invalid page"},
+		{"limit=0", "invalid limit"},
+		{"limit=101", "invalid limit"},
+	}
+
+	for _, tt := range tests {
+		var body map[string]string
+		code := apiGet(t, handler, "/api/users/1/list?"+tt.query, testAPIKey, &body)
+		if code != http.StatusBadRequest || body["error"] != tt.want {
+			t.Errorf("%s: got %d %v, want 400 %q", tt.query, code, body, tt.want)
+		}
+	}
+}
+
+func TestAPIUnknownPath(t *testing.T) {
+	handler := APIHandler(newTestContainer(t, nil), testAPIKey)
+
+	var body map[string]string
+	if code := apiGet(t, handler, "/api/nothing", testAPIKey, &body); code != http.StatusNotFound || body["error"] != "not found" {
+		t.Errorf("got %d %v, want 404 not found", code, body)
+	}
+}
+
+func TestAPIList(t *testing.T) {
+	c := newTestContainer(t, testutil.DB(t))
+	handler := APIHandler(c, testAPIKey)
+
+	var missing map[string]string
+	if code := apiGet(t, handler, "/api/users/1/list", testAPIKey, &missing); code != http.StatusNotFound {
+		t.Errorf("got status %d for an unknown user, want 404", code)
+	}
+
+	if err := c.UserService.EnsureUserExists("1", "tester", ""); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := c.UserService.AddToUserList("1", 5114, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add anime: %v", err)
+	}
+
+	var body struct {
+		Items []models.UserMediaWithDetails `json:"items"`
+		Page  int                           `json:"page"`
+		Limit int                           `json:"limit"`
+		Total int                           `json:"total"`
+	}
+	if code := apiGet(t, handler, "/api/users/1/list?status=watching&limit=5", testAPIKey, &body); code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", code)
+	}
+	if body.Total != 1 || body.Page != 1 || body.Limit != 5 || len(body.Items) != 1 {
+		t.Fatalf("got %+v, want the one entry on page 1 of 5", body)
+	}
+	if item := body.Items[0]; item.Media.ExternalID != "5114" || item.UserMedia.Status != models.StatusWatching {
+		t.Errorf("got %+v, want anime 5114 as watching", item)
+	}
+
+	// an empty page is an empty array, not null
+	var raw map[string]json.RawMessage
+	apiGet(t, handler, "/api/users/1/list?status=completed", testAPIKey, &raw)
+	if string(raw["items"]) != "[]" {
+		t.Errorf("got items %s, want []", raw["items"])
+	}
+}
+
+func TestAPIReminders(t *testing.T) {
+	c := newTestContainer(t, testutil.DB(t))
+	handler := APIHandler(c, testAPIKey)
+
+	if err := c.UserService.EnsureUserExists("1", "tester", ""); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if code := apiGet(t, handler, "/api/users/1/reminders", testAPIKey, &raw); code != http.StatusOK || string(raw["items"]) != "[]" {
+		t.Errorf("got %d with items %s, want 200 and []", code, raw["items"])
+	}
+
+	if err := c.ReminderService.CreateReminder("1", 5114, "watch it", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("failed to create reminder: %v", err)
+	}
+
+	var body struct {
+		Items []models.Reminder `json:"items"`
+	}
+	if code := apiGet(t, handler, "/api/users/1/reminders", testAPIKey, &body); code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", code)
+	}
+	if len(body.Items) != 1 || body.Items[0].Message != "watch it" {
+		t.Errorf("got %+v, want the one reminder", body.Items)
+	}
+}
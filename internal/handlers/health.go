@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"sletish/internal/container"
+)
+
+// HealthHandler reports whether the database and Redis are reachable, along with the
+// database pool's live stats for diagnosing connection exhaustion.
+func HealthHandler(container *container.Container) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		stat := container.DB.Stat()
+		pool := map[string]any{
+			"total_conns":        stat.TotalConns(),
+			"acquired_conns":     stat.AcquiredConns(),
+			"idle_conns":         stat.IdleConns(),
+			"constructing_conns": stat.ConstructingConns(),
+			"max_conns":          stat.MaxConns(),
+			"acquire_count":      stat.AcquireCount(),
+			"empty_acquire":      stat.EmptyAcquireCount(),
+			"acquire_duration":   stat.AcquireDuration().String(),
+		}
+
+		dbLatency, redisLatency, err := container.UserService.Ping()
+		if err != nil {
+			container.Logger.WithError(err).Warn("Health check failed")
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{
+				"status": "unhealthy",
+				"error":  err.Error(),
+				"pool":   pool,
+			})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"status":           "ok",
+			"db_latency_ms":    dbLatency.Milliseconds(),
+			"redis_latency_ms": redisLatency.Milliseconds(),
+			"pool":             pool,
+		})
+	}
+}
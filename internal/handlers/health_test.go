@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sletish/internal/testutil"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestHealthReportsPoolStats(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://test@127.0.0.1:1/test?connect_timeout=1&pool_max_conns=7")
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	var body struct {
+		Status string         `json:"status"`
+		Pool   map[string]any `json:"pool"`
+	}
+	code := apiGet(t, HealthHandler(newTestContainer(t, pool)), "/healthz", "", &body)
+
+	// the database is unreachable, and the pool stats help say why
+	if code != http.StatusServiceUnavailable || body.Status != "unhealthy" {
+		t.Errorf("got %d %q, want 503 unhealthy", code, body.Status)
+	}
+	if body.Pool["max_conns"] != float64(7) {
+		t.Errorf("got pool %v, want max_conns 7", body.Pool)
+	}
+}
+
+func TestHealthOK(t *testing.T) {
+	var body struct {
+		Status string         `json:"status"`
+		Pool   map[string]any `json:"pool"`
+	}
+	code := apiGet(t, HealthHandler(newTestContainer(t, testutil.DB(t))), "/healthz", "", &body)
+
+	if code != http.StatusOK || body.Status != "ok" {
+		t.Errorf("got %d %q, want 200 ok", code, body.Status)
+	}
+	if _, ok := body.Pool["acquired_conns"]; !ok {
+		t.Errorf("got pool %v, want the live stats", body.Pool)
+	}
+}
+
+func TestHealthRejectsPost(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HealthHandler(newTestContainer(t, nil)).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/healthz", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got %d, want 405", rec.Code)
+	}
+}
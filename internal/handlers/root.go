@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+	"sletish/internal/bot"
+)
+
+// RootHandler answers platform health checks that probe "/" instead of /healthz.
+// It doesn't touch the database, it only shows the process is serving.
+func RootHandler(name string) http.HandlerFunc {
+	if name == "" {
+		name = bot.DefaultBotName
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(name))
+	}
+}
+
+// FaviconHandler replies with no content so browsers hitting the root page
+// don't leave 404s in the logs.
+func FaviconHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
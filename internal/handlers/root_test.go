@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sletish/internal/bot"
+	"testing"
+)
+
+func TestRootHandlerDefaultsName(t *testing.T) {
+	rec := httptest.NewRecorder()
+	RootHandler("")(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != bot.DefaultBotName {
+		t.Errorf("got %d %q, want 200 with the default name", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("got Content-Type %q, want plain text", ct)
+	}
+}
+
+func TestRootHandlerUsesConfiguredName(t *testing.T) {
+	rec := httptest.NewRecorder()
+	RootHandler("Otaku Log")(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Body.String() != "Otaku Log" {
+		t.Errorf("got %q, want the configured name", rec.Body.String())
+	}
+}
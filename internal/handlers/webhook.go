@@ -12,36 +12,49 @@ import (
 func WebhookHandler(container *container.Container, botToken string) http.HandlerFunc {
 	// set bot token for reminder service
 	container.ReminderService.SetBotToken(botToken)
+	container.AiringService.SetBotToken(botToken)
+	container.AotdService.SetBotToken(botToken)
 
 	commandHandler := bot.NewHandler(
 		container.AnimeService,
 		container.UserService,
 		container.ReminderService, // ORDER OF DEPS MATTER, BEFORE YOU END UP DEBUGGING A NON-ISSUE!!!!
+		container.CallbackStore,
+		container.PendingStore,
+		container.FeedbackService,
 		container.Logger,
-		botToken,
+		services.NewTelegramClient(botToken),
 	)
+	commandHandler.SetAdmins(container.AdminUserIDs, container.AdminChatID)
+	commandHandler.SetWarmQueries(container.WarmQueries)
+	commandHandler.SetListGroupLimit(container.ListGroupLimit)
+	commandHandler.SetMediaService(container.MediaService)
+	commandHandler.SetBranding(container.BotName, container.BotDescription)
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeJSONErrorCode(w, http.StatusMethodNotAllowed, "method_not_allowed", "webhook only accepts POST")
 			return
 		}
 
 		update, err := services.ParseTelegramRequest(r)
 		if err != nil {
 			container.Logger.WithError(err).Error("Error parsing request")
-			http.Error(w, "Bad request", http.StatusBadRequest)
+			writeJSONErrorCode(w, http.StatusBadRequest, "invalid_update", "request body is not a valid Telegram update")
 			return
 		}
 
+		container.Logger.WithField("update_id", update.UpdateId).Debug("Update received")
+
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 
+		// answer straight away, processing errors are handled (and logged) by the bot
+		// itself and a non-2xx would only make Telegram redeliver the update
 		go func() {
 			defer cancel()
 			commandHandler.ProcessMessage(ctx, update)
 		}()
 
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "update_id": update.UpdateId})
 	}
 }
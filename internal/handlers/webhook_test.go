@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// postWebhook sends body to the webhook and decodes its JSON reply.
+func postWebhook(t *testing.T, handler http.Handler, method, body string) (int, map[string]any) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, "/webhook", strings.NewReader(body)))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+	var reply map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&reply); err != nil {
+		t.Fatalf("failed to decode reply: %v", err)
+	}
+	return rec.Code, reply
+}
+
+func TestWebhookAcknowledgesUpdate(t *testing.T) {
+	handler := WebhookHandler(newTestContainer(t, nil), "token")
+
+	// no message, so the bot ignores it without needing the database
+	code, reply := postWebhook(t, handler, http.MethodPost, `{"update_id": 815}`)
+	if code != http.StatusOK {
+		t.Errorf("got %d, want 200", code)
+	}
+	if reply["ok"] != true || reply["update_id"] != float64(815) {
+		t.Errorf("got %v, want ok with the update ID", reply)
+	}
+}
+
+func TestWebhookErrors(t *testing.T) {
+	handler := WebhookHandler(newTestContainer(t, nil), "token")
+
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		wantCode int
+		wantErr  string
+	}{
+		{"not JSON", http.MethodPost, "not json", http.StatusBadRequest, "invalid_update"},
+		{"empty body", http.MethodPost, "", http.StatusBadRequest, "invalid_update"},
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed, "method_not_allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, reply := postWebhook(t, handler, tt.method, tt.body)
+			if code != tt.wantCode || reply["code"] != tt.wantErr {
+				t.Errorf("got %d %v, want %d with code %q", code, reply, tt.wantCode, tt.wantErr)
+			}
+			if msg, _ := reply["error"].(string); msg == "" {
+				t.Errorf("got %v, want an error message", reply)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Package i18n holds the translated templates for messages the bot sends on its own,
+// like reminder notifications, where there's no incoming message to answer in kind.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is used for users without a language or one we have no translation for.
+const DefaultLocale = "en"
+
+// Message keys.
+const (
+	ReminderNotification = "reminder_notification" // title, message, date, MAL ID
+	DateFormat           = "date_format"           // Go layout for dates in messages
+)
+
+var catalog = map[string]map[string]string{
+	"en": {
+		ReminderNotification: "🔔 <b>Reminder!</b>\n\n🎬 <b>%s</b>\n💬 \"%s\"\n\n⏰ <i>You set this reminder for %s</i>\n\n<a href=\"https://myanimelist.net/anime/%s\">🔗 View on MyAnimeList</a>",
+		DateFormat:           "January 2, 2006",
+	},
+	"es": {
+		ReminderNotification: "🔔 <b>¡Recordatorio!</b>\n\n🎬 <b>%s</b>\n💬 \"%s\"\n\n⏰ <i>Programaste este recordatorio para el %s</i>\n\n<a href=\"https://myanimelist.net/anime/%s\">🔗 Ver en MyAnimeList</a>",
+		DateFormat:           "02/01/2006",
+	},
+	"pt": {
+		ReminderNotification: "🔔 <b>Lembrete!</b>\n\n🎬 <b>%s</b>\n💬 \"%s\"\n\n⏰ <i>Você definiu este lembrete para %s</i>\n\n<a href=\"https://myanimelist.net/anime/%s\">🔗 Ver no MyAnimeList</a>",
+		DateFormat:           "02/01/2006",
+	},
+	"fr": {
+		ReminderNotification: "🔔 <b>Rappel !</b>\n\n🎬 <b>%s</b>\n💬 « %s »\n\n⏰ <i>Vous avez programmé ce rappel pour le %s</i>\n\n<a href=\"https://myanimelist.net/anime/%s\">🔗 Voir sur MyAnimeList</a>",
+		DateFormat:           "02/01/2006",
+	},
+	"de": {
+		ReminderNotification: "🔔 <b>Erinnerung!</b>\n\n🎬 <b>%s</b>\n💬 „%s“\n\n⏰ <i>Du hast diese Erinnerung für den %s eingestellt</i>\n\n<a href=\"https://myanimelist.net/anime/%s\">🔗 Auf MyAnimeList ansehen</a>",
+		DateFormat:           "02.01.2006",
+	},
+	"ru": {
+		ReminderNotification: "🔔 <b>Напоминание!</b>\n\n🎬 <b>%s</b>\n💬 «%s»\n\n⏰ <i>Вы установили это напоминание на %s</i>\n\n<a href=\"https://myanimelist.net/anime/%s\">🔗 Открыть на MyAnimeList</a>",
+		DateFormat:           "02.01.2006",
+	},
+}
+
+// Locale maps a language tag from Telegram (e.g. "pt-br") onto a supported locale,
+// falling back to DefaultLocale.
+func Locale(languageCode string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(languageCode)), "-")
+	if _, ok := catalog[lang]; ok {
+		return lang
+	}
+	return DefaultLocale
+}
+
+// T renders the message for key in locale, using the English text for anything
+// missing from that locale's catalog.
+func T(locale, key string, args ...any) string {
+	template, ok := catalog[Locale(locale)][key]
+	if !ok {
+		template = catalog[DefaultLocale][key]
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// FormatDate formats t with the locale's date layout.
+func FormatDate(locale string, t time.Time) string {
+	return t.Format(T(locale, DateFormat))
+}
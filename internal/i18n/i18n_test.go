@@ -0,0 +1,59 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocale(t *testing.T) {
+	tests := map[string]string{
+		"":      "en",
+		"en":    "en",
+		"de":    "de",
+		"pt-br": "pt",
+		"PT-BR": "pt",
+		" fr ":  "fr",
+		"ja":    "en",
+		"zh-tw": "en",
+	}
+
+	for code, want := range tests {
+		if got := Locale(code); got != want {
+			t.Errorf("Locale(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	for locale, messages := range catalog {
+		for key, english := range catalog[DefaultLocale] {
+			translated, ok := messages[key]
+			if !ok {
+				t.Errorf("%s is missing %s", locale, key)
+				continue
+			}
+			// the same arguments are passed whatever the locale
+			if got, want := strings.Count(translated, "%s"), strings.Count(english, "%s"); got != want {
+				t.Errorf("%s %s has %d %%s verbs, want %d", locale, key, got, want)
+			}
+		}
+	}
+}
+
+func TestReminderNotificationIsTranslated(t *testing.T) {
+	remindAt := time.Date(2024, 3, 9, 18, 0, 0, 0, time.UTC)
+
+	german := T("de-DE", ReminderNotification, "Frieren", "weiterschauen", FormatDate("de", remindAt), "52991")
+	for _, want := range []string{"🔔 <b>Erinnerung!</b>", "„weiterschauen“", "für den 09.03.2024", "anime/52991"} {
+		if !strings.Contains(german, want) {
+			t.Errorf("got %q, want it to contain %q", german, want)
+		}
+	}
+
+	// no translation, so English
+	english := T("ja", ReminderNotification, "Frieren", "keep watching", FormatDate("ja", remindAt), "52991")
+	if !strings.Contains(english, "<b>Reminder!</b>") || !strings.Contains(english, "for March 9, 2024") {
+		t.Errorf("got %q, want the English notification", english)
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+// AniList list statuses, as used in MediaListCollection entries
+const (
+	AniListCurrent   = "CURRENT"
+	AniListPlanning  = "PLANNING"
+	AniListCompleted = "COMPLETED"
+	AniListDropped   = "DROPPED"
+	AniListPaused    = "PAUSED"
+	AniListRepeating = "REPEATING"
+)
+
+// AniListRequest is the body of a GraphQL request to AniList.
+type AniListRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+// AniListCollectionResponse is AniList's answer to a MediaListCollection query.
+type AniListCollectionResponse struct {
+	Data struct {
+		MediaListCollection *struct {
+			Lists []struct {
+				Entries []AniListEntry `json:"entries"`
+			} `json:"lists"`
+		} `json:"MediaListCollection"`
+	} `json:"data"`
+	Errors []AniListError `json:"errors"`
+}
+
+type AniListError struct {
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+// AniListEntry is one anime in an AniList user's list.
+type AniListEntry struct {
+	Status   string  `json:"status"`
+	Score    float64 `json:"score"` // 0-10, 0 when unrated
+	Progress int     `json:"progress"`
+	Media    struct {
+		IDMal int `json:"idMal"` // 0 when AniList has no MyAnimeList mapping
+		Title struct {
+			Romaji string `json:"romaji"`
+		} `json:"title"`
+	} `json:"media"`
+}
+
+// ImportResult counts what happened to each entry of an imported list.
+type ImportResult struct {
+	Imported int
+	Skipped  int // already in the list, no MyAnimeList ID or unknown status
+	Failed   int
+}
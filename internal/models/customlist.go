@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Custom list limits
+const (
+	MaxCustomListNameLength = 50
+	MaxCustomListsPerUser   = 20
+)
+
+// CustomList is a user-named list of anime, separate from the status list.
+type CustomList struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	ItemCount int       `json:"item_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Feedback is a bug report or suggestion sent by a user with /feedback.
+type Feedback struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,21 +1,128 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 type JikanSearchResponse struct {
 	Data       []AnimeData `json:"data"`
 	Pagination Pagination  `json:"pagination"`
 }
 
+// SearchFilters narrows an anime search. Zero values mean no filter.
+type SearchFilters struct {
+	Type string // tv, movie, ova, special, ona, music
+	Year int
+	SFW  bool // exclude adult content
+	Page int  // 1-based, 0 means the first page
+}
+
+// Airing statuses as reported by Jikan
+const (
+	AiringStatusAiring   = "Currently Airing"
+	AiringStatusFinished = "Finished Airing"
+	AiringStatusUpcoming = "Not yet aired"
+)
+
+// ValidAnimeTypes are the type filters Jikan accepts.
+var ValidAnimeTypes = []string{"tv", "movie", "ova", "special", "ona", "music"}
+
 type AnimeData struct {
-	MalID    int     `json:"mal_id"`
-	Title    string  `json:"title"`
-	Score    float64 `json:"score"`
-	Episodes int     `json:"episodes"`
-	Status   string  `json:"status"`
-	Synopsis string  `json:"synopsis"`
-	Images   Images  `json:"images"`
-	Genres   []Genre `json:"genres"`
-	Year     int     `json:"year"`
-	Type     string  `json:"type"`
+	MalID int    `json:"mal_id"`
+	Title string `json:"title"`
+	// TitleEnglish is only used as a fallback, Jikan occasionally sends a null title.
+	TitleEnglish string  `json:"title_english"`
+	Score        float64 `json:"score"`
+	Episodes     int     `json:"episodes"`
+	Status       string  `json:"status"`
+	Synopsis     string  `json:"synopsis"`
+	Images       Images  `json:"images"`
+	Genres       []Genre `json:"genres"`
+	Year         int     `json:"year"`
+	Type         string  `json:"type"`
+	Rating       string  `json:"rating"`   // content rating, e.g. "PG-13 - Teens 13 or older"
+	Duration     string  `json:"duration"` // e.g. "24 min per ep", or "1 hr 50 min" for a movie
+	Aired        Aired   `json:"aired"`
+}
+
+// Aired is when an anime aired. Jikan only knows the day, sent as midnight UTC;
+// From is nil for anime without an announced date.
+type Aired struct {
+	From   *time.Time `json:"from"`
+	To     *time.Time `json:"to"`
+	String string     `json:"string"` // e.g. "Apr 7, 2013 to Sep 29, 2013"
+}
+
+// IsAdult reports whether the anime is rated Rx (hentai).
+func (a AnimeData) IsAdult() bool {
+	return strings.HasPrefix(a.Rating, "Rx")
+}
+
+// JikanRecommendationsResponse is Jikan's list of titles users recommend alongside an anime.
+type JikanRecommendationsResponse struct {
+	Data []struct {
+		Entry struct {
+			MalID int    `json:"mal_id"`
+			Title string `json:"title"`
+		} `json:"entry"`
+		Votes int `json:"votes"`
+	} `json:"data"`
+}
+
+// Recommendation is an anime recommended alongside another, with how many users voted for it.
+type Recommendation struct {
+	MalID int    `json:"mal_id"`
+	Title string `json:"title"`
+	Votes int    `json:"votes"`
+}
+
+// JikanCharactersResponse is Jikan's full cast list for an anime. It isn't paginated.
+type JikanCharactersResponse struct {
+	Data []struct {
+		Character struct {
+			MalID int    `json:"mal_id"`
+			Name  string `json:"name"`
+		} `json:"character"`
+		Role      string `json:"role"`
+		Favorites int    `json:"favorites"`
+	} `json:"data"`
+}
+
+// Character is someone in an anime's cast.
+type Character struct {
+	MalID int    `json:"mal_id"`
+	Name  string `json:"name"`
+	Role  string `json:"role"` // Main or Supporting
+}
+
+// JikanEpisodesResponse is one page of an anime's episodes, JikanEpisodesPageSize per page.
+type JikanEpisodesResponse struct {
+	Data       []Episode `json:"data"`
+	Pagination struct {
+		LastVisiblePage int  `json:"last_visible_page"`
+		HasNextPage     bool `json:"has_next_page"`
+	} `json:"pagination"`
+}
+
+// JikanEpisodesPageSize is how many episodes Jikan returns per page.
+const JikanEpisodesPageSize = 100
+
+// Episode is a single episode of an anime. MalID is the episode number.
+type Episode struct {
+	MalID  int    `json:"mal_id"`
+	Title  string `json:"title"`
+	Aired  string `json:"aired"`
+	Filler bool   `json:"filler"`
+	Recap  bool   `json:"recap"`
+}
+
+// EpisodePage is one Jikan page of episodes.
+type EpisodePage struct {
+	Episodes []Episode `json:"episodes"`
+	Page     int       `json:"page"`
+	LastPage int       `json:"last_page"`
+	HasNext  bool      `json:"has_next"`
 }
 
 type Images struct {
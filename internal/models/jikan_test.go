@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// These refer to MalID directly, so renaming the field back to MalId breaks the build.
+var (
+	_ = AnimeData{}.MalID
+	_ = Recommendation{}.MalID
+	_ = Character{}.MalID
+	_ = Episode{}.MalID
+)
+
+func TestAnimeDataDecodesMalID(t *testing.T) {
+	var anime AnimeData
+	if err := json.Unmarshal([]byte(`{"mal_id": 5114, "title": "Fullmetal Alchemist: Brotherhood"}`), &anime); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if anime.MalID != 5114 {
+		t.Errorf("MalID = %d, want 5114", anime.MalID)
+	}
+
+	encoded, err := json.Marshal(anime)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"mal_id":5114`) {
+		t.Errorf("encoded anime %s has no mal_id", encoded)
+	}
+}
+
+// TestMalIDFieldNaming checks every mal_id field in the Jikan models, nested ones included,
+// is named MalID.
+func TestMalIDFieldNaming(t *testing.T) {
+	types := []any{
+		AnimeData{},
+		JikanRecommendationsResponse{},
+		Recommendation{},
+		JikanCharactersResponse{},
+		Character{},
+		JikanEpisodesResponse{},
+		Episode{},
+	}
+
+	for _, v := range types {
+		checkMalIDFields(t, reflect.TypeOf(v), reflect.TypeOf(v).Name())
+	}
+}
+
+func checkMalIDFields(t *testing.T, typ reflect.Type, path string) {
+	t.Helper()
+
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		checkMalIDFields(t, typ.Elem(), path)
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "mal_id" && field.Name != "MalID" {
+			t.Errorf("%s.%s is tagged mal_id, want it named MalID", path, field.Name)
+		}
+		if strings.EqualFold(field.Name, "MalID") && field.Name != "MalID" {
+			t.Errorf("%s.%s should be spelled MalID", path, field.Name)
+		}
+		checkMalIDFields(t, field.Type, path+"."+field.Name)
+	}
+}
+
+func TestAnimeDataDecodesAired(t *testing.T) {
+	var anime AnimeData
+	body := `{"mal_id": 1, "aired": {"from": "2013-04-07T00:00:00+00:00", "to": null, "string": "Apr 7, 2013 to ?"}}`
+	if err := json.Unmarshal([]byte(body), &anime); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if anime.Aired.From == nil || !anime.Aired.From.Equal(time.Date(2013, time.April, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Aired.From = %v, want April 7, 2013", anime.Aired.From)
+	}
+	if anime.Aired.To != nil || anime.Aired.String != "Apr 7, 2013 to ?" {
+		t.Errorf("Aired = %+v, want no end date", anime.Aired)
+	}
+
+	// not yet announced
+	if err := json.Unmarshal([]byte(`{"mal_id": 2, "aired": {"from": null, "to": null, "string": "Not available"}}`), &anime); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if anime.Aired.From != nil {
+		t.Errorf("Aired.From = %v, want nil", anime.Aired.From)
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PendingAction is an interactive step a user has started but not finished,
+// e.g. a removal waiting for confirmation.
+type PendingAction struct {
+	Type      string            `json:"type"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// Pending action types
+const (
+	PendingConfirmRemove = "confirm_remove"
+	PendingAddWizard     = "add_wizard"
+	PendingBulkStatus    = "bulk_status"
+)
+
+// Add wizard steps, stored under the "step" key of an add_wizard action
+const (
+	WizardStepAwaitQuery = "await_query" // waiting for the user to type a title
+	WizardStepAwaitPick  = "await_pick"  // results shown, waiting for a pick
+)
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// MaxQueueSize is how many anime a user's watch queue can hold.
+const MaxQueueSize = 50
+
+// QueueItem is an anime in the user's watch queue. Position 1 is next up.
+type QueueItem struct {
+	Position int       `json:"position"`
+	Media    Media     `json:"media"`
+	AddedAt  time.Time `json:"added_at"`
+}
@@ -9,6 +9,9 @@ type Reminder struct {
 	Message        string    `json:"message"`
 	RemindAt       time.Time `json:"remind_at"`
 	Sent           bool      `json:"sent"`
+	Failed         bool      `json:"failed"` // delivery was given up on
+	FailureCount   int       `json:"failure_count"`
+	LastError      string    `json:"last_error,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 	MediaTitle     string    `json:"media_title,omitempty"`
 	MediaPosterURL string    `json:"media_poster_url,omitempty"`
@@ -0,0 +1,16 @@
+package models
+
+// SearchResult is a page of anime search results, independent of the metadata provider.
+type SearchResult struct {
+	Items   []AnimeData `json:"items"`
+	HasNext bool        `json:"has_next"`
+	Total   int         `json:"total"`
+}
+
+// LastSearch is a user's most recent search, kept so /again can fetch its next page.
+type LastSearch struct {
+	Query   string        `json:"query"`
+	Filters SearchFilters `json:"filters"`
+	Page    int           `json:"page"`
+	HasNext bool          `json:"has_next"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// UserSettings is a user's preferences, stored in user_settings.
+type UserSettings struct {
+	Timezone            string          `json:"timezone" db:"timezone"`
+	LanguageCode        string          `json:"language_code,omitempty" db:"language_code"`
+	SearchVerbosity     SearchVerbosity `json:"search_verbosity" db:"search_verbosity"`
+	ShowNSFW            bool            `json:"show_nsfw" db:"show_nsfw"`
+	IsPublic            bool            `json:"is_public" db:"is_public"`
+	NotificationsPaused bool            `json:"notifications_paused" db:"notifications_paused"`
+	AotdEnabled         bool            `json:"aotd_enabled" db:"aotd_enabled"`
+	SilentReminders     bool            `json:"silent_reminders" db:"silent_reminders"`
+	UpdatedAt           time.Time       `json:"updated_at" db:"updated_at"` // zero until something was changed
+}
+
+// DefaultUserSettings returns the settings of a user who hasn't changed any,
+// matching the user_settings column defaults.
+func DefaultUserSettings() UserSettings {
+	return UserSettings{
+		Timezone:        "UTC",
+		SearchVerbosity: VerbosityNormal,
+	}
+}
+
+// Setting keys, used by UpdateSetting and the /settings buttons to say which setting to change
+const (
+	SettingNSFW          = "nsfw"
+	SettingPublic        = "public"
+	SettingNotifications = "notifications" // value is whether notifications are paused
+	SettingAotd          = "aotd"
+	SettingSilent        = "silent"
+	SettingVerbosity     = "verbosity"
+	SettingTimezone      = "timezone"
+	SettingLanguage      = "language"
+)
+
+// NextVerbosity returns the level after v, wrapping around, for cycling through them.
+func NextVerbosity(v SearchVerbosity) SearchVerbosity {
+	switch v {
+	case VerbosityCompact:
+		return VerbosityNormal
+	case VerbosityNormal:
+		return VerbosityDetailed
+	default:
+		return VerbosityCompact
+	}
+}
+
+// ChatMode is whose list the list commands use in a group chat.
+type ChatMode string
+
+const (
+	ChatModePersonal ChatMode = "personal" // every member keeps their own list
+	ChatModeShared   ChatMode = "shared"   // the chat has one list everyone edits
+)
+
+// IsValid reports whether the mode is one of the known modes.
+func (m ChatMode) IsValid() bool {
+	return m == ChatModePersonal || m == ChatModeShared
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// GenreCount is how many entries of a user's list carry a genre.
+type GenreCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// GenreRating is how many titles of a genre a user has watched and their average
+// personal rating. AverageRating is nil when none of them are rated.
+type GenreRating struct {
+	Name          string   `json:"name"`
+	Count         int      `json:"count"`
+	Rated         int      `json:"rated"`
+	AverageRating *float64 `json:"average_rating,omitempty"`
+}
+
+// LeaderboardEntry is a public user's completion count for the leaderboard.
+type LeaderboardEntry struct {
+	Username    string `json:"username"`
+	Completions int    `json:"completions"`
+}
+
+// MonthlyCount is how many completions a user had in the calendar month starting at Month.
+type MonthlyCount struct {
+	Month time.Time `json:"month"`
+	Count int       `json:"count"`
+}
+
+// FormatWatchTime is how many minutes a user has spent on one format (TV, Movie, OVA...).
+type FormatWatchTime struct {
+	Format  string `json:"format"`
+	Minutes int    `json:"minutes"`
+}
+
+// TrendingMedia is an anime and how many users added it recently.
+type TrendingMedia struct {
+	Media Media `json:"media"`
+	Adds  int   `json:"adds"`
+}
+
+// RatingBuckets are the rating ranges used by the ratings histogram, lowest first.
+// A bucket holds ratings from its lower bound up to (not including) the next bucket's.
+var RatingBuckets = []string{"1-2", "3-4", "5-6", "7-8", "9-10"}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tag limits
+const (
+	MaxTagLength    = 30
+	MaxTagsPerEntry = 10
+)
+
+// NormalizeTag lowercases a tag and strips a leading "#". Reports false if the result
+// is empty, too long, or has characters other than letters, digits, "_" and "-".
+func NormalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+		return "", false
+	}
+
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return "", false
+		}
+	}
+
+	return tag, true
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   string
+		wantOK bool
+	}{
+		{"rewatch", "rewatch", true},
+		{"#Rewatch", "rewatch", true},
+		{" with-friends_2 ", "with-friends_2", true},
+		{"鋼の錬金術師", "鋼の錬金術師", true},
+		{"", "", false},
+		{"#", "", false},
+		{"two words", "", false},
+		{"<b>", "", false},
+		{strings.Repeat("a", MaxTagLength), strings.Repeat("a", MaxTagLength), true},
+		{strings.Repeat("a", MaxTagLength+1), "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeTag(tt.tag)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NormalizeTag(%q) = %q, %v, want %q, %v", tt.tag, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
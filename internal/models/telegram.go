@@ -4,16 +4,49 @@ package models
 // which may contain either a message or a callback query.
 type Update struct {
 	UpdateId      int            `json:"update_id"`
-	Message       Message        `json:"message"`
+	Message       *Message       `json:"message,omitempty"`
 	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
 }
 
-// Message represents a standard text message sent in a chat.
+// Message represents a message sent in a chat. Only one of
+// Text, Document, Photo, Sticker or Voice is usually set.
+// From is nil for messages sent on behalf of a channel.
 type Message struct {
-	MessageId int    `json:"message_id"`
-	Text      string `json:"text"`
-	Chat      Chat   `json:"chat"`
-	From      User   `json:"from"`
+	MessageId int         `json:"message_id"`
+	Text      string      `json:"text"`
+	Chat      Chat        `json:"chat"`
+	From      *User       `json:"from,omitempty"`
+	Document  *Document   `json:"document,omitempty"`
+	Photo     []PhotoSize `json:"photo,omitempty"`
+	Sticker   *Sticker    `json:"sticker,omitempty"`
+	Voice     *Voice      `json:"voice,omitempty"`
+}
+
+// Document represents a general file sent in a message.
+type Document struct {
+	FileId   string `json:"file_id"`
+	FileName string `json:"file_name,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileSize int    `json:"file_size,omitempty"`
+}
+
+// PhotoSize represents one size of a photo sent in a message.
+type PhotoSize struct {
+	FileId string `json:"file_id"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Sticker represents a sticker sent in a message.
+type Sticker struct {
+	FileId string `json:"file_id"`
+	Emoji  string `json:"emoji,omitempty"`
+}
+
+// Voice represents a voice note sent in a message.
+type Voice struct {
+	FileId   string `json:"file_id"`
+	Duration int    `json:"duration"`
 }
 
 // Chat represents a Telegram chat, which may be a private chat, group, etc.
@@ -23,9 +56,33 @@ type Chat struct {
 
 // User represents a Telegram user or bot who sent the message or query.
 type User struct {
-	Id        int    `json:"id"`
-	FirstName string `json:"first_name"`
-	Username  string `json:"username"`
+	Id           int    `json:"id"`
+	FirstName    string `json:"first_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+// GetMeResponse is the Telegram API response to getMe.
+type GetMeResponse struct {
+	Ok     bool `json:"ok"`
+	Result User `json:"result"`
+}
+
+// ChatMember is a user's membership in a chat, as returned by getChatMember.
+type ChatMember struct {
+	Status string `json:"status"` // creator, administrator, member, restricted, left or kicked
+	User   User   `json:"user"`
+}
+
+// IsAdmin reports whether the member can manage the chat.
+func (m ChatMember) IsAdmin() bool {
+	return m.Status == "creator" || m.Status == "administrator"
+}
+
+// GetChatMemberResponse is the Telegram API response to getChatMember.
+type GetChatMemberResponse struct {
+	Ok     bool       `json:"ok"`
+	Result ChatMember `json:"result"`
 }
 
 // CallbackQuery represents a callback query triggered by
@@ -56,6 +113,22 @@ type TelegramResponse struct {
 	Text        string                `json:"text"`
 	ParseMode   string                `json:"parse_mode,omitempty"`
 	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	// DisableNotification delivers the message without a sound
+	DisableNotification bool `json:"disable_notification,omitempty"`
+}
+
+// SentMessageResponse represents Telegram's reply to sendMessage,
+// which carries the message that was sent.
+type SentMessageResponse struct {
+	Ok     bool    `json:"ok"`
+	Result Message `json:"result"`
+}
+
+// TelegramErrorResponse is the body Telegram sends with a non-OK response.
+type TelegramErrorResponse struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
 }
 
 // BotCommandMenu defines a command and description for Telegram's bot command menu.
@@ -75,13 +148,21 @@ type BotCommand struct {
 
 // CallbackData defines the structure of data attached to inline buttons
 // to facilitate various types of user interaction, including pagination.
+//
+// JSON keys are kept to a single letter or two because Telegram caps
+// callback_data at 64 bytes.
 type CallbackData struct {
-	Action  string `json:"action"`
-	AnimeID string `json:"anime_id,omitempty"`
-	Status  string `json:"status,omitempty"`
-	Page    int    `json:"page,omitempty"`
-	Limit   int    `json:"limit,omitempty"`
-	Total   int    `json:"total,omitempty"`
+	Action  string `json:"a"`
+	AnimeID string `json:"id,omitempty"`
+	Status  string `json:"s,omitempty"`
+	Page    int    `json:"p,omitempty"`
+	Limit   int    `json:"l,omitempty"`
+	Total   int    `json:"t,omitempty"`
+	Genre   string `json:"g,omitempty"`
+	Tag     string `json:"tg,omitempty"`
+	Target  string `json:"to,omitempty"` // status to move to, for bulk_status
+	Query   string `json:"q,omitempty"`  // search text for history_search, setting key for toggle_setting
+	Rating  string `json:"r,omitempty"`  // list rating filter, e.g. "rated>=8"
 }
 
 // AnswerCallbackQuery represents a request to respond to a callback query.
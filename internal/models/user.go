@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Status string
 
@@ -12,35 +15,90 @@ const (
 	StatusWatchlist Status = "watchlist"
 )
 
+// IsValid reports whether the status is one of the known list statuses.
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusWatching, StatusCompleted, StatusOnHold, StatusDropped, StatusWatchlist:
+		return true
+	}
+	return false
+}
+
+// SearchVerbosity is how much detail a user wants in search results.
+type SearchVerbosity string
+
+const (
+	VerbosityCompact  SearchVerbosity = "compact"  // title and ID only
+	VerbosityNormal   SearchVerbosity = "normal"   // details for the top result, brief others
+	VerbosityDetailed SearchVerbosity = "detailed" // longer synopsis and details for every result
+)
+
+// IsValid reports whether the verbosity is one of the known levels.
+func (v SearchVerbosity) IsValid() bool {
+	switch v {
+	case VerbosityCompact, VerbosityNormal, VerbosityDetailed:
+		return true
+	}
+	return false
+}
+
+// Personal rating bounds, inclusive
+const (
+	MinRating = 1.0
+	MaxRating = 10.0
+)
+
+// RatingFilter narrows a list to entries whose personal rating compares to Value,
+// e.g. {">=", 8}. Unrated entries never match.
+type RatingFilter struct {
+	Op    string  `json:"op"` // one of RatingOperators
+	Value float64 `json:"value"`
+}
+
+// RatingOperators are the comparisons a RatingFilter accepts, longest first so
+// ">=" is matched before ">".
+var RatingOperators = []string{">=", "<=", ">", "<", "="}
+
+// String renders the filter the way it's typed, e.g. "rated>=8".
+func (f RatingFilter) String() string {
+	return fmt.Sprintf("rated%s%g", f.Op, f.Value)
+}
+
 type AppUser struct {
 	ID        string    `json:"id" db:"id" validate:"required"`
 	Username  *string   `json:"username" db:"username" validate:"max=50"`
-	Platform  string    `json:"platform" db:"platform" validate:"required,oneof=telegram"` // **NOTE:MODIFY FOR FUTURE PLATFORMS**
+	Platform  string    `json:"platform" db:"platform" validate:"required,oneof=telegram telegram_chat"` // **NOTE:MODIFY FOR FUTURE PLATFORMS**
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type Media struct {
-	ID          int       `json:"id" db:"id"`
-	ExternalID  string    `json:"external_id" db:"external_id"`
-	Title       string    `json:"title" db:"title"`
-	Type        string    `json:"type" db:"type"`
-	Description string    `json:"description" db:"description"`
-	ReleaseDate *string   `json:"release_date" db:"release_date"`
-	PosterURL   string    `json:"poster_url" db:"poster_url"`
-	Rating      *float64  `json:"rating" db:"rating"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ID           int       `json:"id" db:"id"`
+	ExternalID   string    `json:"external_id" db:"external_id"`
+	Title        string    `json:"title" db:"title"`
+	Type         string    `json:"type" db:"type"`
+	Description  string    `json:"description" db:"description"`
+	ReleaseDate  *string   `json:"release_date" db:"release_date"`
+	PosterURL    string    `json:"poster_url" db:"poster_url"`
+	Rating       *float64  `json:"rating" db:"rating"`
+	AiringStatus string    `json:"airing_status" db:"airing_status"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
 
 type UserMedia struct {
-	ID        int       `json:"id" db:"id"`
-	UserID    string    `json:"user_id" db:"user_id"`
-	MediaID   int       `json:"media_id" db:"media_id"`
-	Status    Status    `json:"status" db:"status"`
-	Rating    float64   `json:"rating" db:"rating"`
-	Notes     string    `json:"notes" db:"notes"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID              int        `json:"id" db:"id"`
+	UserID          string     `json:"user_id" db:"user_id"`
+	MediaID         int        `json:"media_id" db:"media_id"`
+	Status          Status     `json:"status" db:"status"`
+	Rating          float64    `json:"rating" db:"rating"`
+	Notes           string     `json:"notes" db:"notes"`
+	Tags            []string   `json:"tags" db:"tags"`
+	EpisodesWatched int        `json:"episodes_watched" db:"episodes_watched"` // 0 when no progress was reported
+	StartedAt       *time.Time `json:"started_at" db:"started_at"`             // first time status became watching
+	CompletedAt     *time.Time `json:"completed_at" db:"completed_at"`         // last time status became completed
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // set while the entry is archived
 }
 
 type UserMediaWithDetails struct {
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sletish/internal/models"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultEpisodeCheckInterval is how often airing anime are re-checked unless configured otherwise.
+	DefaultEpisodeCheckInterval = time.Hour
+	airingCheckBatchSize        = 25
+)
+
+// AiringService periodically re-checks anime that users are watching while they
+// air, and tells those users once the anime has finished airing.
+type AiringService struct {
+	db           *pgxpool.Pool
+	logger       *logrus.Logger
+	botToken     string
+	isRunning    atomic.Bool
+	startOnce    sync.Once
+	workers      sync.WaitGroup
+	animeService *Client
+	interval     time.Duration
+}
+
+// NewAiringService creates the service. Once started, its worker checks a batch of
+// airing anime every interval. A non-positive interval uses DefaultEpisodeCheckInterval.
+func NewAiringService(db *pgxpool.Pool, logger *logrus.Logger, botToken string, animeService *Client, interval time.Duration) *AiringService {
+	if interval <= 0 {
+		interval = DefaultEpisodeCheckInterval
+	}
+
+	service := &AiringService{
+		db:           db,
+		logger:       logger,
+		botToken:     botToken,
+		animeService: animeService,
+		interval:     interval,
+	}
+
+	return service
+}
+
+// Start runs the airing worker in the background until ctx is cancelled or StopWorker
+// is called. Only the first call starts a worker, so a service never runs two.
+func (s *AiringService) Start(ctx context.Context) {
+	s.startOnce.Do(func() {
+		s.isRunning.Store(true)
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			s.runWorker(ctx)
+		}()
+	})
+}
+
+// Wait blocks until the worker started by Start has stopped. It returns at once if
+// the worker was never started.
+func (s *AiringService) Wait() {
+	s.workers.Wait()
+}
+
+func (s *AiringService) runWorker(ctx context.Context) {
+	s.logger.WithField("interval", s.interval).Info("Starting airing worker...")
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.isRunning.Store(false)
+		case <-ticker.C:
+		}
+
+		if !s.isRunning.Load() {
+			break
+		}
+
+		s.logger.Debug("Checking airing statuses...")
+
+		if err := s.processAiringChanges(); err != nil {
+			s.logger.WithError(err).Error("Error processing airing changes")
+		}
+	}
+
+	s.logger.Info("Airing worker stopped")
+}
+
+// processAiringChanges re-checks a batch of airing anime that someone is watching,
+// least recently checked first, and notifies watchers of those that finished.
+// Media saved before airing statuses were tracked is picked up too, to record its status.
+func (s *AiringService) processAiringChanges() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT m.id, m.external_id, m.title, COALESCE(m.airing_status, '')
+		FROM media m
+		WHERE (m.airing_status = $1 OR m.airing_status IS NULL)
+		AND EXISTS (
+			SELECT 1 FROM user_media um
+			WHERE um.media_id = m.id AND um.status = 'watching' AND um.deleted_at IS NULL
+		)
+		ORDER BY m.airing_checked_at ASC NULLS FIRST
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, models.AiringStatusAiring, airingCheckBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to query airing media: %w", err)
+	}
+
+	var airing []models.Media
+	for rows.Next() {
+		var media models.Media
+		if err := rows.Scan(&media.ID, &media.ExternalID, &media.Title, &media.AiringStatus); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan airing media: %w", err)
+		}
+		airing = append(airing, media)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating airing media: %w", err)
+	}
+
+	var finishedCount, errorCount int
+
+	for _, media := range airing {
+		finished, err := s.checkMedia(ctx, media)
+		if err != nil {
+			s.logger.WithError(err).WithField("media_id", media.ID).Error("Failed to check airing status")
+			errorCount++
+			continue
+		}
+		if finished {
+			finishedCount++
+		}
+	}
+
+	if finishedCount > 0 || errorCount > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"checked":  len(airing),
+			"finished": finishedCount,
+			"errors":   errorCount,
+		}).Info("Processed airing changes")
+	}
+
+	return nil
+}
+
+// checkMedia fetches the current airing status and stores it. Reports whether the
+// anime just finished airing, in which case its watchers have been notified.
+func (s *AiringService) checkMedia(ctx context.Context, media models.Media) (bool, error) {
+	animeID, err := strconv.Atoi(media.ExternalID)
+	if err != nil {
+		return false, fmt.Errorf("invalid external ID %q: %w", media.ExternalID, err)
+	}
+
+	anime, err := s.animeService.GetAnimeByID(animeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch anime: %w", err)
+	}
+
+	if !isFinishedAiring(media.AiringStatus, anime.Status) {
+		_, err := s.db.Exec(ctx, "UPDATE media SET airing_status = NULLIF($2, ''), airing_checked_at = NOW() WHERE id = $1", media.ID, anime.Status)
+		if err != nil {
+			return false, fmt.Errorf("failed to update airing status: %w", err)
+		}
+		return false, nil
+	}
+
+	// Only the update that actually flips the status gets a row back, so a transition
+	// is announced once even if checks overlap.
+	updateQuery := `
+		UPDATE media
+		SET airing_status = $2, airing_checked_at = NOW()
+		WHERE id = $1 AND airing_status = $3
+		RETURNING id
+	`
+
+	var updatedID int
+	err = s.db.QueryRow(ctx, updateQuery, media.ID, anime.Status, models.AiringStatusAiring).Scan(&updatedID)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update airing status: %w", err)
+	}
+
+	if err := s.notifyWatchers(ctx, media); err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
+// isFinishedAiring reports whether a status change means the anime finished airing.
+func isFinishedAiring(previous, current string) bool {
+	return previous == models.AiringStatusAiring && current == models.AiringStatusFinished
+}
+
+func (s *AiringService) notifyWatchers(ctx context.Context, media models.Media) error {
+	watchersQuery := `
+		SELECT um.user_id
+		FROM user_media um
+		JOIN users u ON u.id = um.user_id
+		LEFT JOIN user_settings us ON us.user_id = u.id
+		WHERE um.media_id = $1 AND um.status = 'watching' AND um.deleted_at IS NULL AND u.blocked = false
+		AND COALESCE(us.notifications_paused, false) = false
+	`
+
+	rows, err := s.db.Query(ctx, watchersQuery, media.ID)
+	if err != nil {
+		return fmt.Errorf("failed to query watchers: %w", err)
+	}
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan watcher: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating watchers: %w", err)
+	}
+
+	text := fmt.Sprintf(`🏁 <b>Finished airing!</b>
+
+🎬 <b>%s</b> has aired its final episode. Time to binge the rest!
+
+<a href="https://myanimelist.net/anime/%s">🔗 View on MyAnimeList</a>`, html.EscapeString(media.Title), media.ExternalID)
+
+	for _, userID := range userIDs {
+		chatID, err := strconv.Atoi(userID)
+		if err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Warn("Invalid user ID for airing notification")
+			continue
+		}
+
+		if err := SendTelegramMessage(ctx, s.botToken, chatID, text); err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Error("Failed to send airing notification")
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"media_id": media.ID,
+		"watchers": len(userIDs),
+	}).Info("Sent finished airing notifications")
+
+	return nil
+}
+
+func (s *AiringService) StopWorker() {
+	s.isRunning.Store(false)
+	s.logger.Info("Airing worker stop requested")
+}
+
+func (s *AiringService) SetBotToken(botToken string) {
+	s.botToken = botToken
+}
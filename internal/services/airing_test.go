@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sletish/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIsFinishedAiring(t *testing.T) {
+	tests := []struct {
+		previous, current string
+		want              bool
+	}{
+		{models.AiringStatusAiring, models.AiringStatusFinished, true},
+		{models.AiringStatusAiring, models.AiringStatusAiring, false},
+		{models.AiringStatusFinished, models.AiringStatusFinished, false}, // already announced
+		{"", models.AiringStatusFinished, false},                          // never seen airing
+		{models.AiringStatusUpcoming, models.AiringStatusFinished, false},
+		{models.AiringStatusUpcoming, models.AiringStatusAiring, false},
+	}
+
+	for _, tt := range tests {
+		if got := isFinishedAiring(tt.previous, tt.current); got != tt.want {
+			t.Errorf("isFinishedAiring(%q, %q) = %v, want %v", tt.previous, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestAiringServiceInterval(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		want     time.Duration
+	}{
+		{0, DefaultEpisodeCheckInterval},
+		{-time.Minute, DefaultEpisodeCheckInterval},
+		{30 * time.Minute, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := NewAiringService(nil, newTestLogger(), "", nil, tt.interval).interval; got != tt.want {
+			t.Errorf("interval %v got %v, want %v", tt.interval, got, tt.want)
+		}
+	}
+}
+
+func TestAiringWorkerChecksEveryInterval(t *testing.T) {
+	users := newTestUserService(t, models.AnimeData{MalID: 1, Title: "Airing", Type: "TV", Status: models.AiringStatusAiring})
+	newTestUser(t, users, "1")
+	if err := users.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add anime: %v", err)
+	}
+	recordTelegram(t)
+
+	const interval = 100 * time.Millisecond
+	service := NewAiringService(users.db, newTestLogger(), "test", users.client, interval)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := time.Now()
+	service.Start(ctx)
+
+	// well before the default hour, the first tick checks the watched anime
+	query := "SELECT airing_checked_at IS NOT NULL FROM media WHERE external_id = '1'"
+	for checked := false; !checked; {
+		if time.Since(started) > interval+time.Second {
+			t.Fatalf("anime not checked within %v of starting", interval+time.Second)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if err := users.db.QueryRow(context.Background(), query).Scan(&checked); err != nil {
+			t.Fatalf("failed to get media: %v", err)
+		}
+	}
+}
+
+// recordTelegram points the Bot API at a server that records who messages are sent to.
+func recordTelegram(t *testing.T) func() []int {
+	t.Helper()
+
+	var mu sync.Mutex
+	var chats []int
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.TelegramResponse
+		json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		chats = append(chats, request.ChatId)
+		mu.Unlock()
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+
+	return func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), chats...)
+	}
+}
+
+func TestAiringNotifiesWatchersOnce(t *testing.T) {
+	users := newTestUserService(t, models.AnimeData{MalID: 1, Title: "Airing", Type: "TV", Status: models.AiringStatusFinished})
+	ctx := context.Background()
+	for id, status := range map[string]models.Status{"1": models.StatusWatching, "2": models.StatusWatching, "3": models.StatusCompleted} {
+		newTestUser(t, users, id)
+		if err := users.AddToUserList(id, 1, status, nil, nil); err != nil {
+			t.Fatalf("failed to add anime for %s: %v", id, err)
+		}
+	}
+
+	// last seen airing, Jikan now says it finished
+	if _, err := users.db.Exec(ctx, "UPDATE media SET airing_status = $1", models.AiringStatusAiring); err != nil {
+		t.Fatalf("failed to set airing status: %v", err)
+	}
+	sent := recordTelegram(t)
+	service := NewAiringService(users.db, newTestLogger(), "test", users.client, 0)
+
+	if err := service.processAiringChanges(); err != nil {
+		t.Fatalf("processAiringChanges failed: %v", err)
+	}
+	if got := sent(); len(got) != 2 {
+		t.Fatalf("sent to %v, want the 2 watchers", got)
+	}
+
+	media, err := users.GetMediaByAnimeID(1)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	if media.AiringStatus != models.AiringStatusFinished {
+		t.Errorf("got airing status %q, want it stored as finished", media.AiringStatus)
+	}
+
+	// the next run and a check that still saw the old status announce nothing more
+	if err := service.processAiringChanges(); err != nil {
+		t.Fatalf("processAiringChanges failed: %v", err)
+	}
+	stale := models.Media{ID: media.ID, ExternalID: "1", Title: "Airing", AiringStatus: models.AiringStatusAiring}
+	if finished, err := service.checkMedia(ctx, stale); err != nil || finished {
+		t.Errorf("got finished %v (err %v) for an already announced anime, want false", finished, err)
+	}
+	if got := sent(); len(got) != 2 {
+		t.Errorf("sent %d notifications in total, want 2", len(got))
+	}
+}
+
+func TestAiringStillAiringIsNotAnnounced(t *testing.T) {
+	users := newTestUserService(t, models.AnimeData{MalID: 1, Title: "Airing", Type: "TV", Status: models.AiringStatusAiring})
+	newTestUser(t, users, "1")
+	if err := users.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add anime: %v", err)
+	}
+	sent := recordTelegram(t)
+	service := NewAiringService(users.db, newTestLogger(), "test", users.client, 0)
+
+	if err := service.processAiringChanges(); err != nil {
+		t.Fatalf("processAiringChanges failed: %v", err)
+	}
+	if got := sent(); len(got) != 0 {
+		t.Errorf("sent to %v, want nothing while it airs", got)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sletish/internal/models"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	aniListTimeout = 30 * time.Second
+	// maxAniListResponseSize caps an AniList response, a list of a few thousand entries is well under it
+	maxAniListResponseSize = 10 * 1024 * 1024
+)
+
+// aniListHTTPClient is shared by AniList imports so connections are reused.
+var aniListHTTPClient = &http.Client{
+	Timeout: aniListTimeout,
+	Transport: &http.Transport{
+		MaxIdleConns:          10,
+		MaxIdleConnsPerHost:   2,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+}
+
+// aniListAPIURL is a variable so tests can point AniList at a stub server.
+var aniListAPIURL = "https://graphql.anilist.co"
+
+var (
+	ErrAniListUserNotFound = errors.New("anilist user not found")
+	ErrAniListPrivate      = errors.New("anilist list is private")
+)
+
+// aniListCollectionQuery fetches every anime in a user's lists with the score on a 10 point scale.
+const aniListCollectionQuery = `query ($userName: String) {
+  MediaListCollection(userName: $userName, type: ANIME) {
+    lists {
+      entries {
+        status
+        score(format: POINT_10_DECIMAL)
+        progress
+        media { idMal title { romaji } }
+      }
+    }
+  }
+}`
+
+// aniListStatuses maps AniList list statuses to ours. Rewatching counts as watching.
+var aniListStatuses = map[string]models.Status{
+	models.AniListCurrent:   models.StatusWatching,
+	models.AniListRepeating: models.StatusWatching,
+	models.AniListCompleted: models.StatusCompleted,
+	models.AniListPaused:    models.StatusOnHold,
+	models.AniListDropped:   models.StatusDropped,
+	models.AniListPlanning:  models.StatusWatchlist,
+}
+
+// AniListStatus returns our status for an AniList list status, and false if it has none.
+func AniListStatus(status string) (models.Status, bool) {
+	mapped, ok := aniListStatuses[strings.ToUpper(status)]
+	return mapped, ok
+}
+
+// newAniListRequest builds the GraphQL request for username's anime lists.
+func newAniListRequest(username string) models.AniListRequest {
+	return models.AniListRequest{
+		Query:     aniListCollectionQuery,
+		Variables: map[string]any{"userName": username},
+	}
+}
+
+// FetchAniListEntries returns every anime in a public AniList user's lists.
+// Returns ErrAniListUserNotFound or ErrAniListPrivate when the list can't be read.
+func FetchAniListEntries(ctx context.Context, username string) ([]models.AniListEntry, error) {
+	body, err := json.Marshal(newAniListRequest(username))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anilist request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, aniListTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, aniListAPIURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anilist request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := aniListHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach anilist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := readLimited(resp.Body, maxAniListResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anilist response: %w", err)
+	}
+
+	var collection models.AniListCollectionResponse
+	if err := json.Unmarshal(respBody, &collection); err != nil {
+		return nil, fmt.Errorf("failed to decode anilist response (HTTP %d): %w", resp.StatusCode, err)
+	}
+
+	// AniList answers unknown users with a 404 and private ones with an error mentioning it
+	for _, e := range collection.Errors {
+		if strings.Contains(strings.ToLower(e.Message), "private") {
+			return nil, ErrAniListPrivate
+		}
+		if e.Status == http.StatusNotFound {
+			return nil, ErrAniListUserNotFound
+		}
+	}
+	if len(collection.Errors) > 0 {
+		return nil, fmt.Errorf("anilist error: %s", collection.Errors[0].Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("anilist returned HTTP %d", resp.StatusCode)
+	}
+	if collection.Data.MediaListCollection == nil {
+		return nil, ErrAniListUserNotFound
+	}
+
+	var entries []models.AniListEntry
+	for _, list := range collection.Data.MediaListCollection.Lists {
+		entries = append(entries, list.Entries...)
+	}
+
+	return entries, nil
+}
+
+// ImportAniList adds the anime in an AniList user's public lists to userID's list.
+// Anime already in the list are left alone, as are entries without a MyAnimeList ID.
+// Media are fetched from Jikan through the rate limiter, so big lists take a while.
+// Stops at ErrListFull or ErrServiceUnavailable, returning the counts so far with it.
+func (s *UserService) ImportAniList(ctx context.Context, userID, username string) (models.ImportResult, error) {
+	var result models.ImportResult
+
+	entries, err := FetchAniListEntries(ctx, username)
+	if err != nil {
+		return result, err
+	}
+
+	for _, entry := range entries {
+		animeID := entry.Media.IDMal
+		status, ok := AniListStatus(entry.Status)
+		if animeID <= 0 || !ok {
+			result.Skipped++
+			continue
+		}
+
+		if _, err := s.GetUserEntry(ctx, userID, animeID); err == nil {
+			result.Skipped++
+			continue
+		} else if !errors.Is(err, ErrNotInUserList) {
+			s.logger.WithError(err).WithField("anime_id", animeID).Warn("Failed to check list during import")
+			result.Failed++
+			continue
+		}
+
+		var rating *float64
+		if entry.Score >= models.MinRating && entry.Score <= models.MaxRating {
+			rating = &entry.Score
+		}
+
+		if err := s.AddToUserList(userID, animeID, status, rating, nil); err != nil {
+			// neither gets better for the entries after this one
+			if errors.Is(err, ErrListFull) || errors.Is(err, ErrServiceUnavailable) {
+				return result, err
+			}
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"anime_id": animeID,
+				"title":    entry.Media.Title.Romaji,
+			}).Warn("Failed to import anilist entry")
+			result.Failed++
+			continue
+		}
+
+		if entry.Progress > 0 {
+			if err := s.SetProgress(userID, animeID, entry.Progress); err != nil {
+				s.logger.WithError(err).WithField("anime_id", animeID).Warn("Failed to import anilist progress")
+			}
+		}
+
+		result.Imported++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sletish/internal/models"
+	"strings"
+	"testing"
+)
+
+// newTestAniList points AniList at handler until the test ends.
+func newTestAniList(t *testing.T, handler http.Handler) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	original := aniListAPIURL
+	aniListAPIURL = server.URL
+	t.Cleanup(func() { aniListAPIURL = original })
+}
+
+func TestAniListStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   models.Status
+		wantOK bool
+	}{
+		{"CURRENT", models.StatusWatching, true},
+		{"REPEATING", models.StatusWatching, true},
+		{"COMPLETED", models.StatusCompleted, true},
+		{"PAUSED", models.StatusOnHold, true},
+		{"DROPPED", models.StatusDropped, true},
+		{"PLANNING", models.StatusWatchlist, true},
+		{"current", models.StatusWatching, true},
+		{"WATCHING", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := AniListStatus(tt.status)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("AniListStatus(%q) = %q, %v, want %q, %v", tt.status, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNewAniListRequest(t *testing.T) {
+	body, err := json.Marshal(newAniListRequest("Frieren_fan"))
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+
+	var got struct {
+		Query     string            `json:"query"`
+		Variables map[string]string `json:"variables"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+	if got.Variables["userName"] != "Frieren_fan" {
+		t.Errorf("got variables %v, want the username passed as $userName", got.Variables)
+	}
+	// the username is only ever a variable, never part of the query text
+	if strings.Contains(got.Query, "Frieren_fan") {
+		t.Errorf("got query %q with the username in it", got.Query)
+	}
+	for _, want := range []string{"MediaListCollection(userName: $userName, type: ANIME)", "score(format: POINT_10_DECIMAL)", "idMal"} {
+		if !strings.Contains(got.Query, want) {
+			t.Errorf("query is missing %q:\n%s", want, got.Query)
+		}
+	}
+}
+
+func TestFetchAniListEntries(t *testing.T) {
+	var request models.AniListRequest
+	newTestAniList(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with Content-Type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"data":{"MediaListCollection":{"lists":[
+			{"entries":[{"status":"CURRENT","score":8.5,"progress":3,"media":{"idMal":5114,"title":{"romaji":"Hagane no Renkinjutsushi"}}}]},
+			{"entries":[{"status":"PLANNING","score":0,"progress":0,"media":{"idMal":null,"title":{"romaji":"AniList Only"}}}]}
+		]}}}`))
+	}))
+
+	entries, err := FetchAniListEntries(context.Background(), "tester")
+	if err != nil {
+		t.Fatalf("FetchAniListEntries failed: %v", err)
+	}
+	if request.Variables["userName"] != "tester" {
+		t.Errorf("got variables %v, want the username", request.Variables)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want both lists flattened", len(entries))
+	}
+	if e := entries[0]; e.Status != "CURRENT" || e.Score != 8.5 || e.Progress != 3 || e.Media.IDMal != 5114 {
+		t.Errorf("got %+v, want the watching entry", e)
+	}
+	if entries[1].Media.IDMal != 0 {
+		t.Errorf("got MAL ID %d, want 0 without a mapping", entries[1].Media.IDMal)
+	}
+}
+
+func TestFetchAniListEntriesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unknown user", http.StatusNotFound, `{"data":{"MediaListCollection":null},"errors":[{"message":"User not found","status":404}]}`, ErrAniListUserNotFound},
+		{"private list", http.StatusNotFound, `{"data":{"MediaListCollection":null},"errors":[{"message":"Private User","status":404}]}`, ErrAniListPrivate},
+		{"no collection", http.StatusOK, `{"data":{"MediaListCollection":null}}`, ErrAniListUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestAniList(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+
+			if _, err := FetchAniListEntries(context.Background(), "tester"); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// anything else is reported as it is
+	newTestAniList(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"data":null,"errors":[{"message":"Too Many Requests.","status":429}]}`))
+	}))
+	_, err := FetchAniListEntries(context.Background(), "tester")
+	if err == nil || errors.Is(err, ErrAniListUserNotFound) || !strings.Contains(err.Error(), "Too Many Requests") {
+		t.Errorf("got %v, want the AniList error passed on", err)
+	}
+}
+
+func TestFetchAniListEntriesRejectsOversizedResponse(t *testing.T) {
+	newTestAniList(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"MediaListCollection":{"lists":[{"entries":[`))
+		w.Write([]byte(strings.Repeat(" ", maxAniListResponseSize)))
+		w.Write([]byte(`]}]}}}`))
+	}))
+
+	_, err := FetchAniListEntries(context.Background(), "tester")
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("got %v, want the response rejected as too large", err)
+	}
+}
+
+func TestImportAniList(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	if err := s.AddToUserList("1", 3, models.StatusDropped, nil, nil); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+
+	newTestAniList(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"MediaListCollection":{"lists":[{"entries":[
+			{"status":"COMPLETED","score":9,"progress":12,"media":{"idMal":1}},
+			{"status":"PAUSED","score":0,"progress":0,"media":{"idMal":2}},
+			{"status":"CURRENT","score":7,"progress":1,"media":{"idMal":3}},
+			{"status":"PLANNING","score":0,"progress":0,"media":{"idMal":0}}
+		]}]}}}`))
+	}))
+
+	result, err := s.ImportAniList(context.Background(), "1", "tester")
+	if err != nil {
+		t.Fatalf("ImportAniList failed: %v", err)
+	}
+	if want := (models.ImportResult{Imported: 2, Skipped: 2}); result != want {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+
+	ctx := context.Background()
+	want := map[int]models.Status{1: models.StatusCompleted, 2: models.StatusOnHold, 3: models.StatusDropped}
+	for id, status := range want {
+		entry, err := s.GetUserEntry(ctx, "1", id)
+		if err != nil {
+			t.Fatalf("failed to get entry %d: %v", id, err)
+		}
+		if entry.UserMedia.Status != status {
+			t.Errorf("%d got status %q, want %q", id, entry.UserMedia.Status, status)
+		}
+	}
+	if entry, _ := s.GetUserEntry(ctx, "1", 1); entry.UserMedia.Rating != 9 || entry.UserMedia.EpisodesWatched != 12 {
+		t.Errorf("got rating %v and %d episodes, want the AniList score and progress", entry.UserMedia.Rating, entry.UserMedia.EpisodesWatched)
+	}
+}
@@ -1,15 +1,22 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"sletish/internal/models"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
@@ -17,25 +24,60 @@ import (
 )
 
 const (
-	jikanAPIURL        = "https://api.jikan.moe/v4"
-	defaultTimeout     = 30 * time.Second
-	rateLimitDelay     = 1 * time.Second
-	maxRetries         = 3
-	retryDelay         = 2 * time.Second
-	userAgent          = "AnimeTrackerBot/1.0"
-	maxSearchResults   = 10
-	searchCachePrefix  = "anime:search:"
-	detailsCachePrefix = "anime:details:"
-	searchCacheTTL     = 4 * time.Hour
-	detailsCacheTTL    = 24 * time.Hour
+	jikanAPIURL         = "https://api.jikan.moe/v4"
+	defaultTimeout      = 30 * time.Second
+	rateLimitDelay      = 1 * time.Second
+	maxRetries          = 3
+	retryDelay          = 2 * time.Second
+	userAgent           = "AnimeTrackerBot/1.0"
+	maxSearchResults    = 10
+	searchCachePrefix   = "anime:search:v2:" // v2: cached as models.SearchResult
+	detailsCachePrefix  = "anime:details:"
+	topCachePrefix      = "anime:top:"
+	recsCachePrefix     = "anime:recs:"
+	charsCachePrefix    = "anime:characters:"
+	episodesCachePrefix = "anime:episodes:"
+	topCacheTTL         = 6 * time.Hour
+	searchCacheTTL      = 4 * time.Hour
+	detailsCacheTTL     = 24 * time.Hour
+	maxResponseSize     = 5 * 1024 * 1024 // 5MB, limit response size to prevent memory issue
 )
 
+// AnimeProvider looks up anime from an external catalogue. Client implements it
+// against Jikan; the bot handler depends on this so it can run without the network.
+type AnimeProvider interface {
+	SearchAnime(query string, filters models.SearchFilters) (*models.SearchResult, error)
+	GetAnimeByID(id int) (*models.AnimeData, error)
+	GetTopAnime(filter string, page int, sfw bool) (*models.SearchResult, error)
+	WarmCache(ctx context.Context, queries []string) (int, error)
+	GetRecommendations(animeID int) ([]models.Recommendation, error)
+	GetAnimeCharacters(animeID int) ([]models.Character, error)
+	GetAnimeEpisodes(animeID, page int) (*models.EpisodePage, error)
+}
+
+// TopPageSize is how many anime GetTopAnime asks for per page.
+const TopPageSize = 10
+
+// Filters accepted by GetTopAnime, the empty filter ranks all anime by score.
+const (
+	TopFilterAiring   = "airing"
+	TopFilterUpcoming = "upcoming"
+)
+
+// ErrInvalidTopFilter is returned by GetTopAnime for filters other than the TopFilter ones.
+var ErrInvalidTopFilter = errors.New("invalid top anime filter")
+
+// ErrNotFound is returned when Jikan answers 404, e.g. for an anime ID it doesn't have.
+var ErrNotFound = errors.New("not found on the anime service")
+
 type Client struct {
 	baseURL     string
 	httpClient  *http.Client
 	logger      *logrus.Logger
 	rateLimiter *rate.Limiter
 	redis       *redis.Client
+	breaker     *circuitBreaker
+	retryDelay  time.Duration
 }
 
 type ClientConfig struct {
@@ -81,26 +123,69 @@ func NewClientWithConfig(config *ClientConfig) *Client {
 		logger:      config.Logger,
 		rateLimiter: rate.NewLimiter(rate.Limit(1)/rate.Limit(time.Duration(config.RateLimit).Seconds()), 1),
 		redis:       config.Redis,
+		breaker:     jikanBreaker,
+		retryDelay:  config.RetryDelay,
+	}
+
+	if client.retryDelay <= 0 {
+		client.retryDelay = retryDelay
 	}
 
 	return client
 }
 
-func (c *Client) SearchAnime(query string) (*models.JikanSearchResponse, error) {
-	if strings.TrimSpace(query) == "" {
+// SanitizeSearchQuery strips control characters from a search query and collapses
+// runs of whitespace, so equivalent queries hit Jikan and the cache the same way.
+func SanitizeSearchQuery(query string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, query)
+
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+func (c *Client) SearchAnime(query string, filters models.SearchFilters) (*models.SearchResult, error) {
+	return c.searchAnime(context.Background(), query, filters)
+}
+
+func (c *Client) searchAnime(ctx context.Context, query string, filters models.SearchFilters) (*models.SearchResult, error) {
+	query = SanitizeSearchQuery(query)
+	if query == "" {
 		return nil, fmt.Errorf("search query cannot be empty")
 	}
 
-	c.logger.WithField("query", query).Info("Searching anime...")
+	c.logger.WithFields(logrus.Fields{
+		"query": query,
+		"type":  filters.Type,
+		"year":  filters.Year,
+	}).Info("Searching anime...")
 
 	// check cache first
 	cacheKey := searchCachePrefix + query
+	if filters.Type != "" {
+		cacheKey += "|type=" + filters.Type
+	}
+	if filters.Year > 0 {
+		cacheKey += "|year=" + strconv.Itoa(filters.Year)
+	}
+	if filters.SFW {
+		cacheKey += "|sfw"
+	}
+	if filters.Page > 1 {
+		cacheKey += "|page=" + strconv.Itoa(filters.Page)
+	}
 	if c.redis != nil {
-		cached, err := c.redis.Get(context.Background(), cacheKey).Result()
+		cached, err := c.redis.Get(ctx, cacheKey).Result()
 		if err == nil {
 			c.logger.WithField("query", query).Info("Retrieved search results from cache")
 
-			var cachedResponse models.JikanSearchResponse
+			var cachedResponse models.SearchResult
 			if err := json.Unmarshal([]byte(cached), &cachedResponse); err == nil {
 				return &cachedResponse, nil
 			} else {
@@ -112,30 +197,27 @@ func (c *Client) SearchAnime(query string) (*models.JikanSearchResponse, error)
 	}
 
 	// if no cache, hit API
-	params := url.Values{}
-	params.Set("q", query)
-	params.Set("limit", strconv.Itoa(maxSearchResults))
-	params.Set("sort", "desc")
+	searchURL := fmt.Sprintf("%s/anime?%s", c.baseURL, searchParams(query, filters).Encode())
 
-	searchURL := fmt.Sprintf("%s/anime?%s", c.baseURL, params.Encode())
-
-	resp, err := c.makeRequest(searchURL)
+	resp, err := c.makeRequest(ctx, searchURL)
 	if err != nil {
 		return nil, err
 	}
 
-	var searchResult models.JikanSearchResponse
-	if err := json.Unmarshal(resp, &searchResult); err != nil {
+	var jikanResponse models.JikanSearchResponse
+	if err := json.Unmarshal(resp, &jikanResponse); err != nil {
 		return nil, err
 	}
 
+	searchResult := searchResultFromJikan(jikanResponse)
+
 	// cache results
 	if c.redis != nil {
 		responseJSON, err := json.Marshal(searchResult)
 		if err != nil {
 			c.logger.WithError(err).Warn("Failed to marshal search result for caching")
 		} else {
-			if err := c.redis.Set(context.Background(), cacheKey, responseJSON, searchCacheTTL).Err(); err != nil {
+			if err := c.redis.Set(ctx, cacheKey, responseJSON, searchCacheTTL).Err(); err != nil {
 				c.logger.WithError(err).Warn("Failed to write search result to cache")
 			} else {
 				c.logger.WithField("query", query).Debug("Search result cached successfully")
@@ -146,6 +228,376 @@ func (c *Client) SearchAnime(query string) (*models.JikanSearchResponse, error)
 	return &searchResult, nil
 }
 
+// GetTopAnime returns a page of Jikan's top anime, optionally narrowed to currently
+// airing or upcoming titles. Results are cached per filter and page.
+func (c *Client) GetTopAnime(filter string, page int, sfw bool) (*models.SearchResult, error) {
+	params, err := topParams(filter, page, sfw)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"filter": filter,
+		"page":   page,
+	}).Info("Fetching top anime...")
+
+	cacheKey := fmt.Sprintf("%s%s:%d", topCachePrefix, filter, page)
+	if sfw {
+		cacheKey += "|sfw"
+	}
+	if c.redis != nil {
+		cached, err := c.redis.Get(context.Background(), cacheKey).Result()
+		if err == nil {
+			var cachedResponse models.SearchResult
+			if err := json.Unmarshal([]byte(cached), &cachedResponse); err == nil {
+				return &cachedResponse, nil
+			} else {
+				c.logger.WithError(err).Warn("Failed to unmarshal cached top anime")
+			}
+		} else if err != redis.Nil {
+			c.logger.WithError(err).Warn("Failed to read from Redis")
+		}
+	}
+
+	resp, err := c.makeRequest(context.Background(), fmt.Sprintf("%s/top/anime?%s", c.baseURL, params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top anime: %w", err)
+	}
+
+	var jikanResponse models.JikanSearchResponse
+	if err := json.Unmarshal(resp, &jikanResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal top anime response: %w", err)
+	}
+
+	result := searchResultFromJikan(jikanResponse)
+
+	if c.redis != nil {
+		responseJSON, err := json.Marshal(result)
+		if err != nil {
+			c.logger.WithError(err).Warn("Failed to marshal top anime for caching")
+		} else if err := c.redis.Set(context.Background(), cacheKey, responseJSON, topCacheTTL).Err(); err != nil {
+			c.logger.WithError(err).Warn("Failed to write top anime to cache")
+		}
+	}
+
+	return &result, nil
+}
+
+// topParams builds the Jikan query parameters for a top anime page.
+func topParams(filter string, page int, sfw bool) (url.Values, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("invalid page %d", page)
+	}
+
+	params := url.Values{}
+	switch filter {
+	case "":
+	case TopFilterAiring, TopFilterUpcoming:
+		params.Set("filter", filter)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTopFilter, filter)
+	}
+
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(TopPageSize))
+	if sfw {
+		params.Set("sfw", "true")
+	}
+
+	return params, nil
+}
+
+// WarmCache runs each query through SearchAnime so the results are cached before
+// users ask for them. Queries are cached with adult content filtered out, like most
+// users search. Requests go through the usual rate limiter, so this takes about a
+// second per uncached query. Returns how many queries are now cached; a failed query
+// doesn't stop the rest, but if Jikan is down or ctx is done warming stops early.
+func (c *Client) WarmCache(ctx context.Context, queries []string) (int, error) {
+	if c.redis == nil {
+		return 0, fmt.Errorf("no cache configured")
+	}
+
+	warmed := 0
+	var lastErr error
+	for _, query := range queries {
+		if err := ctx.Err(); err != nil {
+			return warmed, err
+		}
+
+		if _, err := c.searchAnime(ctx, query, models.SearchFilters{SFW: true}); err != nil {
+			c.logger.WithError(err).WithField("query", query).Warn("Failed to warm search cache")
+			if errors.Is(err, ErrServiceUnavailable) || ctx.Err() != nil {
+				return warmed, err
+			}
+			lastErr = err
+			continue
+		}
+		warmed++
+	}
+
+	if warmed == 0 && lastErr != nil {
+		return 0, fmt.Errorf("failed to warm any query: %w", lastErr)
+	}
+
+	return warmed, nil
+}
+
+// GetAnimeCharacters returns an anime's whole cast, main characters first. Cached like anime details.
+func (c *Client) GetAnimeCharacters(animeID int) ([]models.Character, error) {
+	if animeID <= 0 {
+		return nil, fmt.Errorf("invalid anime ID: %d", animeID)
+	}
+
+	var characters []models.Character
+	cacheKey := charsCachePrefix + strconv.Itoa(animeID)
+	if c.readCache(cacheKey, &characters) {
+		return characters, nil
+	}
+
+	resp, err := c.makeRequest(context.Background(), fmt.Sprintf("%s/anime/%d/characters", c.baseURL, animeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get characters for %d: %w", animeID, err)
+	}
+
+	var jikanResponse models.JikanCharactersResponse
+	if err := json.Unmarshal(resp, &jikanResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal characters for %d: %w", animeID, err)
+	}
+
+	characters = make([]models.Character, 0, len(jikanResponse.Data))
+	for _, item := range jikanResponse.Data {
+		characters = append(characters, models.Character{
+			MalID: item.Character.MalID,
+			Name:  item.Character.Name,
+			Role:  item.Role,
+		})
+	}
+
+	// Jikan orders by favorites, main cast is more useful at the top
+	slices.SortStableFunc(characters, func(a, b models.Character) int {
+		return cmp.Compare(characterRoleRank(a.Role), characterRoleRank(b.Role))
+	})
+
+	c.writeCache(cacheKey, characters, detailsCacheTTL)
+	return characters, nil
+}
+
+func characterRoleRank(role string) int {
+	if role == "Main" {
+		return 0
+	}
+	return 1
+}
+
+// GetAnimeEpisodes returns one Jikan page of an anime's episodes (1-based,
+// models.JikanEpisodesPageSize per page). Cached like anime details.
+func (c *Client) GetAnimeEpisodes(animeID, page int) (*models.EpisodePage, error) {
+	if animeID <= 0 {
+		return nil, fmt.Errorf("invalid anime ID: %d", animeID)
+	}
+	page = max(page, 1)
+
+	var episodes models.EpisodePage
+	cacheKey := fmt.Sprintf("%s%d:%d", episodesCachePrefix, animeID, page)
+	if c.readCache(cacheKey, &episodes) {
+		return &episodes, nil
+	}
+
+	resp, err := c.makeRequest(context.Background(), fmt.Sprintf("%s/anime/%d/episodes?page=%d", c.baseURL, animeID, page))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get episodes for %d: %w", animeID, err)
+	}
+
+	var jikanResponse models.JikanEpisodesResponse
+	if err := json.Unmarshal(resp, &jikanResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal episodes for %d: %w", animeID, err)
+	}
+
+	episodes = models.EpisodePage{
+		Episodes: jikanResponse.Data,
+		Page:     page,
+		LastPage: max(jikanResponse.Pagination.LastVisiblePage, page),
+		HasNext:  jikanResponse.Pagination.HasNextPage,
+	}
+
+	c.writeCache(cacheKey, episodes, detailsCacheTTL)
+	return &episodes, nil
+}
+
+// readCache decodes the cached value at key into dest. Reports whether it was there.
+func (c *Client) readCache(key string, dest any) bool {
+	if c.redis == nil {
+		return false
+	}
+
+	cached, err := c.redis.Get(context.Background(), key).Result()
+	if err != nil {
+		if err != redis.Nil {
+			c.logger.WithError(err).Warn("Failed to read from Redis")
+		}
+		return false
+	}
+
+	if err := json.Unmarshal([]byte(cached), dest); err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to unmarshal cached value")
+		return false
+	}
+
+	return true
+}
+
+func (c *Client) writeCache(key string, value any, ttl time.Duration) {
+	if c.redis == nil {
+		return
+	}
+
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to marshal value for caching")
+		return
+	}
+
+	if err := c.redis.Set(context.Background(), key, valueJSON, ttl).Err(); err != nil {
+		c.logger.WithError(err).WithField("key", key).Warn("Failed to write to cache")
+	}
+}
+
+// GetRandomAnime returns a random anime. Never cached, every call is a new draw.
+// With sfw set, Jikan only draws from anime that aren't rated Rx.
+func (c *Client) GetRandomAnime(sfw bool) (*models.AnimeData, error) {
+	reqURL := c.baseURL + "/random/anime"
+	if sfw {
+		reqURL += "?sfw=true"
+	}
+
+	resp, err := c.makeRequest(context.Background(), reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get random anime: %w", err)
+	}
+
+	var animeResp struct {
+		Data models.AnimeData `json:"data"`
+	}
+
+	if err := json.Unmarshal(resp, &animeResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal random anime: %w", err)
+	}
+
+	if !fillMissingTitle(&animeResp.Data) {
+		animeResp.Data.Title = fmt.Sprintf("Anime #%d", animeResp.Data.MalID)
+	}
+
+	return &animeResp.Data, nil
+}
+
+// GetRecommendations returns the titles MyAnimeList users recommend for fans of an anime,
+// most voted first. Cached like anime details.
+func (c *Client) GetRecommendations(animeID int) ([]models.Recommendation, error) {
+	if animeID <= 0 {
+		return nil, fmt.Errorf("invalid anime ID: %d", animeID)
+	}
+
+	cacheKey := recsCachePrefix + strconv.Itoa(animeID)
+	if c.redis != nil {
+		cached, err := c.redis.Get(context.Background(), cacheKey).Result()
+		if err == nil {
+			var recs []models.Recommendation
+			if err := json.Unmarshal([]byte(cached), &recs); err == nil {
+				return recs, nil
+			} else {
+				c.logger.WithError(err).Warn("Failed to unmarshal cached recommendations")
+			}
+		} else if err != redis.Nil {
+			c.logger.WithError(err).Warn("Failed to read from Redis")
+		}
+	}
+
+	resp, err := c.makeRequest(context.Background(), fmt.Sprintf("%s/anime/%d/recommendations", c.baseURL, animeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations for %d: %w", animeID, err)
+	}
+
+	var jikanResponse models.JikanRecommendationsResponse
+	if err := json.Unmarshal(resp, &jikanResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recommendations for %d: %w", animeID, err)
+	}
+
+	recs := make([]models.Recommendation, 0, len(jikanResponse.Data))
+	for _, item := range jikanResponse.Data {
+		if strings.TrimSpace(item.Entry.Title) == "" {
+			continue
+		}
+		recs = append(recs, models.Recommendation{
+			MalID: item.Entry.MalID,
+			Title: item.Entry.Title,
+			Votes: item.Votes,
+		})
+	}
+
+	if c.redis != nil {
+		recsJSON, err := json.Marshal(recs)
+		if err != nil {
+			c.logger.WithError(err).Warn("Failed to marshal recommendations for caching")
+		} else if err := c.redis.Set(context.Background(), cacheKey, recsJSON, detailsCacheTTL).Err(); err != nil {
+			c.logger.WithError(err).Warn("Failed to write recommendations to cache")
+		}
+	}
+
+	return recs, nil
+}
+
+// searchResultFromJikan maps a Jikan search response onto the provider-neutral SearchResult.
+// Entries without any title are dropped, there's nothing useful to show for them.
+func searchResultFromJikan(resp models.JikanSearchResponse) models.SearchResult {
+	items := make([]models.AnimeData, 0, len(resp.Data))
+	for _, anime := range resp.Data {
+		if !fillMissingTitle(&anime) {
+			continue
+		}
+		items = append(items, anime)
+	}
+
+	return models.SearchResult{
+		Items:   items,
+		HasNext: resp.Pagination.HasNextPage,
+		Total:   resp.Pagination.Items.Total,
+	}
+}
+
+// fillMissingTitle falls back to the English title when Jikan sends a null or blank title.
+// Returns false if the anime has no usable title at all.
+func fillMissingTitle(anime *models.AnimeData) bool {
+	anime.Title = strings.TrimSpace(anime.Title)
+	if anime.Title == "" {
+		anime.Title = strings.TrimSpace(anime.TitleEnglish)
+	}
+	return anime.Title != ""
+}
+
+// searchParams builds the Jikan query parameters for a search.
+func searchParams(query string, filters models.SearchFilters) url.Values {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("limit", strconv.Itoa(maxSearchResults))
+	params.Set("sort", "desc")
+
+	if filters.Type != "" {
+		params.Set("type", filters.Type)
+	}
+	if filters.Year > 0 {
+		params.Set("start_date", fmt.Sprintf("%d-01-01", filters.Year))
+		params.Set("end_date", fmt.Sprintf("%d-12-31", filters.Year))
+	}
+	if filters.SFW {
+		params.Set("sfw", "true")
+	}
+	if filters.Page > 1 {
+		params.Set("page", strconv.Itoa(filters.Page))
+	}
+
+	return params
+}
+
 func FormatAnimeMessage(animes []models.AnimeData) string {
 	if len(animes) == 0 {
 		return "No anime found for your search query."
@@ -161,7 +613,7 @@ func FormatAnimeMessage(animes []models.AnimeData) string {
 		}
 
 		// Anime title with number
-		message.WriteString(fmt.Sprintf("<b>%d. %s</b>\n", i+1, anime.Title))
+		message.WriteString(fmt.Sprintf("<b>%d. %s</b>\n", i+1, html.EscapeString(anime.Title)))
 
 		// ID for adding to list
 		message.WriteString(fmt.Sprintf("🆔 ID: <code>%d</code>", anime.MalID))
@@ -201,20 +653,14 @@ func FormatAnimeMessage(animes []models.AnimeData) string {
 			for _, genre := range anime.Genres {
 				genres = append(genres, genre.Name)
 			}
-			genreText := strings.Join(genres, ", ")
-			if len(genreText) > 50 {
-				genreText = genreText[:50] + "..."
-			}
-			message.WriteString(fmt.Sprintf("🏷 %s\n", genreText))
+			genreText := TruncateText(strings.Join(genres, ", "), 50)
+			message.WriteString(fmt.Sprintf("🏷 %s\n", html.EscapeString(genreText)))
 		}
 
 		// Synopsis (shortened)
 		if anime.Synopsis != "" {
-			synopsis := anime.Synopsis
-			if len(synopsis) > 150 {
-				synopsis = synopsis[:150] + "..."
-			}
-			message.WriteString(fmt.Sprintf("📝 %s\n", synopsis))
+			synopsis := TruncateText(anime.Synopsis, 150)
+			message.WriteString(fmt.Sprintf("📝 %s\n", html.EscapeString(synopsis)))
 		}
 
 		// Link to MyAnimeList
@@ -231,16 +677,32 @@ func FormatAnimeMessage(animes []models.AnimeData) string {
 	return message.String()
 }
 
-func (c *Client) makeRequest(url string) ([]byte, error) {
+// makeRequest GETs url, retrying server errors and rate limits; other 4xx answers fail
+// at once, a 404 with ErrNotFound. While Jikan looks to be down (several requests
+// in a row failed outright) it fails fast with ErrServiceUnavailable instead. Waits
+// for the rate limiter and between retries give up once ctx is done.
+func (c *Client) makeRequest(ctx context.Context, url string) ([]byte, error) {
+	if !c.breaker.Allow() {
+		return nil, ErrServiceUnavailable
+	}
+
 	var rErr error
+	// whether the last failure points at Jikan being down rather than a bad request
+	upstreamDown := false
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		if !c.rateLimiter.Allow() {
 			c.logger.Debug("Rate limit: sleeping")
-			c.rateLimiter.Wait(context.Background())
+			if err := c.rateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
 		}
 
-		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 		if err != nil {
 			rErr = fmt.Errorf("failed to create request: %w", err)
 			continue
@@ -251,17 +713,33 @@ func (c *Client) makeRequest(url string) ([]byte, error) {
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			// our own cancellation says nothing about Jikan
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			rErr = fmt.Errorf("failed to make HTTP request: %w", err)
+			upstreamDown = true
 			c.retryLogger(attempt, url, err)
-			c.waitForRetry(attempt)
+			c.waitForRetry(ctx, attempt)
 			continue
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
+
+			// a bad request or a missing anime won't change on a retry, a rate limit might
+			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				c.breaker.Success()
+				if resp.StatusCode == http.StatusNotFound {
+					return nil, ErrNotFound
+				}
+				return nil, fmt.Errorf("API returned status code %d", resp.StatusCode)
+			}
+
 			rErr = fmt.Errorf("API returned status code %d", resp.StatusCode)
-			c.retryLogger(attempt, url, err)
-			c.waitForRetry(attempt)
+			upstreamDown = resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+			c.retryLogger(attempt, url, rErr)
+			c.waitForRetry(ctx, attempt)
 			continue
 		}
 
@@ -270,8 +748,9 @@ func (c *Client) makeRequest(url string) ([]byte, error) {
 
 		if err != nil {
 			rErr = fmt.Errorf("failed to read response body: %w", err)
+			upstreamDown = true
 			c.retryLogger(attempt, url, err)
-			c.waitForRetry(attempt)
+			c.waitForRetry(ctx, attempt)
 			continue
 		}
 
@@ -282,9 +761,19 @@ func (c *Client) makeRequest(url string) ([]byte, error) {
 			"response_size": len(body),
 		}).Debug("API request successful")
 
+		c.breaker.Success()
 		return body, nil
 	}
 
+	if upstreamDown {
+		if c.breaker.Failure() {
+			c.logger.WithField("cooldown", breakerCooldown).Warn("Jikan looks to be down, failing fast for a while")
+		}
+	} else {
+		// Jikan answered, it just didn't like the request
+		c.breaker.Success()
+	}
+
 	return nil, fmt.Errorf("failed %d, attempts: %w", maxRetries, rErr)
 }
 
@@ -296,53 +785,51 @@ func (c *Client) retryLogger(attempt int, url string, err error) {
 	}).Warn("API request failed, retrying...")
 }
 
+// readRespBody reads the whole response body, rejecting bodies over maxResponseSize.
+// Content-Length isn't trusted (chunked responses report -1), the limit is enforced
+// on the bytes actually read.
 func (c *Client) readRespBody(resp *http.Response) ([]byte, error) {
-	// limit response size to prevent memory issue
-	const maxResponseSize = 5 * 1024 * 1024 // 5MB
+	return readLimited(resp.Body, maxResponseSize)
+}
 
-	if resp.ContentLength > maxResponseSize {
-		return nil, fmt.Errorf("response too large: %d bytes", resp.ContentLength)
+// readLimited reads r to the end, failing if it holds more than limit bytes.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	// read one byte past the limit so an oversized body can be told apart from one that fits exactly
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// read with size limit
-
-	var initialCap int64 = 1024 // Default initial capacity
-	if resp.ContentLength > 0 && resp.ContentLength <= maxResponseSize {
-		initialCap = resp.ContentLength
-	}
-	body := make([]byte, 0, initialCap)
-
-	buf := make([]byte, 4096)
-	totalRead := 0
-
-	for {
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			totalRead += n
-			if totalRead > maxResponseSize {
-				return nil, fmt.Errorf("response too large: exceeded % bytes", maxResponseSize)
-			}
-			body = append(body, buf[:n]...)
-		}
-		if err != nil {
-			if err.Error() == "EOF" {
-				break
-			}
-			return nil, err
-		}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("response too large: exceeded %d bytes", limit)
 	}
 
 	return body, nil
 }
 
-func (c *Client) waitForRetry(attempt int) {
+func (c *Client) waitForRetry(ctx context.Context, attempt int) {
 	if attempt < maxRetries-1 {
-		delay := time.Duration(attempt+1) * retryDelay
+		delay := retryBackoff(attempt, c.retryDelay)
 		c.logger.WithField("delay", delay).Debug("waiting before retry")
-		time.Sleep(delay)
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
 	}
 }
 
+// retryBackoff returns how long to wait before retrying after the given attempt.
+// The linear backoff is jittered into [backoff/2, backoff) so concurrent failed
+// requests don't all retry against Jikan at the same moment.
+func retryBackoff(attempt int, base time.Duration) time.Duration {
+	backoff := time.Duration(attempt+1) * base
+	half := backoff / 2
+	return half + rand.N(backoff-half)
+}
+
 func (c *Client) GetAnimeByID(id int) (*models.AnimeData, error) {
 	if id <= 0 {
 		return nil, fmt.Errorf("invalid anime ID: %d", id)
@@ -370,7 +857,7 @@ func (c *Client) GetAnimeByID(id int) (*models.AnimeData, error) {
 
 	reqURL := fmt.Sprintf("%s/anime/%d", c.baseURL, id)
 
-	resp, err := c.makeRequest(reqURL)
+	resp, err := c.makeRequest(context.Background(), reqURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get anime by ID %d: %w", id, err)
 	}
@@ -383,6 +870,11 @@ func (c *Client) GetAnimeByID(id int) (*models.AnimeData, error) {
 		return nil, fmt.Errorf("failed to unmarshal anime response for ID %d: %w", id, err)
 	}
 
+	if !fillMissingTitle(&animeResp.Data) {
+		// still usable for lists and reminders, but never show a blank title
+		animeResp.Data.Title = fmt.Sprintf("Anime #%d", id)
+	}
+
 	if c.redis != nil {
 		animeJSON, err := json.Marshal(animeResp.Data)
 		if err != nil {
@@ -402,4 +894,4 @@ func (c *Client) GetAnimeByID(id int) (*models.AnimeData, error) {
 	}).Info("Anime details fetched successfully")
 
 	return &animeResp.Data, nil
-}
\ No newline at end of file
+}
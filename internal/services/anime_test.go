@@ -0,0 +1,520 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sletish/internal/models"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// newTestClient returns a Client talking to handler instead of Jikan, with its own
+// breaker and retry delays short enough for tests.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClientWithConfig(&ClientConfig{
+		BaseURL:    server.URL,
+		Timeout:    5 * time.Second,
+		RateLimit:  time.Millisecond,
+		RetryDelay: time.Millisecond,
+		Logger:     newTestLogger(),
+	})
+	client.breaker = newCircuitBreaker(breakerThreshold, breakerCooldown)
+	return client
+}
+
+func TestMakeRequestNonOKStatusDoesNotPanic(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantCalls int32
+	}{
+		{http.StatusNotFound, 1},
+		{http.StatusBadRequest, 1},
+		{http.StatusTooManyRequests, maxRetries},
+		{http.StatusServiceUnavailable, maxRetries},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var calls atomic.Int32
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+
+			_, err := client.GetAnimeByID(1)
+			if err == nil {
+				t.Fatal("GetAnimeByID succeeded against a failing API")
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("API called %d times, want %d", got, tt.wantCalls)
+			}
+			if notFound := errors.Is(err, ErrNotFound); notFound != (tt.status == http.StatusNotFound) {
+				t.Errorf("got %v, want ErrNotFound only for a 404", err)
+			}
+		})
+	}
+}
+
+func TestMakeRequestServerErrorsOpenBreaker(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	for i := 0; i < breakerThreshold; i++ {
+		if _, err := client.GetAnimeByID(1); err == nil || errors.Is(err, ErrServiceUnavailable) {
+			t.Fatalf("request %d: got %v, want a failed request", i+1, err)
+		}
+	}
+
+	before := calls.Load()
+	_, err := client.GetAnimeByID(1)
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("got %v once the breaker should be open, want ErrServiceUnavailable", err)
+	}
+	if calls.Load() != before {
+		t.Error("open breaker still contacted the API")
+	}
+}
+
+func TestMakeRequestClientErrorsDontOpenBreaker(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	for i := 0; i < breakerThreshold+1; i++ {
+		if _, err := client.GetAnimeByID(1); errors.Is(err, ErrServiceUnavailable) {
+			t.Fatalf("request %d failed fast, a 404 shouldn't open the breaker", i+1)
+		}
+	}
+}
+
+func TestSearchParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters models.SearchFilters
+		want    map[string]string // "" means the param must be absent
+	}{
+		{"none", models.SearchFilters{}, map[string]string{"q": "akira", "type": "", "start_date": "", "end_date": "", "sfw": "", "page": ""}},
+		{"type", models.SearchFilters{Type: "movie"}, map[string]string{"type": "movie"}},
+		{"year", models.SearchFilters{Year: 2019}, map[string]string{"start_date": "2019-01-01", "end_date": "2019-12-31"}},
+		{"sfw and page", models.SearchFilters{SFW: true, Page: 3}, map[string]string{"sfw": "true", "page": "3"}},
+		{"first page", models.SearchFilters{Page: 1}, map[string]string{"page": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := searchParams("akira", tt.filters)
+			for key, want := range tt.want {
+				if got := params.Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchAnimeSendsFilters(t *testing.T) {
+	var query url.Values
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"data":[],"pagination":{}}`))
+	}))
+
+	if _, err := client.SearchAnime("akira", models.SearchFilters{Type: "movie", Year: 1988}); err != nil {
+		t.Fatalf("SearchAnime failed: %v", err)
+	}
+	if query.Get("q") != "akira" || query.Get("type") != "movie" || query.Get("start_date") != "1988-01-01" || query.Get("end_date") != "1988-12-31" {
+		t.Errorf("got query %v, want akira movies from 1988", query)
+	}
+}
+
+func TestRetryBackoffIsJittered(t *testing.T) {
+	const base = 100 * time.Millisecond
+
+	for attempt := range 3 {
+		backoff := time.Duration(attempt+1) * base
+		seen := make(map[time.Duration]bool)
+		for range 200 {
+			delay := retryBackoff(attempt, base)
+			if delay < backoff/2 || delay >= backoff {
+				t.Fatalf("attempt %d: got delay %v, want within [%v, %v)", attempt, delay, backoff/2, backoff)
+			}
+			seen[delay] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("attempt %d: got the same delay every time, want it jittered", attempt)
+		}
+	}
+}
+
+func TestReadLimited(t *testing.T) {
+	const limit = 16
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"normal", `{"data":[]}`, false},
+		{"empty", "", false},
+		{"exactly at the limit", strings.Repeat("a", limit), false},
+		{"one byte over", strings.Repeat("a", limit+1), true},
+		{"far over", strings.Repeat("a", 10*limit), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := readLimited(strings.NewReader(tt.body), limit)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "exceeded 16 bytes") {
+					t.Errorf("got %v, want the body rejected as too large", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readLimited failed: %v", err)
+			}
+			if string(body) != tt.body {
+				t.Errorf("got %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestReadLimitedReadError(t *testing.T) {
+	_, err := readLimited(iotest.ErrReader(errors.New("connection reset")), 16)
+	if err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("got %v, want the read error", err)
+	}
+}
+
+func TestReadRespBodyIgnoresContentLength(t *testing.T) {
+	oversized := strings.Repeat("a", maxResponseSize+1)
+
+	tests := []struct {
+		name          string
+		contentLength int64
+	}{
+		{"chunked", -1},
+		{"understated", 100},
+	}
+
+	client := NewClientWithConfig(&ClientConfig{Logger: newTestLogger()})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				ContentLength: tt.contentLength,
+				Body:          io.NopCloser(strings.NewReader(oversized)),
+			}
+			if _, err := client.readRespBody(resp); err == nil {
+				t.Error("got no error for an oversized body")
+			}
+		})
+	}
+}
+
+func TestMakeRequestRejectsOversizedChunkedResponse(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// flushing before the end makes the response chunked, without a Content-Length
+		chunk := []byte(strings.Repeat("a", 1024*1024))
+		for range maxResponseSize/len(chunk) + 1 {
+			w.Write(chunk)
+			w.(http.Flusher).Flush()
+		}
+	}))
+
+	if _, err := client.GetAnimeByID(1); err == nil || !strings.Contains(err.Error(), "response too large") {
+		t.Errorf("got %v, want the chunked response rejected as too large", err)
+	}
+}
+
+func TestSanitizeSearchQuery(t *testing.T) {
+	tests := []struct {
+		name, query, want string
+	}{
+		{"plain", "naruto", "naruto"},
+		{"trimmed", "  naruto  ", "naruto"},
+		{"collapsed whitespace", "one\t\tpiece\n film", "one piece film"},
+		{"control characters", "nar\x00uto\x1b", "naruto"},
+		{"zero width", "naru\u200bto\ufeff", "naruto"},
+		{"unicode kept", "進撃の巨人", "進撃の巨人"},
+		{"only control characters", "\x00\x07\u200b", ""},
+		{"only whitespace", " \t\n ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeSearchQuery(tt.query); got != tt.want {
+				t.Errorf("SanitizeSearchQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchAnimeSanitizesQuery(t *testing.T) {
+	var got string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("q")
+		w.Write([]byte(`{"data":[],"pagination":{}}`))
+	}))
+
+	if _, err := client.SearchAnime(" one\x00  piece ", models.SearchFilters{}); err != nil {
+		t.Fatalf("SearchAnime failed: %v", err)
+	}
+	if got != "one piece" {
+		t.Errorf("Jikan got q=%q, want %q", got, "one piece")
+	}
+
+	if _, err := client.SearchAnime("\x00\u200b", models.SearchFilters{}); err == nil {
+		t.Error("got no error for a query that's empty once sanitized")
+	}
+}
+
+func TestSearchAnimeMapsJikanResponse(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{
+			"data": [
+				{"mal_id": 5114, "title": "Fullmetal Alchemist: Brotherhood", "type": "TV", "episodes": 64},
+				{"mal_id": 121, "title": "Fullmetal Alchemist", "type": "TV", "episodes": 51}
+			],
+			"pagination": {"last_visible_page": 3, "has_next_page": true, "items": {"count": 2, "total": 25, "per_page": 2}}
+		}`))
+	}))
+	redisClient, _ := newTestRedis(t)
+	client.redis = redisClient
+
+	want := models.SearchResult{
+		Items: []models.AnimeData{
+			{MalID: 5114, Title: "Fullmetal Alchemist: Brotherhood", Type: "TV", Episodes: 64},
+			{MalID: 121, Title: "Fullmetal Alchemist", Type: "TV", Episodes: 51},
+		},
+		HasNext: true,
+		Total:   25,
+	}
+
+	// the second search is served from the cache, which must hold the same neutral result
+	for i := range 2 {
+		got, err := client.SearchAnime("fullmetal", models.SearchFilters{})
+		if err != nil {
+			t.Fatalf("SearchAnime failed: %v", err)
+		}
+		if got.HasNext != want.HasNext || got.Total != want.Total || len(got.Items) != len(want.Items) {
+			t.Fatalf("search %d: got %+v, want %+v", i+1, got, want)
+		}
+		for j := range want.Items {
+			if g, w := got.Items[j], want.Items[j]; g.MalID != w.MalID || g.Title != w.Title || g.Episodes != w.Episodes {
+				t.Errorf("search %d: item %d got %+v, want %+v", i+1, j, g, w)
+			}
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d Jikan calls, want the second search cached", calls.Load())
+	}
+}
+
+func TestSearchAnimeToleratesNullFields(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"data": [
+				{"mal_id": 1, "title": null, "title_english": null, "score": null},
+				{"mal_id": 2, "title": null, "title_english": "Ghost in the Shell", "synopsis": null},
+				{"mal_id": 3, "title": "  ", "title_english": ""},
+				{"mal_id": 4, "title": "Akira", "score": null, "episodes": null, "year": null}
+			],
+			"pagination": {"has_next_page": false, "items": {"total": 4}}
+		}`))
+	}))
+
+	got, err := client.SearchAnime("ghost", models.SearchFilters{})
+	if err != nil {
+		t.Fatalf("SearchAnime failed: %v", err)
+	}
+	if len(got.Items) != 2 {
+		t.Fatalf("got %d items, want the 2 with a title", len(got.Items))
+	}
+	if got.Items[0].MalID != 2 || got.Items[0].Title != "Ghost in the Shell" {
+		t.Errorf("got %+v, want the English title as a fallback", got.Items[0])
+	}
+	if akira := got.Items[1]; akira.Title != "Akira" || akira.Score != 0 || akira.Episodes != 0 || akira.Year != 0 {
+		t.Errorf("got %+v, want nulls read as zero", akira)
+	}
+}
+
+func TestGetAnimeByIDWithoutTitle(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"mal_id": 7, "title": null, "title_english": null, "synopsis": null}}`))
+	}))
+
+	anime, err := client.GetAnimeByID(7)
+	if err != nil {
+		t.Fatalf("GetAnimeByID failed: %v", err)
+	}
+	if anime.Title != "Anime #7" {
+		t.Errorf("got title %q, want a placeholder rather than a blank", anime.Title)
+	}
+}
+
+func TestGetTopAnimeRequestsFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		page   int
+		sfw    bool
+		want   url.Values
+	}{
+		{"", 1, false, url.Values{"page": {"1"}, "limit": {"10"}}},
+		{TopFilterAiring, 2, false, url.Values{"filter": {"airing"}, "page": {"2"}, "limit": {"10"}}},
+		{TopFilterUpcoming, 1, true, url.Values{"filter": {"upcoming"}, "page": {"1"}, "limit": {"10"}, "sfw": {"true"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			var path string
+			var query url.Values
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path, query = r.URL.Path, r.URL.Query()
+				w.Write([]byte(`{"data":[{"mal_id":1,"title":"Top"}],"pagination":{}}`))
+			}))
+
+			if _, err := client.GetTopAnime(tt.filter, tt.page, tt.sfw); err != nil {
+				t.Fatalf("GetTopAnime failed: %v", err)
+			}
+			if path != "/top/anime" {
+				t.Errorf("got path %q, want /top/anime", path)
+			}
+			if query.Encode() != tt.want.Encode() {
+				t.Errorf("got query %q, want %q", query.Encode(), tt.want.Encode())
+			}
+		})
+	}
+}
+
+func TestGetTopAnimeRejectsInvalidFilter(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+
+	for _, filter := range []string{"bypopularity", "AIRING", "airing&sfw=false"} {
+		if _, err := client.GetTopAnime(filter, 1, false); !errors.Is(err, ErrInvalidTopFilter) {
+			t.Errorf("filter %q got %v, want ErrInvalidTopFilter", filter, err)
+		}
+	}
+	if _, err := client.GetTopAnime("", 0, false); err == nil {
+		t.Error("got no error for page 0")
+	}
+	if calls.Load() != 0 {
+		t.Errorf("made %d requests, want none for invalid arguments", calls.Load())
+	}
+}
+
+func TestGetTopAnimeCachedPerFilterAndPage(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"data":[{"mal_id":1,"title":"Top"}],"pagination":{}}`))
+	}))
+	redisClient, _ := newTestRedis(t)
+	client.redis = redisClient
+
+	requests := []struct {
+		filter string
+		page   int
+	}{
+		{"", 1}, {"", 1}, {TopFilterAiring, 1}, {TopFilterAiring, 1}, {TopFilterAiring, 2}, {TopFilterUpcoming, 1},
+	}
+	for _, r := range requests {
+		if _, err := client.GetTopAnime(r.filter, r.page, false); err != nil {
+			t.Fatalf("GetTopAnime(%q, %d) failed: %v", r.filter, r.page, err)
+		}
+	}
+
+	if calls.Load() != 4 {
+		t.Errorf("made %d requests, want one per filter and page", calls.Load())
+	}
+}
+
+func TestWarmCachePopulatesSearchKeys(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") == "broken" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":[{"mal_id":1,"title":"Naruto"}],"pagination":{}}`))
+	}))
+	redisClient, server := newTestRedis(t)
+	client.redis = redisClient
+
+	warmed, err := client.WarmCache(context.Background(), []string{"naruto", "broken", "one piece"})
+	if err != nil {
+		t.Fatalf("WarmCache failed: %v", err)
+	}
+	if warmed != 2 {
+		t.Errorf("warmed %d queries, want 2 with the broken one skipped", warmed)
+	}
+
+	// warmed as the SFW searches users make by default
+	for _, key := range []string{searchCachePrefix + "naruto|sfw", searchCachePrefix + "one piece|sfw"} {
+		if !server.Exists(key) {
+			t.Errorf("cache key %q missing, have %q", key, server.Keys())
+		}
+	}
+	if server.Exists(searchCachePrefix + "broken|sfw") {
+		t.Error("failed query was cached")
+	}
+}
+
+func TestWarmCacheStopsWhenCancelled(t *testing.T) {
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// the run is cancelled while this search is in flight
+		cancel()
+		w.Write([]byte(`{"data":[],"pagination":{}}`))
+	}))
+	redisClient, _ := newTestRedis(t)
+	client.redis = redisClient
+
+	warmed, err := client.WarmCache(ctx, []string{"naruto", "bleach", "one piece"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if warmed > 1 {
+		t.Errorf("warmed %d queries after being cancelled", warmed)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d requests, want none after the cancel", calls.Load())
+	}
+}
+
+func TestWarmCacheErrors(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	if _, err := client.WarmCache(context.Background(), []string{"naruto"}); err == nil {
+		t.Error("got no error without a cache")
+	}
+
+	redisClient, _ := newTestRedis(t)
+	client.redis = redisClient
+	if warmed, err := client.WarmCache(context.Background(), []string{"naruto", "bleach"}); err == nil || warmed != 0 {
+		t.Errorf("got %d warmed and %v, want an error when nothing could be warmed", warmed, err)
+	}
+}
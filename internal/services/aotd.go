@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sletish/internal/models"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	aotdWorkerInterval = 15 * time.Minute
+	aotdSendHour       = 9  // local hour from which the day's suggestion goes out
+	aotdDedupeDays     = 60 // an anime isn't suggested to the same user again within this window
+	aotdPickAttempts   = 5  // random draws before giving up until the next tick
+	aotdSynopsisLen    = 300
+)
+
+// aotdSubscriber is a user who opted in to the anime of the day.
+type aotdSubscriber struct {
+	UserID   string
+	Timezone string
+	ShowNSFW bool
+	LastSent *time.Time
+}
+
+// AnimeOfTheDayService sends each subscribed user a random anime suggestion once a
+// day, in the morning of their own time zone.
+type AnimeOfTheDayService struct {
+	db           *pgxpool.Pool
+	logger       *logrus.Logger
+	botToken     string
+	isRunning    atomic.Bool
+	startOnce    sync.Once
+	workers      sync.WaitGroup
+	animeService *Client
+}
+
+func NewAnimeOfTheDayService(db *pgxpool.Pool, logger *logrus.Logger, botToken string, animeService *Client) *AnimeOfTheDayService {
+	service := &AnimeOfTheDayService{
+		db:           db,
+		logger:       logger,
+		botToken:     botToken,
+		animeService: animeService,
+	}
+
+	return service
+}
+
+// Start runs the anime of the day worker in the background until ctx is cancelled or StopWorker
+// is called. Only the first call starts a worker, so a service never runs two.
+func (s *AnimeOfTheDayService) Start(ctx context.Context) {
+	s.startOnce.Do(func() {
+		s.isRunning.Store(true)
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			s.runWorker(ctx)
+		}()
+	})
+}
+
+// Wait blocks until the worker started by Start has stopped. It returns at once if
+// the worker was never started.
+func (s *AnimeOfTheDayService) Wait() {
+	s.workers.Wait()
+}
+
+func (s *AnimeOfTheDayService) runWorker(ctx context.Context) {
+	s.logger.Info("Starting anime of the day worker...")
+	ticker := time.NewTicker(aotdWorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.isRunning.Store(false)
+		case <-ticker.C:
+		}
+
+		if !s.isRunning.Load() {
+			break
+		}
+
+		if err := s.processDue(); err != nil {
+			s.logger.WithError(err).Error("Error sending anime of the day")
+		}
+	}
+
+	s.logger.Info("Anime of the day worker stopped")
+}
+
+// processDue sends today's suggestion to every subscriber it's due for.
+func (s *AnimeOfTheDayService) processDue() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	if _, err := s.db.Exec(ctx, "DELETE FROM aotd_history WHERE sent_on < CURRENT_DATE - $1::int", aotdDedupeDays+1); err != nil {
+		s.logger.WithError(err).Warn("Failed to prune anime of the day history")
+	}
+
+	query := `
+		SELECT u.id, us.timezone, us.show_nsfw, (SELECT MAX(h.sent_on) FROM aotd_history h WHERE h.user_id = u.id)
+		FROM users u
+		JOIN user_settings us ON us.user_id = u.id
+		WHERE us.aotd_enabled = true AND u.blocked = false AND us.notifications_paused = false
+	`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query anime of the day subscribers: %w", err)
+	}
+
+	var subscribers []aotdSubscriber
+	for rows.Next() {
+		var sub aotdSubscriber
+		if err := rows.Scan(&sub.UserID, &sub.Timezone, &sub.ShowNSFW, &sub.LastSent); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan anime of the day subscriber: %w", err)
+		}
+		subscribers = append(subscribers, sub)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating anime of the day subscribers: %w", err)
+	}
+
+	now := time.Now()
+	var sentCount, errorCount int
+
+	for _, sub := range subscribers {
+		loc, err := time.LoadLocation(sub.Timezone)
+		if err != nil {
+			s.logger.WithError(err).WithField("user_id", sub.UserID).Warn("Invalid time zone, using UTC")
+			loc = time.UTC
+		}
+
+		localNow := now.In(loc)
+		if !isAotdDue(localNow, sub.LastSent) {
+			continue
+		}
+
+		if err := s.sendSuggestion(ctx, sub, localNow); err != nil {
+			s.logger.WithError(err).WithField("user_id", sub.UserID).Error("Failed to send anime of the day")
+			errorCount++
+			continue
+		}
+		sentCount++
+	}
+
+	if sentCount > 0 || errorCount > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"sent":   sentCount,
+			"errors": errorCount,
+		}).Info("Processed anime of the day")
+	}
+
+	return nil
+}
+
+// isAotdDue reports whether a user whose local time is localNow should get today's
+// suggestion: it's past the send hour and nothing has been sent yet on this local date.
+func isAotdDue(localNow time.Time, lastSent *time.Time) bool {
+	if localNow.Hour() < aotdSendHour {
+		return false
+	}
+	if lastSent == nil {
+		return true
+	}
+	ly, lm, ld := lastSent.Date()
+	ny, nm, nd := localNow.Date()
+	return time.Date(ly, lm, ld, 0, 0, 0, 0, time.UTC).Before(time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC))
+}
+
+// sendSuggestion picks an anime the user hasn't been suggested recently and doesn't
+// already have in their list, records it, then sends it.
+func (s *AnimeOfTheDayService) sendSuggestion(ctx context.Context, sub aotdSubscriber, localNow time.Time) error {
+	chatID, err := strconv.Atoi(sub.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q: %w", sub.UserID, err)
+	}
+
+	anime, err := s.pickAnime(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	// recorded before sending, so a failed send skips a day rather than risking two
+	recordQuery := `
+		INSERT INTO aotd_history (user_id, external_id, sent_on)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, external_id) DO UPDATE SET sent_on = EXCLUDED.sent_on
+	`
+	today := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)
+	if _, err := s.db.Exec(ctx, recordQuery, sub.UserID, strconv.Itoa(anime.MalID), today); err != nil {
+		return fmt.Errorf("failed to record anime of the day: %w", err)
+	}
+
+	return SendTelegramMessage(ctx, s.botToken, chatID, formatAotdMessage(anime))
+}
+
+// pickAnime draws random anime until one is neither recently suggested nor in the user's list.
+func (s *AnimeOfTheDayService) pickAnime(ctx context.Context, sub aotdSubscriber) (*models.AnimeData, error) {
+	seenQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM aotd_history
+			WHERE user_id = $1 AND external_id = $2 AND sent_on >= CURRENT_DATE - $3::int
+		) OR EXISTS (
+			SELECT 1 FROM user_media um
+			JOIN media m ON m.id = um.media_id
+			WHERE um.user_id = $1 AND m.external_id = $2 AND um.deleted_at IS NULL
+		)
+	`
+
+	for attempt := 0; attempt < aotdPickAttempts; attempt++ {
+		anime, err := s.animeService.GetRandomAnime(!sub.ShowNSFW)
+		if err != nil {
+			return nil, err
+		}
+		if !sub.ShowNSFW && anime.IsAdult() {
+			continue
+		}
+
+		var seen bool
+		err = s.db.QueryRow(ctx, seenQuery, sub.UserID, strconv.Itoa(anime.MalID), aotdDedupeDays).Scan(&seen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check anime of the day history: %w", err)
+		}
+		if !seen {
+			return anime, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no unseen anime after %d draws", aotdPickAttempts)
+}
+
+func formatAotdMessage(anime *models.AnimeData) string {
+	var message strings.Builder
+	message.WriteString("🌅 <b>Anime of the Day</b>\n\n")
+	message.WriteString(fmt.Sprintf("🎬 <b>%s</b>\n", html.EscapeString(anime.Title)))
+
+	var details []string
+	if anime.Type != "" {
+		details = append(details, anime.Type)
+	}
+	if anime.Episodes > 0 {
+		details = append(details, fmt.Sprintf("%d eps", anime.Episodes))
+	}
+	if anime.Score > 0 {
+		details = append(details, fmt.Sprintf("⭐ %.2f", anime.Score))
+	}
+	if len(details) > 0 {
+		message.WriteString(strings.Join(details, " · ") + "\n")
+	}
+
+	if synopsis := strings.TrimSpace(anime.Synopsis); synopsis != "" {
+		message.WriteString("\n" + html.EscapeString(TruncateText(synopsis, aotdSynopsisLen)) + "\n")
+	}
+
+	message.WriteString(fmt.Sprintf("\n➕ /add %d watchlist\n", anime.MalID))
+	message.WriteString(fmt.Sprintf(`<a href="https://myanimelist.net/anime/%d">🔗 View on MyAnimeList</a>`, anime.MalID))
+	message.WriteString("\n\n<i>Turn these off with /aotd off.</i>")
+
+	return message.String()
+}
+
+func (s *AnimeOfTheDayService) StopWorker() {
+	s.isRunning.Store(false)
+	s.logger.Info("Anime of the day worker stop requested")
+}
+
+func (s *AnimeOfTheDayService) SetBotToken(botToken string) {
+	s.botToken = botToken
+}
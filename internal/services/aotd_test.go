@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sletish/internal/models"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsAotdDue(t *testing.T) {
+	berlin := time.FixedZone("CET", 60*60)
+	kiritimati := time.FixedZone("LINT", 14*60*60)
+	morning := time.Date(2026, time.March, 10, aotdSendHour, 30, 0, 0, berlin)
+	yesterday := time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		localNow time.Time
+		lastSent *time.Time
+		want     bool
+	}{
+		{"never sent", morning, nil, true},
+		{"before the send hour", morning.Add(-time.Hour), nil, false},
+		{"sent yesterday", morning, &yesterday, true},
+		{"sent today", morning, &today, false},
+		{"sent today, late evening", time.Date(2026, time.March, 10, 23, 0, 0, 0, berlin), &today, false},
+		// 20:00 UTC on the 9th is already the morning of the 10th in Kiritimati
+		{"local date ahead of UTC", time.Date(2026, time.March, 9, 20, 0, 0, 0, time.UTC).In(kiritimati), &yesterday, true},
+	}
+
+	for _, tt := range tests {
+		if got := isAotdDue(tt.localNow, tt.lastSent); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// newTestAotdService returns an anime of the day service whose random anime are the
+// given IDs in turn, repeating the last one.
+func newTestAotdService(t *testing.T, users *UserService, ids ...int) *AnimeOfTheDayService {
+	t.Helper()
+
+	var calls atomic.Int32
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := ids[min(int(calls.Add(1)), len(ids))-1]
+		data := models.AnimeData{MalID: id, Title: fmt.Sprintf("Random %d", id), Type: "TV"}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	return NewAnimeOfTheDayService(users.db, newTestLogger(), "test", client)
+}
+
+// localNoonZone returns a fixed offset time zone where it's currently about noon.
+func localNoonZone() string {
+	offset := 12 - time.Now().UTC().Hour()
+	switch {
+	case offset > 0:
+		return fmt.Sprintf("Etc/GMT-%d", offset)
+	case offset < 0:
+		return fmt.Sprintf("Etc/GMT+%d", -offset)
+	}
+	return "UTC"
+}
+
+func TestAotdPickSkipsSeenAnime(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	ctx := context.Background()
+
+	// 1 was suggested recently, 2 is in the list, 4 was suggested long ago
+	insert := "INSERT INTO aotd_history (user_id, external_id, sent_on) VALUES ('1', $1, CURRENT_DATE - $2::int)"
+	users.db.Exec(ctx, insert, "1", 3)
+	users.db.Exec(ctx, insert, "4", aotdDedupeDays+5)
+	users.AddToUserList("1", 2, models.StatusWatching, nil, nil)
+
+	service := newTestAotdService(t, users, 1, 2, 3)
+	anime, err := service.pickAnime(ctx, aotdSubscriber{UserID: "1"})
+	if err != nil {
+		t.Fatalf("pickAnime failed: %v", err)
+	}
+	if anime.MalID != 3 {
+		t.Errorf("got %d, want 3, the first anime not seen", anime.MalID)
+	}
+
+	if anime, err := newTestAotdService(t, users, 4).pickAnime(ctx, aotdSubscriber{UserID: "1"}); err != nil || anime.MalID != 4 {
+		t.Errorf("got %+v (err %v), want 4 again once it's out of the window", anime, err)
+	}
+
+	if _, err := newTestAotdService(t, users, 1).pickAnime(ctx, aotdSubscriber{UserID: "1"}); err == nil {
+		t.Error("got no error when every draw was already seen")
+	}
+}
+
+func TestAotdSentOncePerDay(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	newTestUser(t, users, "2")
+	if err := users.SetAnimeOfTheDay("1", true, localNoonZone()); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	var sent []string
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg models.TelegramResponse
+		json.NewDecoder(r.Body).Decode(&msg)
+		sent = append(sent, msg.Text)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	service := newTestAotdService(t, users, 5114, 9253)
+
+	for i := 0; i < 2; i++ {
+		if err := service.processDue(); err != nil {
+			t.Fatalf("processDue failed: %v", err)
+		}
+	}
+
+	// only the subscriber, and only once today
+	if len(sent) != 1 || !strings.Contains(sent[0], "Random 5114") || !strings.Contains(sent[0], "/add 5114 watchlist") {
+		t.Fatalf("got %q, want one suggestion of 5114", sent)
+	}
+
+	var count int
+	if err := users.db.QueryRow(context.Background(), "SELECT COUNT(*) FROM aotd_history WHERE user_id = '1'").Scan(&count); err != nil {
+		t.Fatalf("failed to count history: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("got %d history entries, want 1", count)
+	}
+}
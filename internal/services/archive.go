@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ArchiveRetentionDays is how long a removed entry stays archived before it's purged.
+	ArchiveRetentionDays = 30
+	archivePurgeInterval = 24 * time.Hour
+)
+
+var (
+	// ErrNothingToUndo is returned by UndoRemoval when there's no recent removal to restore.
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNotArchived is returned by RestoreEntry when the anime isn't in the user's archive,
+	// either because it was never removed or because it has been purged.
+	ErrNotArchived = errors.New("anime not found in archive")
+)
+
+// SetSoftDelete makes RemoveFromUserList archive entries instead of deleting them.
+func (s *UserService) SetSoftDelete(enabled bool) {
+	s.softDelete = enabled
+}
+
+// SoftDelete reports whether removed entries are archived.
+func (s *UserService) SoftDelete() bool {
+	return s.softDelete
+}
+
+// UndoRemoval restores the user's most recently archived entry and returns its media.
+func (s *UserService) UndoRemoval(userID string) (*models.Media, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin restore: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.checkListRoom(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE user_media
+		SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM user_media
+			WHERE user_id = $1 AND deleted_at > NOW() - $2::int * INTERVAL '1 day'
+			ORDER BY deleted_at DESC
+			LIMIT 1
+		)
+		RETURNING media_id
+	`
+
+	var mediaID int
+	err = tx.QueryRow(ctx, query, userID, ArchiveRetentionDays).Scan(&mediaID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNothingToUndo
+		}
+		return nil, fmt.Errorf("failed to restore user media: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	s.invalidateUserCache(userID)
+
+	var media models.Media
+	err = s.db.QueryRow(ctx, "SELECT id, external_id, title FROM media WHERE id = $1", mediaID).Scan(&media.ID, &media.ExternalID, &media.Title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restored media: %w", err)
+	}
+
+	return &media, nil
+}
+
+// RestoreEntry brings an archived anime back into the user's list with the status,
+// rating, notes and progress it had when it was removed.
+func (s *UserService) RestoreEntry(userID string, animeID int) (*models.UserMediaWithDetails, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin restore: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.checkListRoom(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	// entries past retention are as good as purged, even if the worker hasn't run yet
+	query := `
+		UPDATE user_media um
+		SET deleted_at = NULL, updated_at = NOW()
+		FROM media m
+		WHERE um.media_id = m.id
+		AND um.user_id = $1 AND m.external_id = $2
+		AND um.deleted_at > NOW() - $3::int * INTERVAL '1 day'
+		RETURNING um.id
+	`
+
+	var entryID int
+	err = tx.QueryRow(ctx, query, userID, strconv.Itoa(animeID), ArchiveRetentionDays).Scan(&entryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotArchived
+		}
+		return nil, fmt.Errorf("failed to restore user media: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	s.invalidateUserCache(userID)
+
+	rows, err := s.db.Query(ctx, "SELECT "+userMediaColumns+" FROM user_media um JOIN media m ON um.media_id = m.id WHERE um.id = $1", entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restored media: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanUserMediaRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNotArchived
+	}
+
+	return &items[0], nil
+}
+
+// checkListRoom returns ErrListFull if the user's list is at the size cap. Like
+// AddToUserList it locks the user in tx first, so a concurrent add or restore can't
+// pass the same check before this one commits.
+func (s *UserService) checkListRoom(ctx context.Context, tx pgx.Tx, userID string) error {
+	if s.maxListSize <= 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", userID); err != nil {
+		return fmt.Errorf("failed to lock user list: %w", err)
+	}
+
+	var size int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM user_media WHERE user_id = $1 AND deleted_at IS NULL", userID).Scan(&size); err != nil {
+		return fmt.Errorf("failed to count user media: %w", err)
+	}
+	if size >= s.maxListSize {
+		return fmt.Errorf("%w: %d entries allowed", ErrListFull, s.maxListSize)
+	}
+	return nil
+}
+
+// GetArchivedList returns a page of the user's archived entries, most recently removed first.
+func (s *UserService) GetArchivedList(userID string, page, limit int) ([]models.UserMediaWithDetails, int, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	var total int
+	if err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM user_media WHERE user_id = $1 AND deleted_at IS NOT NULL", userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count archived media: %w", err)
+	}
+
+	query := `
+		SELECT ` + userMediaColumns + `
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND um.deleted_at IS NOT NULL
+		ORDER BY um.deleted_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get archived media: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanUserMediaRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// PurgeArchived permanently deletes entries archived longer than ArchiveRetentionDays.
+func (s *UserService) PurgeArchived() (int64, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	result, err := s.db.Exec(ctx, "DELETE FROM user_media WHERE deleted_at < NOW() - $1::int * INTERVAL '1 day'", ArchiveRetentionDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge archived media: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// StartPurgeWorker purges old archived entries once a day, in the background, until ctx
+// is cancelled or StopPurgeWorker is called. Only the first call starts a worker.
+func (s *UserService) StartPurgeWorker(ctx context.Context) {
+	s.purgeOnce.Do(func() {
+		s.isPurging.Store(true)
+		s.purgeWorker.Add(1)
+		go func() {
+			defer s.purgeWorker.Done()
+			s.runPurgeWorker(ctx)
+		}()
+	})
+}
+
+// WaitPurgeWorker blocks until the worker started by StartPurgeWorker has stopped. It
+// returns at once if the worker was never started.
+func (s *UserService) WaitPurgeWorker() {
+	s.purgeWorker.Wait()
+}
+
+func (s *UserService) runPurgeWorker(ctx context.Context) {
+	s.logger.Info("Starting archive purge worker...")
+
+	ticker := time.NewTicker(archivePurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.isPurging.Store(false)
+		case <-ticker.C:
+		}
+
+		if !s.isPurging.Load() {
+			break
+		}
+
+		purged, err := s.PurgeArchived()
+		if err != nil {
+			s.logger.WithError(err).Error("Error purging archived entries")
+			continue
+		}
+		if purged > 0 {
+			s.logger.WithFields(logrus.Fields{"purged": purged}).Info("Purged archived entries")
+		}
+	}
+
+	s.logger.Info("Archive purge worker stopped")
+}
+
+func (s *UserService) StopPurgeWorker() {
+	s.isPurging.Store(false)
+	s.logger.Info("Archive purge worker stop requested")
+}
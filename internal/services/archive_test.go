@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sletish/internal/models"
+	"sync"
+	"testing"
+)
+
+// newArchivingUserService returns a UserService on the test database that archives removed entries.
+func newArchivingUserService(t *testing.T) *UserService {
+	t.Helper()
+
+	s := newTestUserService(t)
+	s.SetSoftDelete(true)
+	newTestUser(t, s, "1")
+	return s
+}
+
+func TestRemoveArchivesEntry(t *testing.T) {
+	s := newArchivingUserService(t)
+
+	rating := 9.0
+	s.AddToUserList("1", 1, models.StatusCompleted, &rating, nil)
+	s.AddToUserList("1", 2, models.StatusWatching, nil, nil)
+	if err := s.RemoveFromUserList("1", 1); err != nil {
+		t.Fatalf("RemoveFromUserList failed: %v", err)
+	}
+
+	entries, total, err := s.GetUserList("1", "", 1, 10)
+	if err != nil {
+		t.Fatalf("GetUserList failed: %v", err)
+	}
+	if total != 1 || len(entries) != 1 || entries[0].Media.ExternalID != "2" {
+		t.Errorf("got %d entries %+v, want only the one not removed", total, entries)
+	}
+
+	archived, total, err := s.GetArchivedList("1", 1, 10)
+	if err != nil {
+		t.Fatalf("GetArchivedList failed: %v", err)
+	}
+	if total != 1 || len(archived) != 1 || archived[0].Media.ExternalID != "1" || archived[0].UserMedia.DeletedAt == nil {
+		t.Errorf("got %d archived %+v, want the removed entry", total, archived)
+	}
+
+	if err := s.RemoveFromUserList("1", 1); err == nil {
+		t.Error("got no error removing an archived entry again")
+	}
+}
+
+func TestUndoRemoval(t *testing.T) {
+	s := newArchivingUserService(t)
+
+	if _, err := s.UndoRemoval("1"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("got %v with nothing removed, want ErrNothingToUndo", err)
+	}
+
+	s.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	s.AddToUserList("1", 2, models.StatusWatching, nil, nil)
+	s.RemoveFromUserList("1", 1)
+	s.RemoveFromUserList("1", 2)
+
+	// most recently removed first
+	for _, want := range []string{"2", "1"} {
+		media, err := s.UndoRemoval("1")
+		if err != nil {
+			t.Fatalf("UndoRemoval failed: %v", err)
+		}
+		if media.ExternalID != want {
+			t.Errorf("restored %s, want %s", media.ExternalID, want)
+		}
+	}
+
+	if _, total, _ := s.GetUserList("1", "", 1, 10); total != 2 {
+		t.Errorf("got %d entries, want both restored", total)
+	}
+}
+
+func TestRestoreEntryKeepsDetails(t *testing.T) {
+	s := newArchivingUserService(t)
+
+	rating, notes := 7.5, "pick back up"
+	s.AddToUserList("1", 1, models.StatusOnHold, &rating, &notes)
+	s.SetProgress("1", 1, 12)
+	s.RemoveFromUserList("1", 1)
+
+	if _, err := s.RestoreEntry("1", 2); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("got %v restoring an anime never removed, want ErrNotArchived", err)
+	}
+
+	entry, err := s.RestoreEntry("1", 1)
+	if err != nil {
+		t.Fatalf("RestoreEntry failed: %v", err)
+	}
+	got := entry.UserMedia
+	if got.Status != models.StatusOnHold || got.Rating != rating || got.Notes != notes || got.EpisodesWatched != 12 || got.DeletedAt != nil {
+		t.Errorf("got %+v, want the entry back as it was", got)
+	}
+	if _, err := s.RestoreEntry("1", 1); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("got %v restoring it twice, want ErrNotArchived", err)
+	}
+}
+
+func TestRestoreEntryAfterRetention(t *testing.T) {
+	s := newArchivingUserService(t)
+	ctx := context.Background()
+
+	s.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	s.AddToUserList("1", 2, models.StatusWatching, nil, nil)
+	s.RemoveFromUserList("1", 1)
+	s.RemoveFromUserList("1", 2)
+
+	backdate := `
+		UPDATE user_media SET deleted_at = NOW() - ($1::int + 1) * INTERVAL '1 day'
+		WHERE media_id IN (SELECT id FROM media WHERE external_id IN ('1', '2'))
+	`
+	if _, err := s.db.Exec(ctx, backdate, ArchiveRetentionDays); err != nil {
+		t.Fatalf("failed to backdate: %v", err)
+	}
+
+	// past retention it can't be restored, even before the purge
+	if _, err := s.RestoreEntry("1", 1); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("got %v restoring an expired entry, want ErrNotArchived", err)
+	}
+
+	s.PurgeArchived()
+	if _, err := s.RestoreEntry("1", 2); !errors.Is(err, ErrNotArchived) {
+		t.Errorf("got %v restoring a purged entry, want ErrNotArchived", err)
+	}
+}
+
+func TestPurgeArchived(t *testing.T) {
+	s := newArchivingUserService(t)
+	ctx := context.Background()
+
+	s.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	s.AddToUserList("1", 2, models.StatusWatching, nil, nil)
+	s.RemoveFromUserList("1", 1)
+	s.RemoveFromUserList("1", 2)
+
+	backdate := `
+		UPDATE user_media SET deleted_at = NOW() - ($1::int + 1) * INTERVAL '1 day'
+		WHERE media_id = (SELECT id FROM media WHERE external_id = '1')
+	`
+	if _, err := s.db.Exec(ctx, backdate, ArchiveRetentionDays); err != nil {
+		t.Fatalf("failed to backdate: %v", err)
+	}
+
+	purged, err := s.PurgeArchived()
+	if err != nil {
+		t.Fatalf("PurgeArchived failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d, want 1", purged)
+	}
+	if archived, total, _ := s.GetArchivedList("1", 1, 10); total != 1 || archived[0].Media.ExternalID != "2" {
+		t.Errorf("got %d archived %+v, want only the recent removal kept", total, archived)
+	}
+}
+
+func TestRemoveWithoutSoftDelete(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	s.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	if err := s.RemoveFromUserList("1", 1); err != nil {
+		t.Fatalf("RemoveFromUserList failed: %v", err)
+	}
+
+	if _, total, _ := s.GetArchivedList("1", 1, 10); total != 0 {
+		t.Errorf("got %d archived, want the entry deleted", total)
+	}
+	if _, err := s.UndoRemoval("1"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("got %v, want nothing to undo", err)
+	}
+}
+
+func TestRestoreEntryConcurrentRespectsMaxSize(t *testing.T) {
+	s := newArchivingUserService(t)
+
+	for _, id := range []int{1, 2, 3, 4} {
+		if err := s.AddToUserList("1", id, models.StatusWatching, nil, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", id, err)
+		}
+		if err := s.RemoveFromUserList("1", id); err != nil {
+			t.Fatalf("failed to remove %d: %v", id, err)
+		}
+	}
+	s.SetMaxListSize(1)
+
+	// an undo and restores racing each other, only one fits
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i == 0 {
+				_, errs[i] = s.UndoRemoval("1")
+				return
+			}
+			_, errs[i] = s.RestoreEntry("1", i)
+		}()
+	}
+	wg.Wait()
+
+	restored := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			restored++
+		case !errors.Is(err, ErrListFull):
+			t.Errorf("restore %d: got %v, want nil or ErrListFull", i, err)
+		}
+	}
+	if restored != 1 {
+		t.Errorf("%d restores succeeded, want 1", restored)
+	}
+
+	_, total, err := s.GetUserList("1", "", 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get list: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("got %d entries, want the list capped at 1", total)
+	}
+}
+
+func TestPurgeWorkerStopsConcurrently(t *testing.T) {
+	s := NewUserService(nil, nil, newTestLogger(), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.StartPurgeWorker(ctx)
+
+	// stopping from another goroutine while the worker winds down must not race
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.StopPurgeWorker()
+	}()
+	cancel()
+	<-done
+
+	s.WaitPurgeWorker()
+	if s.isPurging.Load() {
+		t.Error("worker still flagged as purging after it stopped")
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	breakerThreshold = 3                // consecutive failed requests before the breaker opens
+	breakerCooldown  = 60 * time.Second // how long requests fail fast once open
+)
+
+// ErrServiceUnavailable is returned without contacting Jikan while it looks to be down.
+var ErrServiceUnavailable = errors.New("anime service temporarily unavailable")
+
+// jikanBreaker is shared by every Client, they all talk to the same API.
+var jikanBreaker = newCircuitBreaker(breakerThreshold, breakerCooldown)
+
+// circuitBreaker stops calls to a failing upstream for a cooldown once enough
+// consecutive calls have failed. After the cooldown one call is let through; if it
+// succeeds the breaker closes, if it fails the breaker opens again.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may go ahead.
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.probing {
+		return false
+	}
+
+	// cooldown over, let a single call through to see if the upstream is back
+	b.probing = true
+	return true
+}
+
+// Success records a successful call and closes the breaker.
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a failed call, opening the breaker once the threshold is reached.
+// Returns true if this call opened it.
+func (b *circuitBreaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures < b.threshold {
+		return false
+	}
+
+	b.openUntil = b.now().Add(b.cooldown)
+	return true
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for the breaker.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestBreaker(clock *fakeClock) *circuitBreaker {
+	b := newCircuitBreaker(3, time.Minute)
+	b.now = clock.now
+	return b
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	b := newTestBreaker(clock)
+
+	for i := 0; i < 2; i++ {
+		if opened := b.Failure(); opened {
+			t.Fatalf("breaker opened after %d failures, want 3", i+1)
+		}
+		if !b.Allow() {
+			t.Fatalf("breaker refused a call after %d failures", i+1)
+		}
+	}
+
+	if opened := b.Failure(); !opened {
+		t.Fatal("third failure didn't open the breaker")
+	}
+	if b.Allow() {
+		t.Fatal("open breaker allowed a call during the cooldown")
+	}
+
+	clock.t = clock.t.Add(59 * time.Second)
+	if b.Allow() {
+		t.Fatal("breaker allowed a call before the cooldown was over")
+	}
+}
+
+func TestCircuitBreakerHalfOpenLetsOneProbeThrough(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	b := newTestBreaker(clock)
+	for i := 0; i < 3; i++ {
+		b.Failure()
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("breaker didn't let a probe through after the cooldown")
+	}
+	if b.Allow() {
+		t.Fatal("breaker let a second call through while the probe is running")
+	}
+}
+
+func TestCircuitBreakerClosesOnProbeSuccess(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	b := newTestBreaker(clock)
+	for i := 0; i < 3; i++ {
+		b.Failure()
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	b.Allow()
+	b.Success()
+
+	for i := 0; i < 5; i++ {
+		if !b.Allow() {
+			t.Fatalf("closed breaker refused call %d", i+1)
+		}
+	}
+
+	// the failure count starts over once closed
+	b.Failure()
+	b.Failure()
+	if !b.Allow() {
+		t.Fatal("breaker opened again before reaching the threshold")
+	}
+}
+
+func TestCircuitBreakerReopensOnProbeFailure(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	b := newTestBreaker(clock)
+	for i := 0; i < 3; i++ {
+		b.Failure()
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	b.Allow()
+	if opened := b.Failure(); !opened {
+		t.Fatal("failed probe didn't reopen the breaker")
+	}
+	if b.Allow() {
+		t.Fatal("breaker allowed a call right after a failed probe")
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatal("breaker didn't probe again after the second cooldown")
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	callbackCachePrefix = "callback:"
+	callbackTokenPrefix = "t:"
+	callbackTokenTTL    = 48 * time.Hour // buttons older than this are rarely pressed
+	callbackTokenBytes  = 9              // 12 chars once base64 encoded
+)
+
+// ErrCallbackExpired is returned when a button's token is no longer in the store.
+var ErrCallbackExpired = errors.New("callback data expired")
+
+// CallbackStore keeps inline button payloads in Redis so that only a short token
+// has to fit into Telegram's 64-byte callback_data.
+type CallbackStore struct {
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+// NewCallbackStore creates and returns a new CallbackStore.
+func NewCallbackStore(redis *redis.Client, logger *logrus.Logger) *CallbackStore {
+	return &CallbackStore{
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// Save stores the payload under a new random token and returns the value to use
+// as the button's callback_data.
+func (s *CallbackStore) Save(ctx context.Context, data models.CallbackData) (string, error) {
+	if s.redis == nil {
+		return "", fmt.Errorf("callback store requires redis")
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal callback data: %w", err)
+	}
+
+	buf := make([]byte, callbackTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate callback token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := s.redis.Set(ctx, callbackCachePrefix+token, payload, callbackTokenTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to store callback data: %w", err)
+	}
+
+	return callbackTokenPrefix + token, nil
+}
+
+// Load resolves a token produced by Save back into its payload.
+// Returns ErrCallbackExpired if the token is unknown or has expired.
+func (s *CallbackStore) Load(ctx context.Context, callbackData string) (*models.CallbackData, error) {
+	if s.redis == nil {
+		return nil, fmt.Errorf("callback store requires redis")
+	}
+
+	if !IsCallbackToken(callbackData) {
+		return nil, ErrCallbackExpired
+	}
+	token := strings.TrimPrefix(callbackData, callbackTokenPrefix)
+
+	payload, err := s.redis.Get(ctx, callbackCachePrefix+token).Result()
+	if err == redis.Nil {
+		return nil, ErrCallbackExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load callback data: %w", err)
+	}
+
+	var data models.CallbackData
+	if err := json.Unmarshal([]byte(payload), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal callback data: %w", err)
+	}
+
+	return &data, nil
+}
+
+// IsCallbackToken reports whether callback_data holds a store token.
+func IsCallbackToken(callbackData string) bool {
+	return strings.HasPrefix(callbackData, callbackTokenPrefix)
+}
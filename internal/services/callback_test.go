@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sletish/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCallbackStoreRoundTrip(t *testing.T) {
+	client, _ := newTestRedis(t)
+	store := NewCallbackStore(client, newTestLogger())
+
+	want := models.CallbackData{
+		Action: "list_page",
+		Status: "watching",
+		Page:   3,
+		Limit:  5,
+		Total:  42,
+		Genre:  "Slice of Life",
+		Rating: "rated>=8",
+	}
+
+	token, err := store.Save(context.Background(), want)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if !IsCallbackToken(token) {
+		t.Errorf("Save returned %q, want a callback token", token)
+	}
+	if len(token) > 64 {
+		t.Errorf("token is %d bytes, Telegram allows 64", len(token))
+	}
+
+	got, err := store.Load(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if *got != want {
+		t.Errorf("Load returned %+v, want %+v", *got, want)
+	}
+}
+
+func TestCallbackStoreExpiredToken(t *testing.T) {
+	client, server := newTestRedis(t)
+	store := NewCallbackStore(client, newTestLogger())
+
+	token, err := store.Save(context.Background(), models.CallbackData{Action: "add_anime", AnimeID: "5114"})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	server.FastForward(callbackTokenTTL + time.Second)
+
+	if _, err := store.Load(context.Background(), token); !errors.Is(err, ErrCallbackExpired) {
+		t.Errorf("Load of an expired token returned %v, want ErrCallbackExpired", err)
+	}
+}
+
+func TestCallbackStoreRejectsNonTokens(t *testing.T) {
+	client, _ := newTestRedis(t)
+	store := NewCallbackStore(client, newTestLogger())
+
+	for _, raw := range []string{`{"a":"list_page","p":0}`, "noop", "", "t"} {
+		if _, err := store.Load(context.Background(), raw); !errors.Is(err, ErrCallbackExpired) {
+			t.Errorf("Load(%q) returned %v, want ErrCallbackExpired", raw, err)
+		}
+	}
+}
+
+func TestCallbackStoreUsesRequestContext(t *testing.T) {
+	client, _ := newTestRedis(t)
+	store := NewCallbackStore(client, newTestLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := store.Save(ctx, models.CallbackData{Action: "noop"}); err == nil {
+		t.Error("Save succeeded with a cancelled context")
+	}
+	if _, err := store.Load(ctx, callbackTokenPrefix+"abc"); err == nil || errors.Is(err, ErrCallbackExpired) {
+		t.Errorf("Load with a cancelled context returned %v, want the context error", err)
+	}
+}
@@ -0,0 +1,153 @@
+//go:build card
+
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sletish/internal/models"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	cardWidth      = 480
+	cardPadding    = 24
+	cardLineHeight = 20
+	cardBarWidth   = 200
+	cardTopTitles  = 5
+	cardTitleWidth = 60 // characters, basicfont has no wrapping
+)
+
+var (
+	cardBackground = color.RGBA{R: 0x1e, G: 0x1f, B: 0x2b, A: 0xff}
+	cardText       = color.RGBA{R: 0xee, G: 0xee, B: 0xf2, A: 0xff}
+	cardMuted      = color.RGBA{R: 0x9a, G: 0x9c, B: 0xb0, A: 0xff}
+	cardBar        = color.RGBA{R: 0x5b, G: 0x8d, B: 0xef, A: 0xff}
+)
+
+// RenderStatsCard draws a PNG summary of the user's list: entries per status and
+// their top rated titles.
+func (s *UserService) RenderStatsCard(userID string) ([]byte, error) {
+	counts, err := s.StatusCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := s.topRatedTitles(userID, cardTopTitles)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []models.Status{
+		models.StatusWatching,
+		models.StatusCompleted,
+		models.StatusWatchlist,
+		models.StatusOnHold,
+		models.StatusDropped,
+	}
+
+	total, highest := 0, 0
+	for _, status := range statuses {
+		total += counts[status]
+		highest = max(highest, counts[status])
+	}
+
+	lines := 3 + len(statuses) // heading, total, blank, statuses
+	if len(titles) > 0 {
+		lines += 2 + len(titles)
+	}
+	height := 2*cardPadding + lines*cardLineHeight
+
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: cardBackground}, image.Point{}, draw.Src)
+
+	y := cardPadding + cardLineHeight
+	drawCardText(img, cardPadding, y, "ANIME STATS", cardText)
+	y += cardLineHeight
+	drawCardText(img, cardPadding, y, fmt.Sprintf("%d anime in list", total), cardMuted)
+	y += 2 * cardLineHeight
+
+	for _, status := range statuses {
+		count := counts[status]
+		drawCardText(img, cardPadding, y, fmt.Sprintf("%-10s %4d", status, count), cardText)
+
+		if highest > 0 && count > 0 {
+			barLeft := cardPadding + 130
+			barRight := barLeft + max(2, cardBarWidth*count/highest)
+			bar := image.Rect(barLeft, y-10, barRight, y)
+			draw.Draw(img, bar, &image.Uniform{C: cardBar}, image.Point{}, draw.Src)
+		}
+		y += cardLineHeight
+	}
+
+	if len(titles) > 0 {
+		y += cardLineHeight
+		drawCardText(img, cardPadding, y, "TOP TITLES", cardText)
+		y += cardLineHeight
+
+		for i, title := range titles {
+			drawCardText(img, cardPadding, y, fmt.Sprintf("%d. %s", i+1, TruncateText(title, cardTitleWidth)), cardMuted)
+			y += cardLineHeight
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode stats card: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// topRatedTitles returns the titles of the user's highest rated entries.
+func (s *UserService) topRatedTitles(userID string, limit int) ([]string, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT m.title
+		FROM user_media um
+		JOIN media m ON m.id = um.media_id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL AND um.rating IS NOT NULL
+		ORDER BY um.rating DESC, m.title ASC
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top titles: %w", err)
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, fmt.Errorf("failed to scan title: %w", err)
+		}
+		titles = append(titles, title)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating titles: %w", err)
+	}
+
+	return titles, nil
+}
+
+func drawCardText(img draw.Image, x, y int, text string, c color.Color) {
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  &image.Uniform{C: c},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
@@ -0,0 +1,9 @@
+//go:build !card
+
+package services
+
+// RenderStatsCard is only available when built with the "card" tag, which pulls
+// in the image dependencies.
+func (s *UserService) RenderStatsCard(userID string) ([]byte, error) {
+	return nil, ErrStatsCardUnavailable
+}
@@ -0,0 +1,16 @@
+//go:build !card
+
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRenderStatsCardUnavailable(t *testing.T) {
+	s := NewUserService(nil, nil, newTestLogger(), nil)
+
+	if _, err := s.RenderStatsCard("1"); !errors.Is(err, ErrStatsCardUnavailable) {
+		t.Errorf("got %v, want ErrStatsCardUnavailable without the card tag", err)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build card
+
+package services
+
+import (
+	"bytes"
+	"image/png"
+	"sletish/internal/models"
+	"testing"
+)
+
+func TestRenderStatsCard(t *testing.T) {
+	s := newTestUserService(t, models.AnimeData{MalID: 1, Title: "A title far too long to fit on the card without being cut somewhere along the way", Type: "TV"})
+	newTestUser(t, s, "1")
+
+	rating := 9.0
+	for id, status := range map[int]models.Status{1: models.StatusCompleted, 2: models.StatusWatching, 3: models.StatusWatching} {
+		if err := s.AddToUserList("1", id, status, &rating, nil); err != nil {
+			t.Fatalf("failed to add anime %d: %v", id, err)
+		}
+	}
+
+	card, err := s.RenderStatsCard("1")
+	if err != nil {
+		t.Fatalf("RenderStatsCard failed: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(card))
+	if err != nil {
+		t.Fatalf("card isn't a valid PNG: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != cardWidth || bounds.Dy() == 0 {
+		t.Errorf("got a %dx%d card, want %d wide", bounds.Dx(), bounds.Dy(), cardWidth)
+	}
+}
+
+func TestRenderStatsCardEmptyList(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	card, err := s.RenderStatsCard("1")
+	if err != nil {
+		t.Fatalf("RenderStatsCard failed: %v", err)
+	}
+	if _, err := png.Decode(bytes.NewReader(card)); err != nil {
+		t.Errorf("card isn't a valid PNG: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+const chatModeCachePrefix = "chat:mode:"
+
+var (
+	ErrNotGroupChat    = errors.New("not a group chat")
+	ErrInvalidChatMode = errors.New("invalid chat mode")
+)
+
+// A shared chat's list belongs to a users row whose ID is the chat's ID, with the
+// telegram_chat platform. Group chat IDs are negative, so they can't collide with user
+// IDs, and every list query keyed by user_id works for the shared list unchanged.
+
+// IsGroupChat reports whether chatID is a group chat rather than a private one.
+func IsGroupChat(chatID string) bool {
+	id, err := strconv.ParseInt(chatID, 10, 64)
+	return err == nil && id < 0
+}
+
+// GetChatMode returns the chat's mode, personal for chats that never set one.
+// If available, it reads it from Redis cache first.
+func (s *UserService) GetChatMode(chatID string) (models.ChatMode, error) {
+	if !IsGroupChat(chatID) {
+		return models.ChatModePersonal, nil
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	cacheKey := chatModeCachePrefix + chatID
+
+	if s.redis != nil {
+		cached, err := s.redis.Get(ctx, cacheKey).Result()
+		if err == nil {
+			if mode := models.ChatMode(cached); mode.IsValid() {
+				return mode, nil
+			}
+		} else if err != redis.Nil {
+			s.logger.WithError(err).Warn("Failed to read from Redis")
+		}
+	}
+
+	var mode models.ChatMode
+	err := s.db.QueryRow(ctx, "SELECT chat_mode FROM chat_settings WHERE chat_id = $1", chatID).Scan(&mode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		mode = models.ChatModePersonal
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get chat mode: %w", err)
+	}
+
+	if s.redis != nil {
+		if err := s.redis.Set(ctx, cacheKey, string(mode), userCacheTTL).Err(); err != nil {
+			s.logger.WithError(err).Warn("Failed to cache chat mode")
+		}
+	}
+
+	return mode, nil
+}
+
+// SetChatMode changes a group chat's mode. Switching to shared creates the chat's
+// list if it doesn't have one yet; switching back to personal keeps it for next time.
+// Returns ErrNotGroupChat for private chats or ErrInvalidChatMode.
+func (s *UserService) SetChatMode(chatID, updatedBy string, mode models.ChatMode) error {
+	if !IsGroupChat(chatID) {
+		return fmt.Errorf("%w: %q", ErrNotGroupChat, chatID)
+	}
+	if !mode.IsValid() {
+		return fmt.Errorf("%w: %q", ErrInvalidChatMode, mode)
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin chat mode update: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if mode == models.ChatModeShared {
+		ownerQuery := `
+			INSERT INTO users (id, platform, created_at, updated_at)
+			VALUES ($1, 'telegram_chat', NOW(), NOW())
+			ON CONFLICT (id) DO NOTHING
+		`
+		if _, err := tx.Exec(ctx, ownerQuery, chatID); err != nil {
+			return fmt.Errorf("failed to create shared list owner: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO chat_settings (chat_id, chat_mode, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (chat_id) DO UPDATE SET chat_mode = EXCLUDED.chat_mode, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`
+	if _, err := tx.Exec(ctx, query, chatID, string(mode), updatedBy); err != nil {
+		return fmt.Errorf("failed to update chat mode: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit chat mode update: %w", err)
+	}
+
+	if s.redis != nil {
+		if err := s.redis.Del(context.Background(), chatModeCachePrefix+chatID).Err(); err != nil {
+			s.logger.WithError(err).Warn("Failed to invalidate chat mode cache")
+		}
+	}
+	return nil
+}
+
+// ListOwner returns whose list userID's list commands in chatID act on: the chat's
+// when it's a shared group chat, userID's own otherwise.
+func (s *UserService) ListOwner(userID, chatID string) (string, error) {
+	mode, err := s.GetChatMode(chatID)
+	if err != nil {
+		return "", err
+	}
+
+	if mode == models.ChatModeShared {
+		return chatID, nil
+	}
+	return userID, nil
+}
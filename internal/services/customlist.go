@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrCustomListExists   = errors.New("custom list already exists")
+	ErrCustomListNotFound = errors.New("custom list not found")
+	ErrCustomListLimit    = errors.New("too many custom lists")
+	ErrAlreadyInList      = errors.New("anime already in list")
+)
+
+// CreateCustomList creates a new named list for the user. Names are unique per user, ignoring case.
+func (s *UserService) CreateCustomList(userID, name string) (*models.CustomList, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	var count int
+	if err := s.db.QueryRow(ctx, "SELECT COUNT(*) FROM custom_lists WHERE user_id = $1", userID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count custom lists: %w", err)
+	}
+	if count >= models.MaxCustomListsPerUser {
+		return nil, ErrCustomListLimit
+	}
+
+	query := `
+		INSERT INTO custom_lists (user_id, name, created_at)
+		VALUES ($1, $2, NOW())
+		RETURNING id, created_at
+	`
+
+	list := &models.CustomList{UserID: userID, Name: name}
+	err := s.db.QueryRow(ctx, query, userID, name).Scan(&list.ID, &list.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			return nil, ErrCustomListExists
+		}
+		return nil, fmt.Errorf("failed to create custom list: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"list_id": list.ID,
+	}).Info("Created custom list")
+
+	return list, nil
+}
+
+// GetCustomLists returns the user's custom lists with their item counts, by name.
+func (s *UserService) GetCustomLists(userID string) ([]models.CustomList, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT cl.id, cl.user_id, cl.name, COUNT(cli.media_id), cl.created_at
+		FROM custom_lists cl
+		LEFT JOIN custom_list_items cli ON cli.list_id = cl.id
+		WHERE cl.user_id = $1
+		GROUP BY cl.id
+		ORDER BY LOWER(cl.name) ASC
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custom lists: %w", err)
+	}
+	defer rows.Close()
+
+	var lists []models.CustomList
+	for rows.Next() {
+		var list models.CustomList
+		if err := rows.Scan(&list.ID, &list.UserID, &list.Name, &list.ItemCount, &list.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan custom list: %w", err)
+		}
+		lists = append(lists, list)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating custom lists: %w", err)
+	}
+
+	return lists, nil
+}
+
+// AddToCustomList adds an anime to one of the user's custom lists, creating the media
+// entry from Jikan if needed. Returns ErrAlreadyInList if it's already there.
+func (s *UserService) AddToCustomList(userID, name string, animeID int) (*models.Media, error) {
+	list, err := s.getCustomList(userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := s.getOrCreateMediaByID(animeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get/create media: %w", err)
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		INSERT INTO custom_list_items (list_id, media_id, added_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (list_id, media_id) DO NOTHING
+	`
+
+	result, err := s.db.Exec(ctx, query, list.ID, media.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add to custom list: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return media, ErrAlreadyInList
+	}
+
+	return media, nil
+}
+
+// GetCustomListItems returns a custom list and its anime, most recently added first.
+func (s *UserService) GetCustomListItems(userID, name string) (*models.CustomList, []models.Media, error) {
+	list, err := s.getCustomList(userID, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT m.id, m.external_id, m.title, m.type
+		FROM custom_list_items cli
+		JOIN media m ON m.id = cli.media_id
+		WHERE cli.list_id = $1
+		ORDER BY cli.added_at DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, list.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query custom list items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.Media
+	for rows.Next() {
+		var media models.Media
+		if err := rows.Scan(&media.ID, &media.ExternalID, &media.Title, &media.Type); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan custom list item: %w", err)
+		}
+		items = append(items, media)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating custom list items: %w", err)
+	}
+
+	list.ItemCount = len(items)
+	return list, items, nil
+}
+
+// getCustomList finds one of the user's lists by name, ignoring case.
+func (s *UserService) getCustomList(userID, name string) (*models.CustomList, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, name, created_at
+		FROM custom_lists
+		WHERE user_id = $1 AND LOWER(name) = LOWER($2)
+	`
+
+	var list models.CustomList
+	err := s.db.QueryRow(ctx, query, userID, name).Scan(&list.ID, &list.UserID, &list.Name, &list.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrCustomListNotFound, strconv.Quote(name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom list: %w", err)
+	}
+
+	return &list, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"testing"
+)
+
+func TestCreateCustomList(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	newTestUser(t, users, "2")
+
+	list, err := users.CreateCustomList("1", "Movie Night")
+	if err != nil {
+		t.Fatalf("CreateCustomList failed: %v", err)
+	}
+	if list.ID == 0 || list.Name != "Movie Night" || list.CreatedAt.IsZero() {
+		t.Errorf("got %+v, want the new list with an ID and creation time", list)
+	}
+
+	if _, err := users.CreateCustomList("1", "movie night"); !errors.Is(err, ErrCustomListExists) {
+		t.Errorf("got %v for the same name in another case, want ErrCustomListExists", err)
+	}
+	// names are unique per user
+	if _, err := users.CreateCustomList("2", "Movie Night"); err != nil {
+		t.Errorf("another user's list with the same name failed: %v", err)
+	}
+}
+
+func TestCustomListLimit(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+
+	for i := 0; i < models.MaxCustomListsPerUser; i++ {
+		if _, err := users.CreateCustomList("1", fmt.Sprintf("List %d", i)); err != nil {
+			t.Fatalf("CreateCustomList %d failed: %v", i, err)
+		}
+	}
+	if _, err := users.CreateCustomList("1", "One Too Many"); !errors.Is(err, ErrCustomListLimit) {
+		t.Errorf("got %v, want ErrCustomListLimit", err)
+	}
+}
+
+func TestAddToCustomListAndShow(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	users.CreateCustomList("1", "With Friends")
+	users.CreateCustomList("1", "Movie Night")
+
+	for _, id := range []int{1, 2} {
+		if _, err := users.AddToCustomList("1", "movie night", id); err != nil {
+			t.Fatalf("AddToCustomList %d failed: %v", id, err)
+		}
+	}
+	if media, err := users.AddToCustomList("1", "Movie Night", 1); !errors.Is(err, ErrAlreadyInList) || media == nil {
+		t.Errorf("got %v, want ErrAlreadyInList with the anime", err)
+	}
+	if _, err := users.AddToCustomList("1", "Nope", 1); !errors.Is(err, ErrCustomListNotFound) {
+		t.Errorf("got %v for a missing list, want ErrCustomListNotFound", err)
+	}
+
+	list, items, err := users.GetCustomListItems("1", "Movie Night")
+	if err != nil {
+		t.Fatalf("GetCustomListItems failed: %v", err)
+	}
+	if list.ItemCount != 2 || len(items) != 2 {
+		t.Fatalf("got %d items (count %d), want 2", len(items), list.ItemCount)
+	}
+	if items[0].Title != "Test Anime 2" || items[1].Title != "Test Anime 1" {
+		t.Errorf("got %q then %q, want most recently added first", items[0].Title, items[1].Title)
+	}
+
+	// custom lists are kept apart from the status list
+	if entries, _, err := users.GetUserList("1", "", 1, 10); err != nil || len(entries) != 0 {
+		t.Errorf("got status list %+v (err %v), want it empty", entries, err)
+	}
+
+	lists, err := users.GetCustomLists("1")
+	if err != nil {
+		t.Fatalf("GetCustomLists failed: %v", err)
+	}
+	if len(lists) != 2 || lists[0].Name != "Movie Night" || lists[0].ItemCount != 2 || lists[1].ItemCount != 0 {
+		t.Errorf("got %+v, want both lists by name with their counts", lists)
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sletish/internal/models"
+	"strconv"
+	"time"
+)
+
+// exportDateFormat is used for dates in exports, sortable in spreadsheets.
+const exportDateFormat = "2006-01-02"
+
+// csvHeader is the header row of ExportCSV, in column order.
+var csvHeader = []string{"title", "external_id", "status", "rating", "progress", "added"}
+
+// ExportEntry is one list entry in a JSON export.
+type ExportEntry struct {
+	Title      string   `json:"title"`
+	ExternalID string   `json:"external_id"`
+	Status     string   `json:"status"`
+	Rating     *float64 `json:"rating,omitempty"`
+	Progress   int      `json:"progress"`
+	Notes      string   `json:"notes,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Added      string   `json:"added"`
+}
+
+// ExportJSON returns the user's whole list as an indented JSON array, oldest entry first.
+func (s *UserService) ExportJSON(userID string) ([]byte, int, error) {
+	list, err := s.exportList(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]ExportEntry, 0, len(list))
+	for _, item := range list {
+		entry := ExportEntry{
+			Title:      item.Media.Title,
+			ExternalID: item.Media.ExternalID,
+			Status:     string(item.UserMedia.Status),
+			Progress:   item.UserMedia.EpisodesWatched,
+			Notes:      item.UserMedia.Notes,
+			Tags:       item.UserMedia.Tags,
+			Added:      item.UserMedia.CreatedAt.Format(exportDateFormat),
+		}
+		if item.UserMedia.Rating > 0 {
+			rating := item.UserMedia.Rating
+			entry.Rating = &rating
+		}
+		entries = append(entries, entry)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal export: %w", err)
+	}
+
+	return data, len(entries), nil
+}
+
+// ExportCSV returns the user's whole list as CSV with a header row, oldest entry first.
+// Unrated entries have an empty rating.
+func (s *UserService) ExportCSV(userID string) ([]byte, int, error) {
+	list, err := s.exportList(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, 0, fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	for _, item := range list {
+		rating := ""
+		if item.UserMedia.Rating > 0 {
+			rating = strconv.FormatFloat(item.UserMedia.Rating, 'f', -1, 64)
+		}
+
+		record := []string{
+			item.Media.Title,
+			item.Media.ExternalID,
+			string(item.UserMedia.Status),
+			rating,
+			strconv.Itoa(item.UserMedia.EpisodesWatched),
+			item.UserMedia.CreatedAt.Format(exportDateFormat),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, 0, fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, 0, fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	return buf.Bytes(), len(list), nil
+}
+
+// ExportFilename returns a dated filename for an export with the given extension.
+func ExportFilename(ext string) string {
+	return fmt.Sprintf("anime-list-%s.%s", time.Now().Format(exportDateFormat), ext)
+}
+
+// exportList loads every entry of the user's list, oldest first.
+func (s *UserService) exportList(userID string) ([]models.UserMediaWithDetails, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := "SELECT " + userMediaColumns + `
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL
+		ORDER BY um.created_at ASC
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query list for export: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserMediaRows(rows)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"sletish/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExportCSV(t *testing.T) {
+	s := newTestUserService(t,
+		models.AnimeData{MalID: 1, Title: "Kaguya-sama: Love Is War", Type: "TV"},
+		models.AnimeData{MalID: 2, Title: "Monogatari, Second Season", Type: "TV"},
+		models.AnimeData{MalID: 3, Title: `The "Hentai" Prince and the Stony Cat`, Type: "TV"},
+	)
+	newTestUser(t, s, "1")
+
+	rating := 8.5
+	s.AddToUserList("1", 1, models.StatusCompleted, &rating, nil)
+	s.AddToUserList("1", 2, models.StatusWatching, nil, nil)
+	s.SetProgress("1", 2, 7)
+	s.AddToUserList("1", 3, models.StatusWatchlist, nil, nil)
+
+	data, count, err := s.ExportCSV("1")
+	if err != nil {
+		t.Fatalf("ExportCSV failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("got %d entries, want 3", count)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("export isn't valid CSV: %v\n%s", err, data)
+	}
+	if len(records) != 4 || !slices.Equal(records[0], csvHeader) {
+		t.Fatalf("got %q, want the header and 3 rows", records)
+	}
+
+	today := time.Now().Format(exportDateFormat)
+	want := [][]string{
+		{"Kaguya-sama: Love Is War", "1", "completed", "8.5", "0", today},
+		{"Monogatari, Second Season", "2", "watching", "", "7", today},
+		{`The "Hentai" Prince and the Stony Cat`, "3", "watchlist", "", "0", today},
+	}
+	for i, row := range want {
+		if !slices.Equal(records[i+1], row) {
+			t.Errorf("row %d got %q, want %q", i+1, records[i+1], row)
+		}
+	}
+
+	if !bytes.Contains(data, []byte(`"Monogatari, Second Season"`)) || !bytes.Contains(data, []byte(`"The ""Hentai"" Prince and the Stony Cat"`)) {
+		t.Errorf("got %s, want titles with commas and quotes quoted", data)
+	}
+}
+
+func TestExportSkipsArchivedEntries(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	s.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	s.AddToUserList("1", 2, models.StatusDropped, nil, nil)
+	if err := s.RemoveFromUserList("1", 2); err != nil {
+		t.Fatalf("failed to archive: %v", err)
+	}
+
+	data, count, err := s.ExportJSON("1")
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+
+	var entries []ExportEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("export isn't valid JSON: %v", err)
+	}
+	if count != 1 || len(entries) != 1 || entries[0].ExternalID != "1" {
+		t.Errorf("got %d entries %+v, want only the one still in the list", count, entries)
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	feedbackRateLimitPrefix = "feedback:ratelimit:"
+	feedbackRateLimitWindow = 1 * time.Minute // one message per user per window
+	MaxFeedbackLength       = 1000
+)
+
+// ErrFeedbackRateLimited is returned when a user sends feedback again too soon.
+var ErrFeedbackRateLimited = errors.New("feedback rate limited")
+
+type FeedbackService struct {
+	db     *pgxpool.Pool
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+// NewFeedbackService creates and returns a new FeedbackService.
+func NewFeedbackService(db *pgxpool.Pool, redis *redis.Client, logger *logrus.Logger) *FeedbackService {
+	return &FeedbackService{
+		db:     db,
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// Submit stores a user's feedback message.
+// Returns ErrFeedbackRateLimited if the user already sent feedback within the rate limit window.
+func (s *FeedbackService) Submit(userID, message string) (*models.Feedback, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if s.redis != nil {
+		allowed, err := s.redis.SetNX(ctx, feedbackRateLimitPrefix+userID, 1, feedbackRateLimitWindow).Result()
+		if err != nil {
+			// don't lose feedback because redis is down
+			s.logger.WithError(err).Warn("Failed to check feedback rate limit")
+		} else if !allowed {
+			return nil, ErrFeedbackRateLimited
+		}
+	}
+
+	feedback := &models.Feedback{
+		UserID:  userID,
+		Message: message,
+	}
+
+	query := `
+		INSERT INTO feedback (user_id, message, created_at)
+		VALUES ($1, $2, NOW())
+		RETURNING id, created_at
+	`
+
+	if err := s.db.QueryRow(ctx, query, userID, message).Scan(&feedback.ID, &feedback.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":     userID,
+		"feedback_id": feedback.ID,
+	}).Info("Feedback received")
+
+	return feedback, nil
+}
+
+// List returns the most recent feedback, newest first.
+func (s *FeedbackService) List(limit int) ([]models.Feedback, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT f.id, f.user_id, COALESCE(u.username, ''), f.message, f.created_at
+		FROM feedback f
+		LEFT JOIN users u ON u.id = f.user_id
+		ORDER BY f.created_at DESC, f.id DESC
+		LIMIT $1
+	`
+
+	rows, err := s.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedback: %w", err)
+	}
+	defer rows.Close()
+
+	var feedback []models.Feedback
+	for rows.Next() {
+		var f models.Feedback
+		if err := rows.Scan(&f.ID, &f.UserID, &f.Username, &f.Message, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		feedback = append(feedback, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback: %w", err)
+	}
+
+	return feedback, nil
+}
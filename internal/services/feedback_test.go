@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"sletish/internal/testutil"
+	"testing"
+)
+
+func TestFeedbackSubmitAndList(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	newTestUser(t, users, "2")
+	redisClient, server := newTestRedis(t)
+	service := NewFeedbackService(users.db, redisClient, newTestLogger())
+
+	first, err := service.Submit("1", "search misses sequels")
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if first.ID == 0 || first.CreatedAt.IsZero() {
+		t.Errorf("got %+v, want an ID and creation time", first)
+	}
+
+	if _, err := service.Submit("1", "again"); !errors.Is(err, ErrFeedbackRateLimited) {
+		t.Errorf("got %v for a second message in the window, want ErrFeedbackRateLimited", err)
+	}
+	// the limit is per user
+	if _, err := service.Submit("2", "dark mode please"); err != nil {
+		t.Fatalf("Submit for another user failed: %v", err)
+	}
+
+	server.FastForward(feedbackRateLimitWindow)
+	if _, err := service.Submit("1", "after the window"); err != nil {
+		t.Errorf("Submit after the window failed: %v", err)
+	}
+
+	feedback, err := service.List(2)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(feedback) != 2 {
+		t.Fatalf("got %d feedback, want the limit of 2", len(feedback))
+	}
+	if feedback[0].Message != "after the window" || feedback[1].Message != "dark mode please" {
+		t.Errorf("got %q then %q, want newest first", feedback[0].Message, feedback[1].Message)
+	}
+	if feedback[0].UserID != "1" || feedback[0].Username != "tester" {
+		t.Errorf("got sender %q (@%s), want 1 (@tester)", feedback[0].UserID, feedback[0].Username)
+	}
+}
+
+func TestFeedbackListEmpty(t *testing.T) {
+	service := NewFeedbackService(testutil.DB(t), nil, newTestLogger())
+
+	feedback, err := service.List(10)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(feedback) != 0 {
+		t.Errorf("got %d feedback, want none", len(feedback))
+	}
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sletish/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// saveMediaGenres upserts the genres and links them to the media record.
+// Safe to call repeatedly for the same media.
+func saveMediaGenres(ctx context.Context, db *pgxpool.Pool, mediaID int, genres []models.Genre) error {
+	for _, genre := range genres {
+		if genre.Name == "" {
+			continue
+		}
+
+		var genreID int
+		upsertQuery := `
+		INSERT INTO genres (name)
+		VALUES ($1)
+		ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id
+		`
+		if err := db.QueryRow(ctx, upsertQuery, genre.Name).Scan(&genreID); err != nil {
+			return fmt.Errorf("failed to upsert genre %q: %w", genre.Name, err)
+		}
+
+		linkQuery := `
+		INSERT INTO media_genres (media_id, genre_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		`
+		if _, err := db.Exec(ctx, linkQuery, mediaID, genreID); err != nil {
+			return fmt.Errorf("failed to link genre %q: %w", genre.Name, err)
+		}
+	}
+
+	return nil
+}
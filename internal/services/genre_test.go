@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"sletish/internal/models"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestGetUserListByGenre(t *testing.T) {
+	s := newTestUserService(t,
+		models.AnimeData{MalID: 1, Title: "Mecha One", Genres: []models.Genre{{Name: "Action"}, {Name: "Mecha"}}},
+		models.AnimeData{MalID: 2, Title: "Quiet Days", Genres: []models.Genre{{Name: "Slice of Life"}}},
+		models.AnimeData{MalID: 3, Title: "Mecha Two", Genres: []models.Genre{{Name: "Mecha"}}},
+	)
+	newTestUser(t, s, "1")
+	newTestUser(t, s, "2")
+
+	for _, id := range []int{1, 2, 3} {
+		if err := s.AddToUserList("1", id, models.StatusWatching, nil, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", id, err)
+		}
+	}
+	// someone else's entry with the genre isn't in user 1's results
+	if err := s.AddToUserList("2", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+
+	tests := []struct {
+		genre string
+		want  []string
+	}{
+		{"Mecha", []string{"Mecha One", "Mecha Two"}},
+		{"mecha", []string{"Mecha One", "Mecha Two"}}, // case insensitive
+		{"Slice of Life", []string{"Quiet Days"}},
+		{"Horror", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.genre, func(t *testing.T) {
+			entries, total, err := s.GetUserListByGenre("1", tt.genre, 1, 10)
+			if err != nil {
+				t.Fatalf("GetUserListByGenre failed: %v", err)
+			}
+			if total != len(tt.want) || len(entries) != len(tt.want) {
+				t.Fatalf("got %d entries (total %d), want %d", len(entries), total, len(tt.want))
+			}
+
+			got := make(map[string]bool)
+			for _, e := range entries {
+				got[e.Media.Title] = true
+			}
+			for _, title := range tt.want {
+				if !got[title] {
+					t.Errorf("missing %q", title)
+				}
+			}
+		})
+	}
+}
+
+// mediaGenres returns the names of the genres linked to a media row.
+func mediaGenres(t *testing.T, s *UserService, mediaID int) []string {
+	t.Helper()
+
+	rows, err := s.db.Query(context.Background(), `
+		SELECT g.name FROM media_genres mg JOIN genres g ON g.id = mg.genre_id
+		WHERE mg.media_id = $1 ORDER BY g.name`, mediaID)
+	if err != nil {
+		t.Fatalf("failed to query genres: %v", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatalf("failed to read genres: %v", err)
+	}
+	return names
+}
+
+func TestCreateMediaSavesGenresOnce(t *testing.T) {
+	anime := models.AnimeData{MalID: 1, Title: "Mecha One", Genres: []models.Genre{{Name: "Action"}, {Name: "Mecha"}, {Name: ""}}}
+	s := newTestUserService(t, anime)
+
+	media, err := s.createMediaFromJikan(anime)
+	if err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+	if got := mediaGenres(t, s, media.ID); !slices.Equal(got, []string{"Action", "Mecha"}) {
+		t.Errorf("got genres %q, want Action and Mecha", got)
+	}
+
+	again, err := s.createMediaFromJikan(anime)
+	if err != nil {
+		t.Fatalf("failed to create media again: %v", err)
+	}
+	if again.ID != media.ID {
+		t.Errorf("creating again made media %d, want the existing %d", again.ID, media.ID)
+	}
+	if got := mediaGenres(t, s, media.ID); !slices.Equal(got, []string{"Action", "Mecha"}) {
+		t.Errorf("got genres %q after creating again, want no duplicates", got)
+	}
+
+	var genres int
+	if err := s.db.QueryRow(context.Background(), "SELECT COUNT(*) FROM genres").Scan(&genres); err != nil {
+		t.Fatalf("failed to count genres: %v", err)
+	}
+	if genres != 2 {
+		t.Errorf("got %d genres, want 2", genres)
+	}
+}
+
+func TestTopGenres(t *testing.T) {
+	s := newTestUserService(t,
+		models.AnimeData{MalID: 1, Title: "One", Genres: []models.Genre{{Name: "Action"}, {Name: "Mecha"}}},
+		models.AnimeData{MalID: 2, Title: "Two", Genres: []models.Genre{{Name: "Action"}}},
+		models.AnimeData{MalID: 3, Title: "Three", Genres: []models.Genre{{Name: "Action"}, {Name: "Drama"}}},
+	)
+	newTestUser(t, s, "1")
+	for _, id := range []int{1, 2, 3} {
+		if err := s.AddToUserList("1", id, models.StatusCompleted, nil, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", id, err)
+		}
+	}
+
+	top, err := s.TopGenres("1", 1)
+	if err != nil {
+		t.Fatalf("TopGenres failed: %v", err)
+	}
+	if len(top) != 1 || top[0].Name != "Action" || top[0].Count != 3 {
+		t.Errorf("got %+v, want Action with 3", top)
+	}
+}
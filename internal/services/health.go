@@ -0,0 +1,29 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// Ping checks the database and Redis connections and returns how long each round trip took.
+// Redis latency is zero when Redis isn't configured.
+func (s *UserService) Ping() (dbLatency, redisLatency time.Duration, err error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	start := time.Now()
+	if err := s.db.Ping(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to ping database: %w", err)
+	}
+	dbLatency = time.Since(start)
+
+	if s.redis != nil {
+		start = time.Now()
+		if err := s.redis.Ping(ctx).Err(); err != nil {
+			return dbLatency, 0, fmt.Errorf("failed to ping redis: %w", err)
+		}
+		redisLatency = time.Since(start)
+	}
+
+	return dbLatency, redisLatency, nil
+}
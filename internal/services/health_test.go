@@ -0,0 +1,29 @@
+package services
+
+import (
+	"sletish/internal/testutil"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	redisClient, _ := newTestRedis(t)
+	s := NewUserService(testutil.DB(t), redisClient, newTestLogger(), nil)
+
+	dbLatency, redisLatency, err := s.Ping()
+	if err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if dbLatency <= 0 || redisLatency <= 0 {
+		t.Errorf("got database %v and redis %v, want both measured", dbLatency, redisLatency)
+	}
+}
+
+func TestPingRedisDown(t *testing.T) {
+	redisClient, server := newTestRedis(t)
+	server.Close()
+	s := NewUserService(testutil.DB(t), redisClient, newTestLogger(), nil)
+
+	if _, _, err := s.Ping(); err == nil {
+		t.Error("got no error with redis down")
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sletish/internal/models"
+	"sletish/internal/testutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestRedis returns a client for an in-memory Redis that's closed with the test.
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+// newTestLogger returns a logger that discards its output.
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// newTestUserService returns a UserService on the test database, whose anime lookups
+// are answered from anime, or with a made up anime for other IDs. Skipped without
+// TEST_DATABASE_URL.
+func newTestUserService(t *testing.T, anime ...models.AnimeData) *UserService {
+	t.Helper()
+
+	byID := make(map[int]models.AnimeData)
+	for _, a := range anime {
+		byID[a.MalID] = a
+	}
+
+	db := testutil.DB(t)
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/anime/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		data, ok := byID[id]
+		if !ok {
+			data = models.AnimeData{MalID: id, Title: fmt.Sprintf("Test Anime %d", id), Type: "TV"}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	return NewUserService(db, nil, newTestLogger(), client)
+}
+
+// newTestUser creates a user for tests using the database.
+func newTestUser(t *testing.T, s *UserService, userID string) {
+	t.Helper()
+
+	if err := s.EnsureUserExists(userID, "tester", ""); err != nil {
+		t.Fatalf("failed to create user %s: %v", userID, err)
+	}
+}
+
+// newTestTelegram points the Bot API at handler until the test ends.
+func newTestTelegram(t *testing.T, handler http.Handler) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	original := telegramAPIURL
+	telegramAPIURL = server.URL + "/bot"
+	t.Cleanup(func() { telegramAPIURL = original })
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sletish/internal/models"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	lastSearchPrefix = "search:last:"
+	lastSearchTTL    = 24 * time.Hour
+)
+
+// SaveLastSearch remembers the user's most recent search for /again, replacing any earlier one.
+func (s *UserService) SaveLastSearch(userID string, search models.LastSearch) error {
+	if s.redis == nil {
+		return fmt.Errorf("last search requires redis")
+	}
+
+	searchJSON, err := json.Marshal(search)
+	if err != nil {
+		return fmt.Errorf("failed to marshal last search: %w", err)
+	}
+
+	if err := s.redis.Set(context.Background(), lastSearchPrefix+userID, searchJSON, lastSearchTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store last search: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastSearch returns the user's most recent search, or nil if there is none or it has expired.
+func (s *UserService) GetLastSearch(userID string) (*models.LastSearch, error) {
+	if s.redis == nil {
+		return nil, nil
+	}
+
+	cached, err := s.redis.Get(context.Background(), lastSearchPrefix+userID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last search: %w", err)
+	}
+
+	var search models.LastSearch
+	if err := json.Unmarshal([]byte(cached), &search); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal last search: %w", err)
+	}
+
+	return &search, nil
+}
+
+// ClearLastSearch forgets the user's most recent search.
+func (s *UserService) ClearLastSearch(userID string) error {
+	if s.redis == nil {
+		return nil
+	}
+
+	if err := s.redis.Del(context.Background(), lastSearchPrefix+userID).Err(); err != nil {
+		return fmt.Errorf("failed to clear last search: %w", err)
+	}
+
+	return nil
+}
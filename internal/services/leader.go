@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const leaderLockPrefix = "leader:"
+
+// renewLeaderScript extends the lock only while this instance still holds it.
+var renewLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaderScript deletes the lock only if this instance holds it.
+var releaseLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// LeaderLock elects one instance, among replicas sharing Redis, to run a worker.
+// The holder keeps renewing the lock; if it dies, the lock expires after ttl and
+// another instance takes over.
+type LeaderLock struct {
+	redis  *redis.Client
+	logger *logrus.Logger
+	key    string
+	token  string // identifies this instance as the holder
+	ttl    time.Duration
+	leader atomic.Bool
+}
+
+func NewLeaderLock(redis *redis.Client, logger *logrus.Logger, name string, ttl time.Duration) *LeaderLock {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+
+	return &LeaderLock{
+		redis:  redis,
+		logger: logger,
+		key:    leaderLockPrefix + name,
+		token:  hex.EncodeToString(buf),
+		ttl:    ttl,
+	}
+}
+
+// Hold tries to become, then stay, the leader every third of the ttl until ctx is
+// cancelled, and releases the lock on the way out.
+func (l *LeaderLock) Hold(ctx context.Context) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		l.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			l.Release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsLeader reports whether this instance held the lock at the last refresh.
+func (l *LeaderLock) IsLeader() bool {
+	return l.leader.Load()
+}
+
+// Release gives up the lock if this instance holds it, so another can take over right away.
+func (l *LeaderLock) Release() {
+	if !l.leader.Swap(false) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := releaseLeaderScript.Run(ctx, l.redis, []string{l.key}, l.token).Err(); err != nil {
+		l.logger.WithError(err).WithField("lock", l.key).Warn("Failed to release leader lock")
+		return
+	}
+	l.logger.WithField("lock", l.key).Info("Released leader lock")
+}
+
+// refresh renews the lock if this instance holds it, otherwise tries to take it.
+// When Redis can't be reached this instance steps down: skipping work is better
+// than two instances doing it twice.
+func (l *LeaderLock) refresh(ctx context.Context) {
+	held, err := l.tryAcquire(ctx)
+	if err != nil {
+		l.logger.WithError(err).WithField("lock", l.key).Warn("Failed to refresh leader lock")
+		held = false
+	}
+
+	if was := l.leader.Swap(held); was != held {
+		if held {
+			l.logger.WithField("lock", l.key).Info("Acquired leader lock")
+		} else {
+			l.logger.WithField("lock", l.key).Info("Lost leader lock")
+		}
+	}
+}
+
+func (l *LeaderLock) tryAcquire(ctx context.Context) (bool, error) {
+	if l.leader.Load() {
+		renewed, err := renewLeaderScript.Run(ctx, l.redis, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+		if err != nil {
+			return false, err
+		}
+		if renewed == 1 {
+			return true, nil
+		}
+		// it expired under us, e.g. a long pause or a Redis restart; try to take it again
+	}
+
+	return l.redis.SetNX(ctx, l.key, l.token, l.ttl).Result()
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLeaderLockOneHolderAtATime(t *testing.T) {
+	client, _ := newTestRedis(t)
+	first := NewLeaderLock(client, newTestLogger(), "test", time.Minute)
+	second := NewLeaderLock(client, newTestLogger(), "test", time.Minute)
+	ctx := context.Background()
+
+	first.refresh(ctx)
+	second.refresh(ctx)
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("got first %v and second %v, want only the first leading", first.IsLeader(), second.IsLeader())
+	}
+
+	// renewing keeps it
+	first.refresh(ctx)
+	second.refresh(ctx)
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("after renewing got first %v and second %v, want the first still leading", first.IsLeader(), second.IsLeader())
+	}
+
+	first.Release()
+	second.refresh(ctx)
+	if first.IsLeader() || !second.IsLeader() {
+		t.Errorf("after release got first %v and second %v, want the second leading", first.IsLeader(), second.IsLeader())
+	}
+
+	// another name is another lock
+	other := NewLeaderLock(client, newTestLogger(), "other", time.Minute)
+	if other.refresh(ctx); !other.IsLeader() {
+		t.Error("a lock with another name wasn't acquired")
+	}
+}
+
+func TestLeaderLockTakenOverAfterExpiry(t *testing.T) {
+	client, server := newTestRedis(t)
+	first := NewLeaderLock(client, newTestLogger(), "test", time.Minute)
+	second := NewLeaderLock(client, newTestLogger(), "test", time.Minute)
+	ctx := context.Background()
+
+	first.refresh(ctx)
+	// the first stopped renewing, e.g. it died
+	server.FastForward(2 * time.Minute)
+
+	second.refresh(ctx)
+	if !second.IsLeader() {
+		t.Fatal("second didn't take over the expired lock")
+	}
+
+	// the first can't renew or take back a lock that's now the second's
+	first.refresh(ctx)
+	if first.IsLeader() {
+		t.Error("first still leads after its lock was taken over")
+	}
+	if token, _ := server.Get(leaderLockPrefix + "test"); token != second.token {
+		t.Errorf("got lock holder %q, want the second's %q", token, second.token)
+	}
+}
+
+func TestLeaderLockReleaseKeepsOthersLock(t *testing.T) {
+	client, server := newTestRedis(t)
+	first := NewLeaderLock(client, newTestLogger(), "test", time.Minute)
+	second := NewLeaderLock(client, newTestLogger(), "test", time.Minute)
+	ctx := context.Background()
+
+	first.refresh(ctx)
+	server.FastForward(2 * time.Minute)
+	second.refresh(ctx)
+
+	// the first hasn't noticed it lost the lock yet
+	first.Release()
+	if token, _ := server.Get(leaderLockPrefix + "test"); token != second.token {
+		t.Errorf("got lock holder %q after the old holder released, want the second's kept", token)
+	}
+}
+
+func TestLeaderLockStepsDownWithoutRedis(t *testing.T) {
+	client, server := newTestRedis(t)
+	lock := NewLeaderLock(client, newTestLogger(), "test", time.Minute)
+	ctx := context.Background()
+
+	lock.refresh(ctx)
+	if !lock.IsLeader() {
+		t.Fatal("lock wasn't acquired")
+	}
+
+	server.Close()
+	lock.refresh(ctx)
+	if lock.IsLeader() {
+		t.Error("still leading while Redis is unreachable")
+	}
+}
+
+func TestLeaderLockHoldReleasesOnCancel(t *testing.T) {
+	client, server := newTestRedis(t)
+	lock := NewLeaderLock(client, newTestLogger(), "test", 30*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		lock.Hold(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !lock.IsLeader() {
+		if time.Now().After(deadline) {
+			t.Fatal("Hold didn't acquire the lock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// renewed well past its ttl
+	time.Sleep(100 * time.Millisecond)
+	if !server.Exists(leaderLockPrefix + "test") {
+		t.Fatal("lock expired while held")
+	}
+
+	cancel()
+	<-done
+	if lock.IsLeader() || server.Exists(leaderLockPrefix+"test") {
+		t.Error("lock wasn't released when Hold returned")
+	}
+}
+
+func TestReminderWorkerSkipsWhileAnotherInstanceLeads(t *testing.T) {
+	users := newTestUserService(t)
+	newDueReminder(t, users)
+	client, _ := newTestRedis(t)
+
+	var calls atomic.Int32
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+
+	// another replica already holds the lock
+	other := NewLeaderLock(client, newTestLogger(), "reminder-worker", time.Minute)
+	other.refresh(context.Background())
+
+	const interval = 20 * time.Millisecond
+	service := NewReminderService(users.db, newTestLogger(), client, "test", nil, interval)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service.Start(ctx)
+
+	time.Sleep(10 * interval)
+	if calls.Load() != 0 {
+		t.Fatalf("sent %d reminders while another instance held the lock, want none", calls.Load())
+	}
+
+	// once the other replica lets go, this one takes over on its next renewal
+	other.Release()
+	service.leaderLock.refresh(ctx)
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("reminder not sent after taking over the lock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("sent %d reminders, want 1", calls.Load())
+	}
+}
+
+func TestReminderWaitReturnsWithLockReleased(t *testing.T) {
+	client, server := newTestRedis(t)
+	service := NewReminderService(nil, newTestLogger(), client, "", nil, time.Hour)
+
+	// never started, nothing to wait for
+	service.Wait()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	service.Start(ctx)
+
+	deadline := time.Now().Add(time.Second)
+	for !service.leaderLock.IsLeader() {
+		if time.Now().After(deadline) {
+			t.Fatal("worker didn't take the leader lock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	service.Wait()
+
+	if service.GetWorkerStats().IsRunning {
+		t.Error("worker still running after Wait")
+	}
+	// nothing left that could take the lock back once Redis is closed
+	if server.Exists(leaderLockPrefix + "reminder-worker") {
+		t.Error("leader lock still held after Wait")
+	}
+}
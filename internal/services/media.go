@@ -0,0 +1,509 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	repairWorkerInterval = 24 * time.Hour
+	// mediaRefreshTimeout bounds a RefreshStale run, Jikan allows about one request a second
+	mediaRefreshTimeout = time.Hour
+)
+
+var (
+	ErrMediaInUse     = errors.New("media is still referenced")
+	ErrMediaNotFound  = errors.New("media not found")
+	ErrMergeSameMedia = errors.New("cannot merge media into itself")
+)
+
+// OrphanCounts is how many rows point at media that no longer exists.
+type OrphanCounts struct {
+	UserMedia int
+	Reminders int
+}
+
+// MediaService guards media integrity in the application, on top of the schema's
+// foreign keys: media is only deleted when nothing refers to it, and a repair job
+// reports rows left pointing at missing media.
+type MediaService struct {
+	db           *pgxpool.Pool
+	redis        *redis.Client // the users' caches, dropped when their entries' media changes
+	logger       *logrus.Logger
+	isRunning    atomic.Bool
+	startOnce    sync.Once
+	workers      sync.WaitGroup
+	animeService *Client
+}
+
+func NewMediaService(db *pgxpool.Pool, redis *redis.Client, logger *logrus.Logger, animeService *Client) *MediaService {
+	service := &MediaService{
+		db:           db,
+		redis:        redis,
+		logger:       logger,
+		animeService: animeService,
+	}
+
+	return service
+}
+
+// SafeDelete deletes a media row only if no list entry, reminder, custom list or queue refers to it.
+// Returns ErrMediaInUse if it's referenced and ErrMediaNotFound if it doesn't exist.
+func (s *MediaService) SafeDelete(mediaID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// single statement, so nothing can start referencing it between the check and the delete
+	query := `
+		DELETE FROM media m
+		WHERE m.id = $1
+		AND NOT EXISTS (SELECT 1 FROM user_media WHERE media_id = m.id)
+		AND NOT EXISTS (SELECT 1 FROM reminders WHERE media_id = m.id)
+		AND NOT EXISTS (SELECT 1 FROM custom_list_items WHERE media_id = m.id)
+		AND NOT EXISTS (SELECT 1 FROM watch_queue WHERE media_id = m.id)
+	`
+
+	result, err := s.db.Exec(ctx, query, mediaID)
+	if err != nil {
+		return fmt.Errorf("failed to delete media: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		var exists bool
+		if err := s.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM media WHERE id = $1)", mediaID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check media: %w", err)
+		}
+		if !exists {
+			return ErrMediaNotFound
+		}
+		return ErrMediaInUse
+	}
+
+	s.logger.WithField("media_id", mediaID).Info("Deleted media")
+	return nil
+}
+
+// MergeResult is what MergeMedia moved from the dropped media row to the kept one.
+type MergeResult struct {
+	UserMedia          int // list entries repointed
+	Duplicates         int // list entries dropped because the user already had the kept media
+	Reminders          int
+	DuplicateReminders int // pending reminders dropped because the user had the same one for the kept media
+	CustomListItems    int
+	QueueItems         int
+}
+
+// MergeMedia folds the dropID media row into keepID: list entries, reminders, custom
+// list items, queue items and genres are repointed to keepID and dropID is deleted, all in one
+// transaction. Where a user has both rows in their list, or a pending reminder for both at
+// the same time, the one for keepID wins, except that a live list entry beats an archived one.
+// The cached lists of users who had either row are dropped. Returns ErrMediaNotFound if either row doesn't exist.
+func (s *MediaService) MergeMedia(keepID, dropID int) (MergeResult, error) {
+	var result MergeResult
+	if keepID == dropID {
+		return result, ErrMergeSameMedia
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin merge: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// lock both rows so nothing new can reference the dropped one mid-merge
+	var found int
+	lockQuery := `SELECT COUNT(*) FROM (SELECT id FROM media WHERE id IN ($1, $2) FOR UPDATE) locked`
+	if err := tx.QueryRow(ctx, lockQuery, keepID, dropID).Scan(&found); err != nil {
+		return result, fmt.Errorf("failed to lock media: %w", err)
+	}
+	if found != 2 {
+		return result, ErrMediaNotFound
+	}
+
+	// their cached entries still point at the dropped media
+	rows, err := tx.Query(ctx, "SELECT DISTINCT user_id FROM user_media WHERE media_id IN ($1, $2)", keepID, dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to get merged media users: %w", err)
+	}
+	listUsers, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return result, fmt.Errorf("failed to get merged media users: %w", err)
+	}
+
+	// an archived entry for the kept media gives way to a live one for the dropped media,
+	// otherwise the anime would silently leave the user's list
+	archivedQuery := `
+		DELETE FROM user_media k
+		USING user_media d
+		WHERE k.media_id = $1 AND d.media_id = $2 AND d.user_id = k.user_id
+		AND k.deleted_at IS NOT NULL AND d.deleted_at IS NULL
+	`
+	tag, err := tx.Exec(ctx, archivedQuery, keepID, dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to drop archived duplicate user media: %w", err)
+	}
+	result.Duplicates = int(tag.RowsAffected())
+
+	duplicatesQuery := `
+		DELETE FROM user_media d
+		USING user_media k
+		WHERE d.media_id = $2 AND k.media_id = $1 AND k.user_id = d.user_id
+	`
+	tag, err = tx.Exec(ctx, duplicatesQuery, keepID, dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to drop duplicate user media: %w", err)
+	}
+	result.Duplicates += int(tag.RowsAffected())
+
+	tag, err = tx.Exec(ctx, "UPDATE user_media SET media_id = $1 WHERE media_id = $2", keepID, dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to repoint user media: %w", err)
+	}
+	result.UserMedia = int(tag.RowsAffected())
+
+	// a pending reminder at the same time for both would break the pending reminders unique index
+	duplicateRemindersQuery := `
+		DELETE FROM reminders d
+		USING reminders k
+		WHERE d.media_id = $2 AND k.media_id = $1
+		AND k.user_id = d.user_id AND k.remind_at = d.remind_at
+		AND d.sent = false AND d.failed = false
+		AND k.sent = false AND k.failed = false
+	`
+	tag, err = tx.Exec(ctx, duplicateRemindersQuery, keepID, dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to drop duplicate reminders: %w", err)
+	}
+	result.DuplicateReminders = int(tag.RowsAffected())
+
+	tag, err = tx.Exec(ctx, "UPDATE reminders SET media_id = $1 WHERE media_id = $2", keepID, dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to repoint reminders: %w", err)
+	}
+	result.Reminders = int(tag.RowsAffected())
+
+	// lists holding both keep theirs, the rest are left to the cascade below
+	customListQuery := `
+		UPDATE custom_list_items d
+		SET media_id = $1
+		WHERE d.media_id = $2
+		AND NOT EXISTS (SELECT 1 FROM custom_list_items k WHERE k.list_id = d.list_id AND k.media_id = $1)
+	`
+	tag, err = tx.Exec(ctx, customListQuery, keepID, dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to repoint custom list items: %w", err)
+	}
+	result.CustomListItems = int(tag.RowsAffected())
+
+	// same for queues; where both were queued the dropped one goes and the queue closes up
+	queueQuery := `
+		UPDATE watch_queue d
+		SET media_id = $1
+		WHERE d.media_id = $2
+		AND NOT EXISTS (SELECT 1 FROM watch_queue k WHERE k.user_id = d.user_id AND k.media_id = $1)
+	`
+	tag, err = tx.Exec(ctx, queueQuery, keepID, dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to repoint queue items: %w", err)
+	}
+	result.QueueItems = int(tag.RowsAffected())
+
+	rows, err = tx.Query(ctx, "DELETE FROM watch_queue WHERE media_id = $1 RETURNING user_id", dropID)
+	if err != nil {
+		return result, fmt.Errorf("failed to drop duplicate queue items: %w", err)
+	}
+	queueUsers, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return result, fmt.Errorf("failed to drop duplicate queue items: %w", err)
+	}
+	for _, userID := range queueUsers {
+		if err := renumberQueue(ctx, tx, userID); err != nil {
+			return result, err
+		}
+	}
+
+	genresQuery := `
+		INSERT INTO media_genres (media_id, genre_id)
+		SELECT $1, genre_id FROM media_genres WHERE media_id = $2
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, genresQuery, keepID, dropID); err != nil {
+		return result, fmt.Errorf("failed to merge media genres: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM media WHERE id = $1", dropID); err != nil {
+		return result, fmt.Errorf("failed to delete merged media: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	s.invalidateUserCaches(listUsers)
+
+	s.logger.WithFields(logrus.Fields{
+		"keep_id":             keepID,
+		"drop_id":             dropID,
+		"user_media":          result.UserMedia,
+		"duplicates":          result.Duplicates,
+		"reminders":           result.Reminders,
+		"duplicate_reminders": result.DuplicateReminders,
+		"custom_list_items":   result.CustomListItems,
+		"queue_items":         result.QueueItems,
+	}).Info("Merged media")
+
+	return result, nil
+}
+
+// RefreshResult is what a RefreshStale run did.
+type RefreshResult struct {
+	Stale   int // media older than the cutoff
+	Updated int
+	Failed  int
+}
+
+// RefreshStale re-fetches media whose metadata is older than olderThan and updates
+// title, score, episodes, description, airing status and genres. Requests go through
+// the client's rate limiter; the run stops early if Jikan is down. Users with refreshed
+// media in their list have their cached lists dropped.
+func (s *MediaService) RefreshStale(olderThan time.Duration) (RefreshResult, error) {
+	var result RefreshResult
+
+	ctx, cancel := context.WithTimeout(context.Background(), mediaRefreshTimeout)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, `
+		SELECT id, external_id
+		FROM media
+		WHERE COALESCE(refreshed_at, created_at) < $1
+		ORDER BY COALESCE(refreshed_at, created_at) ASC
+	`, time.Now().Add(-olderThan))
+	if err != nil {
+		return result, fmt.Errorf("failed to query stale media: %w", err)
+	}
+
+	type staleMedia struct {
+		id         int
+		externalID string
+	}
+	var stale []staleMedia
+	for rows.Next() {
+		var m staleMedia
+		if err := rows.Scan(&m.id, &m.externalID); err != nil {
+			rows.Close()
+			return result, fmt.Errorf("failed to scan stale media: %w", err)
+		}
+		stale = append(stale, m)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("error iterating stale media: %w", err)
+	}
+
+	result.Stale = len(stale)
+
+	updateQuery := `
+		UPDATE media
+		SET title = $2, rating = $3, episodes = NULLIF($4, 0), description = $5,
+			airing_status = NULLIF($6, ''), format = NULLIF($7, ''), episode_minutes = NULLIF($8, 0),
+			refreshed_at = NOW()
+		WHERE id = $1
+	`
+
+	var updated []int
+	// the caches of users with refreshed media hold the old details, even if the run stops early
+	defer func() {
+		s.invalidateMediaUsers(updated)
+	}()
+
+	for _, m := range stale {
+		if ctx.Err() != nil {
+			return result, fmt.Errorf("media refresh timed out: %w", ctx.Err())
+		}
+
+		animeID, err := strconv.Atoi(m.externalID)
+		if err != nil {
+			s.logger.WithField("media_id", m.id).Warn("Skipping media with a non-numeric external ID")
+			result.Failed++
+			continue
+		}
+
+		anime, err := s.animeService.GetAnimeByID(animeID)
+		if err != nil {
+			if errors.Is(err, ErrServiceUnavailable) {
+				return result, err
+			}
+			s.logger.WithError(err).WithField("media_id", m.id).Warn("Failed to re-fetch media")
+			result.Failed++
+			continue
+		}
+
+		var rating *float64
+		if anime.Score > 0 {
+			rating = &anime.Score
+		}
+
+		if _, err := s.db.Exec(ctx, updateQuery, m.id, anime.Title, rating, anime.Episodes, anime.Synopsis, anime.Status,
+			anime.Type, ParseEpisodeMinutes(anime.Duration)); err != nil {
+			s.logger.WithError(err).WithField("media_id", m.id).Warn("Failed to update media")
+			result.Failed++
+			continue
+		}
+
+		if err := saveMediaGenres(ctx, s.db, m.id, anime.Genres); err != nil {
+			s.logger.WithError(err).WithField("media_id", m.id).Warn("Failed to update media genres")
+		}
+
+		updated = append(updated, m.id)
+		result.Updated++
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"stale":   result.Stale,
+		"updated": result.Updated,
+		"failed":  result.Failed,
+	}).Info("Refreshed stale media")
+
+	return result, nil
+}
+
+// invalidateMediaUsers drops the caches of every user with one of mediaIDs in their list.
+func (s *MediaService) invalidateMediaUsers(mediaIDs []int) {
+	if s.redis == nil || len(mediaIDs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.db.Query(ctx, "SELECT DISTINCT user_id FROM user_media WHERE media_id = ANY($1)", mediaIDs)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to get users of refreshed media")
+		return
+	}
+	userIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to get users of refreshed media")
+		return
+	}
+
+	s.invalidateUserCaches(userIDs)
+}
+
+// invalidateUserCaches removes the users' cached profiles and list entries, like
+// UserService.invalidateUserCache does after a list change.
+func (s *MediaService) invalidateUserCaches(userIDs []string) {
+	if s.redis == nil || len(userIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, 2*len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, userCachePrefix+userID, userEntryCachePrefix+userID)
+	}
+	if err := s.redis.Del(context.Background(), keys...).Err(); err != nil {
+		s.logger.WithError(err).Warn("Failed to invalidate user caches")
+	}
+}
+
+// FindOrphans counts list entries and reminders whose media no longer exists.
+func (s *MediaService) FindOrphans() (OrphanCounts, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var counts OrphanCounts
+
+	userMediaQuery := `
+		SELECT COUNT(*)
+		FROM user_media um
+		LEFT JOIN media m ON m.id = um.media_id
+		WHERE m.id IS NULL
+	`
+	if err := s.db.QueryRow(ctx, userMediaQuery).Scan(&counts.UserMedia); err != nil {
+		return counts, fmt.Errorf("failed to count orphaned user media: %w", err)
+	}
+
+	remindersQuery := `
+		SELECT COUNT(*)
+		FROM reminders r
+		LEFT JOIN media m ON m.id = r.media_id
+		WHERE m.id IS NULL
+	`
+	if err := s.db.QueryRow(ctx, remindersQuery).Scan(&counts.Reminders); err != nil {
+		return counts, fmt.Errorf("failed to count orphaned reminders: %w", err)
+	}
+
+	return counts, nil
+}
+
+// Start runs the repair worker in the background until ctx is cancelled or StopWorker
+// is called. Only the first call starts a worker, so a service never runs two.
+func (s *MediaService) Start(ctx context.Context) {
+	s.startOnce.Do(func() {
+		s.isRunning.Store(true)
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			s.runWorker(ctx)
+		}()
+	})
+}
+
+// Wait blocks until the worker started by Start has stopped. It returns at once if
+// the worker was never started.
+func (s *MediaService) Wait() {
+	s.workers.Wait()
+}
+
+func (s *MediaService) runWorker(ctx context.Context) {
+	s.logger.Info("Starting media repair worker...")
+	ticker := time.NewTicker(repairWorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.isRunning.Store(false)
+		case <-ticker.C:
+		}
+
+		if !s.isRunning.Load() {
+			break
+		}
+
+		counts, err := s.FindOrphans()
+		if err != nil {
+			s.logger.WithError(err).Error("Error checking for orphaned media references")
+			continue
+		}
+
+		if counts.UserMedia > 0 || counts.Reminders > 0 {
+			// these would be silently dropped by every join on media
+			s.logger.WithFields(logrus.Fields{
+				"user_media": counts.UserMedia,
+				"reminders":  counts.Reminders,
+			}).Warn("Found rows referencing missing media")
+		}
+	}
+
+	s.logger.Info("Media repair worker stopped")
+}
+
+func (s *MediaService) StopWorker() {
+	s.isRunning.Store(false)
+	s.logger.Info("Media repair worker stop requested")
+}
@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sletish/internal/models"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeMediaRepointsReferences(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	newTestUser(t, users, "2")
+	media := NewMediaService(users.db, nil, newTestLogger(), nil)
+	ctx := context.Background()
+
+	// user 1 has both rows in their list, user 2 only the duplicate
+	users.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	users.AddToUserList("1", 2, models.StatusCompleted, nil, nil)
+	users.AddToUserList("2", 2, models.StatusCompleted, nil, nil)
+	if _, err := users.CreateCustomList("1", "favs"); err != nil {
+		t.Fatalf("failed to create custom list: %v", err)
+	}
+	if _, err := users.AddToCustomList("1", "favs", 2); err != nil {
+		t.Fatalf("failed to add to custom list: %v", err)
+	}
+
+	keep, err := users.GetMediaByAnimeID(1)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	drop, err := users.GetMediaByAnimeID(2)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	insert := "INSERT INTO reminders (user_id, media_id, message, remind_at) VALUES ('2', $1, 'test', NOW() + INTERVAL '1 day')"
+	if _, err := users.db.Exec(ctx, insert, drop.ID); err != nil {
+		t.Fatalf("failed to create reminder: %v", err)
+	}
+
+	result, err := media.MergeMedia(keep.ID, drop.ID)
+	if err != nil {
+		t.Fatalf("MergeMedia failed: %v", err)
+	}
+	want := MergeResult{UserMedia: 1, Duplicates: 1, Reminders: 1, CustomListItems: 1}
+	if result != want {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+
+	for _, table := range []string{"user_media", "reminders", "custom_list_items"} {
+		var count int
+		if err := users.db.QueryRow(ctx, "SELECT COUNT(*) FROM "+table+" WHERE media_id = $1", drop.ID).Scan(&count); err != nil {
+			t.Fatalf("failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("got %d %s rows still pointing at the dropped media", count, table)
+		}
+	}
+	if counts, err := media.FindOrphans(); err != nil || counts != (OrphanCounts{}) {
+		t.Errorf("got %+v (err %v), want nothing orphaned", counts, err)
+	}
+	if _, err := users.GetMediaByAnimeID(2); err == nil {
+		t.Error("dropped media still exists")
+	}
+
+	// the kept entry wins where the user had both
+	entries, _, err := users.GetUserList("1", "", 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get list: %v", err)
+	}
+	if len(entries) != 1 || entries[0].UserMedia.Status != models.StatusWatching {
+		t.Errorf("got %+v, want only the kept watching entry", entries)
+	}
+	if entries, _, err := users.GetUserList("2", "", 1, 10); err != nil || len(entries) != 1 || entries[0].Media.ID != keep.ID {
+		t.Errorf("got %+v (err %v), want user 2's entry moved to the kept media", entries, err)
+	}
+}
+
+func TestMergeMediaKeepsLiveEntryOverArchived(t *testing.T) {
+	users := newArchivingUserService(t)
+	media := NewMediaService(users.db, nil, newTestLogger(), nil)
+
+	// the entry for the kept media was archived, the one for the dropped media is live
+	users.AddToUserList("1", 1, models.StatusDropped, nil, nil)
+	if err := users.RemoveFromUserList("1", 1); err != nil {
+		t.Fatalf("failed to archive: %v", err)
+	}
+	users.AddToUserList("1", 2, models.StatusWatching, nil, nil)
+
+	keep, err := users.GetMediaByAnimeID(1)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	drop, err := users.GetMediaByAnimeID(2)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+
+	result, err := media.MergeMedia(keep.ID, drop.ID)
+	if err != nil {
+		t.Fatalf("MergeMedia failed: %v", err)
+	}
+	if result.Duplicates != 1 || result.UserMedia != 1 {
+		t.Errorf("got %+v, want the archived duplicate dropped and the live entry moved", result)
+	}
+
+	entries, _, err := users.GetUserList("1", "", 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get list: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Media.ID != keep.ID || entries[0].UserMedia.Status != models.StatusWatching {
+		t.Errorf("got %+v, want the live watching entry on the kept media", entries)
+	}
+	if archived, total, err := users.GetArchivedList("1", 1, 10); err != nil || total != 0 {
+		t.Errorf("got archived %+v (err %v), want nothing archived left", archived, err)
+	}
+}
+
+func TestMergeMediaRejectsBadIDs(t *testing.T) {
+	users := newTestUserService(t)
+	media := NewMediaService(users.db, nil, newTestLogger(), nil)
+
+	keep, err := users.getOrCreateMediaByID(1)
+	if err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+
+	if _, err := media.MergeMedia(keep.ID, keep.ID); !errors.Is(err, ErrMergeSameMedia) {
+		t.Errorf("got %v merging media into itself, want ErrMergeSameMedia", err)
+	}
+	if _, err := media.MergeMedia(keep.ID, keep.ID+1000); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("got %v merging missing media, want ErrMediaNotFound", err)
+	}
+	if _, err := users.GetMediaByAnimeID(1); err != nil {
+		t.Errorf("kept media is gone after a refused merge: %v", err)
+	}
+}
+
+func TestMergeMediaDropsCollidingReminders(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	media := NewMediaService(users.db, nil, newTestLogger(), nil)
+	ctx := context.Background()
+
+	keep, err := users.getOrCreateMediaByID(1)
+	if err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+	drop, err := users.getOrCreateMediaByID(2)
+	if err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+
+	at := time.Now().Add(time.Hour).Truncate(time.Minute)
+	insert := "INSERT INTO reminders (user_id, media_id, message, remind_at) VALUES ('1', $1, 'test', $2)"
+	for _, r := range []struct {
+		mediaID int
+		at      time.Time
+	}{
+		{keep.ID, at},
+		{drop.ID, at},                // same time as the kept one, dropped
+		{drop.ID, at.Add(time.Hour)}, // different time, moved
+	} {
+		if _, err := users.db.Exec(ctx, insert, r.mediaID, r.at); err != nil {
+			t.Fatalf("failed to create reminder: %v", err)
+		}
+	}
+
+	result, err := media.MergeMedia(keep.ID, drop.ID)
+	if err != nil {
+		t.Fatalf("MergeMedia failed: %v", err)
+	}
+	if result.DuplicateReminders != 1 || result.Reminders != 1 {
+		t.Errorf("got %d duplicate and %d moved reminders, want 1 and 1", result.DuplicateReminders, result.Reminders)
+	}
+
+	var count int
+	if err := users.db.QueryRow(ctx, "SELECT COUNT(*) FROM reminders WHERE media_id = $1", keep.ID).Scan(&count); err != nil {
+		t.Fatalf("failed to count reminders: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("got %d reminders for the kept media, want 2", count)
+	}
+}
+
+func TestRefreshStaleUpdatesOldMedia(t *testing.T) {
+	users := newTestUserService(t)
+	ctx := context.Background()
+
+	ids := make(map[int]int) // anime ID to media ID
+	for _, animeID := range []int{1, 2, 3} {
+		media, err := users.getOrCreateMediaByID(animeID)
+		if err != nil {
+			t.Fatalf("failed to create media: %v", err)
+		}
+		ids[animeID] = media.ID
+	}
+	// 1 and 2 are stale, 3 was just fetched
+	age := "UPDATE media SET created_at = NOW() - INTERVAL '40 days' WHERE id = ANY($1)"
+	if _, err := users.db.Exec(ctx, age, []int{ids[1], ids[2]}); err != nil {
+		t.Fatalf("failed to age media: %v", err)
+	}
+
+	// Jikan now has new metadata for 1 and nothing for 2
+	var fetched []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		if r.URL.Path != "/anime/1" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": models.AnimeData{
+			MalID: 1, Title: "Renamed", Score: 8.5, Episodes: 24, Synopsis: "New synopsis.", Status: "Finished Airing", Type: "TV",
+		}})
+	}))
+	media := NewMediaService(users.db, nil, newTestLogger(), client)
+
+	result, err := media.RefreshStale(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("RefreshStale failed: %v", err)
+	}
+	if want := (RefreshResult{Stale: 2, Updated: 1, Failed: 1}); result != want {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+	if strings.Contains(strings.Join(fetched, ","), "/anime/3") {
+		t.Errorf("fetched %q, want the fresh media left alone", fetched)
+	}
+
+	var (
+		title, description string
+		rating             *float64
+		episodes           *int
+		refreshedAt        *time.Time
+	)
+	query := "SELECT title, rating, episodes, description, refreshed_at FROM media WHERE id = $1"
+	if err := users.db.QueryRow(ctx, query, ids[1]).Scan(&title, &rating, &episodes, &description, &refreshedAt); err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	if title != "Renamed" || rating == nil || *rating != 8.5 || episodes == nil || *episodes != 24 || description != "New synopsis." {
+		t.Errorf("got %q, rating %v, episodes %v and %q, want the re-fetched metadata", title, rating, episodes, description)
+	}
+	if refreshedAt == nil {
+		t.Error("got no refreshed_at after refreshing")
+	}
+
+	// only the one that failed is still stale
+	result, err = media.RefreshStale(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("RefreshStale failed: %v", err)
+	}
+	if want := (RefreshResult{Stale: 1, Failed: 1}); result != want {
+		t.Errorf("second run got %+v, want %+v", result, want)
+	}
+}
+
+func TestRefreshStaleStopsWhenJikanIsDown(t *testing.T) {
+	users := newTestUserService(t)
+	for _, animeID := range []int{1, 2} {
+		if _, err := users.getOrCreateMediaByID(animeID); err != nil {
+			t.Fatalf("failed to create media: %v", err)
+		}
+	}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	// the first failure opens the breaker, so the second fetch fails fast and ends the run
+	client.breaker = newCircuitBreaker(1, time.Hour)
+	media := NewMediaService(users.db, nil, newTestLogger(), client)
+
+	result, err := media.RefreshStale(0)
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("got %v, want ErrServiceUnavailable", err)
+	}
+	if want := (RefreshResult{Stale: 2, Failed: 1}); result != want {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+}
+
+func TestMergeMediaDropsCachedEntries(t *testing.T) {
+	users := newTestUserService(t)
+	redisClient, server := newTestRedis(t)
+	users.redis = redisClient
+	newTestUser(t, users, "1")
+	media := NewMediaService(users.db, redisClient, newTestLogger(), nil)
+	ctx := context.Background()
+
+	users.AddToUserList("1", 2, models.StatusWatching, nil, nil)
+	if _, err := users.GetUserEntry(ctx, "1", 2); err != nil {
+		t.Fatalf("GetUserEntry failed: %v", err)
+	}
+	keep, err := users.getOrCreateMediaByID(1)
+	if err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+	drop, err := users.GetMediaByAnimeID(2)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+
+	if _, err := media.MergeMedia(keep.ID, drop.ID); err != nil {
+		t.Fatalf("MergeMedia failed: %v", err)
+	}
+	if server.Exists(userEntryCachePrefix + "1") {
+		t.Error("entry cache survived the merge")
+	}
+	if _, err := users.GetUserEntry(ctx, "1", 2); !errors.Is(err, ErrNotInUserList) {
+		t.Errorf("got %v for the merged away anime, want ErrNotInUserList", err)
+	}
+	if _, err := users.GetUserEntry(ctx, "1", 1); err != nil {
+		t.Errorf("got %v for the kept anime, want the repointed entry", err)
+	}
+}
+
+func TestRefreshStaleDropsCachedEntries(t *testing.T) {
+	users := newTestUserService(t)
+	redisClient, _ := newTestRedis(t)
+	users.redis = redisClient
+	newTestUser(t, users, "1")
+	ctx := context.Background()
+
+	users.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	if _, err := users.GetUserEntry(ctx, "1", 1); err != nil {
+		t.Fatalf("GetUserEntry failed: %v", err)
+	}
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"data": models.AnimeData{MalID: 1, Title: "Renamed", Type: "TV"}})
+	}))
+	media := NewMediaService(users.db, redisClient, newTestLogger(), client)
+
+	if _, err := media.RefreshStale(0); err != nil {
+		t.Fatalf("RefreshStale failed: %v", err)
+	}
+
+	entry, err := users.GetUserEntry(ctx, "1", 1)
+	if err != nil {
+		t.Fatalf("GetUserEntry failed: %v", err)
+	}
+	if entry.Media.Title != "Renamed" {
+		t.Errorf("got title %q, want the refreshed one rather than the cached", entry.Media.Title)
+	}
+}
+
+func TestCreateMediaKeepsFullDescription(t *testing.T) {
+	synopsis := strings.Repeat("A long synopsis. ", 200)
+	users := newTestUserService(t, models.AnimeData{MalID: 1, Title: "Long", Type: "TV", Synopsis: synopsis})
+
+	if _, err := users.getOrCreateMediaByID(1); err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+
+	media, err := users.GetMediaByAnimeID(1)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	if media.Description != synopsis {
+		t.Errorf("got a %d byte description, want the %d byte synopsis intact", len(media.Description), len(synopsis))
+	}
+}
+
+func TestSafeDeleteRefusesReferencedMedia(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	service := NewMediaService(users.db, nil, newTestLogger(), nil)
+
+	if err := users.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add anime: %v", err)
+	}
+	listed, err := users.GetMediaByAnimeID(1)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	unused, err := users.getOrCreateMediaByID(2)
+	if err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+
+	if err := service.SafeDelete(listed.ID); !errors.Is(err, ErrMediaInUse) {
+		t.Errorf("got %v deleting listed media, want ErrMediaInUse", err)
+	}
+	if _, err := users.GetMediaByAnimeID(1); err != nil {
+		t.Errorf("listed media is gone after a refused delete: %v", err)
+	}
+
+	if err := service.SafeDelete(unused.ID); err != nil {
+		t.Fatalf("SafeDelete of unused media failed: %v", err)
+	}
+	if err := service.SafeDelete(unused.ID); !errors.Is(err, ErrMediaNotFound) {
+		t.Errorf("got %v deleting it again, want ErrMediaNotFound", err)
+	}
+}
+
+func TestFindOrphans(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	service := NewMediaService(users.db, nil, newTestLogger(), nil)
+	ctx := context.Background()
+
+	if counts, err := service.FindOrphans(); err != nil || counts != (OrphanCounts{}) {
+		t.Fatalf("got %+v (err %v) on a clean database, want no orphans", counts, err)
+	}
+
+	users.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	media, err := users.GetMediaByAnimeID(1)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	insert := "INSERT INTO reminders (user_id, media_id, message, remind_at) VALUES ('1', $1, 'test', NOW())"
+	if _, err := users.db.Exec(ctx, insert, media.ID); err != nil {
+		t.Fatalf("failed to create reminder: %v", err)
+	}
+
+	// the foreign keys would cascade, so skip them the way a botched manual fix could
+	conn, err := users.db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire connection: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SET session_replication_role = replica"); err != nil {
+		t.Skipf("can't bypass foreign keys on the test database: %v", err)
+	}
+	_, err = conn.Exec(ctx, "DELETE FROM media WHERE id = $1", media.ID)
+	conn.Exec(ctx, "RESET session_replication_role")
+	if err != nil {
+		t.Fatalf("failed to delete media: %v", err)
+	}
+
+	counts, err := service.FindOrphans()
+	if err != nil {
+		t.Fatalf("FindOrphans failed: %v", err)
+	}
+	if counts.UserMedia != 1 || counts.Reminders != 1 {
+		t.Errorf("got %+v, want 1 orphaned list entry and reminder", counts)
+	}
+}
+
+func TestMergeMediaRepointsQueueItems(t *testing.T) {
+	users := newTestUserService(t)
+	media := NewMediaService(users.db, nil, newTestLogger(), nil)
+
+	// user 1 queued both rows, user 2 only the duplicate
+	newTestQueue(t, users, 1, 3, 2, 4)
+	newTestUser(t, users, "2")
+	if _, err := users.AddToQueue("2", 2); err != nil {
+		t.Fatalf("failed to queue: %v", err)
+	}
+
+	keep, err := users.GetMediaByAnimeID(1)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	drop, err := users.GetMediaByAnimeID(2)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+
+	result, err := media.MergeMedia(keep.ID, drop.ID)
+	if err != nil {
+		t.Fatalf("MergeMedia failed: %v", err)
+	}
+	if result.QueueItems != 1 {
+		t.Errorf("got %d queue items moved, want user 2's", result.QueueItems)
+	}
+
+	// queueOrder fails on a gap where the dropped duplicate was
+	if got := queueOrder(t, users, "1"); !slices.Equal(got, []string{"1", "3", "4"}) {
+		t.Errorf("got %v, want the duplicate gone and the queue closed up", got)
+	}
+	if got := queueOrder(t, users, "2"); !slices.Equal(got, []string{"1"}) {
+		t.Errorf("got %v, want user 2's item moved to the kept media", got)
+	}
+}
+
+func TestSafeDeleteRefusesQueuedMedia(t *testing.T) {
+	users := newTestUserService(t)
+	service := NewMediaService(users.db, nil, newTestLogger(), nil)
+	newTestQueue(t, users, 1)
+
+	queued, err := users.GetMediaByAnimeID(1)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	if err := service.SafeDelete(queued.ID); !errors.Is(err, ErrMediaInUse) {
+		t.Errorf("got %v deleting queued media, want ErrMediaInUse", err)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sletish/internal/models"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	pendingActionPrefix = "pending:user:"
+	pendingActionTTL    = 10 * time.Minute // abandoned flows go stale after this
+)
+
+// PendingActionStore keeps each user's in-progress interactive action in Redis.
+// A user has at most one pending action; starting a new one replaces the old.
+type PendingActionStore struct {
+	redis  *redis.Client
+	logger *logrus.Logger
+}
+
+// NewPendingActionStore creates and returns a new PendingActionStore.
+func NewPendingActionStore(redis *redis.Client, logger *logrus.Logger) *PendingActionStore {
+	return &PendingActionStore{
+		redis:  redis,
+		logger: logger,
+	}
+}
+
+// Set stores the user's pending action, replacing any previous one.
+func (s *PendingActionStore) Set(userID string, action models.PendingAction) error {
+	if s.redis == nil {
+		return fmt.Errorf("pending action store requires redis")
+	}
+
+	if action.CreatedAt.IsZero() {
+		action.CreatedAt = time.Now()
+	}
+
+	actionJSON, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending action: %w", err)
+	}
+
+	if err := s.redis.Set(context.Background(), pendingActionPrefix+userID, actionJSON, pendingActionTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store pending action: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the user's pending action, or nil if there is none or it has expired.
+func (s *PendingActionStore) Get(userID string) (*models.PendingAction, error) {
+	if s.redis == nil {
+		return nil, nil
+	}
+
+	cached, err := s.redis.Get(context.Background(), pendingActionPrefix+userID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending action: %w", err)
+	}
+
+	var action models.PendingAction
+	if err := json.Unmarshal([]byte(cached), &action); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending action: %w", err)
+	}
+
+	return &action, nil
+}
+
+// Clear removes the user's pending action. Reports whether there was one.
+func (s *PendingActionStore) Clear(userID string) (bool, error) {
+	if s.redis == nil {
+		return false, nil
+	}
+
+	deleted, err := s.redis.Del(context.Background(), pendingActionPrefix+userID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to clear pending action: %w", err)
+	}
+
+	return deleted > 0, nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"sletish/internal/models"
+	"testing"
+	"time"
+)
+
+func TestPendingActionStore(t *testing.T) {
+	client, server := newTestRedis(t)
+	store := NewPendingActionStore(client, newTestLogger())
+
+	if pending, err := store.Get("1"); err != nil || pending != nil {
+		t.Fatalf("got %+v (err %v), want no pending action", pending, err)
+	}
+
+	action := models.PendingAction{Type: models.PendingConfirmRemove, Data: map[string]string{"anime_id": "5114"}}
+	if err := store.Set("1", action); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	pending, err := store.Get("1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if pending == nil || pending.Type != models.PendingConfirmRemove || pending.Data["anime_id"] != "5114" {
+		t.Fatalf("got %+v, want the confirm remove action", pending)
+	}
+	if pending.CreatedAt.IsZero() {
+		t.Error("got no creation time")
+	}
+	if other, _ := store.Get("2"); other != nil {
+		t.Errorf("got %+v for another user, want nothing", other)
+	}
+
+	if cleared, err := store.Clear("1"); err != nil || !cleared {
+		t.Errorf("got cleared %v (err %v), want the action cleared", cleared, err)
+	}
+	if cleared, err := store.Clear("1"); err != nil || cleared {
+		t.Errorf("got cleared %v (err %v) the second time, want nothing to clear", cleared, err)
+	}
+
+	store.Set("1", action)
+	server.FastForward(pendingActionTTL + time.Second)
+	if pending, err := store.Get("1"); err != nil || pending != nil {
+		t.Errorf("got %+v (err %v) after the TTL, want it expired", pending, err)
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrQueueEmpty    = errors.New("watch queue is empty")
+	ErrQueueFull     = errors.New("watch queue is full")
+	ErrAlreadyQueued = errors.New("anime already queued")
+	ErrNotQueued     = errors.New("anime not in watch queue")
+)
+
+// Queue positions run 1..n without gaps. Every change locks the user's row first, so
+// two changes to the same queue never interleave; the (user_id, position) unique
+// constraint is deferred, which lets positions be shifted freely inside the transaction.
+
+// AddToQueue puts an anime at the end of the user's watch queue, creating the media
+// entry from Jikan if needed. Returns ErrAlreadyQueued or ErrQueueFull.
+func (s *UserService) AddToQueue(userID string, animeID int) (*models.QueueItem, error) {
+	media, err := s.getOrCreateMediaByID(animeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get/create media: %w", err)
+	}
+
+	item := &models.QueueItem{Media: *media}
+	err = s.inQueueTx(userID, func(ctx context.Context, tx pgx.Tx) error {
+		var size int
+		if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM watch_queue WHERE user_id = $1", userID).Scan(&size); err != nil {
+			return fmt.Errorf("failed to count watch queue: %w", err)
+		}
+		if size >= models.MaxQueueSize {
+			return ErrQueueFull
+		}
+
+		query := `
+			INSERT INTO watch_queue (user_id, media_id, position, added_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (user_id, media_id) DO NOTHING
+			RETURNING position, added_at
+		`
+		err := tx.QueryRow(ctx, query, userID, media.ID, size+1).Scan(&item.Position, &item.AddedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAlreadyQueued
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add to watch queue: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// GetQueue returns the user's watch queue, next up first.
+func (s *UserService) GetQueue(userID string) ([]models.QueueItem, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT q.position, q.added_at, m.id, m.external_id, m.title, m.type
+		FROM watch_queue q
+		JOIN media m ON m.id = q.media_id
+		WHERE q.user_id = $1
+		ORDER BY q.position ASC
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watch queue: %w", err)
+	}
+	defer rows.Close()
+
+	var queue []models.QueueItem
+	for rows.Next() {
+		var item models.QueueItem
+		if err := rows.Scan(&item.Position, &item.AddedAt, &item.Media.ID, &item.Media.ExternalID, &item.Media.Title, &item.Media.Type); err != nil {
+			return nil, fmt.Errorf("failed to scan watch queue item: %w", err)
+		}
+		queue = append(queue, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating watch queue: %w", err)
+	}
+
+	return queue, nil
+}
+
+// PopQueue takes the anime at the top of the user's watch queue off it and moves
+// the rest up by one. Returns ErrQueueEmpty if there's nothing queued.
+func (s *UserService) PopQueue(userID string) (*models.QueueItem, error) {
+	var item models.QueueItem
+	err := s.inQueueTx(userID, func(ctx context.Context, tx pgx.Tx) error {
+		query := `
+			DELETE FROM watch_queue q
+			USING media m
+			WHERE m.id = q.media_id AND q.user_id = $1 AND q.position = 1
+			RETURNING q.position, q.added_at, m.id, m.external_id, m.title, m.type
+		`
+		err := tx.QueryRow(ctx, query, userID).Scan(&item.Position, &item.AddedAt, &item.Media.ID, &item.Media.ExternalID, &item.Media.Title, &item.Media.Type)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrQueueEmpty
+		}
+		if err != nil {
+			return fmt.Errorf("failed to pop watch queue: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE watch_queue SET position = position - 1 WHERE user_id = $1", userID); err != nil {
+			return fmt.Errorf("failed to advance watch queue: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// MoveInQueue moves a queued anime to position, shifting the ones in between.
+// Positions past the end move it to the end. Returns the position it ended up at,
+// or ErrNotQueued.
+func (s *UserService) MoveInQueue(userID string, animeID, position int) (int, error) {
+	if position < 1 {
+		return 0, fmt.Errorf("invalid queue position %d", position)
+	}
+
+	err := s.inQueueTx(userID, func(ctx context.Context, tx pgx.Tx) error {
+		current, mediaID, err := queuePosition(ctx, tx, userID, animeID)
+		if err != nil {
+			return err
+		}
+
+		var size int
+		if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM watch_queue WHERE user_id = $1", userID).Scan(&size); err != nil {
+			return fmt.Errorf("failed to count watch queue: %w", err)
+		}
+		position = min(position, size)
+
+		switch {
+		case position < current:
+			_, err = tx.Exec(ctx, "UPDATE watch_queue SET position = position + 1 WHERE user_id = $1 AND position >= $2 AND position < $3", userID, position, current)
+		case position > current:
+			_, err = tx.Exec(ctx, "UPDATE watch_queue SET position = position - 1 WHERE user_id = $1 AND position > $2 AND position <= $3", userID, current, position)
+		default:
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to shift watch queue: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE watch_queue SET position = $3 WHERE user_id = $1 AND media_id = $2", userID, mediaID, position); err != nil {
+			return fmt.Errorf("failed to move queue item: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return position, nil
+}
+
+// RemoveFromQueue takes an anime off the user's watch queue and closes the gap.
+// Returns ErrNotQueued if it wasn't queued.
+func (s *UserService) RemoveFromQueue(userID string, animeID int) error {
+	return s.inQueueTx(userID, func(ctx context.Context, tx pgx.Tx) error {
+		current, mediaID, err := queuePosition(ctx, tx, userID, animeID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, "DELETE FROM watch_queue WHERE user_id = $1 AND media_id = $2", userID, mediaID); err != nil {
+			return fmt.Errorf("failed to remove from watch queue: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "UPDATE watch_queue SET position = position - 1 WHERE user_id = $1 AND position > $2", userID, current); err != nil {
+			return fmt.Errorf("failed to shift watch queue: %w", err)
+		}
+		return nil
+	})
+}
+
+// inQueueTx runs fn in a transaction holding the lock on the user's queue.
+func (s *UserService) inQueueTx(userID string, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin watch queue update: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", userID); err != nil {
+		return fmt.Errorf("failed to lock watch queue: %w", err)
+	}
+
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit watch queue update: %w", err)
+	}
+	return nil
+}
+
+// queuePosition returns where an anime is in the user's queue and its media ID, or ErrNotQueued.
+func queuePosition(ctx context.Context, tx pgx.Tx, userID string, animeID int) (int, int, error) {
+	query := `
+		SELECT q.position, q.media_id
+		FROM watch_queue q
+		JOIN media m ON m.id = q.media_id
+		WHERE q.user_id = $1 AND m.external_id = $2
+	`
+
+	var position, mediaID int
+	err := tx.QueryRow(ctx, query, userID, strconv.Itoa(animeID)).Scan(&position, &mediaID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, ErrNotQueued
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to find queue item: %w", err)
+	}
+
+	return position, mediaID, nil
+}
+
+// renumberQueue closes any gaps in the user's queue positions, keeping the order.
+func renumberQueue(ctx context.Context, tx pgx.Tx, userID string) error {
+	query := `
+		UPDATE watch_queue q
+		SET position = r.rn
+		FROM (
+			SELECT media_id, ROW_NUMBER() OVER (ORDER BY position) AS rn
+			FROM watch_queue
+			WHERE user_id = $1
+		) r
+		WHERE q.user_id = $1 AND q.media_id = r.media_id AND q.position <> r.rn
+	`
+
+	if _, err := tx.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to renumber watch queue: %w", err)
+	}
+	return nil
+}
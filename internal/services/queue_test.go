@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// queueOrder returns the anime IDs in the user's queue, checking positions run 1..n.
+func queueOrder(t *testing.T, s *UserService, userID string) []string {
+	t.Helper()
+
+	queue, err := s.GetQueue(userID)
+	if err != nil {
+		t.Fatalf("failed to get queue: %v", err)
+	}
+
+	var ids []string
+	for i, item := range queue {
+		if item.Position != i+1 {
+			t.Errorf("%s is at position %d, want %d", item.Media.ExternalID, item.Position, i+1)
+		}
+		ids = append(ids, item.Media.ExternalID)
+	}
+	return ids
+}
+
+// newTestQueue creates user 1 with the given anime queued in order.
+func newTestQueue(t *testing.T, s *UserService, animeIDs ...int) {
+	t.Helper()
+
+	newTestUser(t, s, "1")
+	for _, id := range animeIDs {
+		if _, err := s.AddToQueue("1", id); err != nil {
+			t.Fatalf("failed to queue %d: %v", id, err)
+		}
+	}
+}
+
+func TestAddToQueueAppends(t *testing.T) {
+	s := newTestUserService(t)
+	newTestQueue(t, s)
+
+	for i, id := range []int{3, 1, 2} {
+		item, err := s.AddToQueue("1", id)
+		if err != nil {
+			t.Fatalf("AddToQueue failed: %v", err)
+		}
+		if item.Position != i+1 || item.Media.ExternalID != fmt.Sprint(id) {
+			t.Errorf("got %+v, want %d at position %d", item, id, i+1)
+		}
+	}
+
+	if _, err := s.AddToQueue("1", 1); !errors.Is(err, ErrAlreadyQueued) {
+		t.Errorf("got %v queueing twice, want ErrAlreadyQueued", err)
+	}
+	if got := queueOrder(t, s, "1"); !slices.Equal(got, []string{"3", "1", "2"}) {
+		t.Errorf("got %v, want the order they were added in", got)
+	}
+}
+
+func TestAddToQueueRespectsMaxSize(t *testing.T) {
+	s := newTestUserService(t)
+	newTestQueue(t, s)
+
+	for id := 1; id <= models.MaxQueueSize; id++ {
+		if _, err := s.AddToQueue("1", id); err != nil {
+			t.Fatalf("failed to queue %d: %v", id, err)
+		}
+	}
+	if _, err := s.AddToQueue("1", models.MaxQueueSize+1); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("got %v, want ErrQueueFull", err)
+	}
+}
+
+func TestAddToQueueConcurrently(t *testing.T) {
+	s := newTestUserService(t)
+	newTestQueue(t, s)
+
+	var wg sync.WaitGroup
+	for id := 1; id <= 10; id++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.AddToQueue("1", id); err != nil {
+				t.Errorf("failed to queue %d: %v", id, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// queueOrder fails on duplicate or skipped positions
+	if got := queueOrder(t, s, "1"); len(got) != 10 {
+		t.Errorf("got %d queued, want 10", len(got))
+	}
+}
+
+func TestPopQueueAdvances(t *testing.T) {
+	s := newTestUserService(t)
+	newTestQueue(t, s, 1, 2, 3)
+
+	for _, want := range []string{"1", "2", "3"} {
+		item, err := s.PopQueue("1")
+		if err != nil {
+			t.Fatalf("PopQueue failed: %v", err)
+		}
+		if item.Media.ExternalID != want {
+			t.Errorf("popped %s, want %s", item.Media.ExternalID, want)
+		}
+		if rest := queueOrder(t, s, "1"); len(rest) > 0 && rest[0] == want {
+			t.Errorf("got %v, want %s gone from the top", rest, want)
+		}
+	}
+
+	if _, err := s.PopQueue("1"); !errors.Is(err, ErrQueueEmpty) {
+		t.Errorf("got %v, want ErrQueueEmpty", err)
+	}
+
+	// a popped anime can be queued again, at the end
+	if item, err := s.AddToQueue("1", 1); err != nil || item.Position != 1 {
+		t.Errorf("got %+v (err %v), want it queued at position 1", item, err)
+	}
+}
+
+func TestMoveInQueue(t *testing.T) {
+	tests := []struct {
+		name     string
+		animeID  int
+		position int
+		wantAt   int
+		want     []string
+	}{
+		{"to the top", 4, 1, 1, []string{"4", "1", "2", "3"}},
+		{"down", 1, 3, 3, []string{"2", "3", "1", "4"}},
+		{"up one", 3, 2, 2, []string{"1", "3", "2", "4"}},
+		{"past the end", 2, 99, 4, []string{"1", "3", "4", "2"}},
+		{"where it is", 2, 2, 2, []string{"1", "2", "3", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestUserService(t)
+			newTestQueue(t, s, 1, 2, 3, 4)
+
+			at, err := s.MoveInQueue("1", tt.animeID, tt.position)
+			if err != nil {
+				t.Fatalf("MoveInQueue failed: %v", err)
+			}
+			if at != tt.wantAt {
+				t.Errorf("moved to %d, want %d", at, tt.wantAt)
+			}
+			if got := queueOrder(t, s, "1"); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveInQueueRejectsBadInput(t *testing.T) {
+	s := newTestUserService(t)
+	newTestQueue(t, s, 1, 2)
+
+	if _, err := s.MoveInQueue("1", 3, 1); !errors.Is(err, ErrNotQueued) {
+		t.Errorf("got %v moving an unqueued anime, want ErrNotQueued", err)
+	}
+	if _, err := s.MoveInQueue("1", 2, 0); err == nil {
+		t.Error("moving to position 0 succeeded")
+	}
+	if got := queueOrder(t, s, "1"); !slices.Equal(got, []string{"1", "2"}) {
+		t.Errorf("got %v, want the queue unchanged", got)
+	}
+}
+
+func TestRemoveFromQueueClosesGap(t *testing.T) {
+	s := newTestUserService(t)
+	newTestQueue(t, s, 1, 2, 3)
+
+	if err := s.RemoveFromQueue("1", 2); err != nil {
+		t.Fatalf("RemoveFromQueue failed: %v", err)
+	}
+	if err := s.RemoveFromQueue("1", 2); !errors.Is(err, ErrNotQueued) {
+		t.Errorf("got %v removing it again, want ErrNotQueued", err)
+	}
+	if got := queueOrder(t, s, "1"); !slices.Equal(got, []string{"1", "3"}) {
+		t.Errorf("got %v, want 1 and 3", got)
+	}
+
+	// added after the gap was closed, so it lands right after
+	if item, err := s.AddToQueue("1", 4); err != nil || item.Position != 3 {
+		t.Errorf("got %+v (err %v), want it at position 3", item, err)
+	}
+}
+
+func TestQueuesAreSeparatePerUser(t *testing.T) {
+	s := newTestUserService(t)
+	newTestQueue(t, s, 1, 2)
+	newTestUser(t, s, "2")
+
+	if item, err := s.AddToQueue("2", 2); err != nil || item.Position != 1 {
+		t.Fatalf("got %+v (err %v), want user 2's queue to start at 1", item, err)
+	}
+	if _, err := s.PopQueue("2"); err != nil {
+		t.Fatalf("PopQueue failed: %v", err)
+	}
+	if got := queueOrder(t, s, "1"); !slices.Equal(got, []string{"1", "2"}) {
+		t.Errorf("got %v, want user 1's queue untouched", got)
+	}
+}
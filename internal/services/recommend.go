@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"sletish/internal/models"
+)
+
+// ListedExternalIDs returns the external IDs of every anime in the user's list, to
+// keep suggestions to things they haven't seen. With includeDropped, dropped entries
+// are left out of the set so they can be suggested again.
+func (s *UserService) ListedExternalIDs(userID string, includeDropped bool) (map[string]bool, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT m.external_id
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL AND NOT ($2 AND um.status = $3)
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, includeDropped, models.StatusDropped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query listed anime: %w", err)
+	}
+	defer rows.Close()
+
+	listed := make(map[string]bool)
+	for rows.Next() {
+		var externalID string
+		if err := rows.Scan(&externalID); err != nil {
+			return nil, fmt.Errorf("failed to scan listed anime: %w", err)
+		}
+		listed[externalID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating listed anime: %w", err)
+	}
+
+	return listed, nil
+}
+
+// RecommendationSeeds returns up to limit external IDs of the anime the user liked
+// most, to base recommendations on: highest rated first, then completed and
+// watching entries, most recently updated first.
+func (s *UserService) RecommendationSeeds(userID string, limit int) ([]string, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT m.external_id
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL AND um.status IN ($2, $3)
+		ORDER BY um.rating DESC NULLS LAST, um.status = $2 DESC, um.updated_at DESC
+		LIMIT $4
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, models.StatusCompleted, models.StatusWatching, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recommendation seeds: %w", err)
+	}
+	defer rows.Close()
+
+	var seeds []string
+	for rows.Next() {
+		var externalID string
+		if err := rows.Scan(&externalID); err != nil {
+			return nil, fmt.Errorf("failed to scan recommendation seed: %w", err)
+		}
+		seeds = append(seeds, externalID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recommendation seeds: %w", err)
+	}
+
+	return seeds, nil
+}
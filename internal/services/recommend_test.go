@@ -0,0 +1,36 @@
+package services
+
+import (
+	"maps"
+	"sletish/internal/models"
+	"testing"
+)
+
+func TestListedExternalIDs(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	s.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	s.AddToUserList("1", 2, models.StatusCompleted, nil, nil)
+	s.AddToUserList("1", 3, models.StatusDropped, nil, nil)
+	s.AddToUserList("1", 4, models.StatusWatchlist, nil, nil)
+	s.RemoveFromUserList("1", 4)
+
+	tests := []struct {
+		includeDropped bool
+		want           map[string]bool
+	}{
+		{false, map[string]bool{"1": true, "2": true, "3": true}},
+		{true, map[string]bool{"1": true, "2": true}},
+	}
+
+	for _, tt := range tests {
+		listed, err := s.ListedExternalIDs("1", tt.includeDropped)
+		if err != nil {
+			t.Fatalf("ListedExternalIDs failed: %v", err)
+		}
+		if !maps.Equal(listed, tt.want) {
+			t.Errorf("includeDropped %v got %v, want %v", tt.includeDropped, listed, tt.want)
+		}
+	}
+}
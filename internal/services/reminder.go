@@ -3,9 +3,14 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"sletish/internal/i18n"
 	"sletish/internal/models"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -16,9 +21,24 @@ import (
 )
 
 const (
-	reminderCachePrefix = "reminder:user"
-	reminderCacheTTL    = 10 * time.Minute
-	workerInterval      = 5 * time.Minute
+	// DefaultReminderCheckInterval is how often due reminders are looked for unless configured
+	// otherwise. A reminder goes out at most this long after it's due.
+	DefaultReminderCheckInterval = 5 * time.Minute
+	reminderCachePrefix          = "reminder:user"
+	reminderCacheTTL             = 10 * time.Minute
+	maxReminderFailures          = 5 // delivery attempts before a reminder is given up on
+	// reminderLeaderTTL is how long the reminder worker's leader lock outlives its holder
+	reminderLeaderTTL = 30 * time.Second
+)
+
+// ErrDuplicateReminder is returned when the user already has a pending reminder
+// for the same anime at the same time.
+var ErrDuplicateReminder = errors.New("reminder already exists")
+
+var (
+	ErrAirDateUnknown  = errors.New("air date unknown")
+	ErrAlreadyAired    = errors.New("anime already aired")
+	ErrReminderTooLate = errors.New("reminder time already passed")
 )
 
 type ReminderService struct {
@@ -26,8 +46,12 @@ type ReminderService struct {
 	redis        *redis.Client
 	logger       *logrus.Logger
 	botToken     string
-	isRunning    bool
-	animeService *Client // needed to ccreate media entries
+	isRunning    atomic.Bool
+	startOnce    sync.Once
+	workers      sync.WaitGroup
+	animeService *Client     // needed to ccreate media entries
+	leaderLock   *LeaderLock // nil without Redis, then this instance always processes
+	interval     time.Duration
 }
 
 type ReminderWorkerStats struct {
@@ -38,32 +62,76 @@ type ReminderWorkerStats struct {
 	IsRunning          bool      `json:"is_running"`
 }
 
-func NewReminderService(db *pgxpool.Pool, logger *logrus.Logger, redis *redis.Client, botToken string, animeService *Client) *ReminderService {
+// NewReminderService creates the service. Once started, its worker sends the reminders
+// that are due every interval. A non-positive interval uses DefaultReminderCheckInterval.
+func NewReminderService(db *pgxpool.Pool, logger *logrus.Logger, redis *redis.Client, botToken string, animeService *Client, interval time.Duration) *ReminderService {
+	if interval <= 0 {
+		interval = DefaultReminderCheckInterval
+	}
+
 	service := &ReminderService{
 		db:           db,
 		logger:       logger,
 		botToken:     botToken,
 		animeService: animeService,
+		interval:     interval,
 	}
 
-	// start worker
-	go service.StartReminderWorker()
+	// with several replicas only the lock holder sends reminders
+	if redis != nil {
+		service.leaderLock = NewLeaderLock(redis, logger, "reminder-worker", reminderLeaderTTL)
+	}
 
 	return service
 }
 
-func (s *ReminderService) StartReminderWorker() {
-	s.logger.Info("Starting reminder worker...")
-	s.isRunning = true
+// Start runs the reminder worker in the background until ctx is cancelled or StopWorker
+// is called. Only the first call starts a worker, so a service never runs two.
+func (s *ReminderService) Start(ctx context.Context) {
+	s.startOnce.Do(func() {
+		if s.leaderLock != nil {
+			s.workers.Add(1)
+			go func() {
+				defer s.workers.Done()
+				s.leaderLock.Hold(ctx)
+			}()
+		}
+		s.isRunning.Store(true)
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			s.runWorker(ctx)
+		}()
+	})
+}
+
+// Wait blocks until the worker and leader lock started by Start have stopped, the lock
+// released. It returns at once if the worker was never started.
+func (s *ReminderService) Wait() {
+	s.workers.Wait()
+}
 
-	ticker := time.NewTicker(workerInterval)
+func (s *ReminderService) runWorker(ctx context.Context) {
+	s.logger.WithField("interval", s.interval).Info("Starting reminder worker...")
+	ticker := time.NewTicker(s.interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		if !s.isRunning {
+	for {
+		select {
+		case <-ctx.Done():
+			s.isRunning.Store(false)
+		case <-ticker.C:
+		}
+
+		if !s.isRunning.Load() {
 			break
 		}
 
+		if s.leaderLock != nil && !s.leaderLock.IsLeader() {
+			s.logger.Debug("Another instance holds the reminder lock, skipping")
+			continue
+		}
+
 		s.logger.Debug("Checking for due reminders...")
 
 		if err := s.processDueReminders(); err != nil {
@@ -79,10 +147,13 @@ func (s *ReminderService) processDueReminders() error {
 	defer cancel()
 
 	query := `
-        SELECT r.id, r.user_id, r.media_id, r.message, r.remind_at, m.title, m.external_id
+        SELECT r.id, r.user_id, r.media_id, r.message, r.remind_at, m.title, m.external_id, COALESCE(us.language_code, ''), COALESCE(us.silent_reminders, false)
         FROM reminders r
         JOIN media m ON r.media_id = m.id
-        WHERE r.sent = false AND r.remind_at <= $1
+        JOIN users u ON r.user_id = u.id
+        LEFT JOIN user_settings us ON us.user_id = u.id
+        WHERE r.sent = false AND r.failed = false AND r.remind_at <= $1
+        AND u.blocked = false AND COALESCE(us.notifications_paused, false) = false
         ORDER BY r.remind_at ASC
         LIMIT 50
     `
@@ -98,16 +169,24 @@ func (s *ReminderService) processDueReminders() error {
 
 	for rows.Next() {
 		var reminder = &models.Reminder{} // using the struct fields that matter instead of rewriting the damn thing
-		err := rows.Scan(reminder.ID, reminder.UserID, reminder.MediaID, reminder.Message, reminder.RemindAt, reminder.MediaTitle, reminder.ExternalID)
+		var languageCode string
+		var silent bool
+		err := rows.Scan(&reminder.ID, &reminder.UserID, &reminder.MediaID, &reminder.Message, &reminder.RemindAt, &reminder.MediaTitle, &reminder.ExternalID, &languageCode, &silent)
 		if err != nil {
 			s.logger.WithError(err).Error("Failed to scan reminder row")
 			errorCount++
 			continue
 		}
 
-		if err := s.sendReminderNotification(ctx, reminder.UserID, reminder.MediaTitle, reminder.ExternalID, reminder.Message, reminder.RemindAt); err != nil {
+		if err := s.sendReminderNotification(ctx, reminder.UserID, i18n.Locale(languageCode), reminder.MediaTitle, reminder.ExternalID, reminder.Message, reminder.RemindAt, silent); err != nil {
 			s.logger.WithError(err).Error("Failed to send reminder notification")
 			errorCount++
+
+			// a blocked bot won't be unblocked by retrying
+			giveUp := errors.Is(err, ErrTelegramForbidden)
+			if err := s.recordReminderFailure(ctx, reminder, err, giveUp); err != nil {
+				s.logger.WithError(err).Error("Failed to record reminder failure")
+			}
 			continue
 		}
 
@@ -134,23 +213,48 @@ func (s *ReminderService) processDueReminders() error {
 	return nil
 }
 
-func (s *ReminderService) sendReminderNotification(ctx context.Context, userID, mediaTitle, externalID, message string, remindAt time.Time) error {
+// sendReminderNotification sends the reminder in the user's locale, English if we don't have it.
+// Silent reminders arrive without a notification sound.
+func (s *ReminderService) sendReminderNotification(ctx context.Context, userID, locale, mediaTitle, externalID, message string, remindAt time.Time, silent bool) error {
 	chatID, err := strconv.Atoi(userID)
 	if err != nil {
 		return fmt.Errorf("invalid user ID: %w", err)
 	}
 
-	notificationText := fmt.Sprintf(`🔔 <b>Reminder!</b>
+	// the message is whatever the user typed and the title comes from Jikan, neither is HTML
+	notificationText := i18n.T(locale, i18n.ReminderNotification,
+		html.EscapeString(mediaTitle), html.EscapeString(message), i18n.FormatDate(locale, remindAt), externalID)
 
-🎬 <b>%s</b>
-💬 "%s"
+	return SendSilentTelegramMessage(ctx, s.botToken, chatID, notificationText, silent)
+}
 
-⏰ <i>You set this reminder for %s</i>
+// recordReminderFailure counts a failed delivery attempt. The reminder is marked failed,
+// and no longer retried, once it reaches maxReminderFailures or when giveUp is set.
+func (s *ReminderService) recordReminderFailure(ctx context.Context, reminder *models.Reminder, sendErr error, giveUp bool) error {
+	updateQuery := `
+	UPDATE reminders
+	SET failure_count = failure_count + 1,
+		last_error = $2,
+		failed = ($3 OR failure_count + 1 >= $4)
+	WHERE id = $1
+	RETURNING failed
+	`
 
-<a href="https://myanimelist.net/anime/%s">🔗 View on MyAnimeList</a>`,
-		mediaTitle, message, remindAt.Format("January 2, 2006"), externalID)
+	var failed bool
+	err := s.db.QueryRow(ctx, updateQuery, reminder.ID, sendErr.Error(), giveUp, maxReminderFailures).Scan(&failed)
+	if err != nil {
+		return fmt.Errorf("failed to record reminder failure: %w", err)
+	}
+
+	if failed {
+		s.invalidateUserReminderCache(reminder.UserID)
+		s.logger.WithFields(logrus.Fields{
+			"reminder_id": reminder.ID,
+			"user_id":     reminder.UserID,
+		}).Warn("Giving up on reminder")
+	}
 
-	return SendTelegramMessage(ctx, s.botToken, chatID, notificationText)
+	return nil
 }
 
 func (s *ReminderService) markReminderAsSent(ctx context.Context, reminderID int) error {
@@ -168,6 +272,8 @@ func (s *ReminderService) markReminderAsSent(ctx context.Context, reminderID int
 	return nil
 }
 
+// CreateReminder schedules a reminder about an anime. Times are kept to the minute, and
+// ErrDuplicateReminder is returned if the user already has one pending for the anime then.
 func (s *ReminderService) CreateReminder(userID string, mediaID int, message string, remindAt time.Time) error {
 	s.logger.WithFields(logrus.Fields{
 		"user_id":   userID,
@@ -187,6 +293,7 @@ func (s *ReminderService) CreateReminder(userID string, mediaID int, message str
 	if remindAt.Before(time.Now()) {
 		return fmt.Errorf("reminder time cannot be in the past")
 	}
+	remindAt = remindAt.Truncate(time.Minute)
 
 	// Check if media exists by external_id, create if it doesn't exist
 	media, err := s.getOrCreateMediaByExternalID(mediaID)
@@ -196,11 +303,15 @@ func (s *ReminderService) CreateReminder(userID string, mediaID int, message str
 	insertQuery := `
 	INSERT INTO reminders (user_id, media_id, message, remind_at, sent, created_at)
 	VALUES ($1, $2, $3, $4, false, $5)
+	ON CONFLICT (user_id, media_id, remind_at) WHERE sent = false AND failed = false DO NOTHING
 	RETURNING id
 	`
 	var reminderID int
 	err = s.db.QueryRow(context.Background(), insertQuery, userID, media.ID, message, remindAt, time.Now()).Scan(&reminderID)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDuplicateReminder
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create reminder: %w", err)
 	}
@@ -216,6 +327,25 @@ func (s *ReminderService) CreateReminder(userID string, mediaID int, message str
 	return nil
 }
 
+// AirDateReminderTime returns when to remind about an anime daysBefore days before its
+// air date. Returns ErrAirDateUnknown without a date, ErrAlreadyAired if it has
+// started airing by now, and ErrReminderTooLate if that many days before is already past.
+func AirDateReminderTime(aired models.Aired, daysBefore int, now time.Time) (time.Time, error) {
+	if aired.From == nil {
+		return time.Time{}, ErrAirDateUnknown
+	}
+	if !aired.From.After(now) {
+		return time.Time{}, ErrAlreadyAired
+	}
+
+	remindAt := aired.From.AddDate(0, 0, -daysBefore)
+	if !remindAt.After(now) {
+		return time.Time{}, ErrReminderTooLate
+	}
+
+	return remindAt, nil
+}
+
 func (s *ReminderService) getOrCreateMediaByExternalID(animeID int) (*models.Media, error) {
 	query := `
     SELECT id, external_id, title, type, description, release_date, poster_url, rating, created_at
@@ -275,13 +405,11 @@ func (s *ReminderService) createMediaFromJikan(jikanAnime models.AnimeData) (*mo
 	if len(jikanAnime.Images.JPG.ImageURL) > 0 {
 		posterURL = jikanAnime.Images.JPG.ImageURL
 	}
-	if len(description) > 1000 {
-		description = description[:1000] + "..."
-	}
 
 	insertQuery := `
-        INSERT INTO media (external_id, title, type, description, release_date, poster_url, rating, created_at)
-        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+        INSERT INTO media (external_id, title, type, description, release_date, poster_url, rating, airing_status, created_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NULLIF($9, ''), $8)
+        ON CONFLICT (external_id) DO UPDATE SET title = EXCLUDED.title
         RETURNING id, external_id, title, type, description, release_date, poster_url, rating, created_at
     `
 
@@ -291,7 +419,7 @@ func (s *ReminderService) createMediaFromJikan(jikanAnime models.AnimeData) (*mo
 	now := time.Now()
 
 	err := s.db.QueryRow(context.Background(), insertQuery,
-		externalID, title, "anime", description, releaseDate, posterURL, rating, now).Scan(
+		externalID, title, "anime", description, releaseDate, posterURL, rating, now, jikanAnime.Status).Scan(
 		&media.ID, &media.ExternalID, &media.Title, &media.Type, &media.Description,
 		&dbReleaseDate, &media.PosterURL, &dbRating, &media.CreatedAt,
 	)
@@ -306,6 +434,10 @@ func (s *ReminderService) createMediaFromJikan(jikanAnime models.AnimeData) (*mo
 		media.Rating = &dbRating.Float64
 	}
 
+	if err := saveMediaGenres(context.Background(), s.db, media.ID, jikanAnime.Genres); err != nil {
+		s.logger.WithError(err).Warn("Failed to save media genres")
+	}
+
 	return &media, nil
 }
 
@@ -355,8 +487,8 @@ func (s *ReminderService) GetUserReminders(userID string, includeSent bool) ([]m
 	}
 
 	query := `
-		SELECT r.id, r.user_id, r.media_id, r.message, r.remind_at, r.sent, r.created_at,
-			   m.title, m.poster_url
+		SELECT r.id, r.user_id, r.media_id, r.message, r.remind_at, r.sent, r.failed, r.failure_count,
+			   r.last_error, r.created_at, m.title, m.poster_url
 		FROM reminders r
 		JOIN media m ON r.media_id = m.id
 		WHERE r.user_id = $1
@@ -378,12 +510,12 @@ func (s *ReminderService) GetUserReminders(userID string, includeSent bool) ([]m
 	var reminders []models.Reminder
 	for rows.Next() {
 		var reminder models.Reminder
-		var mediaTitle, posterURL pgtype.Text
+		var mediaTitle, posterURL, lastError pgtype.Text
 
 		err := rows.Scan(
 			&reminder.ID, &reminder.UserID, &reminder.MediaID, &reminder.Message,
-			&reminder.RemindAt, &reminder.Sent, &reminder.CreatedAt,
-			&mediaTitle, &posterURL,
+			&reminder.RemindAt, &reminder.Sent, &reminder.Failed, &reminder.FailureCount,
+			&lastError, &reminder.CreatedAt, &mediaTitle, &posterURL,
 		)
 
 		if err != nil {
@@ -396,6 +528,9 @@ func (s *ReminderService) GetUserReminders(userID string, includeSent bool) ([]m
 		if posterURL.Valid {
 			reminder.MediaPosterURL = posterURL.String
 		}
+		if lastError.Valid {
+			reminder.LastError = lastError.String
+		}
 
 		reminders = append(reminders, reminder)
 	}
@@ -443,16 +578,24 @@ func (s *ReminderService) CancelReminder(userID string, reminderID int) error {
 
 func (s *ReminderService) GetWorkerStats() ReminderWorkerStats {
 	return ReminderWorkerStats{
-		IsRunning: s.isRunning,
+		IsRunning: s.isRunning.Load(),
 		LastRun:   time.Now(),
 	}
 }
 
 func (s *ReminderService) StopWorker() {
-	s.isRunning = false
+	s.isRunning.Store(false)
 	s.logger.Info("Reminder worker stop requested")
 }
 
+// ReleaseLeaderLock hands the reminder lock to another instance, if this one holds it.
+// Call it on shutdown before Redis is closed.
+func (s *ReminderService) ReleaseLeaderLock() {
+	if s.leaderLock != nil {
+		s.leaderLock.Release()
+	}
+}
+
 func (s *ReminderService) SetBotToken(botToken string) {
 	s.botToken = botToken
 }
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sletish/internal/models"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReminderWorkerStartStop(t *testing.T) {
+	service := NewReminderService(nil, newTestLogger(), nil, "", nil, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// concurrent starts and stats reads must not race, run with -race
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			service.Start(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			service.GetWorkerStats()
+		}()
+	}
+	wg.Wait()
+
+	if !service.GetWorkerStats().IsRunning {
+		t.Fatal("worker isn't running after Start")
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for service.GetWorkerStats().IsRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("worker still running after its context was cancelled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReminderWorkerStopBeforeTick(t *testing.T) {
+	service := NewReminderService(nil, newTestLogger(), nil, "", nil, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service.Start(ctx)
+	service.StopWorker()
+
+	if service.GetWorkerStats().IsRunning {
+		t.Error("worker reports running after StopWorker")
+	}
+}
+
+func TestReminderWorkerDeliversWithinInterval(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	media, err := users.getOrCreateMediaByID(1)
+	if err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+
+	delivered := make(chan time.Time, 1)
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case delivered <- time.Now():
+		default:
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+
+	const interval = 200 * time.Millisecond
+	service := NewReminderService(users.db, newTestLogger(), nil, "test", nil, interval)
+
+	// due between two ticks
+	dueAt := time.Now().Add(interval + interval/2)
+	insert := "INSERT INTO reminders (user_id, media_id, message, remind_at) VALUES ('1', $1, 'test', $2)"
+	if _, err := users.db.Exec(context.Background(), insert, media.ID, dueAt); err != nil {
+		t.Fatalf("failed to create reminder: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service.Start(ctx)
+
+	// one interval, plus some slack for the query and the send
+	bound := interval + 500*time.Millisecond
+	select {
+	case at := <-delivered:
+		if at.Before(dueAt) {
+			t.Errorf("reminder sent %v before it was due", dueAt.Sub(at))
+		}
+		if late := at.Sub(dueAt); late > bound {
+			t.Errorf("reminder sent %v after it was due, want within %v", late, bound)
+		}
+	case <-time.After(time.Until(dueAt) + bound):
+		t.Fatalf("reminder not sent within %v of being due", bound)
+	}
+}
+
+// newDueReminder creates a reminder for user 1 that's already due, and returns its ID.
+func newDueReminder(t *testing.T, users *UserService) int {
+	t.Helper()
+
+	newTestUser(t, users, "1")
+	media, err := users.getOrCreateMediaByID(1)
+	if err != nil {
+		t.Fatalf("failed to create media: %v", err)
+	}
+
+	var id int
+	insert := "INSERT INTO reminders (user_id, media_id, message, remind_at) VALUES ('1', $1, 'test', $2) RETURNING id"
+	if err := users.db.QueryRow(context.Background(), insert, media.ID, time.Now().Add(-time.Minute)).Scan(&id); err != nil {
+		t.Fatalf("failed to create reminder: %v", err)
+	}
+	return id
+}
+
+// reminderState returns whether the reminder was sent or given up on, and its failed attempts.
+func reminderState(t *testing.T, users *UserService, id int) (sent, failed bool, failures int) {
+	t.Helper()
+
+	query := "SELECT sent, failed, failure_count FROM reminders WHERE id = $1"
+	if err := users.db.QueryRow(context.Background(), query, id).Scan(&sent, &failed, &failures); err != nil {
+		t.Fatalf("failed to get reminder: %v", err)
+	}
+	return sent, failed, failures
+}
+
+func TestReminderRetriedThenGivenUp(t *testing.T) {
+	users := newTestUserService(t)
+	id := newDueReminder(t, users)
+
+	var calls atomic.Int32
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`{"ok":false,"description":"Bad Gateway"}`))
+	}))
+	service := NewReminderService(users.db, newTestLogger(), nil, "test", nil, time.Hour)
+
+	for attempt := 1; attempt <= maxReminderFailures; attempt++ {
+		if err := service.processDueReminders(); err != nil {
+			t.Fatalf("processDueReminders failed: %v", err)
+		}
+
+		sent, failed, failures := reminderState(t, users, id)
+		if sent || failures != attempt || failed != (attempt == maxReminderFailures) {
+			t.Fatalf("after attempt %d got sent %v, failed %v and %d failures", attempt, sent, failed, failures)
+		}
+	}
+
+	// given up on, so not tried again
+	service.processDueReminders()
+	if calls.Load() != maxReminderFailures {
+		t.Errorf("made %d attempts, want %d", calls.Load(), maxReminderFailures)
+	}
+
+	reminders, err := service.GetUserReminders("1", true)
+	if err != nil {
+		t.Fatalf("failed to get reminders: %v", err)
+	}
+	if len(reminders) != 1 || !reminders[0].Failed || reminders[0].LastError == "" {
+		t.Errorf("got %+v, want the reminder failed with its last error", reminders)
+	}
+}
+
+func TestReminderForbiddenGivesUpAtOnce(t *testing.T) {
+	users := newTestUserService(t)
+	id := newDueReminder(t, users)
+
+	var calls atomic.Int32
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`))
+	}))
+	service := NewReminderService(users.db, newTestLogger(), nil, "test", nil, time.Hour)
+
+	service.processDueReminders()
+	service.processDueReminders()
+
+	if sent, failed, failures := reminderState(t, users, id); sent || !failed || failures != 1 {
+		t.Errorf("got sent %v, failed %v and %d failures, want failed after 1", sent, failed, failures)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d attempts, want 1", calls.Load())
+	}
+}
+
+func TestReminderUsesUsersLanguage(t *testing.T) {
+	users := newTestUserService(t)
+	if err := users.EnsureUserExists("1", "tester", "de-DE"); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	newDueReminder(t, users)
+
+	var sent models.TelegramResponse
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	service := NewReminderService(users.db, newTestLogger(), nil, "test", nil, time.Hour)
+
+	if err := service.processDueReminders(); err != nil {
+		t.Fatalf("processDueReminders failed: %v", err)
+	}
+	if !strings.Contains(sent.Text, "<b>Erinnerung!</b>") || !strings.Contains(sent.Text, "„test“") {
+		t.Errorf("got %q, want the German notification", sent.Text)
+	}
+}
+
+func TestReminderHeldWhilePaused(t *testing.T) {
+	users := newTestUserService(t)
+	id := newDueReminder(t, users)
+
+	var calls atomic.Int32
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	service := NewReminderService(users.db, newTestLogger(), nil, "test", nil, time.Hour)
+
+	if err := users.SetNotificationsPaused("1", true); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if err := service.processDueReminders(); err != nil {
+		t.Fatalf("processDueReminders failed: %v", err)
+	}
+	if sent, failed, _ := reminderState(t, users, id); sent || failed || calls.Load() != 0 {
+		t.Fatalf("got sent %v and failed %v after %d sends, want the reminder left pending", sent, failed, calls.Load())
+	}
+
+	if err := users.SetNotificationsPaused("1", false); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	if err := service.processDueReminders(); err != nil {
+		t.Fatalf("processDueReminders failed: %v", err)
+	}
+	if sent, _, _ := reminderState(t, users, id); !sent || calls.Load() != 1 {
+		t.Errorf("got sent %v after %d sends, want it delivered once resumed", sent, calls.Load())
+	}
+}
+
+func TestReminderNotificationEscapesHTML(t *testing.T) {
+	var sent models.TelegramResponse
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	service := NewReminderService(nil, newTestLogger(), nil, "test", nil, time.Hour)
+
+	err := service.sendReminderNotification(context.Background(), "1", "en", "Tom & Jerry", "5114", "<b>evil</b>", time.Now(), false)
+	if err != nil {
+		t.Fatalf("sendReminderNotification failed: %v", err)
+	}
+	if !strings.Contains(sent.Text, "&lt;b&gt;evil&lt;/b&gt;") || strings.Contains(sent.Text, "<b>evil</b>") {
+		t.Errorf("got %q, want the message as literal text", sent.Text)
+	}
+	if !strings.Contains(sent.Text, "Tom &amp; Jerry") {
+		t.Errorf("got %q, want the title escaped", sent.Text)
+	}
+}
+
+func TestReminderSilentWhenOptedIn(t *testing.T) {
+	for _, silent := range []bool{false, true} {
+		users := newTestUserService(t)
+		newDueReminder(t, users)
+		if err := users.SetSilentReminders("1", silent); err != nil {
+			t.Fatalf("failed to set silent reminders: %v", err)
+		}
+
+		var sent models.TelegramResponse
+		newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+		}))
+		service := NewReminderService(users.db, newTestLogger(), nil, "test", nil, time.Hour)
+
+		if err := service.processDueReminders(); err != nil {
+			t.Fatalf("processDueReminders failed: %v", err)
+		}
+		if sent.DisableNotification != silent {
+			t.Errorf("silent %v got disable_notification %v", silent, sent.DisableNotification)
+		}
+	}
+}
+
+func TestAirDateReminderTime(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)
+	airsOn := func(t time.Time) models.Aired { return models.Aired{From: &t} }
+
+	tests := []struct {
+		name       string
+		aired      models.Aired
+		daysBefore int
+		want       time.Time
+		wantErr    error
+	}{
+		{"day before", airsOn(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)), 1, time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC), nil},
+		{"on the day", airsOn(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)), 0, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), nil},
+		{"across a month", airsOn(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)), 7, time.Date(2026, time.March, 25, 0, 0, 0, 0, time.UTC), nil},
+		{"across a leap day", airsOn(time.Date(2028, time.March, 1, 0, 0, 0, 0, time.UTC)), 1, time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC), nil},
+		{"tomorrow, reminded today", airsOn(time.Date(2026, time.March, 11, 0, 0, 0, 0, time.UTC)), 1, time.Time{}, ErrReminderTooLate},
+		{"too many days", airsOn(time.Date(2026, time.March, 20, 0, 0, 0, 0, time.UTC)), 30, time.Time{}, ErrReminderTooLate},
+		{"airs right now", airsOn(now), 0, time.Time{}, ErrAlreadyAired},
+		{"already aired", airsOn(time.Date(2013, time.April, 7, 0, 0, 0, 0, time.UTC)), 1, time.Time{}, ErrAlreadyAired},
+		{"no date", models.Aired{}, 1, time.Time{}, ErrAirDateUnknown},
+	}
+
+	for _, tt := range tests {
+		got, err := AirDateReminderTime(tt.aired, tt.daysBefore, now)
+		if !errors.Is(err, tt.wantErr) || !got.Equal(tt.want) {
+			t.Errorf("%s got %v, %v, want %v, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCreateReminderRejectsDuplicates(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	service := NewReminderService(users.db, newTestLogger(), nil, "test", users.client, time.Hour)
+
+	at := time.Now().Add(24 * time.Hour).Truncate(time.Minute).Add(10 * time.Second)
+	if err := service.CreateReminder("1", 1, "first", at); err != nil {
+		t.Fatalf("CreateReminder failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		animeID int
+		at      time.Time
+		wantErr error
+	}{
+		{"exact match", 1, at, ErrDuplicateReminder},
+		// kept to the minute, so seconds apart is the same reminder
+		{"same minute", 1, at.Add(40 * time.Second), ErrDuplicateReminder},
+		{"different time", 1, at.Add(time.Minute), nil},
+		{"another anime", 2, at, nil},
+	}
+
+	for _, tt := range tests {
+		err := service.CreateReminder("1", tt.animeID, "again", tt.at)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s got %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	reminders, err := service.GetUserReminders("1", false)
+	if err != nil {
+		t.Fatalf("failed to get reminders: %v", err)
+	}
+	if len(reminders) != 3 {
+		t.Errorf("got %d reminders, want 3 without the duplicates", len(reminders))
+	}
+}
+
+func TestCreateReminderAllowsSameTimeOnceSent(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	service := NewReminderService(users.db, newTestLogger(), nil, "test", users.client, time.Hour)
+
+	at := time.Now().Add(time.Hour)
+	if err := service.CreateReminder("1", 1, "first", at); err != nil {
+		t.Fatalf("CreateReminder failed: %v", err)
+	}
+	if _, err := users.db.Exec(context.Background(), "UPDATE reminders SET sent = true WHERE user_id = '1'"); err != nil {
+		t.Fatalf("failed to mark sent: %v", err)
+	}
+
+	// only pending reminders count as duplicates
+	if err := service.CreateReminder("1", 1, "second", at); err != nil {
+		t.Errorf("got %v, want a new reminder once the old one was sent", err)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	searchHistoryPrefix = "search:history:"
+	searchHistoryTTL    = 30 * 24 * time.Hour
+	// SearchHistorySize is how many recent searches are kept per user.
+	SearchHistorySize = 10
+)
+
+// PushSearchHistory records a search at the top of the user's history. Repeating a search
+// moves it to the top instead of listing it twice, and the oldest drop off past SearchHistorySize.
+func (s *UserService) PushSearchHistory(userID, search string) error {
+	if s.redis == nil {
+		return nil
+	}
+
+	key := searchHistoryPrefix + userID
+	_, err := s.redis.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		pipe.LRem(context.Background(), key, 0, search)
+		pipe.LPush(context.Background(), key, search)
+		pipe.LTrim(context.Background(), key, 0, SearchHistorySize-1)
+		pipe.Expire(context.Background(), key, searchHistoryTTL)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store search history: %w", err)
+	}
+
+	return nil
+}
+
+// GetSearchHistory returns the user's recent searches, newest first.
+func (s *UserService) GetSearchHistory(userID string) ([]string, error) {
+	if s.redis == nil {
+		return nil, nil
+	}
+
+	history, err := s.redis.LRange(context.Background(), searchHistoryPrefix+userID, 0, SearchHistorySize-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search history: %w", err)
+	}
+
+	return history, nil
+}
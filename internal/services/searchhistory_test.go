@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSearchHistoryDropsOldest(t *testing.T) {
+	client, _ := newTestRedis(t)
+	s := NewUserService(nil, client, newTestLogger(), nil)
+
+	for i := 1; i <= SearchHistorySize+2; i++ {
+		if err := s.PushSearchHistory("1", fmt.Sprintf("search %d", i)); err != nil {
+			t.Fatalf("PushSearchHistory failed: %v", err)
+		}
+	}
+
+	history, err := s.GetSearchHistory("1")
+	if err != nil {
+		t.Fatalf("GetSearchHistory failed: %v", err)
+	}
+	if len(history) != SearchHistorySize {
+		t.Fatalf("got %d searches, want %d", len(history), SearchHistorySize)
+	}
+	if history[0] != fmt.Sprintf("search %d", SearchHistorySize+2) || history[len(history)-1] != "search 3" {
+		t.Errorf("got %q, want newest first with the two oldest dropped", history)
+	}
+
+	if other, err := s.GetSearchHistory("2"); err != nil || len(other) != 0 {
+		t.Errorf("another user got %q (err %v), want no history", other, err)
+	}
+}
+
+func TestSearchHistoryMovesRepeatsToTop(t *testing.T) {
+	client, _ := newTestRedis(t)
+	s := NewUserService(nil, client, newTestLogger(), nil)
+
+	for _, search := range []string{"naruto", "bleach", "naruto"} {
+		if err := s.PushSearchHistory("1", search); err != nil {
+			t.Fatalf("PushSearchHistory failed: %v", err)
+		}
+	}
+
+	history, err := s.GetSearchHistory("1")
+	if err != nil {
+		t.Fatalf("GetSearchHistory failed: %v", err)
+	}
+	if want := []string{"naruto", "bleach"}; !slices.Equal(history, want) {
+		t.Errorf("got %q, want %q", history, want)
+	}
+}
+
+func TestSearchHistoryWithoutRedis(t *testing.T) {
+	s := NewUserService(nil, nil, newTestLogger(), nil)
+
+	if err := s.PushSearchHistory("1", "naruto"); err != nil {
+		t.Errorf("PushSearchHistory got %v, want nothing stored and no error", err)
+	}
+	if history, err := s.GetSearchHistory("1"); err != nil || history != nil {
+		t.Errorf("got %q (err %v), want no history", history, err)
+	}
+}
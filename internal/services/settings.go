@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+const settingsCachePrefix = "user:settings:"
+
+var (
+	ErrUnknownSetting      = errors.New("unknown setting")
+	ErrInvalidSettingValue = errors.New("invalid setting value")
+)
+
+// settingColumns maps setting keys to their user_settings column. Only these
+// columns are ever put into UpdateSetting's query.
+var settingColumns = map[string]string{
+	models.SettingNSFW:          "show_nsfw",
+	models.SettingPublic:        "is_public",
+	models.SettingNotifications: "notifications_paused",
+	models.SettingAotd:          "aotd_enabled",
+	models.SettingSilent:        "silent_reminders",
+	models.SettingVerbosity:     "search_verbosity",
+	models.SettingTimezone:      "timezone",
+	models.SettingLanguage:      "language_code",
+}
+
+// GetSettings returns the user's preferences, with defaults for a user who hasn't
+// changed any. If available, it reads them from Redis cache first.
+func (s *UserService) GetSettings(userID string) (*models.UserSettings, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	cacheKey := settingsCachePrefix + userID
+
+	if s.redis != nil {
+		cached, err := s.redis.Get(ctx, cacheKey).Result()
+		if err == nil {
+			var settings models.UserSettings
+			if err := json.Unmarshal([]byte(cached), &settings); err == nil {
+				return &settings, nil
+			}
+			s.logger.WithError(err).Warn("Failed to unmarshal cached settings")
+		} else if err != redis.Nil {
+			s.logger.WithError(err).Warn("Failed to read from Redis")
+		}
+	}
+
+	query := `
+		SELECT timezone, COALESCE(language_code, ''), search_verbosity, show_nsfw, is_public,
+			notifications_paused, aotd_enabled, silent_reminders, updated_at
+		FROM user_settings
+		WHERE user_id = $1
+	`
+
+	var settings models.UserSettings
+	err := s.db.QueryRow(ctx, query, userID).Scan(
+		&settings.Timezone,
+		&settings.LanguageCode,
+		&settings.SearchVerbosity,
+		&settings.ShowNSFW,
+		&settings.IsPublic,
+		&settings.NotificationsPaused,
+		&settings.AotdEnabled,
+		&settings.SilentReminders,
+		&settings.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		settings = models.DefaultUserSettings()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	if s.redis != nil {
+		if settingsJSON, err := json.Marshal(settings); err == nil {
+			if err := s.redis.Set(ctx, cacheKey, settingsJSON, userCacheTTL).Err(); err != nil {
+				s.logger.WithError(err).Warn("Failed to cache settings")
+			}
+		}
+	}
+
+	return &settings, nil
+}
+
+// UpdateSetting changes one of the user's settings, creating their settings row if needed.
+// Boolean settings take a bool, verbosity a models.SearchVerbosity, time zone an IANA
+// name and language a language tag ("" clears it). Returns ErrUnknownSetting or
+// ErrInvalidSettingValue (ErrInvalidTimezone for time zones) for bad input.
+// Invalidates the settings cache after update.
+func (s *UserService) UpdateSetting(userID, setting string, value any) error {
+	column, ok := settingColumns[setting]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownSetting, setting)
+	}
+
+	value, err := checkSettingValue(setting, value)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		INSERT INTO user_settings (user_id, %[1]s)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET %[1]s = EXCLUDED.%[1]s, updated_at = NOW()
+	`, column)
+
+	if _, err := s.db.Exec(ctx, query, userID, value); err != nil {
+		return fmt.Errorf("failed to update %s setting: %w", setting, err)
+	}
+
+	s.invalidateSettingsCache(userID)
+	return nil
+}
+
+// checkSettingValue validates value for setting, returning it in the form stored in the database.
+func checkSettingValue(setting string, value any) (any, error) {
+	switch setting {
+	case models.SettingVerbosity:
+		verbosity, ok := value.(models.SearchVerbosity)
+		if !ok || !verbosity.IsValid() {
+			return nil, fmt.Errorf("%w: search verbosity %v", ErrInvalidSettingValue, value)
+		}
+		return string(verbosity), nil
+	case models.SettingTimezone:
+		timezone, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: time zone %v", ErrInvalidSettingValue, value)
+		}
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, timezone)
+		}
+		return timezone, nil
+	case models.SettingLanguage:
+		language, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: language %v", ErrInvalidSettingValue, value)
+		}
+		if language == "" {
+			return nil, nil
+		}
+		return language, nil
+	default:
+		enabled, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be on or off", ErrInvalidSettingValue, setting)
+		}
+		return enabled, nil
+	}
+}
+
+// syncLanguage stores the language Telegram reports for the user, if it changed.
+// An empty languageCode leaves the stored one alone.
+func (s *UserService) syncLanguage(userID, languageCode string) error {
+	if languageCode == "" {
+		return nil
+	}
+
+	query := `
+		INSERT INTO user_settings (user_id, language_code)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET language_code = EXCLUDED.language_code, updated_at = NOW()
+		WHERE user_settings.language_code IS DISTINCT FROM EXCLUDED.language_code
+	`
+
+	result, err := s.db.Exec(context.Background(), query, userID, languageCode)
+	if err != nil {
+		return fmt.Errorf("failed to update language: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		s.invalidateSettingsCache(userID)
+	}
+	return nil
+}
+
+// invalidateSettingsCache removes the user's cached settings from Redis, if caching is enabled.
+func (s *UserService) invalidateSettingsCache(userID string) {
+	if s.redis == nil {
+		return
+	}
+
+	if err := s.redis.Del(context.Background(), settingsCachePrefix+userID).Err(); err != nil {
+		s.logger.WithError(err).Warn("Failed to invalidate settings cache")
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"sletish/internal/models"
+	"testing"
+)
+
+func TestGetSettingsDefaultsMissingRow(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	settings, err := s.GetSettings("1")
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if *settings != models.DefaultUserSettings() {
+		t.Errorf("got %+v, want the defaults", *settings)
+	}
+}
+
+func TestUpdateSettingChangesOneField(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	if err := s.UpdateSetting("1", models.SettingNSFW, true); err != nil {
+		t.Fatalf("UpdateSetting failed: %v", err)
+	}
+	settings, err := s.GetSettings("1")
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	want := models.DefaultUserSettings()
+	want.ShowNSFW = true
+	want.UpdatedAt = settings.UpdatedAt
+	if *settings != want {
+		t.Errorf("got %+v, want only show_nsfw changed", *settings)
+	}
+	if settings.UpdatedAt.IsZero() {
+		t.Error("got no updated_at after a change")
+	}
+
+	updates := []struct {
+		setting string
+		value   any
+	}{
+		{models.SettingTimezone, "Asia/Tokyo"},
+		{models.SettingVerbosity, models.VerbosityCompact},
+		{models.SettingLanguage, "de"},
+	}
+	for _, u := range updates {
+		if err := s.UpdateSetting("1", u.setting, u.value); err != nil {
+			t.Fatalf("UpdateSetting %s failed: %v", u.setting, err)
+		}
+	}
+	settings, _ = s.GetSettings("1")
+	if settings.Timezone != "Asia/Tokyo" || settings.SearchVerbosity != models.VerbosityCompact || settings.LanguageCode != "de" || !settings.ShowNSFW {
+		t.Errorf("got %+v, want each update kept", *settings)
+	}
+
+	// an empty language clears it
+	if err := s.UpdateSetting("1", models.SettingLanguage, ""); err != nil {
+		t.Fatalf("UpdateSetting failed: %v", err)
+	}
+	if settings, _ := s.GetSettings("1"); settings.LanguageCode != "" {
+		t.Errorf("got language %q, want it cleared", settings.LanguageCode)
+	}
+}
+
+func TestUpdateSettingRejectsBadInput(t *testing.T) {
+	// no database, bad input is turned away before any query
+	s := NewUserService(nil, nil, newTestLogger(), nil)
+
+	tests := []struct {
+		setting string
+		value   any
+		want    error
+	}{
+		{"theme", true, ErrUnknownSetting},
+		{models.SettingNSFW, "yes", ErrInvalidSettingValue},
+		{models.SettingVerbosity, "compact", ErrInvalidSettingValue},
+		{models.SettingVerbosity, models.SearchVerbosity("loud"), ErrInvalidSettingValue},
+		{models.SettingTimezone, "Mars/Olympus_Mons", ErrInvalidTimezone},
+		{models.SettingTimezone, "", ErrInvalidTimezone},
+		{models.SettingLanguage, 7, ErrInvalidSettingValue},
+	}
+
+	for _, tt := range tests {
+		if err := s.UpdateSetting("1", tt.setting, tt.value); !errors.Is(err, tt.want) {
+			t.Errorf("UpdateSetting(%q, %v) got %v, want %v", tt.setting, tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestSettingsCacheDroppedOnUpdate(t *testing.T) {
+	s := newTestUserService(t)
+	client, server := newTestRedis(t)
+	s.redis = client
+	newTestUser(t, s, "1")
+
+	if _, err := s.GetSettings("1"); err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if !server.Exists(settingsCachePrefix + "1") {
+		t.Fatal("settings weren't cached")
+	}
+
+	if err := s.UpdateSetting("1", models.SettingPublic, true); err != nil {
+		t.Fatalf("UpdateSetting failed: %v", err)
+	}
+	if server.Exists(settingsCachePrefix + "1") {
+		t.Error("cached settings kept after an update")
+	}
+	if settings, err := s.GetSettings("1"); err != nil || !settings.IsPublic {
+		t.Errorf("got %+v (err %v), want the update read back", settings, err)
+	}
+}
+
+func TestGetSettingsReadsCache(t *testing.T) {
+	// no database, so the settings can only come from the cache
+	client, server := newTestRedis(t)
+	s := NewUserService(nil, client, newTestLogger(), nil)
+	server.Set(settingsCachePrefix+"1", `{"timezone":"Europe/Berlin","search_verbosity":"compact","aotd_enabled":true}`)
+
+	settings, err := s.GetSettings("1")
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if settings.Timezone != "Europe/Berlin" || settings.SearchVerbosity != models.VerbosityCompact || !settings.AotdEnabled {
+		t.Errorf("got %+v, want the cached settings", *settings)
+	}
+}
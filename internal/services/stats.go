@@ -0,0 +1,422 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"time"
+)
+
+// ErrStatsCardUnavailable is returned by RenderStatsCard when the bot was built without card support.
+var ErrStatsCardUnavailable = errors.New("stats card not available in this build")
+
+// StatusCounts returns how many entries the user has per status.
+func (s *UserService) StatusCounts(userID string) (map[models.Status]int, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT status, COUNT(*)
+		FROM user_media
+		WHERE user_id = $1 AND deleted_at IS NULL
+		GROUP BY status
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[models.Status]int)
+	for rows.Next() {
+		var status models.Status
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan status count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// TrackedCounts returns how many users the bot has and how many distinct anime it stores.
+func (s *UserService) TrackedCounts() (users int, media int, err error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `SELECT (SELECT COUNT(*) FROM users WHERE platform = 'telegram'), (SELECT COUNT(*) FROM media)`
+	if err := s.db.QueryRow(ctx, query).Scan(&users, &media); err != nil {
+		return 0, 0, fmt.Errorf("failed to count users and media: %w", err)
+	}
+
+	return users, media, nil
+}
+
+// TopGenres returns the genres that appear most across the user's list, most frequent first.
+// Uses the persisted media genres, so nothing is fetched from Jikan.
+func (s *UserService) TopGenres(userID string, limit int) ([]models.GenreCount, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT g.name, COUNT(*) AS total
+		FROM user_media um
+		JOIN media_genres mg ON mg.media_id = um.media_id
+		JOIN genres g ON g.id = mg.genre_id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL
+		GROUP BY g.name
+		ORDER BY total DESC, g.name ASC
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top genres: %w", err)
+	}
+	defer rows.Close()
+
+	var genres []models.GenreCount
+	for rows.Next() {
+		var genre models.GenreCount
+		if err := rows.Scan(&genre.Name, &genre.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan genre count: %w", err)
+		}
+		genres = append(genres, genre)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating genre counts: %w", err)
+	}
+
+	return genres, nil
+}
+
+// GenreRatingBreakdown returns, for every genre the user has watched (anything but
+// their watchlist), how many titles they have and their average rating, best rated first.
+// Unrated entries count towards the title count but not the average; genres without
+// any rated entries come last.
+func (s *UserService) GenreRatingBreakdown(userID string) ([]models.GenreRating, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT g.name, COUNT(*) AS total, COUNT(um.rating) AS rated, AVG(um.rating)::float8 AS average
+		FROM user_media um
+		JOIN media_genres mg ON mg.media_id = um.media_id
+		JOIN genres g ON g.id = mg.genre_id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL AND um.status <> $2
+		GROUP BY g.name
+		ORDER BY average DESC NULLS LAST, total DESC, g.name ASC
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, models.StatusWatchlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query genre ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var genres []models.GenreRating
+	for rows.Next() {
+		var genre models.GenreRating
+		if err := rows.Scan(&genre.Name, &genre.Count, &genre.Rated, &genre.AverageRating); err != nil {
+			return nil, fmt.Errorf("failed to scan genre rating: %w", err)
+		}
+		genres = append(genres, genre)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating genre ratings: %w", err)
+	}
+
+	return genres, nil
+}
+
+// TotalEpisodesWatched returns how many episodes the user has watched across their
+// list and how many titles that covers. Entries without reported progress count as zero.
+func (s *UserService) TotalEpisodesWatched(userID string) (episodes int, titles int, err error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(SUM(COALESCE(episodes_watched, 0)), 0), COUNT(*) FILTER (WHERE episodes_watched > 0)
+		FROM user_media
+		WHERE user_id = $1 AND deleted_at IS NULL
+	`
+
+	if err := s.db.QueryRow(ctx, query, userID).Scan(&episodes, &titles); err != nil {
+		return 0, 0, fmt.Errorf("failed to sum episodes watched: %w", err)
+	}
+
+	return episodes, titles, nil
+}
+
+// maxStreakDays bounds how far back ActivityStreak looks.
+const maxStreakDays = 366
+
+// ActivityStreak returns how many consecutive days, up to today, the user had list
+// activity on. A day counts as active when an entry was added to or last updated in
+// the user's list that day, in the time zone from their settings. A streak isn't broken
+// until a whole day passes without activity, so being active yesterday but not yet today keeps it.
+func (s *UserService) ActivityStreak(userID string) (int, error) {
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		return 0, err
+	}
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		s.logger.WithError(err).WithField("timezone", settings.Timezone).Warn("Unknown time zone, counting the streak in UTC")
+		loc = time.UTC
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT day FROM (
+			SELECT (created_at AT TIME ZONE $2)::date AS day FROM user_media WHERE user_id = $1 AND deleted_at IS NULL
+			UNION
+			SELECT (updated_at AT TIME ZONE $2)::date AS day FROM user_media WHERE user_id = $1 AND deleted_at IS NULL
+		) activity
+		ORDER BY day DESC
+		LIMIT $3
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, loc.String(), maxStreakDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query activity days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []time.Time
+	for rows.Next() {
+		var day time.Time
+		if err := rows.Scan(&day); err != nil {
+			return 0, fmt.Errorf("failed to scan activity day: %w", err)
+		}
+		days = append(days, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating activity days: %w", err)
+	}
+
+	return countStreak(days, time.Now().In(loc)), nil
+}
+
+// countStreak counts the consecutive days ending today, or yesterday if there's no
+// activity today yet. days must be distinct dates, newest first.
+func countStreak(days []time.Time, now time.Time) int {
+	if len(days) == 0 {
+		return 0
+	}
+
+	expected := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if first := days[0]; !sameDate(first, expected) {
+		expected = expected.AddDate(0, 0, -1)
+		if !sameDate(first, expected) {
+			return 0
+		}
+	}
+
+	streak := 0
+	for _, day := range days {
+		if !sameDate(day, expected) {
+			break
+		}
+		streak++
+		expected = expected.AddDate(0, 0, -1)
+	}
+
+	return streak
+}
+
+// sameDate reports whether a and b fall on the same calendar date, ignoring their locations.
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// trendMonths is how many calendar months MonthlyCompletions covers, the current one included.
+const trendMonths = 12
+
+// MonthlyCompletions returns how many entries the user completed in each of the last
+// trendMonths calendar months (UTC), oldest first. Months without completions are
+// included with a zero count, so the result always has trendMonths entries.
+func (s *UserService) MonthlyCompletions(userID string) ([]models.MonthlyCount, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(trendMonths - 1), 0)
+
+	query := `
+		SELECT date_trunc('month', completed_at AT TIME ZONE 'UTC')::date AS month, COUNT(*)
+		FROM user_media
+		WHERE user_id = $1 AND deleted_at IS NULL AND status = 'completed' AND completed_at >= $2
+		GROUP BY month
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query monthly completions: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[time.Time]int)
+	for rows.Next() {
+		var month time.Time
+		var count int
+		if err := rows.Scan(&month, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan monthly completions: %w", err)
+		}
+		counts[time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating monthly completions: %w", err)
+	}
+
+	return bucketMonths(counts, start), nil
+}
+
+// bucketMonths lays counts out over trendMonths consecutive months from start, filling gaps with zero.
+func bucketMonths(counts map[time.Time]int, start time.Time) []models.MonthlyCount {
+	months := make([]models.MonthlyCount, trendMonths)
+	for i := range months {
+		month := start.AddDate(0, i, 0)
+		months[i] = models.MonthlyCount{Month: month, Count: counts[month]}
+	}
+	return months
+}
+
+// RatingDistribution returns how many of the user's rated entries fall in each of
+// models.RatingBuckets. Unrated entries are ignored; empty buckets are left out.
+func (s *UserService) RatingDistribution(userID string) (map[string]int, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT bucket, COUNT(*)
+		FROM (
+			SELECT CASE
+				WHEN rating < 3 THEN '1-2'
+				WHEN rating < 5 THEN '3-4'
+				WHEN rating < 7 THEN '5-6'
+				WHEN rating < 9 THEN '7-8'
+				ELSE '9-10'
+			END AS bucket
+			FROM user_media
+			WHERE user_id = $1 AND deleted_at IS NULL AND rating IS NOT NULL
+		) rated
+		GROUP BY bucket
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating distribution: %w", err)
+	}
+	defer rows.Close()
+
+	distribution := make(map[string]int)
+	for rows.Next() {
+		var bucket string
+		var count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan rating bucket: %w", err)
+		}
+		distribution[bucket] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rating buckets: %w", err)
+	}
+
+	return distribution, nil
+}
+
+// Leaderboard returns the users with the most completions since the given time, most first.
+// Only users who opted in with /public and have a username are included.
+func (s *UserService) Leaderboard(since time.Time, limit int) ([]models.LeaderboardEntry, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT u.username, COUNT(*) AS completions
+		FROM user_media um
+		JOIN users u ON u.id = um.user_id
+		JOIN user_settings us ON us.user_id = u.id
+		WHERE us.is_public = TRUE
+		AND u.username IS NOT NULL AND u.username <> ''
+		AND um.status = 'completed' AND um.deleted_at IS NULL
+		AND um.completed_at >= $1
+		GROUP BY u.id, u.username
+		ORDER BY completions DESC, u.username ASC
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.LeaderboardEntry
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		if err := rows.Scan(&entry.Username, &entry.Completions); err != nil {
+			return nil, fmt.Errorf("failed to scan leaderboard entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating leaderboard: %w", err)
+	}
+
+	return entries, nil
+}
+
+// TrendingMedia returns the anime added by the most users since the given time, most first.
+// Only aggregate counts are exposed, never who added what.
+func (s *UserService) TrendingMedia(since time.Time, limit int) ([]models.TrendingMedia, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT m.id, m.external_id, m.title, COUNT(DISTINCT um.user_id) AS adds
+		FROM user_media um
+		JOIN media m ON m.id = um.media_id
+		WHERE um.created_at >= $1 AND um.deleted_at IS NULL
+		GROUP BY m.id, m.external_id, m.title
+		ORDER BY adds DESC, m.title ASC
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending media: %w", err)
+	}
+	defer rows.Close()
+
+	var trending []models.TrendingMedia
+	for rows.Next() {
+		var item models.TrendingMedia
+		if err := rows.Scan(&item.Media.ID, &item.Media.ExternalID, &item.Media.Title, &item.Adds); err != nil {
+			return nil, fmt.Errorf("failed to scan trending media: %w", err)
+		}
+		trending = append(trending, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trending media: %w", err)
+	}
+
+	return trending, nil
+}
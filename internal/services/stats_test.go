@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sletish/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRatingDistributionBuckets(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	ratings := []float64{1, 2.9, 3, 4.5, 7, 7.5, 8.9, 9, 10}
+	for i, rating := range ratings {
+		if err := s.AddToUserList("1", i+1, models.StatusCompleted, &rating, nil); err != nil {
+			t.Fatalf("failed to add anime %d: %v", i+1, err)
+		}
+	}
+	// unrated entries aren't counted
+	if err := s.AddToUserList("1", 100, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add unrated anime: %v", err)
+	}
+
+	got, err := s.RatingDistribution("1")
+	if err != nil {
+		t.Fatalf("RatingDistribution failed: %v", err)
+	}
+
+	// a bucket runs up to, not including, the next one's lower bound
+	want := map[string]int{"1-2": 2, "3-4": 2, "7-8": 3, "9-10": 2}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRatingDistributionNoRatings(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	if err := s.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add anime: %v", err)
+	}
+
+	got, err := s.RatingDistribution("1")
+	if err != nil {
+		t.Fatalf("RatingDistribution failed: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v, want no buckets", got)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	s := newTestUserService(t)
+	ctx := context.Background()
+	weekAgo := time.Now().Add(-7 * 24 * time.Hour)
+
+	users := []struct {
+		id, username string
+		public       bool
+		completions  int
+	}{
+		{"1", "alice", true, 3},
+		{"2", "bob", true, 0},    // completions added below
+		{"3", "carol", false, 5}, // private, never shown
+		{"4", "", true, 4},       // no username to show
+		{"5", "dave", true, 1},
+	}
+
+	animeID := 1
+	for _, u := range users {
+		if err := s.EnsureUserExists(u.id, u.username, ""); err != nil {
+			t.Fatalf("failed to create user %s: %v", u.id, err)
+		}
+		if err := s.SetPublic(u.id, u.public); err != nil {
+			t.Fatalf("failed to set public for %s: %v", u.id, err)
+		}
+		for range u.completions {
+			if err := s.AddToUserList(u.id, animeID, models.StatusCompleted, nil, nil); err != nil {
+				t.Fatalf("failed to add anime: %v", err)
+			}
+			animeID++
+		}
+	}
+
+	// bob completed 3 before the week, which don't count, and 1 within it
+	for range 3 {
+		if err := s.AddToUserList("2", animeID, models.StatusCompleted, nil, nil); err != nil {
+			t.Fatalf("failed to add anime: %v", err)
+		}
+		animeID++
+	}
+	old := "UPDATE user_media SET completed_at = $1 WHERE user_id = '2' AND completed_at > $2"
+	if _, err := s.db.Exec(ctx, old, weekAgo.Add(-time.Hour), time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("failed to backdate completions: %v", err)
+	}
+	if err := s.AddToUserList("2", animeID, models.StatusCompleted, nil, nil); err != nil {
+		t.Fatalf("failed to add anime: %v", err)
+	}
+
+	entries, err := s.Leaderboard(weekAgo, 10)
+	if err != nil {
+		t.Fatalf("Leaderboard failed: %v", err)
+	}
+
+	// ties are broken by username
+	want := []models.LeaderboardEntry{
+		{Username: "alice", Completions: 3},
+		{Username: "bob", Completions: 1},
+		{Username: "dave", Completions: 1},
+	}
+	if !slices.Equal(entries, want) {
+		t.Errorf("got %+v, want %+v", entries, want)
+	}
+
+	if entries, _ := s.Leaderboard(weekAgo, 1); len(entries) != 1 || entries[0].Username != "alice" {
+		t.Errorf("got %+v with a limit of 1, want only alice", entries)
+	}
+}
+
+func TestTrendingMedia(t *testing.T) {
+	s := newTestUserService(t)
+	ctx := context.Background()
+	weekAgo := time.Now().Add(-7 * 24 * time.Hour)
+
+	// anime 2 is added by three users, 1 and 3 by one each, 4 only before the week
+	adds := map[string][]int{
+		"1": {1, 2, 4},
+		"2": {2, 3},
+		"3": {2},
+	}
+	for userID, animeIDs := range adds {
+		newTestUser(t, s, userID)
+		for _, id := range animeIDs {
+			if err := s.AddToUserList(userID, id, models.StatusWatchlist, nil, nil); err != nil {
+				t.Fatalf("failed to add anime %d: %v", id, err)
+			}
+		}
+	}
+	media, err := s.getOrCreateMediaByID(4)
+	if err != nil {
+		t.Fatalf("failed to get media: %v", err)
+	}
+	if _, err := s.db.Exec(ctx, "UPDATE user_media SET created_at = $1 WHERE media_id = $2", weekAgo.Add(-time.Hour), media.ID); err != nil {
+		t.Fatalf("failed to backdate add: %v", err)
+	}
+
+	trending, err := s.TrendingMedia(weekAgo, 10)
+	if err != nil {
+		t.Fatalf("TrendingMedia failed: %v", err)
+	}
+
+	var got []string
+	for _, item := range trending {
+		got = append(got, fmt.Sprintf("%s:%d", item.Media.ExternalID, item.Adds))
+	}
+	// most added first, ties by title
+	want := []string{"2:3", "1:1", "3:1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if trending, _ := s.TrendingMedia(weekAgo, 1); len(trending) != 1 || trending[0].Media.ExternalID != "2" {
+		t.Errorf("got %+v with a limit of 1, want only anime 2", trending)
+	}
+}
+
+func TestGenreRatingBreakdown(t *testing.T) {
+	s := newTestUserService(t,
+		models.AnimeData{MalID: 1, Title: "One", Genres: []models.Genre{{Name: "Action"}, {Name: "Drama"}}},
+		models.AnimeData{MalID: 2, Title: "Two", Genres: []models.Genre{{Name: "Action"}}},
+		models.AnimeData{MalID: 3, Title: "Three", Genres: []models.Genre{{Name: "Action"}, {Name: "Comedy"}}},
+		models.AnimeData{MalID: 4, Title: "Four", Genres: []models.Genre{{Name: "Horror"}}},
+		models.AnimeData{MalID: 5, Title: "Five", Genres: []models.Genre{{Name: "Romance"}}},
+	)
+	newTestUser(t, s, "1")
+	newTestUser(t, s, "2")
+
+	rate := func(r float64) *float64 { return &r }
+	entries := []struct {
+		id     int
+		status models.Status
+		rating *float64
+	}{
+		{1, models.StatusCompleted, rate(9)},
+		{2, models.StatusCompleted, rate(6)},
+		{3, models.StatusWatching, nil}, // counted, but not in the average
+		{4, models.StatusDropped, nil},
+		{5, models.StatusWatchlist, rate(10)}, // watchlist isn't counted at all
+	}
+	for _, e := range entries {
+		if err := s.AddToUserList("1", e.id, e.status, e.rating, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", e.id, err)
+		}
+	}
+	// another user's ratings don't count
+	if err := s.AddToUserList("2", 2, models.StatusCompleted, rate(1), nil); err != nil {
+		t.Fatalf("failed to add for user 2: %v", err)
+	}
+
+	got, err := s.GenreRatingBreakdown("1")
+	if err != nil {
+		t.Fatalf("GenreRatingBreakdown failed: %v", err)
+	}
+
+	want := []struct {
+		name         string
+		count, rated int
+		average      float64 // 0 for none
+	}{
+		{"Drama", 1, 1, 9},
+		{"Action", 3, 2, 7.5},
+		{"Comedy", 1, 0, 0},
+		{"Horror", 1, 0, 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %d genres", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Name != w.name || g.Count != w.count || g.Rated != w.rated {
+			t.Errorf("genre %d got %s with %d titles and %d rated, want %s with %d and %d", i, g.Name, g.Count, g.Rated, w.name, w.count, w.rated)
+		}
+		if w.average == 0 {
+			if g.AverageRating != nil {
+				t.Errorf("%s got average %v, want none", g.Name, *g.AverageRating)
+			}
+		} else if g.AverageRating == nil || *g.AverageRating != w.average {
+			t.Errorf("%s got average %v, want %v", g.Name, g.AverageRating, w.average)
+		}
+	}
+}
+
+func TestCountStreak(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	daysAgo := func(ago ...int) []time.Time {
+		var days []time.Time
+		for _, n := range ago {
+			days = append(days, time.Date(2024, 3, 10-n, 0, 0, 0, 0, time.UTC))
+		}
+		return days
+	}
+
+	tests := []struct {
+		name string
+		days []time.Time
+		want int
+	}{
+		{"no activity", nil, 0},
+		{"today only", daysAgo(0), 1},
+		{"three days", daysAgo(0, 1, 2), 3},
+		{"broken streak counts from today", daysAgo(0, 2, 3, 4), 1},
+		{"not yet active today", daysAgo(1, 2), 2},
+		{"lapsed", daysAgo(2, 3, 4), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countStreak(tt.days, now); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// the day before the 1st is the last of the previous month
+	first := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	days := []time.Time{first, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC)}
+	if got := countStreak(days, first); got != 3 {
+		t.Errorf("across a month got %d, want 3", got)
+	}
+}
+
+func TestActivityStreak(t *testing.T) {
+	s := newTestUserService(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// user 1 was active the last three days, user 2 today and then not for a day
+	activity := map[string][]int{"1": {0, 1, 2}, "2": {0, 2, 3}}
+	insert := `
+		INSERT INTO user_media (user_id, media_id, status, created_at, updated_at)
+		VALUES ($1, $2, 'watching', $3, $3)
+	`
+	for userID, ago := range activity {
+		newTestUser(t, s, userID)
+		for i, n := range ago {
+			media, err := s.getOrCreateMediaByID(i + 1)
+			if err != nil {
+				t.Fatalf("failed to create media: %v", err)
+			}
+			if _, err := s.db.Exec(ctx, insert, userID, media.ID, now.AddDate(0, 0, -n)); err != nil {
+				t.Fatalf("failed to insert activity: %v", err)
+			}
+		}
+	}
+	newTestUser(t, s, "3")
+
+	for userID, want := range map[string]int{"1": 3, "2": 1, "3": 0} {
+		got, err := s.ActivityStreak(userID)
+		if err != nil {
+			t.Fatalf("ActivityStreak failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("user %s got a %d day streak, want %d", userID, got, want)
+		}
+	}
+}
+
+func TestActivityStreakUsesUserTimeZone(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	ctx := context.Background()
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	if err := s.UpdateSetting("1", models.SettingTimezone, "Asia/Tokyo"); err != nil {
+		t.Fatalf("failed to set time zone: %v", err)
+	}
+
+	// half past midnight yesterday and half past eleven the night before in Tokyo are
+	// two days there, but the same afternoon in UTC
+	today := time.Now().In(tokyo)
+	times := []time.Time{
+		time.Date(today.Year(), today.Month(), today.Day()-1, 0, 30, 0, 0, tokyo),
+		time.Date(today.Year(), today.Month(), today.Day()-2, 23, 30, 0, 0, tokyo),
+	}
+	insert := `
+		INSERT INTO user_media (user_id, media_id, status, created_at, updated_at)
+		VALUES ('1', $1, 'watching', $2, $2)
+	`
+	for i, at := range times {
+		media, err := s.getOrCreateMediaByID(i + 1)
+		if err != nil {
+			t.Fatalf("failed to create media: %v", err)
+		}
+		if _, err := s.db.Exec(ctx, insert, media.ID, at); err != nil {
+			t.Fatalf("failed to insert activity: %v", err)
+		}
+	}
+
+	got, err := s.ActivityStreak("1")
+	if err != nil {
+		t.Fatalf("ActivityStreak failed: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("got a %d day streak, want 2 counted in the user's days", got)
+	}
+}
+
+func TestTotalEpisodesWatched(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	newTestUser(t, s, "2")
+
+	for _, id := range []int{1, 2, 3, 4} {
+		if err := s.AddToUserList("1", id, models.StatusWatching, nil, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", id, err)
+		}
+	}
+	s.AddToUserList("2", 1, models.StatusWatching, nil, nil)
+
+	if episodes, titles, err := s.TotalEpisodesWatched("1"); err != nil || episodes != 0 || titles != 0 {
+		t.Errorf("got %d episodes across %d titles (err %v), want nothing without progress", episodes, titles, err)
+	}
+
+	// 3 has no progress (NULL), 4 is reported as 0
+	progress := map[int]int{1: 12, 2: 25, 4: 0}
+	for id, episodes := range progress {
+		if err := s.SetProgress("1", id, episodes); err != nil {
+			t.Fatalf("SetProgress %d failed: %v", id, err)
+		}
+	}
+	s.SetProgress("2", 1, 100)
+
+	episodes, titles, err := s.TotalEpisodesWatched("1")
+	if err != nil {
+		t.Fatalf("TotalEpisodesWatched failed: %v", err)
+	}
+	if episodes != 37 || titles != 2 {
+		t.Errorf("got %d episodes across %d titles, want 37 across 2", episodes, titles)
+	}
+}
+
+func TestSetProgress(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	s.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+	s.getOrCreateMediaByID(2)
+
+	if err := s.SetProgress("1", 1, -1); err == nil {
+		t.Error("got no error for negative progress")
+	}
+	if err := s.SetProgress("1", 2, 3); err == nil {
+		t.Error("got no error for an anime that isn't in the list")
+	}
+	if err := s.SetProgress("1", 999, 3); err == nil {
+		t.Error("got no error for an unknown anime")
+	}
+}
+
+func TestBucketMonths(t *testing.T) {
+	start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	counts := map[time.Time]int{
+		time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC):    2,
+		time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC):     1,
+		time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC): 5,
+	}
+
+	months := bucketMonths(counts, start)
+	if len(months) != trendMonths {
+		t.Fatalf("got %d months, want %d", len(months), trendMonths)
+	}
+
+	want := []int{2, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 5}
+	for i, month := range months {
+		if wantMonth := start.AddDate(0, i, 0); !month.Month.Equal(wantMonth) {
+			t.Errorf("month %d got %v, want %v", i, month.Month, wantMonth)
+		}
+		if month.Count != want[i] {
+			t.Errorf("%s got %d, want %d", month.Month.Format("Jan 2006"), month.Count, want[i])
+		}
+	}
+}
+
+func TestMonthlyCompletions(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	ctx := context.Background()
+
+	thisMonth := time.Now().UTC()
+	completedAt := map[int]time.Time{
+		1: thisMonth,
+		2: thisMonth.AddDate(0, -3, 0),
+		3: thisMonth.AddDate(0, -3, 0),
+		4: thisMonth.AddDate(0, -13, 0), // before the window
+	}
+	for id, at := range completedAt {
+		if err := s.AddToUserList("1", id, models.StatusCompleted, nil, nil); err != nil {
+			t.Fatalf("failed to add anime %d: %v", id, err)
+		}
+		update := `
+			UPDATE user_media SET completed_at = $2
+			WHERE user_id = '1' AND media_id = (SELECT id FROM media WHERE external_id = $1)
+		`
+		if _, err := s.db.Exec(ctx, update, fmt.Sprint(id), at); err != nil {
+			t.Fatalf("failed to backdate anime %d: %v", id, err)
+		}
+	}
+	s.AddToUserList("1", 5, models.StatusWatching, nil, nil)
+
+	months, err := s.MonthlyCompletions("1")
+	if err != nil {
+		t.Fatalf("MonthlyCompletions failed: %v", err)
+	}
+	if len(months) != trendMonths {
+		t.Fatalf("got %d months, want %d", len(months), trendMonths)
+	}
+
+	total := 0
+	for _, month := range months {
+		total += month.Count
+	}
+	if total != 3 || months[trendMonths-1].Count != 1 || months[trendMonths-4].Count != 2 {
+		t.Errorf("got %+v, want 1 this month and 2 three months ago", months)
+	}
+}
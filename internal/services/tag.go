@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrTagExists   = errors.New("tag already on entry")
+	ErrTagNotFound = errors.New("tag not on entry")
+	ErrTooManyTags = errors.New("too many tags on entry")
+)
+
+// AddTag adds a (normalized) tag to an entry in the user's list.
+// Returns an error if the anime is not found in the user's list.
+func (s *UserService) AddTag(userID string, animeID int, tag string) error {
+	tags, err := s.getEntryTags(userID, animeID)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(tags, tag) {
+		return ErrTagExists
+	}
+	if len(tags) >= models.MaxTagsPerEntry {
+		return ErrTooManyTags
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		UPDATE user_media um
+		SET tags = array_append(um.tags, $3)
+		FROM media m
+		WHERE um.media_id = m.id AND um.user_id = $1 AND m.external_id = $2 AND um.deleted_at IS NULL
+		AND NOT ($3 = ANY(um.tags))
+	`
+
+	if _, err := s.db.Exec(ctx, query, userID, strconv.Itoa(animeID), tag); err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+
+	s.invalidateUserCache(userID)
+	return nil
+}
+
+// RemoveTag removes a tag from an entry in the user's list.
+// Returns ErrTagNotFound if the entry doesn't have it.
+func (s *UserService) RemoveTag(userID string, animeID int, tag string) error {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		UPDATE user_media um
+		SET tags = array_remove(um.tags, $3)
+		FROM media m
+		WHERE um.media_id = m.id AND um.user_id = $1 AND m.external_id = $2 AND um.deleted_at IS NULL
+		AND $3 = ANY(um.tags)
+	`
+
+	result, err := s.db.Exec(ctx, query, userID, strconv.Itoa(animeID), tag)
+	if err != nil {
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		// tell a missing entry apart from a missing tag
+		if _, err := s.getEntryTags(userID, animeID); err != nil {
+			return err
+		}
+		return ErrTagNotFound
+	}
+
+	s.invalidateUserCache(userID)
+	return nil
+}
+
+// GetUserListByTag retrieves the user's list entries carrying the given (normalized) tag.
+func (s *UserService) GetUserListByTag(userID, tag string, page, limit int) ([]models.UserMediaWithDetails, int, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	tagJoin := `
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL AND um.tags && ARRAY[$2::text]
+	`
+
+	var total int
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*)"+tagJoin, userID, tag).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	query := "SELECT " + userMediaColumns + tagJoin +
+		fmt.Sprintf(" ORDER BY um.updated_at DESC LIMIT %d OFFSET %d", limit, (page-1)*limit)
+
+	rows, err := s.db.Query(ctx, query, userID, tag)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	list, err := scanUserMediaRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return list, total, nil
+}
+
+func (s *UserService) getEntryTags(userID string, animeID int) ([]string, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT um.tags
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND m.external_id = $2 AND um.deleted_at IS NULL
+	`
+
+	var tags []string
+	err := s.db.QueryRow(ctx, query, userID, strconv.Itoa(animeID)).Scan(&tags)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("anime not found in user's list")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sletish/internal/models"
+	"slices"
+	"testing"
+)
+
+func TestAddAndRemoveTags(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	if err := users.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add anime: %v", err)
+	}
+
+	for _, tag := range []string{"rewatch", "cozy"} {
+		if err := users.AddTag("1", 1, tag); err != nil {
+			t.Fatalf("AddTag %q failed: %v", tag, err)
+		}
+	}
+	if err := users.AddTag("1", 1, "rewatch"); !errors.Is(err, ErrTagExists) {
+		t.Errorf("got %v for a repeated tag, want ErrTagExists", err)
+	}
+	if err := users.AddTag("1", 2, "rewatch"); err == nil {
+		t.Error("got no error tagging an anime that isn't in the list")
+	}
+
+	if err := users.RemoveTag("1", 1, "cozy"); err != nil {
+		t.Fatalf("RemoveTag failed: %v", err)
+	}
+	if err := users.RemoveTag("1", 1, "cozy"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("got %v removing it again, want ErrTagNotFound", err)
+	}
+	if err := users.RemoveTag("1", 2, "cozy"); err == nil || errors.Is(err, ErrTagNotFound) {
+		t.Errorf("got %v for an anime that isn't in the list, want it reported as not found in the list", err)
+	}
+
+	tags, err := users.getEntryTags("1", 1)
+	if err != nil {
+		t.Fatalf("failed to get tags: %v", err)
+	}
+	if !slices.Equal(tags, []string{"rewatch"}) {
+		t.Errorf("got tags %v, want [rewatch]", tags)
+	}
+}
+
+func TestTagLimit(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	users.AddToUserList("1", 1, models.StatusWatching, nil, nil)
+
+	for i := 0; i < models.MaxTagsPerEntry; i++ {
+		if err := users.AddTag("1", 1, fmt.Sprintf("tag%d", i)); err != nil {
+			t.Fatalf("AddTag %d failed: %v", i, err)
+		}
+	}
+	if err := users.AddTag("1", 1, "onemore"); !errors.Is(err, ErrTooManyTags) {
+		t.Errorf("got %v, want ErrTooManyTags", err)
+	}
+}
+
+func TestGetUserListByTag(t *testing.T) {
+	users := newTestUserService(t)
+	newTestUser(t, users, "1")
+	newTestUser(t, users, "2")
+	for _, id := range []int{1, 2, 3} {
+		users.AddToUserList("1", id, models.StatusWatchlist, nil, nil)
+	}
+	users.AddToUserList("2", 1, models.StatusWatchlist, nil, nil)
+	users.AddTag("1", 1, "rewatch")
+	users.AddTag("1", 3, "rewatch")
+	users.AddTag("1", 2, "cozy")
+	users.AddTag("2", 1, "rewatch")
+
+	list, total, err := users.GetUserListByTag("1", "rewatch", 1, 10)
+	if err != nil {
+		t.Fatalf("GetUserListByTag failed: %v", err)
+	}
+	if total != 2 || len(list) != 2 {
+		t.Fatalf("got %d entries (total %d), want the user's 2 tagged", len(list), total)
+	}
+	for _, item := range list {
+		if !slices.Contains(item.UserMedia.Tags, "rewatch") || item.UserMedia.UserID != "1" {
+			t.Errorf("got %q with tags %v for user %s, want only user 1's #rewatch", item.Media.Title, item.UserMedia.Tags, item.UserMedia.UserID)
+		}
+	}
+
+	if list, total, err := users.GetUserListByTag("1", "nothing", 1, 10); err != nil || total != 0 || len(list) != 0 {
+		t.Errorf("got %d entries (total %d, err %v) for an unused tag, want none", len(list), total, err)
+	}
+}
@@ -4,66 +4,335 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"regexp"
 	"sletish/internal/models"
+	"strconv"
+	"time"
 )
 
-const telegramAPIURL = "https://api.telegram.org/bot"
+// telegramAPIURL is a variable so tests can point the Bot API at a stub server.
+var telegramAPIURL = "https://api.telegram.org/bot"
+
+// Callback answers have to reach Telegram within a few seconds or the button keeps
+// spinning, so each attempt is cut short and a failed one is retried once, quickly.
+// Variables so tests can shorten them.
+var (
+	callbackAnswerTimeout    = 3 * time.Second
+	callbackAnswerRetryDelay = 200 * time.Millisecond
+)
+
+// telegramHTTPClient is shared by every Bot API call so connections to Telegram are reused.
+var telegramHTTPClient = &http.Client{
+	Timeout: 60 * time.Second, // uploads (exports, cards) are the slowest calls
+	Transport: &http.Transport{
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+}
+
+// ErrTelegramForbidden is returned when Telegram refuses to deliver to a chat,
+// e.g. because the user blocked the bot. Retrying won't help.
+var ErrTelegramForbidden = errors.New("telegram: forbidden")
+
+// ErrInvalidBotToken is returned when Telegram doesn't recognize the bot token.
+var ErrInvalidBotToken = errors.New("telegram: invalid bot token")
+
+// botTokenPattern matches tokens as issued by BotFather, e.g. 123456789:AAH...
+var botTokenPattern = regexp.MustCompile(`^[0-9]+:[A-Za-z0-9_-]{30,}$`)
+
+// ValidateBotToken checks the token looks like one issued by BotFather.
+// It doesn't contact Telegram, use GetMe to check the token actually works.
+func ValidateBotToken(botToken string) error {
+	if !botTokenPattern.MatchString(botToken) {
+		return fmt.Errorf("malformed bot token: expected <bot id>:<secret> as given by BotFather")
+	}
+	return nil
+}
+
+// GetMe returns the bot's own user, confirming the token is accepted by Telegram.
+//
+// Returns ErrInvalidBotToken if Telegram rejects the token, or an error if the
+// request fails for any other reason.
+func GetMe(ctx context.Context, botToken string) (*models.User, error) {
+	url := fmt.Sprintf("%s%s/getMe", telegramAPIURL, botToken)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create getMe request: %w", err)
+	}
+
+	resp, err := telegramHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send getMe request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Telegram answers 401 for a wrong secret and 404 for a token it can't parse
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBotToken, newTelegramError("getMe", resp))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newTelegramError("getMe", resp)
+	}
+
+	var me models.GetMeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&me); err != nil {
+		return nil, fmt.Errorf("failed to decode getMe response: %w", err)
+	}
+
+	return &me.Result, nil
+}
+
+// TelegramError is a non-OK response from the Telegram Bot API, carrying the
+// description Telegram gave, e.g. "Bad Request: chat not found".
+//
+// It matches ErrTelegramForbidden with errors.Is when the status is 403 and
+// ErrInvalidBotToken when it is 401.
+type TelegramError struct {
+	Method      string
+	StatusCode  int
+	ErrorCode   int
+	Description string
+}
+
+func (e *TelegramError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("telegram %s API error (status %d)", e.Method, e.StatusCode)
+	}
+	return fmt.Sprintf("telegram %s API error (status %d): %s", e.Method, e.StatusCode, e.Description)
+}
+
+func (e *TelegramError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusForbidden:
+		return ErrTelegramForbidden
+	case http.StatusUnauthorized:
+		return ErrInvalidBotToken
+	}
+	return nil
+}
+
+// maxErrorBodySize caps how much of an error response is read; Telegram's are tiny.
+const maxErrorBodySize = 64 << 10
+
+// newTelegramError builds a TelegramError from a non-OK response, decoding
+// Telegram's error body when there is one.
+func newTelegramError(method string, resp *http.Response) *TelegramError {
+	tgErr := &TelegramError{Method: method, StatusCode: resp.StatusCode}
+
+	var body models.TelegramErrorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&body); err == nil {
+		tgErr.ErrorCode = body.ErrorCode
+		tgErr.Description = body.Description
+	}
+
+	return tgErr
+}
+
+// blockedHandler is called with the chat ID whenever Telegram refuses a send with 403.
+var blockedHandler func(chatId int)
+
+// SetBlockedHandler registers a function to call when a chat turns out to have blocked
+// the bot, so every caller of the send functions gets this handled in one place.
+func SetBlockedHandler(handler func(chatId int)) {
+	blockedHandler = handler
+}
+
+// forbiddenError reports the blocked chat and returns the TelegramError for the response,
+// which wraps ErrTelegramForbidden.
+func forbiddenError(chatId int, method string, resp *http.Response) error {
+	if blockedHandler != nil {
+		blockedHandler(chatId)
+	}
+	return newTelegramError(method, resp)
+}
+
+// TelegramClient is the subset of the Bot API the bot uses to talk to chats.
+// Handler depends on this rather than the API directly so it can be driven by a fake.
+type TelegramClient interface {
+	SendMessage(ctx context.Context, chatId int, text string, keyboard *models.InlineKeyboardMarkup) (int, error)
+	SendPhoto(ctx context.Context, chatId int, photo []byte, filename, caption string) error
+	SendDocument(ctx context.Context, chatId int, document []byte, filename, caption string) error
+	EditMessage(ctx context.Context, chatId int, messageId int, text string, keyboard *models.InlineKeyboardMarkup) error
+	EditMessageKeyboard(ctx context.Context, chatId int, messageId int, keyboard *models.InlineKeyboardMarkup) error
+	DeleteMessage(ctx context.Context, chatId int, messageId int) error
+	AnswerCallbackQuery(ctx context.Context, callbackQueryId string, text string, showAlert bool) error
+	SendTypingAction(ctx context.Context, chatId int) error
+	GetChatMember(ctx context.Context, chatId, userId int) (*models.ChatMember, error)
+}
+
+// TelegramAPIClient implements TelegramClient against the Telegram Bot API.
+type TelegramAPIClient struct {
+	botToken   string
+	httpClient *http.Client
+}
+
+// NewTelegramClient returns a TelegramAPIClient for the bot with the given token.
+func NewTelegramClient(botToken string) *TelegramAPIClient {
+	return &TelegramAPIClient{
+		botToken:   botToken,
+		httpClient: telegramHTTPClient,
+	}
+}
 
 // SendTelegramMessage sends a plain text message to a Telegram chat.
 //
-// It wraps SendTelegramMessageWithKeyboard without any keyboard markup.
+// It's a shorthand for background workers that only hold the bot token.
 // Returns an error if sending the message fails.
 func SendTelegramMessage(ctx context.Context, botToken string, chatId int, text string) error {
-	return SendTelegramMessageWithKeyboard(ctx, botToken, chatId, text, nil)
+	_, err := NewTelegramClient(botToken).SendMessage(ctx, chatId, text, nil)
+	return err
 }
 
-// SendTelegramMessageWithKeyboard sends a text message to a Telegram chat,
+// SendSilentTelegramMessage is SendTelegramMessage, but when silent is set the message
+// arrives without a notification sound.
+func SendSilentTelegramMessage(ctx context.Context, botToken string, chatId int, text string, silent bool) error {
+	_, err := NewTelegramClient(botToken).send(ctx, models.TelegramResponse{
+		ChatId:              chatId,
+		Text:                text,
+		ParseMode:           "HTML",
+		DisableNotification: silent,
+	})
+	return err
+}
+
+// SendMessage sends a text message to a Telegram chat,
 // optionally including an inline keyboard for user interaction.
 //
-// Returns an error if marshaling the request, sending the HTTP request,
-// or receiving a non-OK response from the Telegram API fails.
-func SendTelegramMessageWithKeyboard(ctx context.Context, botToken string, chatId int, text string, keyboard *models.InlineKeyboardMarkup) error {
-	response := models.TelegramResponse{
+// Returns the ID of the sent message so it can be edited later. Returns an error
+// if marshaling the request, sending the HTTP request, or receiving a non-OK
+// response from the Telegram API fails.
+func (c *TelegramAPIClient) SendMessage(ctx context.Context, chatId int, text string, keyboard *models.InlineKeyboardMarkup) (int, error) {
+	return c.send(ctx, models.TelegramResponse{
 		ChatId:      chatId,
 		Text:        text,
 		ParseMode:   "HTML",
 		ReplyMarkup: keyboard,
-	}
+	})
+}
+
+// send posts a prepared sendMessage request and returns the sent message's ID.
+func (c *TelegramAPIClient) send(ctx context.Context, response models.TelegramResponse) (int, error) {
+	chatId := response.ChatId
 
 	jsonData, err := json.Marshal(response)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s%s/sendMessage", telegramAPIURL, botToken)
+	url := fmt.Sprintf("%s%s/sendMessage", telegramAPIURL, c.botToken)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return 0, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusForbidden {
+		return 0, forbiddenError(chatId, "sendMessage", resp)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API error (status %d)", resp.StatusCode)
+		return 0, newTelegramError("sendMessage", resp)
+	}
+
+	var sent models.SentMessageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sent); err != nil {
+		// the message went out, we just can't reference it later
+		return 0, nil
+	}
+
+	return GetMessageID(&sent.Result), nil
+}
+
+// SendPhoto uploads a PNG image to a Telegram chat with an optional HTML caption.
+//
+// Returns an error if building the multipart request, sending the HTTP request,
+// or receiving a non-OK response from the Telegram API fails.
+func (c *TelegramAPIClient) SendPhoto(ctx context.Context, chatId int, photo []byte, filename, caption string) error {
+	return c.sendFile(ctx, "sendPhoto", "photo", chatId, photo, filename, caption)
+}
+
+// SendDocument uploads a file to a Telegram chat as a document with an optional HTML caption.
+//
+// Returns an error if building the multipart request, sending the HTTP request,
+// or receiving a non-OK response from the Telegram API fails.
+func (c *TelegramAPIClient) SendDocument(ctx context.Context, chatId int, document []byte, filename, caption string) error {
+	return c.sendFile(ctx, "sendDocument", "document", chatId, document, filename, caption)
+}
+
+// sendFile uploads data as the given multipart field of a Bot API send method.
+func (c *TelegramAPIClient) sendFile(ctx context.Context, method, field string, chatId int, data []byte, filename, caption string) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := writer.WriteField("chat_id", strconv.Itoa(chatId)); err != nil {
+		return fmt.Errorf("failed to write chat_id field: %w", err)
+	}
+	if caption != "" {
+		if err := writer.WriteField("caption", caption); err != nil {
+			return fmt.Errorf("failed to write caption field: %w", err)
+		}
+		if err := writer.WriteField("parse_mode", "HTML"); err != nil {
+			return fmt.Errorf("failed to write parse_mode field: %w", err)
+		}
+	}
+
+	part, err := writer.CreateFormFile(field, filename)
+	if err != nil {
+		return fmt.Errorf("failed to create %s field: %w", field, err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", field, err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finalize %s request: %w", method, err)
+	}
+
+	url := fmt.Sprintf("%s%s/%s", telegramAPIURL, c.botToken, method)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return forbiddenError(chatId, method, resp)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newTelegramError(method, resp)
 	}
 
 	return nil
 }
 
-// EditTelegramMessage edits an existing message in a Telegram chat.
+// EditMessage edits an existing message in a Telegram chat.
 //
 // Optionally updates the inline keyboard as well. Returns an error if marshaling
 // the request, sending the HTTP request, or receiving a non-OK response from the
 // Telegram API fails.
-func EditTelegramMessage(ctx context.Context, botToken string, chatId int, messageId int, text string, keyboard *models.InlineKeyboardMarkup) error {
+func (c *TelegramAPIClient) EditMessage(ctx context.Context, chatId int, messageId int, text string, keyboard *models.InlineKeyboardMarkup) error {
 	payload := map[string]interface{}{
 		"chat_id":    chatId,
 		"message_id": messageId,
@@ -80,7 +349,7 @@ func EditTelegramMessage(ctx context.Context, botToken string, chatId int, messa
 		return fmt.Errorf("failed to marshal edit request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s%s/editMessageText", telegramAPIURL, botToken)
+	url := fmt.Sprintf("%s%s/editMessageText", telegramAPIURL, c.botToken)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -88,24 +357,24 @@ func EditTelegramMessage(ctx context.Context, botToken string, chatId int, messa
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send edit request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram edit message API error (status %d)", resp.StatusCode)
+		return newTelegramError("editMessageText", resp)
 	}
 
 	return nil
 }
 
-// EditTelegramMessageKeyboard updates only the inline keyboard of a message.
+// EditMessageKeyboard updates only the inline keyboard of a message.
 //
 // Returns an error if marshaling the request, sending the HTTP request,
 // or receiving a non-OK response from the Telegram API fails.
-func EditTelegramMessageKeyboard(ctx context.Context, botToken string, chatId int, messageId int, keyboard *models.InlineKeyboardMarkup) error {
+func (c *TelegramAPIClient) EditMessageKeyboard(ctx context.Context, chatId int, messageId int, keyboard *models.InlineKeyboardMarkup) error {
 	payload := map[string]interface{}{
 		"chat_id":      chatId,
 		"message_id":   messageId,
@@ -117,7 +386,7 @@ func EditTelegramMessageKeyboard(ctx context.Context, botToken string, chatId in
 		return fmt.Errorf("failed to marshal keyboard edit request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s%s/editMessageReplyMarkup", telegramAPIURL, botToken)
+	url := fmt.Sprintf("%s%s/editMessageReplyMarkup", telegramAPIURL, c.botToken)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -125,24 +394,24 @@ func EditTelegramMessageKeyboard(ctx context.Context, botToken string, chatId in
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send keyboard edit request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram edit keyboard API error (status %d)", resp.StatusCode)
+		return newTelegramError("editMessageReplyMarkup", resp)
 	}
 
 	return nil
 }
 
-// DeleteTelegramMessage deletes a message from a Telegram chat.
+// DeleteMessage deletes a message from a Telegram chat.
 //
 // Returns an error if marshaling the request, sending the HTTP request,
 // or receiving a non-OK response from the Telegram API fails.
-func DeleteTelegramMessage(ctx context.Context, botToken string, chatId int, messageId int) error {
+func (c *TelegramAPIClient) DeleteMessage(ctx context.Context, chatId int, messageId int) error {
 	payload := map[string]interface{}{
 		"chat_id":    chatId,
 		"message_id": messageId,
@@ -153,7 +422,7 @@ func DeleteTelegramMessage(ctx context.Context, botToken string, chatId int, mes
 		return fmt.Errorf("failed to marshal delete request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s%s/deleteMessage", telegramAPIURL, botToken)
+	url := fmt.Sprintf("%s%s/deleteMessage", telegramAPIURL, c.botToken)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -161,14 +430,14 @@ func DeleteTelegramMessage(ctx context.Context, botToken string, chatId int, mes
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send delete request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram delete message API error (status %d)", resp.StatusCode)
+		return newTelegramError("deleteMessage", resp)
 	}
 
 	return nil
@@ -178,9 +447,10 @@ func DeleteTelegramMessage(ctx context.Context, botToken string, chatId int, mes
 // by a button in an inline keyboard.
 //
 // It can optionally show a popup notification (showAlert = true).
-// Returns an error if marshaling the request, sending it, or getting
-// a non-OK response from Telegram fails.
-func AnswerCallbackQuery(ctx context.Context, botToken string, callbackQueryId string, text string, showAlert bool) error {
+// A network error or 5xx is retried once after a short delay. Returns an
+// error if marshaling the request, sending it, or getting a non-OK
+// response from Telegram fails.
+func (c *TelegramAPIClient) AnswerCallbackQuery(ctx context.Context, callbackQueryId string, text string, showAlert bool) error {
 	response := models.AnswerCallbackQuery{
 		CallbackQueryId: callbackQueryId,
 		Text:            text,
@@ -192,27 +462,100 @@ func AnswerCallbackQuery(ctx context.Context, botToken string, callbackQueryId s
 		return fmt.Errorf("failed to marshal callback answer: %w", err)
 	}
 
-	url := fmt.Sprintf("%s%s/answerCallbackQuery", telegramAPIURL, botToken)
+	err = c.answerCallbackQuery(ctx, jsonData)
+	if err == nil || !isTransientTelegramError(ctx, err) {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return err
+	case <-time.After(callbackAnswerRetryDelay):
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	return c.answerCallbackQuery(ctx, jsonData)
+}
+
+// answerCallbackQuery makes one answerCallbackQuery attempt, limited to callbackAnswerTimeout.
+func (c *TelegramAPIClient) answerCallbackQuery(ctx context.Context, jsonData []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, callbackAnswerTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s%s/answerCallbackQuery", telegramAPIURL, c.botToken)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create callback answer request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send callback answer: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram callback answer API error (status %d)", resp.StatusCode)
+		return newTelegramError("answerCallbackQuery", resp)
 	}
 
 	return nil
 }
 
+// isTransientTelegramError reports whether a failed call is worth retrying: a network
+// error or timeout while the caller is still waiting, or a 5xx from Telegram. Rejected
+// requests (4xx, e.g. an expired query) fail the same way again.
+func isTransientTelegramError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var tgErr *TelegramError
+	if errors.As(err, &tgErr) {
+		return tgErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// GetChatMember returns userId's membership in a chat, e.g. to check they're an admin.
+//
+// Returns an error if marshaling the request, sending it, getting a non-OK
+// response from Telegram or decoding the member fails.
+func (c *TelegramAPIClient) GetChatMember(ctx context.Context, chatId, userId int) (*models.ChatMember, error) {
+	jsonData, err := json.Marshal(map[string]any{
+		"chat_id": chatId,
+		"user_id": userId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal chat member request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s%s/getChatMember", telegramAPIURL, c.botToken)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat member request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat member: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newTelegramError("getChatMember", resp)
+	}
+
+	var member models.GetChatMemberResponse
+	if err := json.NewDecoder(resp.Body).Decode(&member); err != nil {
+		return nil, fmt.Errorf("failed to decode chat member: %w", err)
+	}
+
+	return &member.Result, nil
+}
+
 // SetBotCommands sets the list of available commands for the bot.
 //
 // These commands appear in Telegram's command menu. Returns an error if
@@ -224,9 +567,20 @@ func SetBotCommands(ctx context.Context, botToken string) error {
 		{Command: "search", Description: "🔍 Search for anime by name"},
 		{Command: "add", Description: "➕ Add anime to your list"},
 		{Command: "list", Description: "📋 View your anime list"},
+		{Command: "mylists", Description: "📂 View your custom lists"},
 		{Command: "update", Description: "🔄 Update anime status in your list"},
 		{Command: "remove", Description: "🗑 Remove anime from your list"},
 		{Command: "profile", Description: "👤 View your profile and stats"},
+		{Command: "stats", Description: "📊 View your list stats"},
+		{Command: "export", Description: "💾 Download your list as JSON or CSV"},
+		{Command: "card", Description: "🖼 Get your stats as an image"},
+		{Command: "leaderboard", Description: "🏆 Top completers this week"},
+		{Command: "recent", Description: "🔥 Anime most added this week"},
+		{Command: "top", Description: "🏆 Top rated, airing or upcoming anime"},
+		{Command: "recommend", Description: "💡 Anime recommended for you"},
+		{Command: "cancel", Description: "✖️ Cancel the current action"},
+		{Command: "feedback", Description: "💬 Report a bug or suggest something"},
+		{Command: "whoami", Description: "🪪 Show how the bot sees you"},
 		{Command: "help", Description: "❓ Show help and available commands"},
 		{Command: "remind", Description: "⏰ Set reminder for anime"},
 		{Command: "reminders", Description: "📝 View your reminders"},
@@ -248,14 +602,48 @@ func SetBotCommands(ctx context.Context, botToken string) error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := telegramHTTPClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to set bot commands: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("set bot commands API error (status %d)", resp.StatusCode)
+		return newTelegramError("setMyCommands", resp)
+	}
+
+	return nil
+}
+
+// SetWebhook tells Telegram to deliver updates to the given HTTPS URL.
+//
+// Returns an error if marshaling the request, sending the HTTP request,
+// or receiving a non-OK response from the Telegram API fails.
+func SetWebhook(ctx context.Context, botToken, webhookURL string) error {
+	payload := map[string]interface{}{
+		"url": webhookURL,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s%s/setWebhook", telegramAPIURL, botToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := telegramHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newTelegramError("setWebhook", resp)
 	}
 
 	return nil
@@ -275,17 +663,19 @@ func ParseTelegramRequest(r *http.Request) (*models.Update, error) {
 
 // GetMessageID extracts the message ID from a Telegram message object.
 //
-// Currently returns 0 as a placeholder. You must update your models.Message
-// struct to include the message_id field for this to work properly.
+// Returns 0 if the message is nil.
 func GetMessageID(message *models.Message) int {
-	return 0
+	if message == nil {
+		return 0
+	}
+	return message.MessageId
 }
 
 // SendTypingAction sends a "typing..." action to a Telegram chat,
 // indicating the bot is working or processing.
 //
 // Returns an error if marshaling or sending the request fails.
-func SendTypingAction(ctx context.Context, botToken string, chatId int) error {
+func (c *TelegramAPIClient) SendTypingAction(ctx context.Context, chatId int) error {
 	payload := map[string]interface{}{
 		"chat_id": chatId,
 		"action":  "typing",
@@ -296,7 +686,7 @@ func SendTypingAction(ctx context.Context, botToken string, chatId int) error {
 		return fmt.Errorf("failed to marshal typing action: %w", err)
 	}
 
-	url := fmt.Sprintf("%s%s/sendChatAction", telegramAPIURL, botToken)
+	url := fmt.Sprintf("%s%s/sendChatAction", telegramAPIURL, c.botToken)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -304,7 +694,7 @@ func SendTypingAction(ctx context.Context, botToken string, chatId int) error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send typing action: %w", err)
 	}
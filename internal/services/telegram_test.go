@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sletish/internal/models"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTelegramClientSendMessage(t *testing.T) {
+	var got models.TelegramResponse
+	var path string
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Write([]byte(`{"ok":true,"result":{"message_id":7}}`))
+	}))
+
+	id, err := NewTelegramClient("token").SendMessage(context.Background(), 42, "<b>hi</b>", nil)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if id != 7 {
+		t.Errorf("got message ID %d, want 7", id)
+	}
+	if path != "/bottoken/sendMessage" {
+		t.Errorf("got path %q, want /bottoken/sendMessage", path)
+	}
+	if got.ChatId != 42 || got.Text != "<b>hi</b>" || got.ParseMode != "HTML" {
+		t.Errorf("got request %+v, want chat 42 with the HTML text", got)
+	}
+}
+
+func TestTelegramClientSendMessageForbidden(t *testing.T) {
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`))
+	}))
+
+	_, err := NewTelegramClient("token").SendMessage(context.Background(), 42, "hi", nil)
+	if !errors.Is(err, ErrTelegramForbidden) {
+		t.Errorf("got %v, want ErrTelegramForbidden", err)
+	}
+}
+
+func TestSendSilentTelegramMessage(t *testing.T) {
+	var body map[string]any
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+
+	if err := SendSilentTelegramMessage(context.Background(), "token", 42, "hi", true); err != nil {
+		t.Fatalf("SendSilentTelegramMessage failed: %v", err)
+	}
+	if body["disable_notification"] != true || body["parse_mode"] != "HTML" {
+		t.Errorf("got %v, want disable_notification set", body)
+	}
+
+	// left out rather than sent as false
+	if err := SendSilentTelegramMessage(context.Background(), "token", 42, "hi", false); err != nil {
+		t.Fatalf("SendSilentTelegramMessage failed: %v", err)
+	}
+	if _, ok := body["disable_notification"]; ok {
+		t.Errorf("got %v, want no disable_notification", body)
+	}
+}
+
+// shortenCallbackAnswers cuts the callback answer timeout and retry delay for the test.
+func shortenCallbackAnswers(t *testing.T, timeout time.Duration) {
+	t.Helper()
+
+	originalTimeout, originalDelay := callbackAnswerTimeout, callbackAnswerRetryDelay
+	callbackAnswerTimeout, callbackAnswerRetryDelay = timeout, time.Millisecond
+	t.Cleanup(func() { callbackAnswerTimeout, callbackAnswerRetryDelay = originalTimeout, originalDelay })
+}
+
+func TestAnswerCallbackQuery(t *testing.T) {
+	var got models.AnswerCallbackQuery
+	var calls atomic.Int32
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/bottoken/answerCallbackQuery" {
+			t.Errorf("got path %q, want /bottoken/answerCallbackQuery", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+
+	if err := NewTelegramClient("token").AnswerCallbackQuery(context.Background(), "q1", "Done", true); err != nil {
+		t.Fatalf("AnswerCallbackQuery failed: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls, want 1", calls.Load())
+	}
+	if got.CallbackQueryId != "q1" || got.Text != "Done" || !got.ShowAlert {
+		t.Errorf("got request %+v, want q1 answered with a Done alert", got)
+	}
+}
+
+func TestAnswerCallbackQueryRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int // per attempt
+		wantCalls int32
+		wantErr   bool
+	}{
+		{"5xx then ok", []int{http.StatusBadGateway, http.StatusOK}, 2, false},
+		{"5xx twice", []int{http.StatusInternalServerError, http.StatusServiceUnavailable}, 2, true},
+		{"4xx", []int{http.StatusBadRequest}, 1, true},
+		{"429", []int{http.StatusTooManyRequests}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortenCallbackAnswers(t, time.Second)
+
+			var calls atomic.Int32
+			newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1))
+				if n > len(tt.statuses) {
+					t.Errorf("unexpected attempt %d", n)
+					return
+				}
+				w.WriteHeader(tt.statuses[n-1])
+				w.Write([]byte(`{"ok":false,"description":"test"}`))
+			}))
+
+			err := NewTelegramClient("token").AnswerCallbackQuery(context.Background(), "q1", "", false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAnswerCallbackQueryTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	shortenCallbackAnswers(t, timeout)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+
+	start := time.Now()
+	err := NewTelegramClient("token").AnswerCallbackQuery(context.Background(), "q1", "", false)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("got no error from a hanging Telegram")
+	}
+	// a timed out attempt is retried once
+	if calls.Load() != 2 {
+		t.Errorf("made %d calls, want 2", calls.Load())
+	}
+	if elapsed > 10*timeout {
+		t.Errorf("took %v, want each attempt cut off after %v", elapsed, timeout)
+	}
+}
+
+func TestAnswerCallbackQueryDoesNotRetryCancelled(t *testing.T) {
+	shortenCallbackAnswers(t, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	release := make(chan struct{})
+	defer close(release)
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		cancel()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+
+	if err := NewTelegramClient("token").AnswerCallbackQuery(ctx, "q1", "", false); err == nil {
+		t.Fatal("got no error after the caller gave up")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls, want no retry once the caller gave up", calls.Load())
+	}
+}
+
+// recordBlocked sets a blocked handler that records the chats it's called with, until the test ends.
+func recordBlocked(t *testing.T) *[]int {
+	t.Helper()
+
+	var blocked []int
+	original := blockedHandler
+	SetBlockedHandler(func(chatId int) { blocked = append(blocked, chatId) })
+	t.Cleanup(func() { SetBlockedHandler(original) })
+	return &blocked
+}
+
+func TestForbiddenSendReportsBlockedChat(t *testing.T) {
+	sends := map[string]func(*TelegramAPIClient) error{
+		"message": func(c *TelegramAPIClient) error {
+			_, err := c.SendMessage(context.Background(), 42, "hi", nil)
+			return err
+		},
+		"photo": func(c *TelegramAPIClient) error {
+			return c.SendPhoto(context.Background(), 42, []byte("png"), "card.png", "")
+		},
+		"document": func(c *TelegramAPIClient) error {
+			return c.SendDocument(context.Background(), 42, []byte("csv"), "list.csv", "")
+		},
+	}
+
+	for name, send := range sends {
+		t.Run(name, func(t *testing.T) {
+			blocked := recordBlocked(t)
+			status := http.StatusInternalServerError
+			newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+				w.Write([]byte(`{"ok":false,"description":"test"}`))
+			}))
+			client := NewTelegramClient("token")
+
+			// other failures don't mean the user blocked the bot
+			if err := send(client); err == nil || len(*blocked) != 0 {
+				t.Fatalf("got %v and blocked %v for a 500, want an error and nothing blocked", err, *blocked)
+			}
+
+			status = http.StatusForbidden
+			if err := send(client); !errors.Is(err, ErrTelegramForbidden) {
+				t.Errorf("got %v, want ErrTelegramForbidden", err)
+			}
+			if len(*blocked) != 1 || (*blocked)[0] != 42 {
+				t.Errorf("got blocked %v, want chat 42", *blocked)
+			}
+		})
+	}
+}
+
+func TestSetWebhook(t *testing.T) {
+	var got map[string]string
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/setWebhook" {
+			t.Errorf("got path %q, want /bottoken/setWebhook", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+
+	if err := SetWebhook(context.Background(), "token", "https://bot.example.com/hook-a1b2c3"); err != nil {
+		t.Fatalf("SetWebhook failed: %v", err)
+	}
+	if got["url"] != "https://bot.example.com/hook-a1b2c3" {
+		t.Errorf("got url %q, want the configured path", got["url"])
+	}
+}
+
+func TestValidateBotToken(t *testing.T) {
+	tests := []struct {
+		token string
+		valid bool
+	}{
+		{"123456789:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw", true},
+		{"123456789:AAH-dq_TcvCH1vGWJxfSeofSAs0K5PALDs", true},
+		{"", false},
+		{"AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw", false},
+		{"bot:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw", false},
+		{"123456789:short", false},
+		{"123456789:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw\n", false},
+		{"123456789:AAHdqTcvCH1vGWJxf/eofSAs0K5PALDsaw", false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateBotToken(tt.token); (err == nil) != tt.valid {
+			t.Errorf("ValidateBotToken(%q) got %v, want valid: %v", tt.token, err, tt.valid)
+		}
+	}
+}
+
+func TestGetMe(t *testing.T) {
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/getMe" {
+			t.Errorf("got path %q, want /bottoken/getMe", r.URL.Path)
+		}
+		w.Write([]byte(`{"ok":true,"result":{"id":42,"is_bot":true,"first_name":"Sletish","username":"sletish_bot"}}`))
+	}))
+
+	me, err := GetMe(context.Background(), "token")
+	if err != nil {
+		t.Fatalf("GetMe failed: %v", err)
+	}
+	if me.Id != 42 || me.Username != "sletish_bot" {
+		t.Errorf("got %+v, want @sletish_bot", me)
+	}
+}
+
+func TestGetMeRejected(t *testing.T) {
+	tests := []struct {
+		status      int
+		wantInvalid bool
+	}{
+		{http.StatusUnauthorized, true},
+		{http.StatusNotFound, true},
+		{http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(`{"ok":false,"description":"test"}`))
+		}))
+
+		_, err := GetMe(context.Background(), "token")
+		if err == nil {
+			t.Errorf("status %d got no error", tt.status)
+			continue
+		}
+		if errors.Is(err, ErrInvalidBotToken) != tt.wantInvalid {
+			t.Errorf("status %d got %v, want ErrInvalidBotToken: %v", tt.status, err, tt.wantInvalid)
+		}
+	}
+}
+
+func TestTelegramErrorCarriesDescription(t *testing.T) {
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+	}))
+
+	_, err := NewTelegramClient("token").SendMessage(context.Background(), 42, "hi", nil)
+
+	var tgErr *TelegramError
+	if !errors.As(err, &tgErr) {
+		t.Fatalf("got %v, want a TelegramError", err)
+	}
+	if tgErr.Method != "sendMessage" || tgErr.StatusCode != 400 || tgErr.ErrorCode != 400 || tgErr.Description != "Bad Request: chat not found" {
+		t.Errorf("got %+v, want sendMessage's 400 with its description", tgErr)
+	}
+	if want := "telegram sendMessage API error (status 400): Bad Request: chat not found"; err.Error() != want {
+		t.Errorf("got message %q, want %q", err.Error(), want)
+	}
+	if errors.Is(err, ErrTelegramForbidden) || errors.Is(err, ErrInvalidBotToken) {
+		t.Errorf("a 400 matched a sentinel error: %v", err)
+	}
+}
+
+func TestTelegramErrorWithoutBody(t *testing.T) {
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html>Bad Gateway</html>"))
+	}))
+
+	err := NewTelegramClient("token").EditMessage(context.Background(), 42, 1, "hi", nil)
+
+	var tgErr *TelegramError
+	if !errors.As(err, &tgErr) || tgErr.Description != "" {
+		t.Fatalf("got %v, want a TelegramError without a description", err)
+	}
+	if want := "telegram editMessageText API error (status 502)"; err.Error() != want {
+		t.Errorf("got message %q, want %q", err.Error(), want)
+	}
+}
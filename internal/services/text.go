@@ -0,0 +1,11 @@
+package services
+
+// TruncateText shortens text to at most limit characters (runes), adding "..." if it was cut,
+// so a multibyte character (common in Japanese titles) is never split in half.
+func TruncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "..."
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  string
+	}{
+		{"short", "Monster", 10, "Monster"},
+		{"exact", "Monster", 7, "Monster"},
+		{"ascii", "Fullmetal Alchemist", 9, "Fullmetal..."},
+		{"japanese", "鋼の錬金術師 FULLMETAL ALCHEMIST", 6, "鋼の錬金術師..."},
+		{"emoji", "🔥🔥🔥🔥", 2, "🔥🔥..."},
+		{"empty", "", 5, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateText(tt.text, tt.limit)
+			if got != tt.want {
+				t.Errorf("TruncateText(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("TruncateText(%q, %d) split a character: %q", tt.text, tt.limit, got)
+			}
+		})
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	// "database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sletish/internal/models"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -17,17 +22,36 @@ import (
 )
 
 const (
-	userCachePrefix  = "user:info:"
-	userCacheTTL     = 30 * time.Minute
-	animeCachePrefix = "anime:details:"
-	animeCacheTTL    = 1 * time.Hour
+	userCachePrefix      = "user:info:"
+	userCacheTTL         = 30 * time.Minute
+	userEntryCachePrefix = "user:entries:" // hash of the user's list entries, keyed by anime ID
+	userEntryCacheTTL    = 10 * time.Minute
+	animeCachePrefix     = "anime:details:"
+	animeCacheTTL        = 1 * time.Hour
 )
 
+// ErrInvalidUserID is returned for user IDs that can't belong to a real Telegram user.
+var ErrInvalidUserID = errors.New("invalid user ID")
+
+// ErrInvalidTimezone is returned for time zone names that aren't in the IANA database.
+var ErrInvalidTimezone = errors.New("invalid time zone")
+
+// ErrListFull is returned when adding a new entry would take a user past the maximum list size.
+var ErrListFull = errors.New("user list is full")
+
+// ErrNotInUserList is returned when an anime isn't in the user's list.
+var ErrNotInUserList = errors.New("anime not found in user's list")
+
 type UserService struct {
-	db     *pgxpool.Pool
-	redis  *redis.Client
-	logger *logrus.Logger
-	client *Client
+	db          *pgxpool.Pool
+	redis       *redis.Client
+	logger      *logrus.Logger
+	client      *Client
+	maxListSize int  // 0 means unlimited
+	softDelete  bool // archive removed entries instead of deleting them
+	isPurging   atomic.Bool
+	purgeOnce   sync.Once
+	purgeWorker sync.WaitGroup
 }
 
 // NewUserService creates and returns a new UserService.
@@ -41,15 +65,31 @@ func NewUserService(db *pgxpool.Pool, redis *redis.Client, logger *logrus.Logger
 	}
 }
 
+// SetMaxListSize caps how many entries a user's list can hold. Zero or less means unlimited.
+func (s *UserService) SetMaxListSize(size int) {
+	s.maxListSize = max(size, 0)
+}
+
+// MaxListSize returns the configured list size cap, 0 if unlimited.
+func (s *UserService) MaxListSize() int {
+	return s.maxListSize
+}
+
 // EnsureUserExists checks whether a user exists in the database.
-// If the user doesn't exist, it creates a new one. If the username has changed, it updates it.
+// If the user doesn't exist, it creates a new one. If the username or language has changed,
+// it updates them; an empty languageCode leaves the stored one alone.
+// Returns ErrInvalidUserID for non-positive IDs, which only come from malformed updates.
 // Also invalidates the user's cache.
-func (s *UserService) EnsureUserExists(userID, username string) error {
+func (s *UserService) EnsureUserExists(userID, username, languageCode string) error {
 	s.logger.WithFields(logrus.Fields{
 		"user_id":  userID,
 		"username": username,
 	}).Info("Checking if user exists...")
 
+	if id, err := strconv.ParseInt(userID, 10, 64); err != nil || id <= 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidUserID, userID)
+	}
+
 	var exists bool
 	err := s.db.QueryRow(context.Background(), "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists)
 	if err != nil {
@@ -76,7 +116,7 @@ func (s *UserService) EnsureUserExists(userID, username string) error {
 		updateQuery := `
 		UPDATE users
 		SET username = $2
-		WHERE id = $1 AND (username IS NULL OR username != $2)
+		WHERE id = $1 AND username IS DISTINCT FROM $2
 		`
 
 		_, err := s.db.Exec(context.Background(), updateQuery, userID, username)
@@ -85,6 +125,10 @@ func (s *UserService) EnsureUserExists(userID, username string) error {
 		}
 	}
 
+	if err := s.syncLanguage(userID, languageCode); err != nil {
+		return err
+	}
+
 	s.invalidateUserCache(userID)
 	return nil
 }
@@ -141,33 +185,112 @@ func (s *UserService) GetUser(userID string) (*models.AppUser, error) {
 	return &user, nil
 }
 
+// SetShowNSFW sets whether the user wants adult content in search results and details.
+func (s *UserService) SetShowNSFW(userID string, show bool) error {
+	return s.UpdateSetting(userID, models.SettingNSFW, show)
+}
+
+// SetSilentReminders sets whether the user's reminders arrive without a notification sound.
+func (s *UserService) SetSilentReminders(userID string, silent bool) error {
+	return s.UpdateSetting(userID, models.SettingSilent, silent)
+}
+
+// SetPublic sets whether the user appears on the leaderboard.
+func (s *UserService) SetPublic(userID string, public bool) error {
+	return s.UpdateSetting(userID, models.SettingPublic, public)
+}
+
+// SetSearchVerbosity sets how much detail the user's search results show.
+func (s *UserService) SetSearchVerbosity(userID string, verbosity models.SearchVerbosity) error {
+	return s.UpdateSetting(userID, models.SettingVerbosity, verbosity)
+}
+
+// SetNotificationsPaused pauses or resumes background notifications for the user.
+// While paused, due reminders stay pending and are delivered after resuming.
+func (s *UserService) SetNotificationsPaused(userID string, paused bool) error {
+	return s.UpdateSetting(userID, models.SettingNotifications, paused)
+}
+
+// SetAnimeOfTheDay turns the daily anime suggestion on or off. A non-empty timezone
+// (an IANA name such as "Europe/Berlin") also changes the time zone it's sent in.
+func (s *UserService) SetAnimeOfTheDay(userID string, enabled bool, timezone string) error {
+	if timezone != "" {
+		if err := s.UpdateSetting(userID, models.SettingTimezone, timezone); err != nil {
+			return err
+		}
+	}
+
+	return s.UpdateSetting(userID, models.SettingAotd, enabled)
+}
+
+// SetBlocked records whether the user has blocked the bot. Blocked users are skipped
+// by reminders and other background notifications.
+func (s *UserService) SetBlocked(userID string, blocked bool) error {
+	result, err := s.db.Exec(context.Background(), "UPDATE users SET blocked = $2 WHERE id = $1 AND blocked <> $2", userID, blocked)
+	if err != nil {
+		return fmt.Errorf("failed to update blocked flag: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"user_id": userID,
+			"blocked": blocked,
+		}).Info("Updated user blocked flag")
+	}
+
+	return nil
+}
+
 // AddToUserList adds an anime (media) to a user's list with a specific status.
-// If the anime is already in the user's list, it updates the status instead.
+// If the anime is already in the user's list, it updates the status instead; an archived
+// entry is restored that way, keeping its rating and notes.
+// Rating and notes are optional; nil leaves an existing value untouched.
+// started_at is set the first time the status becomes watching and never overwritten;
+// completed_at is set when the status becomes completed and cleared when it leaves it.
 // Automatically fetches or creates the media entry from the Jikan API if not present in the DB.
 // Invalidates user cache after the operation.
-func (s *UserService) AddToUserList(userID string, animeID int, status models.Status) error {
+func (s *UserService) AddToUserList(userID string, animeID int, status models.Status, rating *float64, notes *string) error {
 	s.logger.WithFields(logrus.Fields{
 		"user_id":  userID,
 		"anime_id": animeID,
 		"status":   status,
 	}).Info("Adding anime to user list...")
 
+	if rating != nil && (*rating < models.MinRating || *rating > models.MaxRating) {
+		return fmt.Errorf("invalid rating %.1f: must be between %.0f and %.0f", *rating, models.MinRating, models.MaxRating)
+	}
+
 	media, err := s.getOrCreateMediaByID(animeID)
 	if err != nil {
 		return fmt.Errorf("failed to get/create media: %w", err)
 	}
 
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin user media update: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the user so concurrent adds can't both pass the list size check
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", userID); err != nil {
+		return fmt.Errorf("failed to lock user list: %w", err)
+	}
+
 	// check if user has anime on their list
 	var existingAnimeID int
+	var archived bool
 	checkQuery := `
-	SELECT id
+	SELECT id, deleted_at IS NOT NULL
 	FROM user_media
 	WHERE user_id = $1
 	AND media_id = $2
 	`
 
 	isNewEntry := false
-	err = s.db.QueryRow(context.Background(), checkQuery, userID, media.ID).Scan(&existingAnimeID)
+	err = tx.QueryRow(ctx, checkQuery, userID, media.ID).Scan(&existingAnimeID, &archived)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -177,30 +300,57 @@ func (s *UserService) AddToUserList(userID string, animeID int, status models.St
 		}
 	}
 
+	// adding an archived anime again restores it, keeping its rating, notes and progress,
+	// so it counts against the list size like a new entry
+	if (isNewEntry || archived) && s.maxListSize > 0 {
+		var size int
+		if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM user_media WHERE user_id = $1 AND deleted_at IS NULL", userID).Scan(&size); err != nil {
+			return fmt.Errorf("failed to count user list: %w", err)
+		}
+		if size >= s.maxListSize {
+			return fmt.Errorf("%w: %d entries allowed", ErrListFull, s.maxListSize)
+		}
+	}
+
 	now := time.Now()
 
 	if isNewEntry {
 		insertQuery := `
-			INSERT INTO user_media (user_id, media_id, status, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $4)
+			INSERT INTO user_media (user_id, media_id, status, rating, notes, started_at, completed_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $5, $6,
+				CASE WHEN $3 = 'watching' THEN $4::timestamptz END,
+				CASE WHEN $3 = 'completed' THEN $4::timestamptz END,
+				$4, $4)
 			`
 
-		_, err = s.db.Exec(context.Background(), insertQuery, userID, media.ID, status, now)
+		_, err = tx.Exec(ctx, insertQuery, userID, media.ID, status, now, rating, notes)
 		if err != nil {
 			return fmt.Errorf("failed to insert user media: %w", err)
 		}
-		s.logger.Info("Added anime to user list")
 	} else {
 		updateQuery := `
 			UPDATE user_media
-			SET status = $3, updated_at = $4
+			SET status = $3, updated_at = $4, rating = COALESCE($5, rating), notes = COALESCE($6, notes), deleted_at = NULL,
+				started_at = COALESCE(started_at, CASE WHEN $3 = 'watching' THEN $4::timestamptz END),
+				completed_at = CASE WHEN $3 = 'completed' THEN COALESCE(completed_at, $4::timestamptz) END
 			WHERE user_id = $1 AND media_id = $2
 			`
 
-		_, err = s.db.Exec(context.Background(), updateQuery, userID, media.ID, status, now)
+		_, err = tx.Exec(ctx, updateQuery, userID, media.ID, status, now, rating, notes)
 		if err != nil {
 			return fmt.Errorf("failed to update user media: %w", err)
 		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit user media update: %w", err)
+	}
+
+	if isNewEntry {
+		s.logger.Info("Added anime to user list")
+	} else if archived {
+		s.logger.Info("Restored archived anime to user list")
+	} else {
 		s.logger.Info("Updated anime status in user list")
 	}
 
@@ -226,6 +376,11 @@ func (s *UserService) getOrCreateMediaByID(animeID int) (*models.Media, error) {
 	return s.createMediaFromJikan(*jikanAnime)
 }
 
+// GetMediaByAnimeID returns the stored media record for a MyAnimeList ID, without hitting Jikan.
+func (s *UserService) GetMediaByAnimeID(animeID int) (*models.Media, error) {
+	return s.getMediaByExternalID(strconv.Itoa(animeID))
+}
+
 // getMediaByExternalID retrieves a media record from the database using its external (MyAnimeList) ID.
 // Returns an error if not found.
 func (s *UserService) getMediaByExternalID(externalID string) (*models.Media, error) {
@@ -266,7 +421,8 @@ func (s *UserService) getMediaByExternalID(externalID string) (*models.Media, er
 }
 
 // createMediaFromJikan creates a new media record in the database using data fetched from the Jikan API.
-// It stores the media in the database and caches the raw Jikan response in Redis.
+// It stores the media and its genres in the database and caches the raw Jikan response in Redis.
+// If the media already exists (e.g. added concurrently), the existing row is returned instead.
 func (s *UserService) createMediaFromJikan(jikanAnime models.AnimeData) (*models.Media, error) {
 	externalID := strconv.Itoa(jikanAnime.MalID)
 	title := jikanAnime.Title
@@ -281,14 +437,12 @@ func (s *UserService) createMediaFromJikan(jikanAnime models.AnimeData) (*models
 	if len(jikanAnime.Images.JPG.ImageURL) > 0 {
 		posterURL = jikanAnime.Images.JPG.ImageURL
 	}
-	if len(description) > 1000 {
-		description = description[:1000] + "..."
-	}
 
 	// Insert media record
 	insertQuery := `
-		INSERT INTO media (external_id, title, type, description, release_date, poster_url, rating, created_at)
-		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+		INSERT INTO media (external_id, title, type, description, release_date, poster_url, rating, airing_status, episodes, format, episode_minutes, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NULLIF($9, ''), NULLIF($10, 0), NULLIF($11, ''), NULLIF($12, 0), $8)
+		ON CONFLICT (external_id) DO UPDATE SET title = EXCLUDED.title
 		RETURNING id, external_id, title, type, description, release_date, poster_url, rating, created_at
 	`
 
@@ -298,7 +452,7 @@ func (s *UserService) createMediaFromJikan(jikanAnime models.AnimeData) (*models
 	now := time.Now()
 
 	err := s.db.QueryRow(context.Background(), insertQuery,
-		externalID, title, "anime", description, releaseDate, posterURL, rating, now).Scan(
+		externalID, title, "anime", description, releaseDate, posterURL, rating, now, jikanAnime.Status, jikanAnime.Episodes, jikanAnime.Type, ParseEpisodeMinutes(jikanAnime.Duration)).Scan(
 		&media.ID,
 		&media.ExternalID,
 		&media.Title,
@@ -321,6 +475,10 @@ func (s *UserService) createMediaFromJikan(jikanAnime models.AnimeData) (*models
 		media.Rating = &dbRating.Float64
 	}
 
+	if err := saveMediaGenres(context.Background(), s.db, media.ID, jikanAnime.Genres); err != nil {
+		s.logger.WithError(err).Warn("Failed to save media genres")
+	}
+
 	// Cache anime details
 	if s.redis != nil {
 		cacheKey := animeCachePrefix + externalID
@@ -333,21 +491,21 @@ func (s *UserService) createMediaFromJikan(jikanAnime models.AnimeData) (*models
 	return &media, nil
 }
 
-// invalidateUserCache removes the user's cached profile from Redis, if caching is enabled.
+// invalidateUserCache removes the user's cached profile and list entries from Redis, if caching is enabled.
 // Used after any update to ensure fresh data is fetched on the next request.
 func (s *UserService) invalidateUserCache(userID string) {
 	if s.redis == nil {
 		return
 	}
 
-	cacheKey := userCachePrefix + userID
-	if err := s.redis.Del(context.Background(), cacheKey).Err(); err != nil {
+	if err := s.redis.Del(context.Background(), userCachePrefix+userID, userEntryCachePrefix+userID).Err(); err != nil {
 		s.logger.WithError(err).Warn("Failed to invalidate user cache")
 	}
 }
 
 // RemoveFromUserList deletes a media item from the user's list using the anime ID.
-// Returns an error if the media does not exist in the user's list.
+// With soft delete on, the entry is archived instead, so /undo can bring it back
+// until it's purged. Returns an error if the media does not exist in the user's list.
 // Invalidates user cache after deletion.
 func (s *UserService) RemoveFromUserList(userID string, animeID int) error {
 	s.logger.WithFields(logrus.Fields{
@@ -365,7 +523,17 @@ func (s *UserService) RemoveFromUserList(userID string, animeID int) error {
 	DELETE FROM user_media
 	WHERE user_id = $1
 	AND media_id = $2
+	AND deleted_at IS NULL
 	`
+	if s.softDelete {
+		deleteQuery = `
+		UPDATE user_media
+		SET deleted_at = NOW()
+		WHERE user_id = $1
+		AND media_id = $2
+		AND deleted_at IS NULL
+		`
+	}
 
 	result, err := s.db.Exec(context.Background(), deleteQuery, userID, media.ID)
 	if err != nil {
@@ -383,8 +551,67 @@ func (s *UserService) RemoveFromUserList(userID string, animeID int) error {
 	return nil
 }
 
+// GetUserEntry returns a single anime from the user's list with its media details,
+// or ErrNotInUserList if it isn't in the list.
+// If available, it reads the entry from the user's Redis entry cache, which is
+// dropped together with the profile cache whenever the list changes.
+func (s *UserService) GetUserEntry(ctx context.Context, userID string, animeID int) (*models.UserMediaWithDetails, error) {
+	cacheKey := userEntryCachePrefix + userID
+	field := strconv.Itoa(animeID)
+
+	if s.redis != nil {
+		cached, err := s.redis.HGet(ctx, cacheKey, field).Result()
+		if err == nil {
+			var entry models.UserMediaWithDetails
+			if err := json.Unmarshal([]byte(cached), &entry); err == nil {
+				return &entry, nil
+			}
+			s.logger.WithError(err).Warn("Failed to unmarshal cached user entry")
+		} else if err != redis.Nil {
+			s.logger.WithError(err).Warn("Failed to read from Redis")
+		}
+	}
+
+	query := "SELECT " + userMediaColumns + `
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND m.external_id = $2 AND um.deleted_at IS NULL
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user entry: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanUserMediaRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNotInUserList
+	}
+	entry := &items[0]
+
+	if s.redis != nil {
+		if entryJSON, err := json.Marshal(entry); err == nil {
+			_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HSet(ctx, cacheKey, field, entryJSON)
+				pipe.Expire(ctx, cacheKey, userEntryCacheTTL)
+				return nil
+			})
+			if err != nil {
+				s.logger.WithError(err).Warn("Failed to cache user entry")
+			}
+		}
+	}
+
+	return entry, nil
+}
+
 // UpdateAnimeStatus updates the status (e.g., watching, completed) of a specific anime in the user's list.
 // Returns an error if the anime is not found in the user's list.
+// Sets started_at on the first move to watching and keeps completed_at in step with the status.
 // Invalidates user cache after update.
 func (s *UserService) UpdateAnimeStatus(userID string, animeID int, status models.Status) error {
 	// fetch media record by external id
@@ -395,8 +622,10 @@ func (s *UserService) UpdateAnimeStatus(userID string, animeID int, status model
 
 	query := `
 		UPDATE user_media
-		SET status = $1, updated_at = NOW()
-		WHERE user_id = $2 AND media_id = $3
+		SET status = $1, updated_at = NOW(),
+			started_at = COALESCE(started_at, CASE WHEN $1 = 'watching' THEN NOW() END),
+			completed_at = CASE WHEN $1 = 'completed' THEN COALESCE(completed_at, NOW()) END
+		WHERE user_id = $2 AND media_id = $3 AND deleted_at IS NULL
 	`
 
 	result, err := s.db.Exec(context.Background(), query, status, userID, media.ID)
@@ -413,6 +642,65 @@ func (s *UserService) UpdateAnimeStatus(userID string, animeID int, status model
 	return nil
 }
 
+// MoveStatus changes the given anime in the user's list from one status to another.
+// Entries no longer in the from status are left alone. Returns how many were moved.
+func (s *UserService) MoveStatus(userID string, from, to models.Status, animeIDs []string) (int64, error) {
+	if len(animeIDs) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		UPDATE user_media um
+		SET status = $1, updated_at = NOW(),
+			started_at = COALESCE(um.started_at, CASE WHEN $1 = 'watching' THEN NOW() END),
+			completed_at = CASE WHEN $1 = 'completed' THEN COALESCE(um.completed_at, NOW()) END
+		FROM media m
+		WHERE um.media_id = m.id
+		AND um.user_id = $2 AND um.status = $3 AND um.deleted_at IS NULL
+		AND m.external_id = ANY($4)
+	`
+
+	result, err := s.db.Exec(ctx, query, to, userID, from, animeIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move status: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		s.invalidateUserCache(userID)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// SetProgress records how many episodes of an anime in their list the user has watched.
+func (s *UserService) SetProgress(userID string, animeID, episodes int) error {
+	if episodes < 0 {
+		return fmt.Errorf("invalid episode count %d", episodes)
+	}
+
+	media, err := s.getMediaByExternalID(strconv.Itoa(animeID))
+	if err != nil {
+		return fmt.Errorf("anime not found: %w", err)
+	}
+
+	result, err := s.db.Exec(context.Background(),
+		"UPDATE user_media SET episodes_watched = $1, updated_at = NOW() WHERE user_id = $2 AND media_id = $3 AND deleted_at IS NULL",
+		episodes, userID, media.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update progress: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("anime not found in user's list")
+	}
+
+	s.invalidateUserCache(userID)
+	return nil
+}
+
 func (s *UserService) contextWithTimeout() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), 30*time.Second)
 }
@@ -427,7 +715,7 @@ func (s *UserService) GetUserList(userID string, statusFilter string, page, limi
 
 	// Get total count of user's media for pagination
 	var total int
-	countQuery := "SELECT COUNT(*) FROM user_media WHERE user_id = $1"
+	countQuery := "SELECT COUNT(*) FROM user_media WHERE user_id = $1 AND deleted_at IS NULL"
 	args := []interface{}{userID}
 
 	if statusFilter != "" {
@@ -445,12 +733,10 @@ func (s *UserService) GetUserList(userID string, statusFilter string, page, limi
 	}
 
 	query := `
-		SELECT
-			um.id, um.user_id, um.media_id, um.status, um.rating, um.notes, um.created_at, um.updated_at,
-			m.id, m.external_id, m.title, m.type, m.description, m.release_date, m.poster_url, m.rating, m.created_at
+		SELECT ` + userMediaColumns + `
 		FROM user_media um
 		JOIN media m ON um.media_id = m.id
-		WHERE um.user_id = $1
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL
 	`
 
 	// Append status filter if provided
@@ -468,6 +754,137 @@ func (s *UserService) GetUserList(userID string, statusFilter string, page, limi
 	}
 	defer rows.Close()
 
+	list, err := scanUserMediaRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return list, total, nil
+}
+
+// GetUserListByGenre retrieves the user's list entries whose media is tagged with the given genre.
+// Genre matching is case-insensitive. Returns an empty list for genres we've never seen.
+func (s *UserService) GetUserListByGenre(userID, genre string, page, limit int) ([]models.UserMediaWithDetails, int, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	genreJoin := `
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		JOIN media_genres mg ON mg.media_id = m.id
+		JOIN genres g ON g.id = mg.genre_id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL AND LOWER(g.name) = LOWER($2)
+	`
+
+	var total int
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*)"+genreJoin, userID, genre).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	query := "SELECT " + userMediaColumns + genreJoin +
+		fmt.Sprintf(" ORDER BY um.updated_at DESC LIMIT %d OFFSET %d", limit, (page-1)*limit)
+
+	rows, err := s.db.Query(ctx, query, userID, genre)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	list, err := scanUserMediaRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return list, total, nil
+}
+
+// GetUserListByRating retrieves the entries of a user's list whose personal rating
+// matches the filter, highest rated first. Unrated entries are left out.
+func (s *UserService) GetUserListByRating(userID string, filter models.RatingFilter, page, limit int) ([]models.UserMediaWithDetails, int, error) {
+	// the operator goes into the SQL, so only the known ones get through
+	if !slices.Contains(models.RatingOperators, filter.Op) {
+		return nil, 0, fmt.Errorf("invalid rating operator %q", filter.Op)
+	}
+
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	ratingJoin := `
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL AND um.rating ` + filter.Op + ` $2
+	`
+
+	var total int
+	err := s.db.QueryRow(ctx, "SELECT COUNT(*)"+ratingJoin, userID, filter.Value).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get total count: %w", err)
+	}
+
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	query := "SELECT " + userMediaColumns + ratingJoin +
+		fmt.Sprintf(" ORDER BY um.rating DESC, um.updated_at DESC LIMIT %d OFFSET %d", limit, (page-1)*limit)
+
+	rows, err := s.db.Query(ctx, query, userID, filter.Value)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	list, err := scanUserMediaRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return list, total, nil
+}
+
+// FindInUserListByTitle returns up to limit entries of the user's list whose title
+// contains the given text, case-insensitively. Exact title matches come first.
+func (s *UserService) FindInUserListByTitle(userID, title string, limit int) ([]models.UserMediaWithDetails, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, nil
+	}
+
+	// match the text literally, not as a LIKE pattern
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(title) + "%"
+
+	query := "SELECT " + userMediaColumns + `
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL AND m.title ILIKE $2
+		ORDER BY LOWER(m.title) = LOWER($3) DESC, um.updated_at DESC
+		LIMIT $4
+	`
+
+	rows, err := s.db.Query(ctx, query, userID, pattern, title, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search user list by title: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserMediaRows(rows)
+}
+
+// userMediaColumns is the column list scanned by scanUserMediaRows, expects user_media as um and media as m.
+const userMediaColumns = `
+			um.id, um.user_id, um.media_id, um.status, um.rating, um.notes, um.tags, um.episodes_watched, um.started_at, um.completed_at, um.created_at, um.updated_at, um.deleted_at,
+			m.id, m.external_id, m.title, m.type, m.description, m.release_date, m.poster_url, m.rating, m.created_at`
+
+// scanUserMediaRows scans rows selected with userMediaColumns into UserMediaWithDetails.
+func scanUserMediaRows(rows pgx.Rows) ([]models.UserMediaWithDetails, error) {
 	var list []models.UserMediaWithDetails
 
 	for rows.Next() {
@@ -476,6 +893,10 @@ func (s *UserService) GetUserList(userID string, statusFilter string, page, limi
 		var mRating pgtype.Float8
 		var releaseDate pgtype.Text
 		var notes pgtype.Text
+		var episodesWatched pgtype.Int4
+		var startedAt pgtype.Timestamptz
+		var completedAt pgtype.Timestamptz
+		var deletedAt pgtype.Timestamptz
 
 		err := rows.Scan(
 			// UserMedia fields
@@ -485,8 +906,13 @@ func (s *UserService) GetUserList(userID string, statusFilter string, page, limi
 			&item.UserMedia.Status,
 			&umRating,
 			&notes,
+			&item.UserMedia.Tags,
+			&episodesWatched,
+			&startedAt,
+			&completedAt,
 			&item.UserMedia.CreatedAt,
 			&item.UserMedia.UpdatedAt,
+			&deletedAt,
 
 			// Media fields
 			&item.Media.ID,
@@ -500,7 +926,7 @@ func (s *UserService) GetUserList(userID string, statusFilter string, page, limi
 			&item.Media.CreatedAt,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan row: %w", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
 		// Assign values from pgx nullable types
@@ -510,6 +936,18 @@ func (s *UserService) GetUserList(userID string, statusFilter string, page, limi
 		if notes.Valid {
 			item.UserMedia.Notes = notes.String
 		}
+		if episodesWatched.Valid {
+			item.UserMedia.EpisodesWatched = int(episodesWatched.Int32)
+		}
+		if startedAt.Valid {
+			item.UserMedia.StartedAt = &startedAt.Time
+		}
+		if completedAt.Valid {
+			item.UserMedia.CompletedAt = &completedAt.Time
+		}
+		if deletedAt.Valid {
+			item.UserMedia.DeletedAt = &deletedAt.Time
+		}
 
 		if mRating.Valid {
 			item.Media.Rating = &mRating.Float64
@@ -522,5 +960,9 @@ func (s *UserService) GetUserList(userID string, statusFilter string, page, limi
 		list = append(list, item)
 	}
 
-	return list, total, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return list, nil
 }
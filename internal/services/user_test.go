@@ -0,0 +1,468 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sletish/internal/models"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAddToUserListConcurrentAddsRespectMaxSize(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	s.SetMaxListSize(1)
+
+	// create the media up front so the adds race on the list, not on Jikan
+	for _, id := range []int{1, 2, 3, 4} {
+		if _, err := s.getOrCreateMediaByID(id); err != nil {
+			t.Fatalf("failed to create media %d: %v", id, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.AddToUserList("1", i+1, models.StatusWatching, nil, nil)
+		}()
+	}
+	wg.Wait()
+
+	added := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			added++
+		case !errors.Is(err, ErrListFull):
+			t.Errorf("add %d: got %v, want nil or ErrListFull", i+1, err)
+		}
+	}
+	if added != 1 {
+		t.Errorf("%d adds succeeded, want 1", added)
+	}
+
+	entries, total, err := s.GetUserList("1", "", 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get list: %v", err)
+	}
+	if total != 1 || len(entries) != 1 {
+		t.Errorf("got %d entries, want the list capped at 1", total)
+	}
+}
+
+func TestAddToUserListRestoresArchivedEntry(t *testing.T) {
+	s := newArchivingUserService(t)
+
+	rating, notes := 8.0, "rewatch with friends"
+	if err := s.AddToUserList("1", 1, models.StatusWatching, &rating, &notes); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if err := s.RemoveFromUserList("1", 1); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	if err := s.AddToUserList("1", 1, models.StatusCompleted, nil, nil); err != nil {
+		t.Fatalf("failed to add again: %v", err)
+	}
+
+	entry, err := s.GetUserEntry(context.Background(), "1", 1)
+	if err != nil {
+		t.Fatalf("failed to get entry: %v", err)
+	}
+	if entry.UserMedia.DeletedAt != nil {
+		t.Error("entry is still archived")
+	}
+	if entry.UserMedia.Status != models.StatusCompleted {
+		t.Errorf("got status %q, want completed", entry.UserMedia.Status)
+	}
+	if entry.UserMedia.Rating != rating || entry.UserMedia.Notes != notes {
+		t.Errorf("got rating %v and notes %q, want the archived %v and %q kept", entry.UserMedia.Rating, entry.UserMedia.Notes, rating, notes)
+	}
+}
+
+func TestAddToUserListRestoreRespectsMaxSize(t *testing.T) {
+	s := newArchivingUserService(t)
+
+	if err := s.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if err := s.RemoveFromUserList("1", 1); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	if err := s.AddToUserList("1", 2, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+
+	s.SetMaxListSize(1)
+	if err := s.AddToUserList("1", 1, models.StatusWatching, nil, nil); !errors.Is(err, ErrListFull) {
+		t.Errorf("got %v restoring into a full list, want ErrListFull", err)
+	}
+}
+
+func TestGetUserListByRating(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	ratings := map[int]float64{1: 9, 2: 8, 3: 7.5, 4: 5}
+	for id, rating := range ratings {
+		if err := s.AddToUserList("1", id, models.StatusCompleted, &rating, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", id, err)
+		}
+	}
+	if err := s.AddToUserList("1", 5, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add unrated: %v", err)
+	}
+
+	tests := []struct {
+		filter models.RatingFilter
+		want   []string // external IDs, highest rated first
+	}{
+		{models.RatingFilter{Op: ">=", Value: 8}, []string{"1", "2"}},
+		{models.RatingFilter{Op: ">", Value: 8}, []string{"1"}},
+		{models.RatingFilter{Op: "<=", Value: 7.5}, []string{"3", "4"}},
+		{models.RatingFilter{Op: "<", Value: 7.5}, []string{"4"}},
+		{models.RatingFilter{Op: "=", Value: 7.5}, []string{"3"}},
+		{models.RatingFilter{Op: ">=", Value: 0}, []string{"1", "2", "3", "4"}},
+		{models.RatingFilter{Op: "=", Value: 10}, nil},
+	}
+
+	for _, tt := range tests {
+		list, total, err := s.GetUserListByRating("1", tt.filter, 1, 10)
+		if err != nil {
+			t.Fatalf("%s failed: %v", tt.filter, err)
+		}
+		var got []string
+		for _, entry := range list {
+			got = append(got, entry.Media.ExternalID)
+		}
+		if !slices.Equal(got, tt.want) || total != len(tt.want) {
+			t.Errorf("%s got %v of %d, want %v", tt.filter, got, total, tt.want)
+		}
+	}
+
+	// paged like the rest of the list
+	list, total, err := s.GetUserListByRating("1", models.RatingFilter{Op: ">=", Value: 0}, 2, 3)
+	if err != nil || total != 4 || len(list) != 1 || list[0].Media.ExternalID != "4" {
+		t.Errorf("page 2 got %+v of %d (err %v), want only the lowest rated", list, total, err)
+	}
+}
+
+func TestGetUserListByRatingRejectsUnknownOperator(t *testing.T) {
+	// no database, the operator goes into the SQL so it's checked first
+	s := NewUserService(nil, nil, newTestLogger(), nil)
+
+	for _, op := range []string{"!=", "; DROP TABLE users", ""} {
+		if _, _, err := s.GetUserListByRating("1", models.RatingFilter{Op: op, Value: 8}, 1, 10); err == nil {
+			t.Errorf("operator %q got no error", op)
+		}
+	}
+}
+
+func TestEnsureUserExistsRejectsInvalidIDs(t *testing.T) {
+	// no database, invalid IDs must be turned away before any query
+	s := NewUserService(nil, nil, newTestLogger(), nil)
+
+	for _, id := range []string{"0", "-5", "", "abc", "12x"} {
+		if err := s.EnsureUserExists(id, "tester", ""); !errors.Is(err, ErrInvalidUserID) {
+			t.Errorf("EnsureUserExists(%q) got %v, want ErrInvalidUserID", id, err)
+		}
+	}
+}
+
+func TestUsersTableRejectsInvalidTelegramIDs(t *testing.T) {
+	s := newTestUserService(t)
+	ctx := context.Background()
+
+	insert := "INSERT INTO users (id, username, platform, created_at, updated_at) VALUES ($1, 'junk', $2, NOW(), NOW())"
+	if _, err := s.db.Exec(ctx, insert, "0", "telegram"); err == nil {
+		t.Error("inserted a telegram user with ID 0")
+	}
+	if _, err := s.db.Exec(ctx, insert, "42", "telegram"); err != nil {
+		t.Errorf("failed to insert a valid user: %v", err)
+	}
+}
+
+func TestStartedAtIsSetOnFirstWatch(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	ctx := context.Background()
+
+	startedAt := func() *time.Time {
+		t.Helper()
+		entry, err := s.GetUserEntry(ctx, "1", 1)
+		if err != nil {
+			t.Fatalf("failed to get entry: %v", err)
+		}
+		return entry.UserMedia.StartedAt
+	}
+
+	if err := s.AddToUserList("1", 1, models.StatusWatchlist, nil, nil); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if got := startedAt(); got != nil {
+		t.Fatalf("got started_at %v for a watchlist entry, want none", got)
+	}
+
+	if err := s.UpdateAnimeStatus("1", 1, models.StatusWatching); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	first := startedAt()
+	if first == nil {
+		t.Fatal("got no started_at after moving to watching")
+	}
+
+	// later updates, watching again included, keep the first time
+	for _, status := range []models.Status{models.StatusCompleted, models.StatusWatching} {
+		if err := s.UpdateAnimeStatus("1", 1, status); err != nil {
+			t.Fatalf("failed to update status: %v", err)
+		}
+		if got := startedAt(); got == nil || !got.Equal(*first) {
+			t.Errorf("got started_at %v after moving to %s, want %v kept", got, status, first)
+		}
+	}
+	if err := s.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add again: %v", err)
+	}
+	if got := startedAt(); got == nil || !got.Equal(*first) {
+		t.Errorf("got started_at %v after adding again, want %v kept", got, first)
+	}
+}
+
+func TestStartedAtIsSetWhenAddedAsWatching(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	if err := s.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+
+	entry, err := s.GetUserEntry(context.Background(), "1", 1)
+	if err != nil {
+		t.Fatalf("failed to get entry: %v", err)
+	}
+	if entry.UserMedia.StartedAt == nil {
+		t.Error("got no started_at for an entry added as watching")
+	}
+}
+
+func TestGetUserEntry(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	newTestUser(t, s, "2")
+	ctx := context.Background()
+
+	rating, notes := 9.0, "rewatch"
+	if err := s.AddToUserList("1", 1, models.StatusCompleted, &rating, &notes); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+
+	entry, err := s.GetUserEntry(ctx, "1", 1)
+	if err != nil {
+		t.Fatalf("GetUserEntry failed: %v", err)
+	}
+	if entry.Media.ExternalID != "1" || entry.UserMedia.Status != models.StatusCompleted || entry.UserMedia.Rating != rating || entry.UserMedia.Notes != notes {
+		t.Errorf("got %+v, want the completed entry with its rating and notes", entry)
+	}
+
+	if _, err := s.GetUserEntry(ctx, "1", 2); !errors.Is(err, ErrNotInUserList) {
+		t.Errorf("got %v for an anime not in the list, want ErrNotInUserList", err)
+	}
+	if _, err := s.GetUserEntry(ctx, "2", 1); !errors.Is(err, ErrNotInUserList) {
+		t.Errorf("got %v for another user's entry, want ErrNotInUserList", err)
+	}
+}
+
+func TestGetUserEntryCacheDroppedOnChange(t *testing.T) {
+	s := newTestUserService(t)
+	client, server := newTestRedis(t)
+	s.redis = client
+	newTestUser(t, s, "1")
+	ctx := context.Background()
+
+	if err := s.AddToUserList("1", 1, models.StatusWatching, nil, nil); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if _, err := s.GetUserEntry(ctx, "1", 1); err != nil {
+		t.Fatalf("GetUserEntry failed: %v", err)
+	}
+	if cached := server.HGet(userEntryCachePrefix+"1", "1"); cached == "" {
+		t.Fatal("entry wasn't cached")
+	}
+
+	// not-found isn't cached, the anime can be added right after
+	if _, err := s.GetUserEntry(ctx, "1", 2); !errors.Is(err, ErrNotInUserList) {
+		t.Fatalf("got %v, want ErrNotInUserList", err)
+	}
+	if cached := server.HGet(userEntryCachePrefix+"1", "2"); cached != "" {
+		t.Errorf("got %q cached for an anime not in the list, want nothing", cached)
+	}
+
+	if err := s.UpdateAnimeStatus("1", 1, models.StatusCompleted); err != nil {
+		t.Fatalf("failed to update status: %v", err)
+	}
+	entry, err := s.GetUserEntry(ctx, "1", 1)
+	if err != nil {
+		t.Fatalf("GetUserEntry failed: %v", err)
+	}
+	if entry.UserMedia.Status != models.StatusCompleted {
+		t.Errorf("got status %q after updating, want the cached entry dropped", entry.UserMedia.Status)
+	}
+}
+
+func TestGetUserEntryReadsCache(t *testing.T) {
+	// no database, so the entry can only come from the cache
+	client, server := newTestRedis(t)
+	s := NewUserService(nil, client, newTestLogger(), nil)
+
+	server.HSet(userEntryCachePrefix+"1", "5114", `{"user_media":{"status":"watching"},"media":{"external_id":"5114"}}`)
+
+	entry, err := s.GetUserEntry(context.Background(), "1", 5114)
+	if err != nil {
+		t.Fatalf("GetUserEntry failed: %v", err)
+	}
+	if entry.Media.ExternalID != "5114" || entry.UserMedia.Status != models.StatusWatching {
+		t.Errorf("got %+v, want the cached watching entry", entry)
+	}
+}
+
+func TestMoveStatusOnlyMovesFilteredEntries(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+	ctx := context.Background()
+
+	for id, status := range map[int]models.Status{1: models.StatusWatchlist, 2: models.StatusWatchlist, 3: models.StatusWatching} {
+		if err := s.AddToUserList("1", id, status, nil, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", id, err)
+		}
+	}
+
+	// 2 isn't asked for and 3 isn't on the watchlist
+	moved, err := s.MoveStatus("1", models.StatusWatchlist, models.StatusCompleted, []string{"1", "3"})
+	if err != nil {
+		t.Fatalf("MoveStatus failed: %v", err)
+	}
+	if moved != 1 {
+		t.Errorf("moved %d, want 1", moved)
+	}
+
+	want := map[int]models.Status{1: models.StatusCompleted, 2: models.StatusWatchlist, 3: models.StatusWatching}
+	for id, status := range want {
+		entry, err := s.GetUserEntry(ctx, "1", id)
+		if err != nil {
+			t.Fatalf("failed to get entry %d: %v", id, err)
+		}
+		if entry.UserMedia.Status != status {
+			t.Errorf("entry %d got status %q, want %q", id, entry.UserMedia.Status, status)
+		}
+		if id == 1 && entry.UserMedia.CompletedAt == nil {
+			t.Error("got no completed_at after moving to completed")
+		}
+	}
+
+	if moved, err := s.MoveStatus("1", models.StatusWatchlist, models.StatusCompleted, nil); err != nil || moved != 0 {
+		t.Errorf("no IDs got %d moved (err %v), want 0", moved, err)
+	}
+}
+
+func TestBlockedUsersGetNoReminders(t *testing.T) {
+	users := newTestUserService(t)
+	id := newDueReminder(t, users)
+
+	var calls atomic.Int32
+	newTestTelegram(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	service := NewReminderService(users.db, newTestLogger(), nil, "test", nil, time.Hour)
+
+	if err := users.SetBlocked("1", true); err != nil {
+		t.Fatalf("SetBlocked failed: %v", err)
+	}
+	service.processDueReminders()
+	if calls.Load() != 0 {
+		t.Fatalf("sent %d reminders to a user who blocked the bot, want none", calls.Load())
+	}
+
+	if err := users.SetBlocked("1", false); err != nil {
+		t.Fatalf("SetBlocked failed: %v", err)
+	}
+	service.processDueReminders()
+	if sent, _, _ := reminderState(t, users, id); !sent || calls.Load() != 1 {
+		t.Errorf("got sent %v after %d sends, want the reminder delivered once unblocked", sent, calls.Load())
+	}
+}
+
+func TestFindInUserListByTitle(t *testing.T) {
+	s := newTestUserService(t,
+		models.AnimeData{MalID: 1, Title: "Steins;Gate", Type: "TV"},
+		models.AnimeData{MalID: 2, Title: "Steins;Gate 0", Type: "TV"},
+		models.AnimeData{MalID: 3, Title: "100% Pascal-sensei", Type: "TV"},
+		models.AnimeData{MalID: 4, Title: "Steins;Gate: The Movie", Type: "Movie"},
+	)
+	newTestUser(t, s, "1")
+	newTestUser(t, s, "2")
+	for _, id := range []int{1, 2, 3} {
+		if err := s.AddToUserList("1", id, models.StatusWatching, nil, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", id, err)
+		}
+	}
+	// only the user's own list is searched
+	s.AddToUserList("2", 4, models.StatusWatching, nil, nil)
+
+	tests := []struct {
+		title string
+		want  []string
+	}{
+		{"steins;gate", []string{"Steins;Gate", "Steins;Gate 0"}}, // exact match first
+		{"GATE 0", []string{"Steins;Gate 0"}},
+		{"100%", []string{"100% Pascal-sensei"}},
+		{"%", []string{"100% Pascal-sensei"}}, // literal, not a wildcard
+		{"_", nil},
+		{"movie", nil},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		matches, err := s.FindInUserListByTitle("1", tt.title, 10)
+		if err != nil {
+			t.Fatalf("FindInUserListByTitle(%q) failed: %v", tt.title, err)
+		}
+		var got []string
+		for _, m := range matches {
+			got = append(got, m.Media.Title)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("FindInUserListByTitle(%q) got %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSetSearchVerbosity(t *testing.T) {
+	s := newTestUserService(t)
+	newTestUser(t, s, "1")
+
+	settings, err := s.GetSettings("1")
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if settings.SearchVerbosity != models.VerbosityNormal {
+		t.Errorf("got %q by default, want normal", settings.SearchVerbosity)
+	}
+
+	if err := s.SetSearchVerbosity("1", models.VerbosityDetailed); err != nil {
+		t.Fatalf("SetSearchVerbosity failed: %v", err)
+	}
+	if settings, err := s.GetSettings("1"); err != nil || settings.SearchVerbosity != models.VerbosityDetailed {
+		t.Errorf("got %+v (err %v), want detailed", settings, err)
+	}
+
+	if err := s.SetSearchVerbosity("1", "loud"); err == nil {
+		t.Error("got no error for an unknown verbosity")
+	}
+}
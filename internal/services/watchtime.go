@@ -0,0 +1,148 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sletish/internal/models"
+	"sort"
+	"strconv"
+)
+
+// Jikan formats whose runtime is a single piece rather than per episode.
+const formatMovie = "Movie"
+
+// defaultEpisodeMinutes stands in for media whose runtime isn't known yet,
+// by format. A movie's entry is its whole runtime.
+var defaultEpisodeMinutes = map[string]int{
+	"TV":         24,
+	"ONA":        24,
+	"OVA":        30,
+	"Special":    15,
+	"TV Special": 45,
+	"Movie":      100,
+	"Music":      4,
+}
+
+// fallbackEpisodeMinutes is used when neither the runtime nor the format is known.
+const fallbackEpisodeMinutes = 24
+
+// durationPart matches the pieces of a Jikan duration like "1 hr 50 min" or "24 min per ep".
+var durationPart = regexp.MustCompile(`(\d+)\s*(hr|min|sec)`)
+
+// ParseEpisodeMinutes turns a Jikan duration into whole minutes, 0 if it can't be read.
+// Seconds only count when there are no minutes, so a short music video isn't 0.
+func ParseEpisodeMinutes(duration string) int {
+	var minutes, seconds int
+	for _, match := range durationPart.FindAllStringSubmatch(duration, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		switch match[2] {
+		case "hr":
+			minutes += n * 60
+		case "min":
+			minutes += n
+		case "sec":
+			seconds += n
+		}
+	}
+	if minutes == 0 && seconds > 0 {
+		return 1
+	}
+	return minutes
+}
+
+// watchEntry is one list entry's contribution to watch time.
+type watchEntry struct {
+	format         string
+	status         models.Status
+	episodes       int // episodes the user watched
+	totalEpisodes  int
+	episodeMinutes int // 0 when unknown
+}
+
+// minutes is how long the entry took to watch. Movies count their full runtime once,
+// everything else counts the episodes watched; a completed entry without progress
+// counts all its episodes.
+func (e watchEntry) minutes() int {
+	perEpisode := e.episodeMinutes
+	if perEpisode <= 0 {
+		perEpisode = defaultEpisodeMinutes[e.format]
+	}
+	if perEpisode <= 0 {
+		perEpisode = fallbackEpisodeMinutes
+	}
+
+	if e.format == formatMovie {
+		if e.status == models.StatusCompleted || e.episodes > 0 {
+			return perEpisode
+		}
+		return 0
+	}
+
+	episodes := e.episodes
+	if episodes == 0 && e.status == models.StatusCompleted {
+		episodes = e.totalEpisodes
+	}
+	return episodes * perEpisode
+}
+
+// WatchTime estimates the user's total watch time in minutes, broken down by format,
+// largest first. Media saved before formats were kept is counted as "Unknown".
+func (s *UserService) WatchTime(userID string) (int, []models.FormatWatchTime, error) {
+	ctx, cancel := s.contextWithTimeout()
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(m.format, ''), um.status, COALESCE(um.episodes_watched, 0),
+			COALESCE(m.episodes, 0), COALESCE(m.episode_minutes, 0)
+		FROM user_media um
+		JOIN media m ON um.media_id = m.id
+		WHERE um.user_id = $1 AND um.deleted_at IS NULL
+	`
+
+	rows, err := s.db.Query(ctx, query, userID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to query watch time: %w", err)
+	}
+	defer rows.Close()
+
+	byFormat := make(map[string]int)
+	total := 0
+	for rows.Next() {
+		var entry watchEntry
+		if err := rows.Scan(&entry.format, &entry.status, &entry.episodes, &entry.totalEpisodes, &entry.episodeMinutes); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan watch time: %w", err)
+		}
+
+		minutes := entry.minutes()
+		if minutes == 0 {
+			continue
+		}
+
+		format := entry.format
+		if format == "" {
+			format = "Unknown"
+		}
+		byFormat[format] += minutes
+		total += minutes
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("error iterating watch time: %w", err)
+	}
+
+	breakdown := make([]models.FormatWatchTime, 0, len(byFormat))
+	for format, minutes := range byFormat {
+		breakdown = append(breakdown, models.FormatWatchTime{Format: format, Minutes: minutes})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Minutes != breakdown[j].Minutes {
+			return breakdown[i].Minutes > breakdown[j].Minutes
+		}
+		return breakdown[i].Format < breakdown[j].Format
+	})
+
+	return total, breakdown, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"reflect"
+	"sletish/internal/models"
+	"testing"
+)
+
+func TestParseEpisodeMinutes(t *testing.T) {
+	tests := []struct {
+		duration string
+		want     int
+	}{
+		{"24 min per ep", 24},
+		{"1 hr 50 min", 110},
+		{"2 hr", 120},
+		{"1 hr 5 min per ep", 65},
+		{"45 sec", 1},
+		{"3 min 30 sec", 3},
+		{"Unknown", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseEpisodeMinutes(tt.duration); got != tt.want {
+			t.Errorf("ParseEpisodeMinutes(%q) got %d, want %d", tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestWatchEntryMinutes(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry watchEntry
+		want  int
+	}{
+		{"tv progress", watchEntry{format: "TV", status: models.StatusWatching, episodes: 3, totalEpisodes: 12, episodeMinutes: 23}, 69},
+		{"tv completed without progress", watchEntry{format: "TV", status: models.StatusCompleted, totalEpisodes: 12, episodeMinutes: 24}, 288},
+		{"tv runtime unknown", watchEntry{format: "TV", status: models.StatusWatching, episodes: 2}, 48},
+		{"movie completed", watchEntry{format: "Movie", status: models.StatusCompleted, totalEpisodes: 1, episodeMinutes: 110}, 110},
+		{"movie progress counts once", watchEntry{format: "Movie", status: models.StatusWatching, episodes: 1, episodeMinutes: 110}, 110},
+		{"movie not started", watchEntry{format: "Movie", status: models.StatusWatchlist, episodeMinutes: 110}, 0},
+		{"movie runtime unknown", watchEntry{format: "Movie", status: models.StatusCompleted}, 100},
+		{"ova", watchEntry{format: "OVA", status: models.StatusCompleted, totalEpisodes: 2}, 60},
+		{"format unknown", watchEntry{status: models.StatusWatching, episodes: 1}, fallbackEpisodeMinutes},
+		{"watchlist", watchEntry{format: "TV", status: models.StatusWatchlist, totalEpisodes: 12, episodeMinutes: 24}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.entry.minutes(); got != tt.want {
+			t.Errorf("%s got %d minutes, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWatchTimeMixedFormats(t *testing.T) {
+	s := newTestUserService(t,
+		models.AnimeData{MalID: 1, Title: "Series", Type: "TV", Episodes: 12, Duration: "24 min per ep"},
+		models.AnimeData{MalID: 2, Title: "Film", Type: "Movie", Episodes: 1, Duration: "1 hr 50 min"},
+		models.AnimeData{MalID: 3, Title: "Extras", Type: "OVA", Episodes: 4, Duration: "30 min per ep"},
+		models.AnimeData{MalID: 4, Title: "Later", Type: "Movie", Episodes: 1, Duration: "2 hr"},
+		models.AnimeData{MalID: 5, Title: "Airing", Type: "TV"},
+	)
+	newTestUser(t, s, "1")
+
+	entries := map[int]models.Status{
+		1: models.StatusCompleted,
+		2: models.StatusCompleted,
+		3: models.StatusWatching,
+		4: models.StatusWatchlist,
+		5: models.StatusWatching,
+	}
+	for id, status := range entries {
+		if err := s.AddToUserList("1", id, status, nil, nil); err != nil {
+			t.Fatalf("failed to add %d: %v", id, err)
+		}
+	}
+	for id, episodes := range map[int]int{3: 2, 5: 3} {
+		if err := s.SetProgress("1", id, episodes); err != nil {
+			t.Fatalf("SetProgress %d failed: %v", id, err)
+		}
+	}
+
+	total, breakdown, err := s.WatchTime("1")
+	if err != nil {
+		t.Fatalf("WatchTime failed: %v", err)
+	}
+
+	// 12×24 + 3×24 for TV, the film once, 2×30 for the OVA and nothing for the unwatched movie
+	if total != 530 {
+		t.Errorf("got %d minutes, want 530", total)
+	}
+	want := []models.FormatWatchTime{{Format: "TV", Minutes: 360}, {Format: "Movie", Minutes: 110}, {Format: "OVA", Minutes: 60}}
+	if !reflect.DeepEqual(breakdown, want) {
+		t.Errorf("got %+v, want %+v", breakdown, want)
+	}
+
+	if total, breakdown, err := s.WatchTime("2"); err != nil || total != 0 || len(breakdown) != 0 {
+		t.Errorf("empty list got %d minutes and %+v (err %v), want nothing", total, breakdown, err)
+	}
+}
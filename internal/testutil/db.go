@@ -0,0 +1,98 @@
+// Package testutil has helpers shared by the packages' tests.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sletish/migrations"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// testDBLockID keeps tests in different packages, which go test runs in parallel,
+// from using the shared test database at the same time.
+const testDBLockID = 8_410_271_994
+
+// DB returns a pool for the Postgres database in TEST_DATABASE_URL, migrated and with
+// every table emptied. Tests using it are skipped when TEST_DATABASE_URL isn't set.
+//
+// The database is wiped, never point TEST_DATABASE_URL at one holding real data.
+func DB(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping database test")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	// held on its own connection until the test is done
+	lock, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire test database connection: %v", err)
+	}
+	if _, err := lock.Exec(ctx, "SELECT pg_advisory_lock($1)", testDBLockID); err != nil {
+		lock.Release()
+		t.Fatalf("failed to lock test database: %v", err)
+	}
+	t.Cleanup(func() {
+		lock.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", testDBLockID)
+		lock.Release()
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	if err := migrations.Run(ctx, pool, logger); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	if err := truncateAll(ctx, pool); err != nil {
+		t.Fatalf("failed to empty test database: %v", err)
+	}
+
+	return pool
+}
+
+// truncateAll empties every table but the migration version.
+func truncateAll(ctx context.Context, pool *pgxpool.Pool) error {
+	rows, err := pool.Query(ctx, `
+		SELECT quote_ident(tablename)
+		FROM pg_tables
+		WHERE schemaname = 'public' AND tablename <> 'schema_migrations'
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	if len(tables) == 0 {
+		return nil
+	}
+
+	_, err = pool.Exec(ctx, "TRUNCATE "+strings.Join(tables, ", ")+" RESTART IDENTITY CASCADE")
+	return err
+}
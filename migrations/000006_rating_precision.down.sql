@@ -0,0 +1,4 @@
+-- Revert rating precision
+ALTER TABLE media ALTER COLUMN rating TYPE DECIMAL(3, 2);
+
+ALTER TABLE user_media ALTER COLUMN rating TYPE DECIMAL(3, 2);
@@ -0,0 +1,4 @@
+-- DECIMAL(3, 2) tops out at 9.99, so a rating of 10 was rejected
+ALTER TABLE user_media ALTER COLUMN rating TYPE DECIMAL(4, 2);
+
+ALTER TABLE media ALTER COLUMN rating TYPE DECIMAL(4, 2);
@@ -0,0 +1,9 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_media_genres_genre_id;
+
+DROP INDEX IF EXISTS idx_genres_name_lower;
+
+-- Drop tables
+DROP TABLE IF EXISTS media_genres;
+
+DROP TABLE IF EXISTS genres;
@@ -0,0 +1,22 @@
+-- Create genres table
+CREATE TABLE IF NOT EXISTS genres (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL
+);
+
+-- Create media genres join table
+CREATE TABLE IF NOT EXISTS media_genres (
+    media_id INTEGER NOT NULL REFERENCES media (id) ON DELETE CASCADE,
+    genre_id INTEGER NOT NULL REFERENCES genres (id) ON DELETE CASCADE,
+    PRIMARY KEY (media_id, genre_id)
+);
+
+-- Create indexes for genre lookups
+CREATE INDEX IF NOT EXISTS idx_genres_name_lower ON genres (LOWER(name));
+
+CREATE INDEX IF NOT EXISTS idx_media_genres_genre_id ON media_genres (genre_id);
+
+-- Add comments for documentation
+COMMENT ON TABLE genres IS 'Genre names as reported by the source API';
+
+COMMENT ON TABLE media_genres IS 'Links media to their genres';
@@ -0,0 +1,2 @@
+-- Drop adult content opt-in
+ALTER TABLE users DROP COLUMN IF EXISTS show_nsfw;
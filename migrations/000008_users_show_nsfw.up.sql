@@ -0,0 +1,4 @@
+-- Add adult content opt-in to users
+ALTER TABLE users ADD COLUMN IF NOT EXISTS show_nsfw BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN users.show_nsfw IS 'Whether the user opted in to adult (Rx) search results';
@@ -0,0 +1,3 @@
+-- Drop constraints
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS check_users_telegram_id;
@@ -0,0 +1,11 @@
+-- Remove junk users created from malformed updates (e.g. id "0")
+DELETE FROM users
+WHERE
+    platform = 'telegram'
+    AND id !~ '^[1-9][0-9]*$';
+
+-- Telegram user IDs are always positive integers
+ALTER TABLE users ADD CONSTRAINT check_users_telegram_id CHECK (
+    platform <> 'telegram'
+    OR id ~ '^[1-9][0-9]*$'
+);
@@ -0,0 +1,17 @@
+-- Create feedback table
+CREATE TABLE IF NOT EXISTS feedback (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes for feedback table
+CREATE INDEX IF NOT EXISTS idx_feedback_user_id ON feedback (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_feedback_created_at ON feedback (created_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE feedback IS 'Bug reports and suggestions sent with /feedback';
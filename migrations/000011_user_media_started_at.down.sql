@@ -0,0 +1,2 @@
+-- Drop started watching date
+ALTER TABLE user_media DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,4 @@
+-- Track when the user actually started watching, separate from when it was added
+ALTER TABLE user_media ADD COLUMN IF NOT EXISTS started_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN user_media.started_at IS 'When the status first became watching, NULL if it never has';
@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_user_media_completed_at;
+
+-- Drop columns
+ALTER TABLE user_media DROP COLUMN IF EXISTS completed_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_public;
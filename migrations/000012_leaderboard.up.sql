@@ -0,0 +1,21 @@
+-- Leaderboard opt-in
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_public BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN users.is_public IS 'Whether the user opted in to appear on the leaderboard';
+
+-- Track when an entry was (last) completed
+ALTER TABLE user_media ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+
+-- Best guess for existing completed entries
+UPDATE user_media
+SET
+    completed_at = updated_at
+WHERE
+    status = 'completed'
+    AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_media_completed_at ON user_media (completed_at)
+WHERE
+    completed_at IS NOT NULL;
+
+COMMENT ON COLUMN user_media.completed_at IS 'When the status last became completed, NULL if not completed';
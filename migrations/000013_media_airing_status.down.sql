@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_media_airing_status;
+
+-- Drop columns
+ALTER TABLE media DROP COLUMN IF EXISTS airing_checked_at;
+
+ALTER TABLE media DROP COLUMN IF EXISTS airing_status;
@@ -0,0 +1,10 @@
+-- Last known airing status from Jikan, e.g. "Currently Airing"
+ALTER TABLE media ADD COLUMN IF NOT EXISTS airing_status VARCHAR(50);
+
+ALTER TABLE media ADD COLUMN IF NOT EXISTS airing_checked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_media_airing_status ON media (airing_status);
+
+COMMENT ON COLUMN media.airing_status IS 'Last known airing status from Jikan';
+
+COMMENT ON COLUMN media.airing_checked_at IS 'When the airing status was last re-checked';
@@ -0,0 +1,6 @@
+-- Drop delivery failure tracking
+ALTER TABLE reminders DROP COLUMN IF EXISTS failed;
+
+ALTER TABLE reminders DROP COLUMN IF EXISTS last_error;
+
+ALTER TABLE reminders DROP COLUMN IF EXISTS failure_count;
@@ -0,0 +1,12 @@
+-- Track failed deliveries so permanently failing reminders stop retrying
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS failure_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+ALTER TABLE reminders ADD COLUMN IF NOT EXISTS failed BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN reminders.failure_count IS 'How many delivery attempts have failed';
+
+COMMENT ON COLUMN reminders.last_error IS 'Error from the last failed delivery attempt';
+
+COMMENT ON COLUMN reminders.failed IS 'Whether delivery was given up on';
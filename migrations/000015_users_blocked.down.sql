@@ -0,0 +1,2 @@
+-- Drop blocked flag
+ALTER TABLE users DROP COLUMN IF EXISTS blocked;
@@ -0,0 +1,4 @@
+-- Users who blocked the bot, skipped by background notifications
+ALTER TABLE users ADD COLUMN IF NOT EXISTS blocked BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN users.blocked IS 'Whether the user blocked the bot, cleared on /start';
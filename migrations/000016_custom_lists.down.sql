@@ -0,0 +1,4 @@
+-- Drop tables
+DROP TABLE IF EXISTS custom_list_items;
+
+DROP TABLE IF EXISTS custom_lists;
@@ -0,0 +1,29 @@
+-- Create custom lists table
+CREATE TABLE IF NOT EXISTS custom_lists (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- List names are unique per user, ignoring case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_lists_user_name ON custom_lists (user_id, LOWER(name));
+
+-- Create custom list items table
+CREATE TABLE IF NOT EXISTS custom_list_items (
+    list_id INTEGER NOT NULL REFERENCES custom_lists (id) ON DELETE CASCADE,
+    media_id INTEGER NOT NULL REFERENCES media (id) ON DELETE CASCADE,
+    added_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (list_id, media_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_custom_list_items_media_id ON custom_list_items (media_id);
+
+-- Add comments for documentation
+COMMENT ON TABLE custom_lists IS 'User-named lists, separate from the status list';
+
+COMMENT ON TABLE custom_list_items IS 'Media in custom lists';
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_user_media_tags;
+
+-- Drop tags
+ALTER TABLE user_media DROP COLUMN IF EXISTS tags;
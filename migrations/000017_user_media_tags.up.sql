@@ -0,0 +1,6 @@
+-- Free-form labels on list entries
+ALTER TABLE user_media ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_user_media_tags ON user_media USING GIN (tags);
+
+COMMENT ON COLUMN user_media.tags IS 'User-defined lowercase labels, e.g. rewatch';
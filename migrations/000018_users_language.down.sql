@@ -0,0 +1,2 @@
+-- Drop language code
+ALTER TABLE users DROP COLUMN IF EXISTS language_code;
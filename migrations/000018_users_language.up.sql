@@ -0,0 +1,4 @@
+-- Telegram client language, used to localize background notifications
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language_code TEXT;
+
+COMMENT ON COLUMN users.language_code IS 'IETF language tag reported by Telegram, e.g. en or pt-br';
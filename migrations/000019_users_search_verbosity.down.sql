@@ -0,0 +1,3 @@
+-- Drop search verbosity
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_users_search_verbosity;
+ALTER TABLE users DROP COLUMN IF EXISTS search_verbosity;
@@ -0,0 +1,8 @@
+-- How much detail search results show
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_verbosity TEXT NOT NULL DEFAULT 'normal';
+
+ALTER TABLE users ADD CONSTRAINT check_users_search_verbosity CHECK (
+    search_verbosity IN ('compact', 'normal', 'detailed')
+);
+
+COMMENT ON COLUMN users.search_verbosity IS 'compact, normal or detailed search results, set with /verbosity';
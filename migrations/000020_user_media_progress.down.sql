@@ -0,0 +1,3 @@
+-- Drop watch progress
+ALTER TABLE user_media DROP CONSTRAINT IF EXISTS check_user_media_episodes_watched;
+ALTER TABLE user_media DROP COLUMN IF EXISTS episodes_watched;
@@ -0,0 +1,8 @@
+-- Episodes the user has watched of each entry, NULL until they report progress
+ALTER TABLE user_media ADD COLUMN IF NOT EXISTS episodes_watched INTEGER;
+
+ALTER TABLE user_media ADD CONSTRAINT check_user_media_episodes_watched CHECK (
+    episodes_watched IS NULL OR episodes_watched >= 0
+);
+
+COMMENT ON COLUMN user_media.episodes_watched IS 'Episodes watched, set with /progress';
@@ -0,0 +1,2 @@
+-- Drop notifications paused flag
+ALTER TABLE users DROP COLUMN IF EXISTS notifications_paused;
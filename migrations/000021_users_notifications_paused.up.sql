@@ -0,0 +1,4 @@
+-- Users can pause background notifications without losing pending reminders
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notifications_paused BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN users.notifications_paused IS 'Set with /pause, cleared with /resume';
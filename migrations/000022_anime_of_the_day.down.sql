@@ -0,0 +1,6 @@
+-- Drop anime of the day
+DROP TABLE IF EXISTS aotd_history;
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
+
+ALTER TABLE users DROP COLUMN IF EXISTS aotd_enabled;
@@ -0,0 +1,21 @@
+-- Opt-in daily anime suggestion, sent in the user's own time zone
+ALTER TABLE users ADD COLUMN IF NOT EXISTS aotd_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Suggestions already sent, so the same anime isn't suggested again too soon
+CREATE TABLE IF NOT EXISTS aotd_history (
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    external_id VARCHAR(255) NOT NULL,
+    sent_on DATE NOT NULL,
+    PRIMARY KEY (user_id, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_aotd_history_user_sent_on ON aotd_history (user_id, sent_on);
+
+-- Add comments for documentation
+COMMENT ON COLUMN users.aotd_enabled IS 'Set with /aotd on, cleared with /aotd off';
+
+COMMENT ON COLUMN users.timezone IS 'IANA time zone name, e.g. Europe/Berlin';
+
+COMMENT ON COLUMN aotd_history.sent_on IS 'Date the suggestion was sent, in the user''s time zone';
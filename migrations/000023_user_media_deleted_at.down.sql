@@ -0,0 +1,6 @@
+-- Drop archived entries along with the column
+DELETE FROM user_media WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_user_media_archived;
+
+ALTER TABLE user_media DROP COLUMN IF EXISTS deleted_at;
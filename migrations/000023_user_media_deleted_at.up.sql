@@ -0,0 +1,8 @@
+-- Removed entries are archived instead of deleted when soft delete is on, and purged later
+ALTER TABLE user_media ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_user_media_archived ON user_media (user_id, deleted_at)
+WHERE
+    deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN user_media.deleted_at IS 'When the entry was archived by /remove, NULL for live entries';
@@ -0,0 +1,2 @@
+-- Drop silent reminders
+ALTER TABLE users DROP COLUMN IF EXISTS silent_reminders;
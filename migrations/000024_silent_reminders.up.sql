@@ -0,0 +1,4 @@
+-- Users can have reminders arrive without a notification sound
+ALTER TABLE users ADD COLUMN IF NOT EXISTS silent_reminders BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN users.silent_reminders IS 'Set with /silent on, reminders are sent with disable_notification';
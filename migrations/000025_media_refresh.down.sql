@@ -0,0 +1,4 @@
+-- Drop media refresh columns
+ALTER TABLE media DROP COLUMN IF EXISTS refreshed_at;
+
+ALTER TABLE media DROP COLUMN IF EXISTS episodes;
@@ -0,0 +1,8 @@
+-- Media metadata is re-fetched from Jikan once it's old, see /refreshmedia
+ALTER TABLE media ADD COLUMN IF NOT EXISTS episodes INTEGER;
+
+ALTER TABLE media ADD COLUMN IF NOT EXISTS refreshed_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN media.episodes IS 'Episode count from Jikan, NULL while unknown';
+
+COMMENT ON COLUMN media.refreshed_at IS 'When the metadata was last re-fetched, NULL if never since it was created';
@@ -0,0 +1,4 @@
+-- Drop media runtime columns
+ALTER TABLE media DROP COLUMN IF EXISTS episode_minutes;
+
+ALTER TABLE media DROP COLUMN IF EXISTS format;
@@ -0,0 +1,8 @@
+-- Jikan's format and runtime, so watch time can tell a movie from a TV episode
+ALTER TABLE media ADD COLUMN IF NOT EXISTS format VARCHAR(20);
+
+ALTER TABLE media ADD COLUMN IF NOT EXISTS episode_minutes INTEGER;
+
+COMMENT ON COLUMN media.format IS 'Jikan type, e.g. TV, Movie, OVA, Special';
+
+COMMENT ON COLUMN media.episode_minutes IS 'Minutes per episode, the full runtime for movies, NULL while unknown';
@@ -0,0 +1,30 @@
+-- Move preferences back onto users
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+ADD COLUMN IF NOT EXISTS language_code TEXT,
+ADD COLUMN IF NOT EXISTS search_verbosity TEXT NOT NULL DEFAULT 'normal',
+ADD COLUMN IF NOT EXISTS show_nsfw BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS is_public BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS notifications_paused BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS aotd_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS silent_reminders BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users ADD CONSTRAINT check_users_search_verbosity CHECK (
+    search_verbosity IN ('compact', 'normal', 'detailed')
+);
+
+UPDATE users u
+SET
+    timezone = s.timezone,
+    language_code = s.language_code,
+    search_verbosity = s.search_verbosity,
+    show_nsfw = s.show_nsfw,
+    is_public = s.is_public,
+    notifications_paused = s.notifications_paused,
+    aotd_enabled = s.aotd_enabled,
+    silent_reminders = s.silent_reminders
+FROM user_settings s
+WHERE
+    s.user_id = u.id;
+
+DROP TABLE IF EXISTS user_settings;
@@ -0,0 +1,73 @@
+-- Per-user preferences, moved out of users into their own table
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id VARCHAR(255) PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    language_code TEXT,
+    search_verbosity TEXT NOT NULL DEFAULT 'normal',
+    show_nsfw BOOLEAN NOT NULL DEFAULT FALSE,
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    notifications_paused BOOLEAN NOT NULL DEFAULT FALSE,
+    aotd_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    silent_reminders BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP
+    WITH
+        TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE user_settings ADD CONSTRAINT check_user_settings_search_verbosity CHECK (
+    search_verbosity IN ('compact', 'normal', 'detailed')
+);
+
+-- Carry over existing preferences
+INSERT INTO
+    user_settings (
+        user_id,
+        timezone,
+        language_code,
+        search_verbosity,
+        show_nsfw,
+        is_public,
+        notifications_paused,
+        aotd_enabled,
+        silent_reminders
+    )
+SELECT
+    id,
+    timezone,
+    language_code,
+    search_verbosity,
+    show_nsfw,
+    is_public,
+    notifications_paused,
+    aotd_enabled,
+    silent_reminders
+FROM users
+ON CONFLICT (user_id) DO NOTHING;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_users_search_verbosity;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone,
+DROP COLUMN IF EXISTS language_code,
+DROP COLUMN IF EXISTS search_verbosity,
+DROP COLUMN IF EXISTS show_nsfw,
+DROP COLUMN IF EXISTS is_public,
+DROP COLUMN IF EXISTS notifications_paused,
+DROP COLUMN IF EXISTS aotd_enabled,
+DROP COLUMN IF EXISTS silent_reminders;
+
+-- Background workers filter on these
+CREATE INDEX IF NOT EXISTS idx_user_settings_aotd_enabled ON user_settings (user_id)
+WHERE
+    aotd_enabled = TRUE;
+
+CREATE INDEX IF NOT EXISTS idx_user_settings_is_public ON user_settings (user_id)
+WHERE
+    is_public = TRUE;
+
+-- Add comments for documentation
+COMMENT ON TABLE user_settings IS 'Per-user preferences; users without a row use the column defaults';
+
+COMMENT ON COLUMN user_settings.timezone IS 'IANA time zone name, e.g. Europe/Berlin';
+
+COMMENT ON COLUMN user_settings.language_code IS 'IETF language tag reported by Telegram, e.g. en or pt-br';
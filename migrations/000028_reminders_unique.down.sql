@@ -0,0 +1,2 @@
+-- Drop pending reminder uniqueness
+DROP INDEX IF EXISTS idx_reminders_pending_unique;
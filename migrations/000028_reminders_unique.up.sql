@@ -0,0 +1,25 @@
+-- Drop duplicate pending reminders, keeping the oldest, so the unique index can be built
+DELETE FROM reminders r
+USING reminders d
+WHERE
+    r.user_id = d.user_id
+    AND r.media_id = d.media_id
+    AND date_trunc('minute', r.remind_at) = date_trunc('minute', d.remind_at)
+    AND r.sent = false
+    AND r.failed = false
+    AND d.sent = false
+    AND d.failed = false
+    AND r.id > d.id;
+
+UPDATE reminders
+SET
+    remind_at = date_trunc('minute', remind_at)
+WHERE
+    sent = false
+    AND failed = false;
+
+-- A user can only have one pending reminder per anime at a given time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reminders_pending_unique ON reminders (user_id, media_id, remind_at)
+WHERE
+    sent = false
+    AND failed = false;
@@ -0,0 +1,2 @@
+-- Drop watch queue
+DROP TABLE IF EXISTS watch_queue;
@@ -0,0 +1,19 @@
+-- Create watch queue table, the user's own "watch next" order
+CREATE TABLE IF NOT EXISTS watch_queue (
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    media_id INTEGER NOT NULL REFERENCES media (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL CHECK (position > 0),
+    added_at TIMESTAMP
+    WITH
+        TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (user_id, media_id),
+        -- deferred so positions can be shifted within a transaction
+        CONSTRAINT watch_queue_user_position_key UNIQUE (user_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE INDEX IF NOT EXISTS idx_watch_queue_media_id ON watch_queue (media_id);
+
+-- Add comments for documentation
+COMMENT ON TABLE watch_queue IS 'Anime the user queued to watch next, in order';
+
+COMMENT ON COLUMN watch_queue.position IS '1 is next up; positions run 1..n without gaps';
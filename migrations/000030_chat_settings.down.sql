@@ -0,0 +1,10 @@
+-- Drop chat settings and the shared lists
+DROP TABLE IF EXISTS chat_settings;
+
+DELETE FROM users WHERE platform = 'telegram_chat';
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_users_telegram_chat_id;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_users_platform;
+
+ALTER TABLE users ADD CONSTRAINT check_users_platform CHECK (platform IN ('telegram'));
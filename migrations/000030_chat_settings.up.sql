@@ -0,0 +1,27 @@
+-- Shared group lists are owned by a users row for the chat itself
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_users_platform;
+
+ALTER TABLE users ADD CONSTRAINT check_users_platform CHECK (platform IN ('telegram', 'telegram_chat'));
+
+-- Group chat IDs are always negative integers, so they never collide with user IDs
+ALTER TABLE users ADD CONSTRAINT check_users_telegram_chat_id CHECK (
+    platform <> 'telegram_chat'
+    OR id ~ '^-[1-9][0-9]*$'
+);
+
+-- Create chat settings table
+CREATE TABLE IF NOT EXISTS chat_settings (
+    chat_id VARCHAR(255) PRIMARY KEY,
+    chat_mode VARCHAR(16) NOT NULL DEFAULT 'personal',
+    updated_by VARCHAR(255) REFERENCES users (id) ON DELETE SET NULL,
+    updated_at TIMESTAMP
+    WITH
+        TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE chat_settings ADD CONSTRAINT check_chat_settings_chat_mode CHECK (chat_mode IN ('personal', 'shared'));
+
+-- Add comments for documentation
+COMMENT ON TABLE chat_settings IS 'Per-chat preferences for group chats';
+
+COMMENT ON COLUMN chat_settings.chat_mode IS 'personal: members use their own lists; shared: list commands use the chat''s list';
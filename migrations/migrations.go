@@ -0,0 +1,150 @@
+// Package migrations embeds the SQL schema migrations and applies them on startup.
+//
+// Files follow golang-migrate's naming (000001_name.up.sql / .down.sql) and the
+// applied version is tracked in the same schema_migrations table, so the migrate
+// CLI keeps working against databases migrated here and vice versa.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed *.up.sql
+var files embed.FS
+
+// advisoryLockID keeps concurrently starting instances from migrating at the same time.
+const advisoryLockID = 8_410_271_993
+
+type migration struct {
+	version uint64
+	name    string
+}
+
+// Run applies every embedded up migration newer than the database's current version,
+// each in its own transaction. Running it again once up to date does nothing.
+func Run(ctx context.Context, db *pgxpool.Pool, logger *logrus.Logger) error {
+	pending, err := list()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockID)
+
+	if _, err := conn.Exec(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current uint64
+	var dirty bool
+	err = conn.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&current, &dirty)
+	if err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("database schema is dirty at version %d, fix it manually before starting", current)
+	}
+
+	applied := 0
+	for _, m := range pending {
+		if m.version <= current {
+			continue
+		}
+
+		if err := apply(ctx, conn, m); err != nil {
+			return err
+		}
+
+		logger.WithFields(logrus.Fields{
+			"version": m.version,
+			"name":    m.name,
+		}).Info("Applied migration")
+		applied++
+	}
+
+	if applied > 0 {
+		logger.WithField("count", applied).Info("Database migrations complete")
+	} else {
+		logger.Debug("Database schema is up to date")
+	}
+
+	return nil
+}
+
+func apply(ctx context.Context, conn *pgxpool.Conn, m migration) error {
+	sql, err := files.ReadFile(m.name)
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %w", m.name, err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.name, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// no arguments, so this goes over the simple protocol and files can hold several statements
+	if _, err := tx.Exec(ctx, string(sql)); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m.name, err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return fmt.Errorf("failed to clear schema version: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", m.version); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", m.name, err)
+	}
+
+	return nil
+}
+
+// list returns the embedded up migrations in version order.
+func list() ([]migration, error) {
+	names, err := fs.Glob(files, "*.up.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	var migrations []migration
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no version prefix", name)
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %w", name, err)
+		}
+
+		migrations = append(migrations, migration{version: version, name: name})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+//go:embed *.down.sql
+var downFiles embed.FS
+
+func TestListIsOrderedAndPaired(t *testing.T) {
+	migrations, err := list()
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for i, m := range migrations {
+		// versions are contiguous, so a skipped or reused number shows up here
+		if m.version != uint64(i+1) {
+			t.Errorf("migration %s has version %d, want %d", m.name, m.version, i+1)
+		}
+	}
+
+	downs, err := fs.Glob(downFiles, "*.down.sql")
+	if err != nil {
+		t.Fatalf("failed to list down migrations: %v", err)
+	}
+	have := make(map[string]bool)
+	for _, name := range downs {
+		have[name] = true
+	}
+	for _, m := range migrations {
+		if down := strings.TrimSuffix(m.name, ".up.sql") + ".down.sql"; !have[down] {
+			t.Errorf("migration %s has no %s", m.name, down)
+		}
+	}
+}
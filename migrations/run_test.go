@@ -0,0 +1,48 @@
+package migrations_test
+
+import (
+	"context"
+	"io"
+	"sletish/internal/testutil"
+	"sletish/migrations"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRunTwiceOnAFreshDatabase(t *testing.T) {
+	db := testutil.DB(t)
+	ctx := context.Background()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// start over from an empty schema, as on a first deploy
+	if _, err := db.Exec(ctx, "DROP SCHEMA public CASCADE; CREATE SCHEMA public"); err != nil {
+		t.Fatalf("failed to reset schema: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := migrations.Run(ctx, db, logger); err != nil {
+			t.Fatalf("run %d failed: %v", i+1, err)
+		}
+	}
+
+	for _, table := range []string{"users", "media", "user_media", "reminders"} {
+		var exists bool
+		if err := db.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", "public."+table).Scan(&exists); err != nil {
+			t.Fatalf("failed to check table %s: %v", table, err)
+		}
+		if !exists {
+			t.Errorf("table %s wasn't created", table)
+		}
+	}
+
+	var versions int
+	var dirty bool
+	if err := db.QueryRow(ctx, "SELECT COUNT(*), bool_or(dirty) FROM schema_migrations").Scan(&versions, &dirty); err != nil {
+		t.Fatalf("failed to read schema version: %v", err)
+	}
+	if versions != 1 || dirty {
+		t.Errorf("got %d version rows (dirty %v), want one clean version", versions, dirty)
+	}
+}